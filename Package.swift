@@ -2,16 +2,16 @@
 import PackageDescription
 
 let package = Package(
-    name: "TreeSitterHTML",
+    name: "TreeSitterHTMLMustache",
     products: [
-        .library(name: "TreeSitterHTML", targets: ["TreeSitterHTML"]),
+        .library(name: "TreeSitterHTMLMustache", targets: ["TreeSitterHTMLMustache"]),
     ],
     dependencies: [
         .package(url: "https://github.com/tree-sitter/swift-tree-sitter", from: "0.8.0"),
     ],
     targets: [
         .target(
-            name: "TreeSitterHTML",
+            name: "TreeSitterHTMLMustache",
             dependencies: [],
             path: ".",
             sources: [
@@ -25,12 +25,12 @@ let package = Package(
             cSettings: [.headerSearchPath("src")]
         ),
         .testTarget(
-            name: "TreeSitterHTMLTests",
+            name: "TreeSitterHTMLMustacheTests",
             dependencies: [
-                "SwiftTreeSitter",
-                "TreeSitterHTML",
+                .product(name: "SwiftTreeSitter", package: "swift-tree-sitter"),
+                "TreeSitterHTMLMustache",
             ],
-            path: "bindings/swift/TreeSitterHTMLTests"
+            path: "bindings/swift/TreeSitterHTMLMustacheTests"
         )
     ],
     cLanguageStandard: .c11
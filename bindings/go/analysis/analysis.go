@@ -0,0 +1,26 @@
+// Package analysis provides static analysis helpers for htmlmustache
+// templates built on the tree-sitter parse tree.
+package analysis
+
+import (
+	"errors"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+)
+
+// parse parses src with a fresh htmlmustache parser. The caller must close
+// the returned tree.
+func parse(src []byte) (*tree_sitter.Tree, error) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		return nil, err
+	}
+	tree := parser.Parse(src, nil)
+	if tree == nil {
+		return nil, errors.New("analysis: parse failed")
+	}
+	return tree, nil
+}
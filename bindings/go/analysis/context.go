@@ -0,0 +1,385 @@
+package analysis
+
+import (
+	"bytes"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// ContextKind describes what the source at an offset is part of.
+type ContextKind int
+
+const (
+	// InText is text content, including whitespace between nodes.
+	InText ContextKind = iota
+	// InTagName is the name of a start or end tag.
+	InTagName
+	// InAttributeName is an attribute name, or the space in a start tag
+	// where one can be written.
+	InAttributeName
+	// InAttributeValue is an attribute value, outside mustache tags.
+	InAttributeValue
+	// InInterpolation is the name of {{name}} or {{{name}}}.
+	InInterpolation
+	// InSectionName is the name of {{#name}}, {{^name}} or {{/name}}.
+	InSectionName
+	// InPartialName is the name of {{> name}}.
+	InPartialName
+	// InComment is an HTML or mustache comment.
+	InComment
+)
+
+func (k ContextKind) String() string {
+	switch k {
+	case InText:
+		return "text"
+	case InTagName:
+		return "tag name"
+	case InAttributeName:
+		return "attribute name"
+	case InAttributeValue:
+		return "attribute value"
+	case InInterpolation:
+		return "interpolation"
+	case InSectionName:
+		return "section name"
+	case InPartialName:
+		return "partial name"
+	case InComment:
+		return "comment"
+	}
+	return "unknown"
+}
+
+// ScopeKind is the kind of construct that encloses an offset.
+type ScopeKind int
+
+const (
+	ElementScope ScopeKind = iota
+	SectionScope
+	InvertedSectionScope
+)
+
+// Scope is an element or section that encloses an offset.
+type Scope struct {
+	Kind ScopeKind
+	// Name is the tag name of an element or the name of a section.
+	Name      string
+	StartByte uint
+}
+
+// Context describes the source at an offset, for completion engines.
+type Context struct {
+	Kind ContextKind
+	// StartByte and EndByte delimit the name or value the offset is in; both
+	// are the offset when there is none, as in text.
+	StartByte uint
+	EndByte   uint
+	// Prefix is the part of that name or value before the offset: what has
+	// been typed so far.
+	Prefix string
+	// TagName is the tag the offset is in, for tag names, attribute names
+	// and attribute values.
+	TagName string
+	// AttributeName is the attribute whose value holds the offset.
+	AttributeName string
+	// Scopes are the enclosing elements and sections, outermost first.
+	Scopes []Scope
+}
+
+// ContextAt describes the source of src at offset, the position of a cursor.
+// A cursor right after a name is considered in it, so that the name can be
+// completed. Incomplete templates are supported: an unclosed {{ opens a
+// mustache tag up to the offset.
+func ContextAt(src []byte, offset uint) Context {
+	if offset > uint(len(src)) {
+		offset = uint(len(src))
+	}
+	ctx := Context{StartByte: offset, EndByte: offset}
+	tree, err := parse(src)
+	if err != nil {
+		return ctx
+	}
+	defer tree.Close()
+	root := tree.RootNode()
+	ctx.Scopes = scopesAt(root, src, offset)
+
+	if mustacheContext(&ctx, src, offset, DelimitersAt(DelimiterRegions(root, src), offset)) {
+		return ctx
+	}
+	start := offset
+	if start > 0 {
+		start--
+	}
+	leaf := root.DescendantForByteRange(start, offset)
+	if leaf != nil {
+		htmlContext(&ctx, leaf, src, offset)
+	}
+	return ctx
+}
+
+// mustacheContext fills in ctx if offset is inside a mustache tag, which it
+// finds by text so that tags still being typed are recognized. delimiters
+// are the ones active at offset.
+func mustacheContext(ctx *Context, src []byte, offset uint, delimiters Delimiters) bool {
+	open := bytes.LastIndex(src[:offset], []byte(delimiters.Open))
+	if open < 0 || bytes.Contains(src[open+len(delimiters.Open):offset], []byte(delimiters.Close)) {
+		return false
+	}
+	start := uint(open + len(delimiters.Open))
+	ctx.Kind = InInterpolation
+	if start < offset {
+		switch src[start] {
+		case '#', '^', '/':
+			ctx.Kind = InSectionName
+			start++
+		case '>':
+			ctx.Kind = InPartialName
+			start++
+		case '!':
+			ctx.Kind = InComment
+			start++
+		case '{', '&':
+			start++
+		}
+	}
+	for start < offset && (src[start] == ' ' || src[start] == '\t') {
+		start++
+	}
+	end := offset
+	for end < uint(len(src)) && !strings.ContainsRune(" \t\r\n}", rune(src[end])) && !bytes.HasPrefix(src[end:], []byte(delimiters.Close)) {
+		end++
+	}
+	if ctx.Kind == InComment {
+		start, end = offset, offset
+	}
+	ctx.StartByte, ctx.EndByte = start, end
+	ctx.Prefix = string(src[start:offset])
+	return true
+}
+
+// htmlContext fills in ctx from leaf, the smallest node ending at or
+// spanning offset.
+func htmlContext(ctx *Context, leaf *tree_sitter.Node, src []byte, offset uint) {
+	if leaf.EndByte() == offset && leaf.ChildCount() == 0 {
+		switch leaf.Kind() {
+		case ">", "/>":
+			return
+		case "}}", "}}}":
+			// The cursor is after a mustache tag, in whatever holds the tag.
+			if leaf.Parent() == nil || leaf.Parent().Parent() == nil {
+				return
+			}
+			leaf = leaf.Parent().Parent()
+		case "<", "</":
+			ctx.Kind = InTagName
+			if next := leaf.NextSibling(); next != nil && next.Kind() == "html_tag_name" && next.StartByte() == offset {
+				ctx.EndByte = next.EndByte()
+				ctx.TagName = next.Utf8Text(src)
+			}
+			return
+		case "=":
+			ctx.valueAt(leaf, offset, offset, src, offset)
+			return
+		case "\"", "'":
+			// A closing quote ends the value; an opening one starts it.
+			if leaf.PrevSibling() != nil && leaf.PrevSibling().Kind() != "=" {
+				ctx.Kind = InAttributeName
+				ctx.TagName = precedingText(leaf, "html_tag_name", src)
+				return
+			}
+		}
+	}
+
+	for n := leaf; n != nil; n = n.Parent() {
+		switch n.Kind() {
+		case "html_tag_name":
+			ctx.Kind = InTagName
+			ctx.setRange(n.StartByte(), n.EndByte(), src, offset)
+			ctx.TagName = n.Utf8Text(src)
+			return
+		case "html_attribute_name":
+			ctx.Kind = InAttributeName
+			ctx.setRange(n.StartByte(), n.EndByte(), src, offset)
+			ctx.TagName = precedingText(n, "html_tag_name", src)
+			return
+		case "html_quoted_attribute_value":
+			start, end := n.StartByte()+1, n.EndByte()
+			if last := n.Child(n.ChildCount() - 1); n.ChildCount() > 1 && last.ChildCount() == 0 && (last.Kind() == "\"" || last.Kind() == "'") {
+				end--
+			}
+			ctx.valueAt(n, start, end, src, offset)
+			return
+		case "html_attribute_value":
+			if n.Parent() != nil && n.Parent().Kind() == "html_quoted_attribute_value" {
+				continue
+			}
+			start, end := n.StartByte(), n.EndByte()
+			// An unclosed quote is part of the value in erroneous trees.
+			if prev := n.PrevSibling(); prev != nil && (prev.Kind() == "\"" || prev.Kind() == "'") {
+				start = prev.EndByte()
+			}
+			ctx.valueAt(n, start, end, src, offset)
+			return
+		case "html_comment", "mustache_comment":
+			ctx.Kind = InComment
+			return
+		case "html_start_tag", "html_self_closing_tag":
+			ctx.Kind = InAttributeName
+			ctx.TagName = childText(n, "html_tag_name", src)
+			return
+		case "\"", "'":
+			// An opening quote in an erroneous tree, with no value yet.
+			if prev := n.PrevSibling(); prev != nil && prev.Kind() == "=" {
+				ctx.valueAt(n, n.EndByte(), n.EndByte(), src, offset)
+				return
+			}
+		}
+	}
+}
+
+func (ctx *Context) setRange(start, end uint, src []byte, offset uint) {
+	ctx.StartByte, ctx.EndByte = start, end
+	ctx.Prefix = string(src[start:offset])
+}
+
+func (ctx *Context) valueAt(n *tree_sitter.Node, start, end uint, src []byte, offset uint) {
+	ctx.Kind = InAttributeValue
+	if end < offset {
+		end = offset
+	}
+	ctx.setRange(start, end, src, offset)
+	ctx.AttributeName = precedingText(n, "html_attribute_name", src)
+	ctx.TagName = precedingText(n, "html_tag_name", src)
+}
+
+// precedingText returns the text of the closest node of kind before n
+// within its tag. It looks through the preceding siblings of n and of its
+// ancestors up to the enclosing start tag, so it also works in erroneous
+// trees where the tag is not a node.
+func precedingText(n *tree_sitter.Node, kind string, src []byte) string {
+	for m := n; m != nil && m.Parent() != nil; m = m.Parent() {
+		for s := m.PrevSibling(); s != nil; s = s.PrevSibling() {
+			if s.Kind() == kind {
+				return s.Utf8Text(src)
+			}
+		}
+		switch m.Parent().Kind() {
+		case "html_start_tag", "html_self_closing_tag", "ERROR":
+			return ""
+		}
+	}
+	return ""
+}
+
+func childText(n *tree_sitter.Node, kind string, src []byte) string {
+	if child := walk.ChildOfKind(n, kind); child != nil {
+		return child.Utf8Text(src)
+	}
+	return ""
+}
+
+// scopesAt returns the elements and sections under n that enclose offset.
+// Erroneous trees hold unclosed start tags and section openers loose in
+// ERROR nodes, so those are tracked as they open and close.
+func scopesAt(n *tree_sitter.Node, src []byte, offset uint) []Scope {
+	var scopes []Scope
+	pop := func(kind ScopeKind, name string) {
+		for i := len(scopes) - 1; i >= 0; i-- {
+			sameKind := (kind == ElementScope) == (scopes[i].Kind == ElementScope)
+			// Tag names are case-insensitive, so </div> ends <DIV>.
+			if sameKind && (scopes[i].Name == name || kind == ElementScope && strings.EqualFold(scopes[i].Name, name)) {
+				scopes = scopes[:i]
+				return
+			}
+		}
+	}
+	for i := uint(0); i < n.ChildCount(); i++ {
+		child := n.Child(i)
+		if child.StartByte() >= offset {
+			break
+		}
+		inside := offset < child.EndByte() || offset == child.EndByte() && unclosed(child, src)
+		switch child.Kind() {
+		case "html_element", "html_script_element", "html_style_element", "html_raw_element":
+			if inside {
+				scope := Scope{Kind: ElementScope, StartByte: child.StartByte()}
+				if tag := child.Child(0); tag != nil {
+					scope.Name = childText(tag, "html_tag_name", src)
+				}
+				return append(append(scopes, scope), scopesAt(child, src, offset)...)
+			}
+		case "mustache_section", "mustache_inverted_section":
+			if inside {
+				scope := Scope{Kind: SectionScope, StartByte: child.StartByte()}
+				if child.Kind() == "mustache_inverted_section" {
+					scope.Kind = InvertedSectionScope
+				}
+				if begin := child.Child(0); begin != nil {
+					scope.Name = childText(begin, "mustache_tag_name", src)
+				}
+				return append(append(scopes, scope), scopesAt(child, src, offset)...)
+			}
+		case "ERROR":
+			if inside {
+				return append(scopes, scopesAt(child, src, offset)...)
+			}
+		case "html_start_tag":
+			if n.Kind() == "ERROR" {
+				if name := childText(child, "html_tag_name", src); !voidElements[strings.ToLower(name)] {
+					scopes = append(scopes, Scope{Kind: ElementScope, Name: name, StartByte: child.StartByte()})
+				}
+			}
+		case "html_end_tag":
+			if n.Kind() == "ERROR" {
+				pop(ElementScope, childText(child, "html_tag_name", src))
+			}
+		case "mustache_section_begin", "mustache_inverted_section_begin":
+			if n.Kind() == "ERROR" {
+				scope := Scope{Kind: SectionScope, Name: childText(child, "mustache_tag_name", src), StartByte: child.StartByte()}
+				if child.Kind() == "mustache_inverted_section_begin" {
+					scope.Kind = InvertedSectionScope
+				}
+				scopes = append(scopes, scope)
+			}
+		case "mustache_section_end", "mustache_inverted_section_end":
+			if n.Kind() == "ERROR" {
+				pop(SectionScope, childText(child, "mustache_tag_name", src))
+			}
+		}
+	}
+	return scopes
+}
+
+// unclosed reports whether a cursor at the end of n is still inside it: n
+// has no end tag, as in an element closed implicitly by the end of the
+// source.
+func unclosed(n *tree_sitter.Node, src []byte) bool {
+	switch n.Kind() {
+	case "ERROR":
+		return true
+	case "html_element", "html_script_element", "html_style_element", "html_raw_element":
+		last := n.Child(n.ChildCount() - 1)
+		if last == nil || last.Kind() == "html_end_tag" || last.Kind() == "html_self_closing_tag" {
+			return false
+		}
+		if first := n.Child(0); first != nil && first.Kind() == "html_start_tag" {
+			return !voidElements[strings.ToLower(childText(first, "html_tag_name", src))]
+		}
+		return true
+	case "mustache_section", "mustache_inverted_section":
+		last := n.Child(n.ChildCount() - 1)
+		return last == nil || (last.Kind() != "mustache_section_end" && last.Kind() != "mustache_inverted_section_end")
+	}
+	return false
+}
+
+// voidElements never have content or an end tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true,
+	"img": true, "input": true, "link": true, "meta": true, "source": true,
+	"track": true, "wbr": true,
+}
@@ -0,0 +1,103 @@
+package analysis_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+)
+
+func TestContextAt(t *testing.T) {
+	tests := []struct {
+		// src marks the offset with "|".
+		src           string
+		kind          analysis.ContextKind
+		prefix        string
+		tagName       string
+		attributeName string
+	}{
+		{src: "<p>hello |world</p>", kind: analysis.InText},
+		{src: "<p>x</p>|", kind: analysis.InText},
+		{src: "<di|v></div>", kind: analysis.InTagName, prefix: "di", tagName: "div"},
+		{src: "<div>x</d|iv>", kind: analysis.InTagName, prefix: "d", tagName: "div"},
+		{src: "<|", kind: analysis.InTagName},
+		{src: "<div cl|", kind: analysis.InAttributeName, prefix: "cl", tagName: "div"},
+		{src: "<div |class=a>", kind: analysis.InAttributeName, tagName: "div"},
+		{src: `<a href="x" |>`, kind: analysis.InAttributeName, tagName: "a"},
+		{src: `<a href="/us|ers">`, kind: analysis.InAttributeValue, prefix: "/us", tagName: "a", attributeName: "href"},
+		{src: `<a href="|">`, kind: analysis.InAttributeValue, tagName: "a", attributeName: "href"},
+		{src: `<a href="/x|`, kind: analysis.InAttributeValue, prefix: "/x", tagName: "a", attributeName: "href"},
+		{src: `<a href="{{u}}|">`, kind: analysis.InAttributeValue, prefix: "{{u}}", tagName: "a", attributeName: "href"},
+		{src: "<p>{{us|er}}</p>", kind: analysis.InInterpolation, prefix: "us"},
+		{src: "<p>{{{ us|", kind: analysis.InInterpolation, prefix: "us"},
+		{src: "<p>{{|", kind: analysis.InInterpolation},
+		{src: `<a href="{{u|`, kind: analysis.InInterpolation, prefix: "u"},
+		{src: "{{#ite|", kind: analysis.InSectionName, prefix: "ite"},
+		{src: "{{#a}}x{{/|", kind: analysis.InSectionName},
+		{src: "{{> par|", kind: analysis.InPartialName, prefix: "par"},
+		{src: "{{! no|te }}", kind: analysis.InComment},
+		{src: "<!-- no|te -->", kind: analysis.InComment},
+	}
+	for _, test := range tests {
+		offset := strings.Index(test.src, "|")
+		src := []byte(strings.Replace(test.src, "|", "", 1))
+		ctx := analysis.ContextAt(src, uint(offset))
+		if ctx.Kind != test.kind || ctx.Prefix != test.prefix || ctx.TagName != test.tagName || ctx.AttributeName != test.attributeName {
+			t.Errorf("ContextAt(%q) = %v %q tag %q attribute %q, want %v %q tag %q attribute %q", test.src,
+				ctx.Kind, ctx.Prefix, ctx.TagName, ctx.AttributeName,
+				test.kind, test.prefix, test.tagName, test.attributeName)
+		}
+		if ctx.StartByte > uint(offset) || ctx.EndByte < uint(offset) {
+			t.Errorf("ContextAt(%q) range %d-%d does not hold the offset", test.src, ctx.StartByte, ctx.EndByte)
+		}
+	}
+}
+
+func TestContextAtRange(t *testing.T) {
+	src := []byte("<p>{{user.name}}</p>")
+	ctx := analysis.ContextAt(src, 8)
+	if got := string(src[ctx.StartByte:ctx.EndByte]); got != "user.name" {
+		t.Errorf("range covers %q, want %q", got, "user.name")
+	}
+}
+
+func TestContextAtScopes(t *testing.T) {
+	type scope struct {
+		Kind analysis.ScopeKind
+		Name string
+	}
+	tests := []struct {
+		src  string
+		want []scope
+	}{
+		{src: "<p>x</p>|"},
+		{
+			src:  "<ul>{{#items}}<li>{{|}}</li>{{/items}}</ul>",
+			want: []scope{{analysis.ElementScope, "ul"}, {analysis.SectionScope, "items"}, {analysis.ElementScope, "li"}},
+		},
+		{
+			src:  "{{^empty}}<div><p>x</p>|",
+			want: []scope{{analysis.InvertedSectionScope, "empty"}, {analysis.ElementScope, "div"}},
+		},
+		{
+			src:  "<div>{{#a}}x{{/a}}<br>{{#b}}{{na|",
+			want: []scope{{analysis.ElementScope, "div"}, {analysis.SectionScope, "b"}},
+		},
+		{
+			src:  "<DIV><P>x</p>{{#b}}{{na|",
+			want: []scope{{analysis.ElementScope, "DIV"}, {analysis.SectionScope, "b"}},
+		},
+	}
+	for _, test := range tests {
+		offset := strings.Index(test.src, "|")
+		src := []byte(strings.Replace(test.src, "|", "", 1))
+		var got []scope
+		for _, s := range analysis.ContextAt(src, uint(offset)).Scopes {
+			got = append(got, scope{s.Kind, s.Name})
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ContextAt(%q).Scopes = %v, want %v", test.src, got, test.want)
+		}
+	}
+}
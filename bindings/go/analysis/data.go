@@ -0,0 +1,243 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// FindingKind classifies a problem found by CheckData.
+type FindingKind int
+
+const (
+	// MissingVariable is an interpolated name the data does not define.
+	MissingVariable FindingKind = iota
+	// NonArraySection is a section over a string or number, which renders
+	// its content once instead of iterating.
+	NonArraySection
+	// UnusedPartial is a partial that no template includes.
+	UnusedPartial
+	// UndefinedPartial is an include of a partial that does not exist.
+	UndefinedPartial
+)
+
+func (k FindingKind) String() string {
+	switch k {
+	case MissingVariable:
+		return "missingVariable"
+	case NonArraySection:
+		return "nonArraySection"
+	case UnusedPartial:
+		return "unusedPartial"
+	case UndefinedPartial:
+		return "undefinedPartial"
+	}
+	return "unknown"
+}
+
+// MarshalText encodes k as its name, so findings marshal to JSON readably.
+func (k FindingKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Finding is a mismatch between a template and its data.
+type Finding struct {
+	Kind FindingKind `json:"kind"`
+	// Path is the name as written in the template.
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	// StartByte and EndByte delimit the name within the template.
+	StartByte uint `json:"startByte"`
+	EndByte   uint `json:"endByte"`
+}
+
+// CheckData reports the interpolations in template that data, a JSON
+// document, does not define, and the sections over strings or numbers.
+//
+// Names resolve as they would when rendering: the first key up the context
+// stack, the rest strictly. Only content that would render is checked, so
+// a section over a missing or false value is not, and a section over an
+// array is checked once per element, reporting each name at most once.
+// Sections over booleans and objects are not reported, as both are common
+// Mustache idioms. The built-in helpers, {{#if}}, {{#unless}}, {{#with}}
+// and {{#each}}, are checked as they render, block params included; the
+// content of other helpers is checked in the same context. Partials are
+// not followed.
+func CheckData(template, data []byte) ([]Finding, error) {
+	var root any
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("analysis: decoding data: %w", err)
+	}
+	tree, err := parse(template)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+
+	c := &dataChecker{src: template, seen: map[uint]bool{}}
+	c.visit(tree.RootNode(), []any{root})
+	sort.SliceStable(c.findings, func(i, j int) bool {
+		return c.findings[i].StartByte < c.findings[j].StartByte
+	})
+	return c.findings, nil
+}
+
+type dataChecker struct {
+	src      []byte
+	findings []Finding
+	// seen holds the start bytes of names already reported.
+	seen map[uint]bool
+}
+
+func (c *dataChecker) report(name *tree_sitter.Node, kind FindingKind, message string) {
+	if c.seen[name.StartByte()] {
+		return
+	}
+	c.seen[name.StartByte()] = true
+	c.findings = append(c.findings, Finding{
+		Kind:      kind,
+		Path:      name.Utf8Text(c.src),
+		Message:   message,
+		StartByte: name.StartByte(),
+		EndByte:   name.EndByte(),
+	})
+}
+
+func (c *dataChecker) visit(n *tree_sitter.Node, stack []any) {
+	switch n.Kind() {
+	case "mustache_interpolation", "mustache_triple":
+		name := expressionNode(n)
+		if name == nil {
+			return
+		}
+		if _, ok := lookupData(stack, pathKeys(name, c.src)); !ok {
+			c.report(name, MissingVariable, fmt.Sprintf("%q is not defined in the data", name.Utf8Text(c.src)))
+		}
+		return
+	case "mustache_section", "mustache_inverted_section":
+		c.section(n, stack)
+		return
+	}
+	for i := uint(0); i < n.ChildCount(); i++ {
+		c.visit(n.Child(i), stack)
+	}
+}
+
+// section checks the branch of a section that renders: once per item for a
+// section or {{#each}} over an array, with the value pushed for a truthy
+// section or {{#with}}, or in the same context for {{#if}}. The content of
+// other helpers is checked in the same context, both branches.
+func (c *dataChecker) section(n *tree_sitter.Node, stack []any) {
+	tag := ParseSectionTag(n.Child(0), c.src)
+	truthy, falsy, _ := sectionBranches(n)
+	if tag.Subject == nil {
+		c.nodes(truthy, stack)
+		c.nodes(falsy, stack)
+		return
+	}
+	value, _ := lookupData(stack, tag.Keys)
+	holds := truthyData(value) != tag.Unless
+	if n.Kind() == "mustache_inverted_section" {
+		if holds {
+			c.nodes(falsy, stack)
+		} else {
+			c.nodes(truthy, stack)
+		}
+		return
+	}
+	if !holds {
+		c.nodes(falsy, stack)
+		return
+	}
+
+	var contexts []any
+	switch tag.Helper {
+	case IfHelper:
+		c.nodes(truthy, stack)
+		return
+	case WithHelper:
+		contexts = []any{value}
+	default:
+		switch v := value.(type) {
+		case []any:
+			contexts = v
+		case string, float64:
+			if tag.Helper == NoHelper {
+				c.report(tag.Subject, NonArraySection, fmt.Sprintf("section %q is over a %s, not an array", tag.Subject.Utf8Text(c.src), dataType(v)))
+			}
+			contexts = []any{value}
+		default:
+			contexts = []any{value}
+		}
+	}
+	for i, context := range contexts {
+		if len(tag.BlockParams) > 0 {
+			// Block params name the value, or the item and its index,
+			// instead of pushing it.
+			params := map[string]any{tag.BlockParams[0]: context}
+			if len(tag.BlockParams) > 1 && tag.Helper == EachHelper {
+				params[tag.BlockParams[1]] = float64(i)
+			}
+			context = params
+		}
+		c.nodes(truthy, append(stack[:len(stack):len(stack)], context))
+	}
+}
+
+func (c *dataChecker) nodes(nodes []*tree_sitter.Node, stack []any) {
+	for _, n := range nodes {
+		c.visit(n, stack)
+	}
+}
+
+// lookupData resolves keys against the context stack. Nil keys name the
+// top of the stack.
+func lookupData(stack []any, keys []string) (any, bool) {
+	if len(keys) == 0 {
+		return stack[len(stack)-1], true
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		object, ok := stack[i].(map[string]any)
+		if !ok {
+			continue
+		}
+		value, ok := object[keys[0]]
+		if !ok {
+			continue
+		}
+		for _, key := range keys[1:] {
+			object, ok := value.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if value, ok = object[key]; !ok {
+				return nil, false
+			}
+		}
+		return value, true
+	}
+	return nil, false
+}
+
+// truthyData reports whether a section over value renders, as in the
+// render package: only null, false and empty arrays are falsy.
+func truthyData(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case []any:
+		return len(v) > 0
+	}
+	return true
+}
+
+func dataType(value any) string {
+	if _, ok := value.(string); ok {
+		return "string"
+	}
+	return "number"
+}
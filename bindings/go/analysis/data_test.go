@@ -0,0 +1,76 @@
+package analysis_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+)
+
+func TestCheckData(t *testing.T) {
+	template := []byte(`<h1>{{title}}</h1>{{#items}}<li>{{name}} {{price}} {{currency}}</li>{{/items}}` +
+		`{{#user}}{{user.email}} {{nickname}}{{/user}}{{#name}}{{.}}{{/name}}{{#off}}{{ghost}}{{/off}}{{^off}}{{label}}{{/off}}`)
+	data := []byte(`{
+		"items": [{"name": "a", "price": 1}, {"name": "b"}],
+		"currency": "EUR",
+		"user": {"email": "x@example.com"},
+		"name": "shop",
+		"off": false
+	}`)
+	findings, err := analysis.CheckData(template, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type finding struct {
+		Kind analysis.FindingKind
+		Path string
+	}
+	var got []finding
+	for _, f := range findings {
+		got = append(got, finding{f.Kind, f.Path})
+		if string(template[f.StartByte:f.EndByte]) != f.Path {
+			t.Errorf("range of %q covers %q", f.Path, template[f.StartByte:f.EndByte])
+		}
+	}
+	expected := []finding{
+		{analysis.MissingVariable, "title"},
+		{analysis.MissingVariable, "price"},
+		{analysis.MissingVariable, "nickname"},
+		{analysis.NonArraySection, "name"},
+		{analysis.MissingVariable, "label"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("CheckData() =\n%v\nwant\n%v", got, expected)
+	}
+}
+
+func TestCheckDataHelpers(t *testing.T) {
+	template := []byte(`{{#if on}}{{a}}{{else}}{{b}}{{/if}}{{#unless on}}{{c}}{{else}}{{d}}{{/unless}}` +
+		`{{#with user}}{{email}}{{nickname}}{{/with}}{{#each items as |item i|}}{{i}}{{item.name}}{{item.size}}{{/each}}` +
+		`{{#each name}}{{.}}{{/each}}{{#format on}}{{e}}{{/format}}`)
+	data := []byte(`{"on": true, "user": {"email": "x"}, "items": [{"name": "a"}], "name": "shop"}`)
+	findings, err := analysis.CheckData(template, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Kind.String()+" "+f.Path)
+	}
+	expected := []string{
+		"missingVariable a",
+		"missingVariable d",
+		"missingVariable nickname",
+		"missingVariable item.size",
+		"missingVariable e",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("CheckData() =\n%q\nwant\n%q", got, expected)
+	}
+}
+
+func TestCheckDataInvalidJSON(t *testing.T) {
+	if _, err := analysis.CheckData([]byte("{{x}}"), []byte("{")); err == nil {
+		t.Error("CheckData() accepted invalid JSON")
+	}
+}
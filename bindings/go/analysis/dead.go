@@ -0,0 +1,265 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// Facts is what is known about the data a template is rendered with.
+type Facts struct {
+	// Schema, if not nil, describes the data completely: a name an object
+	// has no property for is never defined, and a property listed in
+	// Required always is. An object, string or number is always truthy;
+	// whether a boolean or array is depends on its value.
+	Schema *Schema
+	// Flags maps names, as written in the template, to whether they are
+	// always truthy or always falsy. A flag applies wherever its name
+	// appears and overrides the schema.
+	Flags map[string]bool
+}
+
+// DeadRegion is template content that the facts say never renders.
+type DeadRegion struct {
+	// Path is the name of the section as written.
+	Path     string
+	Inverted bool
+	Message  string
+	// StartByte and EndByte delimit the content that never renders: the
+	// whole section, tags included, or for a section with an {{else}}, the
+	// branch that never renders.
+	StartByte uint
+	EndByte   uint
+	// NameStartByte and NameEndByte delimit the name in the opening tag.
+	NameStartByte uint
+	NameEndByte   uint
+}
+
+// DeadRegions reports the sections of src that never render given facts:
+// sections over names that are always falsy, such as a feature flag that
+// is permanently off, and inverted sections over names that are always
+// truthy. {{#if}}, {{#unless}}, {{#with}} and {{#each}} are reported as
+// the sections they behave like; other helpers are not. Content inside a
+// dead region is not reported again. Partials are not followed.
+func DeadRegions(src []byte, facts Facts) ([]DeadRegion, error) {
+	tree, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+
+	d := &deadFinder{src: src, facts: facts}
+	d.visit(tree.RootNode(), []*Schema{facts.Schema})
+	sort.SliceStable(d.regions, func(i, j int) bool { return d.regions[i].StartByte < d.regions[j].StartByte })
+	return d.regions, nil
+}
+
+// truthiness is what is known of a value's truthiness.
+type truthiness int
+
+const (
+	unknownTruth truthiness = iota
+	alwaysTruthy
+	alwaysFalsy
+)
+
+type deadFinder struct {
+	src     []byte
+	facts   Facts
+	regions []DeadRegion
+}
+
+// visit looks for dead regions under n. stack holds the schemas of the
+// context stack, nil where the context is unknown.
+func (d *deadFinder) visit(n *tree_sitter.Node, stack []*Schema) {
+	switch n.Kind() {
+	case "mustache_section", "mustache_inverted_section":
+		d.section(n, stack)
+		return
+	}
+	for i := uint(0); i < n.ChildCount(); i++ {
+		d.visit(n.Child(i), stack)
+	}
+}
+
+func (d *deadFinder) section(n *tree_sitter.Node, stack []*Schema) {
+	inverted := n.Kind() == "mustache_inverted_section"
+	begin, end := n.Child(0), n.Child(n.ChildCount()-1)
+	tag := ParseSectionTag(begin, d.src)
+	if tag.Subject == nil || !strings.HasSuffix(end.Kind(), "_end") {
+		for i := uint(0); i < n.ChildCount(); i++ {
+			d.visit(n.Child(i), stack)
+		}
+		return
+	}
+	name := tag.Subject
+	path := name.Utf8Text(d.src)
+	truth, schema := d.truth(path, tag.Keys, stack)
+
+	// The branch shown for a truthy value, and the one shown for a falsy
+	// value: the content after an {{else}}, or nothing.
+	truthy, falsy, elseTag := sectionBranches(n)
+	if inverted {
+		truthy, falsy = falsy, truthy
+	}
+	// An inverted section renders its first branch for a falsy value, as
+	// does {{#unless}}.
+	firstShown := alwaysTruthy
+	if inverted != tag.Unless {
+		firstShown = alwaysFalsy
+	}
+	if tag.Unless {
+		truthy, falsy = falsy, truthy
+	}
+
+	if truth != unknownTruth {
+		region := DeadRegion{
+			Path:          path,
+			Inverted:      inverted,
+			Message:       fmt.Sprintf("%q is always falsy", path),
+			NameStartByte: name.StartByte(),
+			NameEndByte:   name.EndByte(),
+		}
+		if truth == alwaysTruthy {
+			region.Message = fmt.Sprintf("%q is always truthy", path)
+		}
+		switch {
+		case truth != firstShown && elseTag == nil:
+			region.StartByte, region.EndByte = n.StartByte(), n.EndByte()
+		case truth != firstShown:
+			region.StartByte, region.EndByte = begin.EndByte(), elseTag.StartByte()
+		case elseTag != nil:
+			region.StartByte, region.EndByte = elseTag.EndByte(), end.StartByte()
+		}
+		if region.EndByte > region.StartByte {
+			d.regions = append(d.regions, region)
+		}
+	}
+
+	if truth != alwaysFalsy {
+		context := stack
+		if !inverted && tag.Helper != IfHelper {
+			// A section pushes its value, or each item of an array; block
+			// params name them instead.
+			item := schema
+			if schema != nil && schema.Type == "array" && tag.Helper != WithHelper {
+				item = schema.Items
+			}
+			if len(tag.BlockParams) > 0 {
+				item = paramSchema(tag, item)
+			}
+			context = append(stack[:len(stack):len(stack)], item)
+		}
+		for _, child := range truthy {
+			d.visit(child, context)
+		}
+	}
+	if truth != alwaysTruthy {
+		for _, child := range falsy {
+			d.visit(child, stack)
+		}
+	}
+}
+
+// paramSchema returns the schema of the context a section with block params
+// pushes: an object holding the params, bound to item and, for {{#each}},
+// an index of which nothing is known.
+func paramSchema(tag SectionTag, item *Schema) *Schema {
+	if item == nil {
+		item = &Schema{}
+	}
+	params := &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{tag.BlockParams[0]: item},
+		Required:   tag.BlockParams[:1],
+	}
+	if len(tag.BlockParams) > 1 && tag.Helper == EachHelper {
+		params.Properties[tag.BlockParams[1]] = &Schema{}
+		params.Required = tag.BlockParams[:2]
+	}
+	return params
+}
+
+// truth returns what is known of the truthiness of the name path, split
+// into keys, and its schema if the facts describe it.
+func (d *deadFinder) truth(path string, keys []string, stack []*Schema) (truthiness, *Schema) {
+	if truthy, ok := d.facts.Flags[path]; ok {
+		if truthy {
+			return alwaysTruthy, nil
+		}
+		return alwaysFalsy, nil
+	}
+	return lookupSchema(stack, keys)
+}
+
+// lookupSchema resolves keys against the schemas of the context stack, as
+// lookupData does against data, and returns what is known of the value's
+// truthiness and its schema. Only a value that is never defined is always
+// falsy. Nothing is known of the implicit iterator, whose keys are nil.
+func lookupSchema(stack []*Schema, keys []string) (truthiness, *Schema) {
+	if len(keys) == 0 {
+		return unknownTruth, nil
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		context := stack[i]
+		switch {
+		case context == nil || context.Type == "":
+			return unknownTruth, nil
+		case context.Type != "object":
+			// Other values have no keys.
+			continue
+		}
+		property, ok := context.Properties[keys[0]]
+		if !ok {
+			continue
+		}
+		truth, schema := property.truth(keys[1:])
+		if context.requires(keys[0]) {
+			return truth, schema
+		}
+		// Where it is missing, the lookup goes on down the stack.
+		if below, _ := lookupSchema(stack[:i], keys); truth == alwaysFalsy && below == alwaysFalsy {
+			return alwaysFalsy, nil
+		}
+		return unknownTruth, schema
+	}
+	return alwaysFalsy, nil
+}
+
+// truth resolves keys strictly under s, which is defined.
+func (s *Schema) truth(keys []string) (truthiness, *Schema) {
+	if len(keys) == 0 {
+		switch s.Type {
+		case "object", "string", "number", "integer":
+			return alwaysTruthy, s
+		}
+		return unknownTruth, s
+	}
+	switch s.Type {
+	case "":
+		return unknownTruth, nil
+	case "object":
+	default:
+		return alwaysFalsy, nil
+	}
+	property, ok := s.Properties[keys[0]]
+	if !ok {
+		return alwaysFalsy, nil
+	}
+	truth, schema := property.truth(keys[1:])
+	if truth == alwaysTruthy && !s.requires(keys[0]) {
+		return unknownTruth, schema
+	}
+	return truth, schema
+}
+
+func (s *Schema) requires(key string) bool {
+	for _, required := range s.Required {
+		if required == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package analysis_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+)
+
+func TestDeadRegions(t *testing.T) {
+	var schema analysis.Schema
+	err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"required": ["user", "items"],
+		"properties": {
+			"user": {"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}, "admin": {"type": "boolean"}}},
+			"items": {"type": "array", "items": {"type": "object", "required": ["label"], "properties": {"label": {"type": "string"}}}},
+			"banner": {"type": "string"}
+		}
+	}`), &schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		src      string
+		facts    analysis.Facts
+		expected []string
+	}{
+		{
+			src:      `{{#featureX}}<p>new</p>{{/featureX}}{{^featureX}}<p>old</p>{{/featureX}}{{#beta}}b{{/beta}}`,
+			facts:    analysis.Facts{Flags: map[string]bool{"featureX": false}},
+			expected: []string{`{{#featureX}}<p>new</p>{{/featureX}}`},
+		},
+		{
+			src:      `{{^featureX}}<p>old</p>{{/featureX}}{{#featureX}}{{#featureX}}x{{/featureX}}{{/featureX}}`,
+			facts:    analysis.Facts{Flags: map[string]bool{"featureX": true}},
+			expected: []string{`{{^featureX}}<p>old</p>{{/featureX}}`},
+		},
+		{
+			src:   `{{#legacy}}{{#banner}}x{{/banner}}{{/legacy}}{{^user}}log in{{/user}}{{^user.name}}anonymous{{/user.name}}{{#user.admin}}admin{{/user.admin}}{{^banner}}b{{/banner}}`,
+			facts: analysis.Facts{Schema: &schema},
+			expected: []string{
+				`{{#legacy}}{{#banner}}x{{/banner}}{{/legacy}}`,
+				`{{^user}}log in{{/user}}`,
+				`{{^user.name}}anonymous{{/user.name}}`,
+			},
+		},
+		{
+			src:      `{{#items}}{{^label}}-{{/label}}{{#user}}{{^name}}?{{/name}}{{/user}}{{#missing}}m{{/missing}}{{/items}}{{^items}}none{{/items}}`,
+			facts:    analysis.Facts{Schema: &schema},
+			expected: []string{`{{^label}}-{{/label}}`, `{{^name}}?{{/name}}`, `{{#missing}}m{{/missing}}`},
+		},
+		{
+			src: `{{#if legacy}}x{{/if}}{{#unless user}}log in{{else}}hi{{/unless}}{{#with user}}{{^name}}?{{/name}}{{/with}}` +
+				`{{#each items as |item|}}{{^item.label}}-{{/item.label}}{{#banner}}b{{/banner}}{{/each}}{{#format legacy}}y{{/format}}`,
+			facts: analysis.Facts{Schema: &schema},
+			expected: []string{
+				`{{#if legacy}}x{{/if}}`,
+				`log in`,
+				`{{^name}}?{{/name}}`,
+				`{{^item.label}}-{{/item.label}}`,
+			},
+		},
+		{
+			src:      `{{#items}}{{#legacy}}x{{/legacy}}{{/items}}`,
+			facts:    analysis.Facts{},
+			expected: nil,
+		},
+	}
+	for _, test := range tests {
+		regions, err := analysis.DeadRegions([]byte(test.src), test.facts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, r := range regions {
+			got = append(got, test.src[r.StartByte:r.EndByte])
+			if test.src[r.NameStartByte:r.NameEndByte] != r.Path {
+				t.Errorf("name range of %q covers %q", r.Path, test.src[r.NameStartByte:r.NameEndByte])
+			}
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("DeadRegions(%q) =\n%q\nwant\n%q", test.src, got, test.expected)
+		}
+	}
+}
+
+func TestDeadRegionMessages(t *testing.T) {
+	regions, err := analysis.DeadRegions([]byte(`{{#a}}x{{/a}}{{^b}}y{{/b}}`), analysis.Facts{Flags: map[string]bool{"a": false, "b": true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []analysis.DeadRegion{
+		{Path: "a", Message: `"a" is always falsy`, StartByte: 0, EndByte: 13, NameStartByte: 3, NameEndByte: 4},
+		{Path: "b", Inverted: true, Message: `"b" is always truthy`, StartByte: 13, EndByte: 26, NameStartByte: 16, NameEndByte: 17},
+	}
+	if !reflect.DeepEqual(regions, expected) {
+		t.Errorf("DeadRegions() =\n%+v\nwant\n%+v", regions, expected)
+	}
+}
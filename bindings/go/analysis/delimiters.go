@@ -0,0 +1,60 @@
+package analysis
+
+import (
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// Delimiters are the strings that open and close mustache tags.
+type Delimiters struct {
+	Open, Close string
+}
+
+// DefaultDelimiters are the delimiters templates start with.
+var DefaultDelimiters = Delimiters{"{{", "}}"}
+
+// DelimiterRegion is a stretch of a template in which the same delimiters
+// are active. A set delimiter tag such as {{=<% %>=}} belongs to the region
+// before it, as it is written with the delimiters it replaces.
+type DelimiterRegion struct {
+	Delimiters
+	StartByte uint
+	EndByte   uint
+}
+
+// DelimiterRegions splits the template rooted at root, whose source is src,
+// into the regions between its set delimiter tags, in document order. They
+// cover the whole source. The parse tree already spells custom delimiters
+// as the default ones in node kinds; the regions are for tools that work
+// on the source text, which is written with the active delimiters.
+func DelimiterRegions(root *tree_sitter.Node, src []byte) []DelimiterRegion {
+	regions := []DelimiterRegion{{Delimiters: DefaultDelimiters}}
+	for tag := range walk.KindNode(root, "mustache_set_delimiter") {
+		open, close := tag.ChildByFieldName("open_delimiter"), tag.ChildByFieldName("close_delimiter")
+		if open == nil || close == nil || tag.HasError() {
+			continue
+		}
+		regions[len(regions)-1].EndByte = tag.EndByte()
+		regions = append(regions, DelimiterRegion{
+			Delimiters: Delimiters{open.Utf8Text(src), close.Utf8Text(src)},
+			StartByte:  tag.EndByte(),
+		})
+	}
+	regions[len(regions)-1].EndByte = uint(len(src))
+	return regions
+}
+
+// DelimitersAt returns the delimiters active at offset in the template
+// split into regions.
+func DelimitersAt(regions []DelimiterRegion, offset uint) Delimiters {
+	for _, r := range regions {
+		if offset < r.EndByte {
+			return r.Delimiters
+		}
+	}
+	if len(regions) == 0 {
+		return DefaultDelimiters
+	}
+	return regions[len(regions)-1].Delimiters
+}
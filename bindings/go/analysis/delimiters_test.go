@@ -0,0 +1,87 @@
+package analysis_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+)
+
+func TestDelimiterRegions(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected []analysis.DelimiterRegion
+	}{
+		{src: "<p>{{name}}</p>", expected: []analysis.DelimiterRegion{
+			{Delimiters: analysis.DefaultDelimiters, StartByte: 0, EndByte: 15},
+		}},
+		{src: "", expected: []analysis.DelimiterRegion{
+			{Delimiters: analysis.DefaultDelimiters},
+		}},
+		{src: "{{a}}{{=<% %>=}}<p><% b %></p><%={{ }}=%>{{c}}", expected: []analysis.DelimiterRegion{
+			{Delimiters: analysis.DefaultDelimiters, StartByte: 0, EndByte: 16},
+			{Delimiters: analysis.Delimiters{Open: "<%", Close: "%>"}, StartByte: 16, EndByte: 41},
+			{Delimiters: analysis.DefaultDelimiters, StartByte: 41, EndByte: 46},
+		}},
+	}
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			src := []byte(test.src)
+			tree := parser.Parse(src, nil)
+			defer tree.Close()
+			got := analysis.DelimiterRegions(tree.RootNode(), src)
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("DelimiterRegions() =\n%+v\nwant\n%+v", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestDelimitersAt(t *testing.T) {
+	custom := analysis.Delimiters{Open: "<%", Close: "%>"}
+	regions := []analysis.DelimiterRegion{
+		{Delimiters: analysis.DefaultDelimiters, StartByte: 0, EndByte: 10},
+		{Delimiters: custom, StartByte: 10, EndByte: 20},
+	}
+	for offset, expected := range map[uint]analysis.Delimiters{
+		0: analysis.DefaultDelimiters, 9: analysis.DefaultDelimiters,
+		10: custom, 20: custom, 25: custom,
+	} {
+		if got := analysis.DelimitersAt(regions, offset); got != expected {
+			t.Errorf("DelimitersAt(%d) = %v, want %v", offset, got, expected)
+		}
+	}
+	if got := analysis.DelimitersAt(nil, 3); got != analysis.DefaultDelimiters {
+		t.Errorf("DelimitersAt(nil) = %v, want the defaults", got)
+	}
+}
+
+func TestContextAtCustomDelimiters(t *testing.T) {
+	tests := []struct {
+		src    string
+		kind   analysis.ContextKind
+		prefix string
+	}{
+		{"{{=<% %>=}}<p><% us|er %></p>", analysis.InInterpolation, "us"},
+		{"{{=<% %>=}}<%#ite|", analysis.InSectionName, "ite"},
+		{"{{=<% %>=}}<p>{{us|er}}</p>", analysis.InText, ""},
+		{"{{=<% %>=}}<%={{ }}=%>{{> par|", analysis.InPartialName, "par"},
+	}
+	for _, test := range tests {
+		offset := strings.Index(test.src, "|")
+		src := []byte(strings.Replace(test.src, "|", "", 1))
+		ctx := analysis.ContextAt(src, uint(offset))
+		if ctx.Kind != test.kind || ctx.Prefix != test.prefix {
+			t.Errorf("ContextAt(%q) = %v %q, want %v %q", test.src, ctx.Kind, ctx.Prefix, test.kind, test.prefix)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package analysis
+
+import (
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// HelperKind says which Handlebars block helper, if any, a section calls.
+type HelperKind int
+
+const (
+	// NoHelper is a Mustache section, {{#name}} or {{^name}}, over the
+	// value of name.
+	NoHelper HelperKind = iota
+	// IfHelper is {{#if cond}}, or {{#unless cond}}, which renders its
+	// content in the same context.
+	IfHelper
+	// WithHelper is {{#with value}}, which pushes value.
+	WithHelper
+	// EachHelper is {{#each list}}, which renders once for each item.
+	EachHelper
+	// OtherHelper is any other helper, or a built-in one called with
+	// anything but a single name, whose effect is unknown.
+	OtherHelper
+)
+
+// SectionTag describes the tag opening a section, or an {{else if cond}}
+// chained onto one.
+type SectionTag struct {
+	Helper HelperKind
+	// Name is the helper name, or the section name for NoHelper.
+	Name string
+	// Subject is the name the section reads: its tag name for NoHelper and
+	// the param of a built-in helper. It is nil for OtherHelper.
+	Subject *tree_sitter.Node
+	// Keys are the components of Subject, nil for the implicit iterator.
+	Keys []string
+	// Unless is set for {{#unless cond}}, which renders for a falsy cond.
+	Unless bool
+	// BlockParams are the names in {{#each list as |item i|}}: the value of
+	// with, or the item and index of each.
+	BlockParams []string
+}
+
+// ParseSectionTag describes tag, a mustache_section_begin,
+// mustache_inverted_section_begin or mustache_else node. It is the way the
+// analyses and the converter tell Handlebars helpers from sections, as the
+// render package does: a tag with params or hash pairs calls a helper.
+func ParseSectionTag(tag *tree_sitter.Node, src []byte) SectionTag {
+	name := tag.ChildByFieldName("name")
+	if name == nil {
+		return SectionTag{Helper: OtherHelper}
+	}
+	s := SectionTag{Name: name.Utf8Text(src), BlockParams: blockParamNames(tag, src)}
+	if !hasArguments(tag) {
+		s.Subject, s.Keys = name, pathKeys(name, src)
+		return s
+	}
+
+	var params []*tree_sitter.Node
+	for i := uint(0); i < tag.ChildCount(); i++ {
+		switch tag.FieldNameForChild(uint32(i)) {
+		case "param":
+			params = append(params, tag.Child(i))
+		case "hash":
+			return SectionTag{Helper: OtherHelper, Name: s.Name, BlockParams: s.BlockParams}
+		}
+	}
+	switch s.Name {
+	case "if", "unless":
+		s.Helper, s.Unless = IfHelper, s.Name == "unless"
+	case "with":
+		s.Helper = WithHelper
+	case "each":
+		s.Helper = EachHelper
+	default:
+		s.Helper = OtherHelper
+	}
+	if s.Helper != OtherHelper && len(params) == 1 {
+		switch params[0].Kind() {
+		case "mustache_path_expression", "mustache_identifier", "mustache_implicit_iterator":
+			s.Subject, s.Keys = params[0], pathKeys(params[0], src)
+			return s
+		}
+	}
+	s.Helper, s.Unless = OtherHelper, false
+	return s
+}
+
+// blockParamNames returns the names in the block params of tag.
+func blockParamNames(tag *tree_sitter.Node, src []byte) []string {
+	params := tag.ChildByFieldName("block_params")
+	if params == nil {
+		return nil
+	}
+	var names []string
+	for i := uint(0); i < params.NamedChildCount(); i++ {
+		names = append(names, params.NamedChild(i).Utf8Text(src))
+	}
+	return names
+}
+
+// sectionBranches splits the content of section, between its begin and end
+// tags, at its first {{else}}: truthy is what renders for a truthy value
+// and falsy the rest, as written; an inverted section renders them the
+// other way round. An unclosed section in an error tree has no end tag.
+func sectionBranches(section *tree_sitter.Node) (truthy, falsy []*tree_sitter.Node, elseTag *tree_sitter.Node) {
+	for i := uint(1); i < section.ChildCount(); i++ {
+		child := section.Child(i)
+		switch {
+		case i+1 == section.ChildCount() && strings.HasSuffix(child.Kind(), "_end"):
+		case child.Kind() == "mustache_else" && elseTag == nil:
+			elseTag = child
+		case elseTag == nil:
+			truthy = append(truthy, child)
+		default:
+			falsy = append(falsy, child)
+		}
+	}
+	return truthy, falsy, elseTag
+}
@@ -0,0 +1,202 @@
+package analysis
+
+import (
+	"bytes"
+	"html"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+	"github.com/reteps/tree-sitter-htmlmustache/queries"
+)
+
+// Symbol is a named construct found by queries/tags.scm.
+type Symbol struct {
+	Name string
+	// Kind is the tag kind from the query: "section", "partial" or "id".
+	Kind string
+	// Reference is true for uses, such as partial includes, and false for
+	// definitions.
+	Reference bool
+	// StartByte and EndByte delimit the whole construct, and NameStartByte
+	// and NameEndByte its name.
+	StartByte     uint
+	EndByte       uint
+	NameStartByte uint
+	NameEndByte   uint
+}
+
+// Outline returns the symbols of src in document order, for symbol pickers
+// and code navigation.
+func Outline(src []byte) ([]Symbol, error) {
+	tree, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+
+	query, err := queries.Tags(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language()))
+	if err != nil {
+		return nil, err
+	}
+	return outlineIn(query, tree.RootNode(), src), nil
+}
+
+func outlineIn(query *tree_sitter.Query, root *tree_sitter.Node, src []byte) []Symbol {
+	cursor := tree_sitter.NewQueryCursor()
+	defer cursor.Close()
+
+	symbols := []Symbol{}
+	names := query.CaptureNames()
+	matches := cursor.Matches(query, root, src)
+	for match := matches.Next(); match != nil; match = matches.Next() {
+		var symbol Symbol
+		var found bool
+		for _, capture := range match.Captures {
+			name := names[capture.Index]
+			switch {
+			case name == "name":
+				// Partial names keep the whitespace after {{>, so trim it
+				// from both the name and its range.
+				text := capture.Node.Utf8Text(src)
+				symbol.Name = strings.TrimSpace(text)
+				symbol.NameStartByte = capture.Node.StartByte() + uint(len(text)-len(strings.TrimLeft(text, " \t\r\n")))
+				symbol.NameEndByte = symbol.NameStartByte + uint(len(symbol.Name))
+			case strings.HasPrefix(name, "definition."), strings.HasPrefix(name, "reference."):
+				role, kind, _ := strings.Cut(name, ".")
+				symbol.Kind = kind
+				symbol.Reference = role == "reference"
+				symbol.StartByte = capture.Node.StartByte()
+				symbol.EndByte = capture.Node.EndByte()
+				found = true
+			}
+		}
+		if found && symbol.Name != "" {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols
+}
+
+// OutlineItem is an element or section in the structure of a template.
+// Exactly one of Tag and Path is set.
+type OutlineItem struct {
+	// Tag is the tag name of an element, as written.
+	Tag string
+	// ID and Classes are the values of the element's id and class
+	// attributes, as written; values with Mustache tags keep them.
+	ID      string
+	Classes []string
+	// Heading is the text of an h1 to h6 element, with entities decoded,
+	// whitespace collapsed and interpolations as written.
+	Heading string
+	// Path is the name of a section as written, and Inverted is set for
+	// inverted sections.
+	Path     string
+	Inverted bool
+	// StartByte and EndByte delimit the whole element or section.
+	StartByte uint
+	EndByte   uint
+	// Children are the elements and sections directly inside this one.
+	Children []OutlineItem
+}
+
+// OutlineTree returns the nested structure of the elements and sections of
+// src, for breadcrumbs and documenting the layout of pages. Unlike Outline,
+// it includes every element, named or not.
+func OutlineTree(src []byte) ([]OutlineItem, error) {
+	tree, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+	return outlineItems(tree.RootNode(), src), nil
+}
+
+// outlineItems returns the items of the elements and sections below n that
+// are not inside another one.
+func outlineItems(n *tree_sitter.Node, src []byte) []OutlineItem {
+	items := []OutlineItem{}
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		child := n.NamedChild(i)
+		item := OutlineItem{StartByte: child.StartByte(), EndByte: child.EndByte()}
+		switch child.Kind() {
+		case "html_element", "html_script_element", "html_style_element", "html_raw_element":
+			tag := walk.ChildOfKind(child, "html_start_tag")
+			if tag == nil {
+				tag = walk.ChildOfKind(child, "html_self_closing_tag")
+			}
+			if tag == nil {
+				break
+			}
+			item.Tag = childText(tag, "html_tag_name", src)
+			item.Classes = []string{}
+			for _, attribute := range nodesOfKind(tag, "html_attribute") {
+				switch name := childText(&attribute, "html_attribute_name", src); strings.ToLower(name) {
+				case "id":
+					item.ID = attributeText(&attribute, src)
+				case "class":
+					item.Classes = append(item.Classes, strings.Fields(attributeText(&attribute, src))...)
+				}
+			}
+			if len(item.Tag) == 2 && (item.Tag[0] == 'h' || item.Tag[0] == 'H') && item.Tag[1] >= '1' && item.Tag[1] <= '6' {
+				item.Heading = headingText(child, src)
+			}
+		case "mustache_section", "mustache_inverted_section":
+			begin := walk.ChildOfKind(child, child.Kind()+"_begin")
+			if begin == nil {
+				break
+			}
+			item.Path = childText(begin, "mustache_tag_name", src)
+			item.Inverted = child.Kind() == "mustache_inverted_section"
+		default:
+			items = append(items, outlineItems(child, src)...)
+			continue
+		}
+		item.Children = outlineItems(child, src)
+		items = append(items, item)
+	}
+	return items
+}
+
+// attributeText returns the value of an html_attribute without its quotes.
+func attributeText(attribute *tree_sitter.Node, src []byte) string {
+	value := attribute.NamedChild(attribute.NamedChildCount() - 1)
+	if value == nil || value.Kind() == "html_attribute_name" {
+		return ""
+	}
+	text := value.Utf8Text(src)
+	if value.Kind() == "html_quoted_attribute_value" && len(text) >= 2 {
+		text = text[1 : len(text)-1]
+	}
+	return text
+}
+
+// headingText returns the text and interpolations inside n, outside of
+// tags, joined by a space where the source has whitespace between them.
+func headingText(n *tree_sitter.Node, src []byte) string {
+	var b strings.Builder
+	last := n.StartByte()
+	walk.Visit(n, func(n *tree_sitter.Node) bool {
+		var text string
+		switch n.Kind() {
+		case "text", "html_entity":
+			text = html.UnescapeString(n.Utf8Text(src))
+		case "mustache_interpolation", "mustache_triple":
+			text = n.Utf8Text(src)
+		case "html_start_tag", "html_end_tag", "html_self_closing_tag":
+			return false
+		default:
+			return true
+		}
+		if b.Len() > 0 && len(bytes.TrimSpace(src[last:n.StartByte()])) < int(n.StartByte()-last) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(text)
+		last = n.EndByte()
+		return false
+	})
+	return strings.Join(strings.Fields(b.String()), " ")
+}
@@ -0,0 +1,88 @@
+package analysis_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+)
+
+func TestOutline(t *testing.T) {
+	src := []byte(`<main id="content">{{#items}}{{> item}}{{/items}}{{^items}}<p id=empty>none</p>{{/items}}</main>`)
+	symbols, err := analysis.Outline(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type symbol struct {
+		Name      string
+		Kind      string
+		Reference bool
+		Text      string
+	}
+	var got []symbol
+	for _, s := range symbols {
+		if string(src[s.NameStartByte:s.NameEndByte]) != s.Name {
+			t.Errorf("name range of %q covers %q", s.Name, src[s.NameStartByte:s.NameEndByte])
+		}
+		got = append(got, symbol{s.Name, s.Kind, s.Reference, string(src[s.StartByte:s.EndByte])})
+	}
+	expected := []symbol{
+		{"content", "id", false, string(src)},
+		{"items", "section", false, "{{#items}}{{> item}}{{/items}}"},
+		{"item", "partial", true, "{{> item}}"},
+		{"items", "section", false, "{{^items}}<p id=empty>none</p>{{/items}}"},
+		{"empty", "id", false, "<p id=empty>none</p>"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Outline() =\n%+v\nwant\n%+v", got, expected)
+	}
+}
+
+func TestOutlineTree(t *testing.T) {
+	src := []byte(`<main id="content" class="page  wide">` +
+		`<h1 class={{theme}}>Hi <b>{{name}}</b> &amp; welcome</h1>` +
+		`{{#items}}<li id="item-{{id}}">{{.}}</li>{{/items}}` +
+		`{{^items}}<br/>{{/items}}` +
+		`</main>` +
+		`<script>go()</script>`)
+	items, err := analysis.OutlineTree(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check the ranges separately, so the expected tree stays readable.
+	var check func(items []analysis.OutlineItem)
+	check = func(items []analysis.OutlineItem) {
+		for i := range items {
+			text := string(src[items[i].StartByte:items[i].EndByte])
+			if items[i].Tag != "" && !strings.HasPrefix(text, "<"+items[i].Tag) ||
+				items[i].Path != "" && !strings.HasPrefix(text[3:], items[i].Path) {
+				t.Errorf("range of %+v covers %q", items[i], text)
+			}
+			items[i].StartByte, items[i].EndByte = 0, 0
+			check(items[i].Children)
+		}
+	}
+	check(items)
+
+	none := []analysis.OutlineItem{}
+	expected := []analysis.OutlineItem{
+		{Tag: "main", ID: "content", Classes: []string{"page", "wide"}, Children: []analysis.OutlineItem{
+			{Tag: "h1", Classes: []string{"{{theme}}"}, Heading: "Hi {{name}} & welcome", Children: []analysis.OutlineItem{
+				{Tag: "b", Classes: []string{}, Children: none},
+			}},
+			{Path: "items", Children: []analysis.OutlineItem{
+				{Tag: "li", ID: "item-{{id}}", Classes: []string{}, Children: none},
+			}},
+			{Path: "items", Inverted: true, Children: []analysis.OutlineItem{
+				{Tag: "br", Classes: []string{}, Children: none},
+			}},
+		}},
+		{Tag: "script", Classes: []string{}, Children: none},
+	}
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("OutlineTree() =\n%+v\nwant\n%+v", items, expected)
+	}
+}
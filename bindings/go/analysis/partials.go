@@ -0,0 +1,182 @@
+package analysis
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// Resolver loads the source of a template or partial by name.
+type Resolver func(name string) ([]byte, error)
+
+// Graph is the partial dependency graph reachable from a root template.
+type Graph struct {
+	// Root is the name of the template the graph was resolved from.
+	Root string
+	// Edges maps each resolved template to the partials it includes, in
+	// order of first appearance.
+	Edges map[string][]string
+	// Cycles lists every include cycle found, each starting and ending with
+	// the same template. Recursive partials are legal Mustache, so cycles
+	// are reported rather than treated as errors.
+	Cycles [][]string
+}
+
+// Templates returns the names of all templates in the graph, sorted.
+func (g *Graph) Templates() []string {
+	names := make([]string, 0, len(g.Edges))
+	for name := range g.Edges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Dependents returns the templates that include name directly or
+// transitively, sorted. Use it to decide what to rebuild when name changes.
+func (g *Graph) Dependents(name string) []string {
+	reverse := map[string][]string{}
+	for from, tos := range g.Edges {
+		for _, to := range tos {
+			reverse[to] = append(reverse[to], from)
+		}
+	}
+	seen := map[string]bool{}
+	queue := []string{name}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, from := range reverse[current] {
+			if !seen[from] {
+				seen[from] = true
+				queue = append(queue, from)
+			}
+		}
+	}
+	delete(seen, name)
+	dependents := make([]string, 0, len(seen))
+	for from := range seen {
+		dependents = append(dependents, from)
+	}
+	sort.Strings(dependents)
+	return dependents
+}
+
+// ResolvePartials parses the template called root, then recursively
+// resolves and parses every partial it includes, parents included.
+func ResolvePartials(root string, resolver Resolver) (*Graph, error) {
+	graph := &Graph{Root: root, Edges: map[string][]string{}}
+	const (
+		visiting = iota + 1
+		done
+	)
+	state := map[string]int{}
+	var stack []string
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i] == name {
+					cycle := append(append([]string{}, stack[i:]...), name)
+					graph.Cycles = append(graph.Cycles, cycle)
+					break
+				}
+			}
+			return nil
+		case done:
+			return nil
+		}
+
+		src, err := resolver(name)
+		if err != nil {
+			return fmt.Errorf("analysis: resolving partial %q: %w", name, err)
+		}
+		partials, err := PartialNames(src)
+		if err != nil {
+			return err
+		}
+
+		state[name] = visiting
+		stack = append(stack, name)
+		graph.Edges[name] = partials
+		for _, partial := range partials {
+			if err := visit(partial); err != nil {
+				return err
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = done
+		return nil
+	}
+
+	if err := visit(root); err != nil {
+		return nil, err
+	}
+	return graph, nil
+}
+
+// PartialNames returns the distinct partial names included by src, in order
+// of first appearance. A parent tag, {{<name}}, includes name as a partial.
+func PartialNames(src []byte) ([]string, error) {
+	tree, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+	return partialNamesIn(tree.RootNode(), src), nil
+}
+
+func partialNamesIn(root *tree_sitter.Node, src []byte) []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, include := range nodesOfKind(root, includeKinds...) {
+		name := includeName(&include, src)
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// includeKinds are the tags that include a partial: {{> name}}, and
+// {{<name}}, which includes it as a parent.
+var includeKinds = []string{"mustache_partial", "mustache_parent"}
+
+// includeName returns the name of the partial n, one of includeKinds,
+// includes, or "".
+func includeName(n *tree_sitter.Node, src []byte) string {
+	switch n.Kind() {
+	case "mustache_partial":
+		if content := walk.ChildOfKind(n, "mustache_partial_content"); content != nil {
+			return strings.TrimSpace(content.Utf8Text(src))
+		}
+	case "mustache_parent":
+		if name := n.Child(0).ChildByFieldName("name"); name != nil {
+			return name.Utf8Text(src)
+		}
+	}
+	return ""
+}
+
+// includeTag returns the tag of n, one of includeKinds, that names the
+// partial: the partial tag itself, or the begin tag of a parent.
+func includeTag(n *tree_sitter.Node) *tree_sitter.Node {
+	if n.Kind() == "mustache_parent" {
+		return n.Child(0)
+	}
+	return n
+}
+
+// nodesOfKind returns all descendants of root (including root) of the given
+// kinds, in document order.
+func nodesOfKind(root *tree_sitter.Node, kinds ...string) []tree_sitter.Node {
+	return slices.Collect(walk.KindNode(root, kinds...))
+}
@@ -0,0 +1,70 @@
+package analysis_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+)
+
+func mapResolver(templates map[string]string) analysis.Resolver {
+	return func(name string) ([]byte, error) {
+		src, ok := templates[name]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		return []byte(src), nil
+	}
+}
+
+func TestResolvePartials(t *testing.T) {
+	resolver := mapResolver(map[string]string{
+		"page":   `{{> header}}<main>{{#items}}{{> item}}{{/items}}</main>{{> footer}}{{> header}}`,
+		"header": `<header>{{> nav}}</header>`,
+		"nav":    `<nav></nav>`,
+		"item":   `<li>{{name}}{{#children}}{{> item}}{{/children}}</li>`,
+		"footer": `<footer></footer>`,
+	})
+
+	graph, err := analysis.ResolvePartials("page", resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := graph.Edges["page"], []string{"header", "item", "footer"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("page edges = %v, want %v", got, want)
+	}
+	if got, want := graph.Templates(), []string{"footer", "header", "item", "nav", "page"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Templates() = %v, want %v", got, want)
+	}
+	if got, want := graph.Cycles, [][]string{{"item", "item"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Cycles = %v, want %v", got, want)
+	}
+	if got, want := graph.Dependents("nav"), []string{"header", "page"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Dependents(nav) = %v, want %v", got, want)
+	}
+}
+
+func TestResolvePartialsParents(t *testing.T) {
+	graph, err := analysis.ResolvePartials("post", mapResolver(map[string]string{
+		"post":   `{{<layout}}{{$main}}{{> item}}{{/main}}{{/layout}}`,
+		"layout": `<main>{{$main}}{{/main}}</main>`,
+		"item":   `<p></p>`,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := graph.Edges["post"], []string{"layout", "item"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("post edges = %v, want %v", got, want)
+	}
+}
+
+func TestResolvePartialsMissing(t *testing.T) {
+	_, err := analysis.ResolvePartials("page", mapResolver(map[string]string{
+		"page": `{{> missing}}`,
+	}))
+	if err == nil {
+		t.Fatal("expected an error for an unresolvable partial")
+	}
+}
@@ -0,0 +1,274 @@
+package analysis
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// ProjectOptions configures CheckProject.
+type ProjectOptions struct {
+	// Partials is the slash-separated directory partial names resolve in.
+	// Templates below it are partials, and the others are pages, which are
+	// rendered directly. If it is empty, partials resolve against the root
+	// and every template is taken to be a page, so none are unused.
+	Partials string
+	// Extensions are the extensions of template files, tried in order when
+	// resolving a partial name. The default is .mustache.
+	Extensions []string
+	// Schema, if not nil, describes the data pages are rendered with, as
+	// for Facts. Interpolations of names it never provides are reported.
+	Schema *Schema
+}
+
+// ProjectFinding is a problem found in a file by CheckProject.
+type ProjectFinding struct {
+	Finding
+	// File is the slash-separated path of the file in the project.
+	File string `json:"file"`
+	// Line and Column are the one-based position of StartByte, with the
+	// column counted in bytes.
+	Line   uint `json:"line"`
+	Column uint `json:"column"`
+}
+
+// CheckProject parses the templates of the project in fsys and reports:
+//
+//   - UnusedPartial for each partial no template includes, at the start
+//     of the partial;
+//   - UndefinedPartial for each include of a partial that has no file;
+//   - MissingVariable, with a schema, for each interpolation in the pages,
+//     and the partials they include, of a name the schema never provides.
+//
+// Findings are sorted by file and offset. Partials are checked for missing
+// variables in the context they are included in, and not at all if no page
+// includes them.
+func CheckProject(fsys fs.FS, opts ProjectOptions) ([]ProjectFinding, error) {
+	extensions := opts.Extensions
+	if len(extensions) == 0 {
+		extensions = []string{".mustache"}
+	}
+	if opts.Partials = path.Clean(opts.Partials); opts.Partials == "." {
+		opts.Partials = ""
+	}
+	p := &project{fsys: fsys, opts: opts, extensions: extensions, files: map[string]*projectFile{}, reported: map[string]bool{}}
+	defer p.close()
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+
+	for _, name := range p.names {
+		file := p.files[name]
+		for _, include := range nodesOfKind(file.tree.RootNode(), includeKinds...) {
+			partial := includeName(&include, file.src)
+			if partial == "" {
+				continue
+			}
+			if target, ok := p.resolve(partial); ok {
+				p.files[target].used = true
+				continue
+			}
+			p.report(file, Finding{
+				Kind:      UndefinedPartial,
+				Path:      partial,
+				Message:   fmt.Sprintf("partial %q has no template", partial),
+				StartByte: includeTag(&include).StartByte(),
+				EndByte:   includeTag(&include).EndByte(),
+			})
+		}
+	}
+	for _, name := range p.names {
+		if file := p.files[name]; file.partial && !file.used {
+			partial := strings.TrimPrefix(trimExtension(name, extensions), opts.Partials+"/")
+			p.report(file, Finding{Kind: UnusedPartial, Path: partial, Message: fmt.Sprintf("partial %q is never included", partial)})
+		}
+	}
+	if opts.Schema != nil {
+		for _, name := range p.names {
+			if file := p.files[name]; !file.partial {
+				p.checkVariables(file, file.tree.RootNode(), []*Schema{opts.Schema}, map[string]bool{name: true})
+			}
+		}
+	}
+
+	sort.SliceStable(p.findings, func(i, j int) bool {
+		a, b := p.findings[i], p.findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.StartByte < b.StartByte
+	})
+	return p.findings, nil
+}
+
+type project struct {
+	fsys       fs.FS
+	opts       ProjectOptions
+	extensions []string
+	// names are the paths of the templates in walk order.
+	names    []string
+	files    map[string]*projectFile
+	findings []ProjectFinding
+	// reported holds the files and offsets of the missing variables found,
+	// so a partial included twice reports each name once.
+	reported map[string]bool
+}
+
+type projectFile struct {
+	name    string
+	src     []byte
+	tree    *tree_sitter.Tree
+	partial bool
+	used    bool
+}
+
+// load reads and parses every template in the project.
+func (p *project) load() error {
+	return fs.WalkDir(p.fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || trimExtension(name, p.extensions) == name {
+			return nil
+		}
+		src, err := fs.ReadFile(p.fsys, name)
+		if err != nil {
+			return err
+		}
+		tree, err := parse(src)
+		if err != nil {
+			return fmt.Errorf("analysis: %s: %w", name, err)
+		}
+		partial := p.opts.Partials != "" && strings.HasPrefix(name, p.opts.Partials+"/")
+		p.names = append(p.names, name)
+		p.files[name] = &projectFile{name: name, src: src, tree: tree, partial: partial}
+		return nil
+	})
+}
+
+func (p *project) close() {
+	for _, file := range p.files {
+		file.tree.Close()
+	}
+}
+
+// resolve returns the template a partial name refers to.
+func (p *project) resolve(partial string) (string, bool) {
+	dir := p.opts.Partials
+	if dir == "" {
+		dir = "."
+	}
+	base := path.Join(dir, partial)
+	if !fs.ValidPath(base) {
+		// The name climbs out of the project.
+		return "", false
+	}
+	for _, ext := range append([]string{""}, p.extensions...) {
+		if _, ok := p.files[base+ext]; ok {
+			return base + ext, true
+		}
+	}
+	return "", false
+}
+
+func (p *project) report(file *projectFile, finding Finding) {
+	before := file.src[:finding.StartByte]
+	p.findings = append(p.findings, ProjectFinding{
+		Finding: finding,
+		File:    file.name,
+		Line:    uint(bytes.Count(before, []byte{'\n'})) + 1,
+		Column:  uint(len(before)-(bytes.LastIndexByte(before, '\n')+1)) + 1,
+	})
+}
+
+// checkVariables reports the interpolations under n of names that stack,
+// the schemas of the context stack, never provides. active holds the
+// templates being checked, so recursive partials are followed once.
+func (p *project) checkVariables(file *projectFile, n *tree_sitter.Node, stack []*Schema, active map[string]bool) {
+	switch n.Kind() {
+	case "mustache_interpolation", "mustache_triple":
+		name := expressionNode(n)
+		if name == nil {
+			return
+		}
+		if truth, _ := lookupSchema(stack, pathKeys(name, file.src)); truth != alwaysFalsy {
+			return
+		}
+		key := fmt.Sprintf("%s:%d", file.name, name.StartByte())
+		if p.reported[key] {
+			return
+		}
+		p.reported[key] = true
+		path := name.Utf8Text(file.src)
+		p.report(file, Finding{
+			Kind:      MissingVariable,
+			Path:      path,
+			Message:   fmt.Sprintf("%q is not provided by the schema", path),
+			StartByte: name.StartByte(),
+			EndByte:   name.EndByte(),
+		})
+		return
+	case "mustache_section", "mustache_inverted_section":
+		name := walk.ChildOfKind(n.Child(0), "mustache_tag_name")
+		if name == nil {
+			break
+		}
+		truth, schema := lookupSchema(stack, pathKeys(name, file.src))
+		inner := stack
+		if n.Kind() == "mustache_section" {
+			item := schema
+			if schema != nil && schema.Type == "array" {
+				item = schema.Items
+			}
+			inner = append(stack[:len(stack):len(stack)], item)
+		}
+		// Content after an {{else}} renders in the outer context. A section
+		// over a name that is never provided renders only that.
+		afterElse := false
+		for i := uint(1); i < n.ChildCount(); i++ {
+			child := n.Child(i)
+			switch {
+			case child.Kind() == "mustache_else":
+				afterElse = true
+			case afterElse:
+				p.checkVariables(file, child, stack, active)
+			case n.Kind() == "mustache_inverted_section" || truth != alwaysFalsy:
+				p.checkVariables(file, child, inner, active)
+			}
+		}
+		return
+	case "mustache_partial", "mustache_parent":
+		if target, ok := p.resolve(includeName(n, file.src)); ok && !active[target] {
+			active[target] = true
+			partial := p.files[target]
+			p.checkVariables(partial, partial.tree.RootNode(), stack, active)
+			delete(active, target)
+		}
+		if n.Kind() == "mustache_partial" {
+			return
+		}
+		// The blocks of a parent tag render in the parent's context, taken
+		// to be the tag's.
+	}
+	for i := uint(0); i < n.ChildCount(); i++ {
+		p.checkVariables(file, n.Child(i), stack, active)
+	}
+}
+
+// trimExtension returns name without the first of extensions it ends
+// with, or name itself if it ends with none.
+func trimExtension(name string, extensions []string) string {
+	for _, ext := range extensions {
+		if strings.HasSuffix(name, ext) && len(name) > len(ext) {
+			return strings.TrimSuffix(name, ext)
+		}
+	}
+	return name
+}
@@ -0,0 +1,109 @@
+package analysis_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+)
+
+func TestCheckProject(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.mustache":              {Data: []byte("<h1>{{title}}</h1>\n{{> header}}\n{{#items}}{{> item}}{{/items}}\n{{> footer}}\n")},
+		"about.mustache":              {Data: []byte("{{#legacy}}{{> old}}{{/legacy}}{{ghost}}")},
+		"notes.txt":                   {Data: []byte("{{> nothing}}")},
+		"partials/header.mustache":    {Data: []byte("<header>{{user.name}} {{user.age}}</header>")},
+		"partials/item.mustache":      {Data: []byte("<li>{{label}} {{title}} {{price}}</li>{{> item}}")},
+		"partials/unused.mustache":    {Data: []byte("{{anything}}")},
+		"partials/old.mustache":       {Data: []byte("{{old}}")},
+		"partials/nested/a.mustache":  {Data: []byte("a")},
+		"partials/nested/b.hbs":       {Data: []byte("b")},
+		"partials/nested/c.mustache~": {Data: []byte("c")},
+	}
+	var schema analysis.Schema
+	err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"title": {"type": "string"},
+			"user": {"type": "object", "properties": {"name": {"type": "string"}}},
+			"items": {"type": "array", "items": {"type": "object", "properties": {"label": {"type": "string"}}}}
+		}
+	}`), &schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := analysis.CheckProject(fsys, analysis.ProjectOptions{Partials: "partials/", Schema: &schema})
+	if err != nil {
+		t.Fatal(err)
+	}
+	type finding struct {
+		Kind         analysis.FindingKind
+		File, Path   string
+		Line, Column uint
+	}
+	var got []finding
+	for _, f := range findings {
+		got = append(got, finding{f.Kind, f.File, f.Path, f.Line, f.Column})
+	}
+	expected := []finding{
+		{analysis.MissingVariable, "about.mustache", "ghost", 1, 34},
+		{analysis.UndefinedPartial, "index.mustache", "footer", 4, 1},
+		{analysis.MissingVariable, "partials/header.mustache", "user.age", 1, 25},
+		{analysis.MissingVariable, "partials/item.mustache", "price", 1, 27},
+		{analysis.UnusedPartial, "partials/nested/a.mustache", "nested/a", 1, 1},
+		{analysis.UnusedPartial, "partials/unused.mustache", "unused", 1, 1},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("CheckProject() =\n%v\nwant\n%v", got, expected)
+	}
+
+	out, err := json.Marshal(findings[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"kind":"missingVariable","path":"ghost","message":"\"ghost\" is not provided by the schema","startByte":33,"endByte":38,"file":"about.mustache","line":1,"column":34}`; string(out) != want {
+		t.Errorf("JSON =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestCheckProjectWithoutPartialsDir(t *testing.T) {
+	fsys := fstest.MapFS{
+		"page.html":       {Data: []byte("{{> shared/nav}}{{> gone}}")},
+		"shared/nav.html": {Data: []byte("<nav></nav>")},
+		"lonely.html":     {Data: []byte("x")},
+	}
+	findings, err := analysis.CheckProject(fsys, analysis.ProjectOptions{Extensions: []string{".html"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].Kind != analysis.UndefinedPartial || findings[0].Path != "gone" || findings[0].Column != 17 {
+		t.Errorf("CheckProject() = %+v", findings)
+	}
+}
+
+func TestCheckProjectParents(t *testing.T) {
+	fsys := fstest.MapFS{
+		"post.mustache":            {Data: []byte("{{<layout}}{{$main}}{{body}}{{/main}}{{/layout}}\n{{<gone}}{{/gone}}")},
+		"partials/layout.mustache": {Data: []byte("<h1>{{title}}</h1>{{$main}}{{/main}}")},
+	}
+	schema := &analysis.Schema{Type: "object", Properties: map[string]*analysis.Schema{"title": {Type: "string"}}}
+	findings, err := analysis.CheckProject(fsys, analysis.ProjectOptions{Partials: "partials", Schema: schema})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range findings {
+		got = append(got, fmt.Sprintf("%s %s:%d:%d %s", f.Kind, f.File, f.Line, f.Column, f.Path))
+	}
+	expected := []string{
+		"missingVariable post.mustache:1:23 body",
+		"undefinedPartial post.mustache:2:1 gone",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("CheckProject() =\n%q\nwant\n%q", got, expected)
+	}
+}
@@ -0,0 +1,237 @@
+package analysis
+
+import (
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// Schema is a JSON-Schema-like description of the data a template expects.
+// It marshals to a subset of JSON Schema.
+type Schema struct {
+	// Type is "object", "array", "string" or "boolean".
+	Type string `json:"type"`
+	// Properties holds the keys of an object.
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	// Required lists the properties of an object that are always present.
+	Required []string `json:"required,omitempty"`
+	// Items describes the elements of an array.
+	Items *Schema `json:"items,omitempty"`
+}
+
+// InferSchema describes the data src reads:
+//
+//   - {{a.b}} makes a an object with a string property b.
+//   - {{#a}}...{{/a}} makes a an array whose items are described by the
+//     names used inside the section, or strings if it uses {{.}}. A
+//     section that reads nothing is a boolean, and one over a name also
+//     used with dotted access is an object.
+//   - {{^a}} alone makes a a boolean.
+//   - {{>*a}} makes a a string, the name of the partial to include.
+//   - {{#each a}} makes a an array, {{#with a}} an object with the
+//     properties read inside, and {{#if a}} and {{#unless a}} read a
+//     without changing the context. Block params, as in
+//     {{#each a as |item|}}, name the item instead. The content of other
+//     helpers is read in the same context, and their arguments are not.
+//
+// Names inside a section are taken to belong to the section's items,
+// though at render time they may resolve further up the context stack.
+// Partials are not followed.
+func InferSchema(src []byte) (*Schema, error) {
+	tree, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+	root := newShape()
+	inferIn(tree.RootNode(), src, inferScope{context: root})
+	return root.objectSchema(), nil
+}
+
+// shape collects how a name is used before it becomes a Schema.
+type shape struct {
+	props map[string]*shape
+	// interpolated is set for {{name}}, or {{.}} within a section over it.
+	interpolated bool
+	// item is the context inside sections over this name.
+	item *shape
+	// list is set for {{#each name}}, which makes name an array even if
+	// its items are never read.
+	list bool
+}
+
+func newShape() *shape {
+	return &shape{props: map[string]*shape{}}
+}
+
+// lookup returns the shape of keys under s, creating it as needed.
+func (s *shape) lookup(keys []string) *shape {
+	for _, key := range keys {
+		next, ok := s.props[key]
+		if !ok {
+			next = newShape()
+			s.props[key] = next
+		}
+		s = next
+	}
+	return s
+}
+
+// inferScope is where names resolve while inferring: the shape of the
+// context, and the shapes block params are bound to. A param bound to nil,
+// such as the index of {{#each list as |item i|}}, describes nothing.
+type inferScope struct {
+	context *shape
+	params  map[string]*shape
+}
+
+// lookup returns the shape keys resolve to in s, or nil.
+func (s inferScope) lookup(keys []string) *shape {
+	if len(keys) > 0 {
+		if param, ok := s.params[keys[0]]; ok {
+			if param == nil {
+				return nil
+			}
+			return param.lookup(keys[1:])
+		}
+	}
+	return s.context.lookup(keys)
+}
+
+// bind returns s with names bound to shapes, in order.
+func (s inferScope) bind(names []string, shapes ...*shape) inferScope {
+	if len(names) == 0 {
+		return s
+	}
+	params := make(map[string]*shape, len(s.params)+len(names))
+	for name, param := range s.params {
+		params[name] = param
+	}
+	for i, name := range names {
+		params[name] = nil
+		if i < len(shapes) {
+			params[name] = shapes[i]
+		}
+	}
+	return inferScope{context: s.context, params: params}
+}
+
+func inferIn(n *tree_sitter.Node, src []byte, scope inferScope) {
+	switch n.Kind() {
+	case "mustache_interpolation", "mustache_triple", "mustache_dynamic_partial":
+		if name := expressionNode(n); name != nil {
+			if target := scope.lookup(pathKeys(name, src)); target != nil {
+				target.interpolated = true
+			}
+		}
+		return
+	case "mustache_section":
+		tag := ParseSectionTag(n.Child(0), src)
+		if tag.Subject == nil || tag.Keys == nil {
+			// {{#.}} iterates the current context in place, and the
+			// content of other helpers is read in the same context.
+			break
+		}
+		target := scope.lookup(tag.Keys)
+		if target == nil {
+			break
+		}
+		truthy, falsy, _ := sectionBranches(n)
+		inner := scope
+		switch tag.Helper {
+		case NoHelper, EachHelper:
+			if target.item == nil {
+				target.item = newShape()
+			}
+			target.list = target.list || tag.Helper == EachHelper
+			if len(tag.BlockParams) > 0 {
+				inner = scope.bind(tag.BlockParams, target.item)
+			} else {
+				inner = inferScope{context: target.item}
+			}
+		case WithHelper:
+			// with pushes an object, whose properties are the names read
+			// inside.
+			if len(tag.BlockParams) > 0 {
+				inner = scope.bind(tag.BlockParams, target)
+			} else {
+				inner = inferScope{context: target}
+			}
+		}
+		for _, child := range truthy {
+			inferIn(child, src, inner)
+		}
+		for _, child := range falsy {
+			inferIn(child, src, scope)
+		}
+		return
+	case "mustache_section_begin", "mustache_inverted_section_begin":
+		// Sections are handled whole; a begin tag met here is an unclosed
+		// one in an error tree, or an inverted section, which reads the
+		// name without changing the context.
+		if tag := ParseSectionTag(n, src); tag.Keys != nil {
+			scope.lookup(tag.Keys)
+		}
+		return
+	}
+	for i := uint(0); i < n.ChildCount(); i++ {
+		inferIn(n.Child(i), src, scope)
+	}
+}
+
+func (s *shape) schema() *Schema {
+	item := s.item
+	if item != nil && len(item.props) == 0 && !item.interpolated {
+		if s.list && len(s.props) == 0 {
+			return &Schema{Type: "array"}
+		}
+		// The section only tests the value.
+		item = nil
+	}
+	switch {
+	case item != nil && len(s.props) > 0:
+		// Dotted access elsewhere: the section pushes an object, not a
+		// list.
+		return mergeShapes(&shape{props: s.props}, &shape{props: item.props}).objectSchema()
+	case item != nil:
+		items := &Schema{Type: "string"}
+		if len(item.props) > 0 {
+			items = item.objectSchema()
+		}
+		return &Schema{Type: "array", Items: items}
+	case len(s.props) > 0:
+		return s.objectSchema()
+	case s.interpolated:
+		return &Schema{Type: "string"}
+	}
+	return &Schema{Type: "boolean"}
+}
+
+func (s *shape) objectSchema() *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for key, prop := range s.props {
+		schema.Properties[key] = prop.schema()
+	}
+	return schema
+}
+
+func mergeShapes(a, b *shape) *shape {
+	merged := newShape()
+	for _, s := range []*shape{a, b} {
+		merged.interpolated = merged.interpolated || s.interpolated
+		merged.list = merged.list || s.list
+		for key, prop := range s.props {
+			if existing, ok := merged.props[key]; ok {
+				merged.props[key] = mergeShapes(existing, prop)
+			} else {
+				merged.props[key] = prop
+			}
+		}
+		if s.item != nil {
+			if merged.item == nil {
+				merged.item = s.item
+			} else {
+				merged.item = mergeShapes(merged.item, s.item)
+			}
+		}
+	}
+	return merged
+}
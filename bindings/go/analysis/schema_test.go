@@ -0,0 +1,69 @@
+package analysis_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+)
+
+func TestInferSchema(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{
+			src:      `<h1>{{title}}</h1><p>{{{user.bio}}}</p>`,
+			expected: `{"type":"object","properties":{"title":{"type":"string"},"user":{"type":"object","properties":{"bio":{"type":"string"}}}}}`,
+		},
+		{
+			src:      `<ul>{{#items}}<li>{{name}} {{price.amount}}</li>{{/items}}</ul>{{^items}}none{{/items}}`,
+			expected: `{"type":"object","properties":{"items":{"type":"array","items":{"type":"object","properties":{"name":{"type":"string"},"price":{"type":"object","properties":{"amount":{"type":"string"}}}}}}}}`,
+		},
+		{
+			src:      `{{#tags}}<b>{{.}}</b>{{/tags}}`,
+			expected: `{"type":"object","properties":{"tags":{"type":"array","items":{"type":"string"}}}}`,
+		},
+		{
+			src:      `{{#admin}}<a href="/admin">admin</a>{{/admin}}{{^loggedIn}}<a>log in</a>{{/loggedIn}}`,
+			expected: `{"type":"object","properties":{"admin":{"type":"boolean"},"loggedIn":{"type":"boolean"}}}`,
+		},
+		{
+			src:      `{{#user}}{{name}}{{/user}}{{user.email}}`,
+			expected: `{"type":"object","properties":{"user":{"type":"object","properties":{"email":{"type":"string"},"name":{"type":"string"}}}}}`,
+		},
+		{
+			src:      `{{#order.lines}}{{#discount}}{{code}}{{/discount}}{{/order.lines}}`,
+			expected: `{"type":"object","properties":{"order":{"type":"object","properties":{"lines":{"type":"array","items":{"type":"object","properties":{"discount":{"type":"array","items":{"type":"object","properties":{"code":{"type":"string"}}}}}}}}}}}`,
+		},
+		{
+			src:      `{{#if admin}}{{name}}{{else}}{{guest}}{{/if}}{{#with user}}{{email}}{{/with}}{{#each tags}}{{.}}{{/each}}{{#each rows}}-{{/each}}`,
+			expected: `{"type":"object","properties":{"admin":{"type":"boolean"},"guest":{"type":"string"},"name":{"type":"string"},"rows":{"type":"array"},"tags":{"type":"array","items":{"type":"string"}},"user":{"type":"object","properties":{"email":{"type":"string"}}}}}`,
+		},
+		{
+			src:      `<{{tag}}><h{{heading.level}}>x</h{{heading.level}}></{{tag}}>`,
+			expected: `{"type":"object","properties":{"heading":{"type":"object","properties":{"level":{"type":"string"}}},"tag":{"type":"string"}}}`,
+		},
+		{
+			src:      `{{>*layout}}{{#pages}}{{>*kind}}{{/pages}}`,
+			expected: `{"type":"object","properties":{"layout":{"type":"string"},"pages":{"type":"array","items":{"type":"object","properties":{"kind":{"type":"string"}}}}}}`,
+		},
+		{
+			src:      `{{#each items as |item i|}}{{i}}{{item.name}}{{title}}{{/each}}{{#with user as |u|}}{{u.email}}{{/with}}{{#format date}}{{when}}{{/format}}`,
+			expected: `{"type":"object","properties":{"items":{"type":"array","items":{"type":"object","properties":{"name":{"type":"string"}}}},"title":{"type":"string"},"user":{"type":"object","properties":{"email":{"type":"string"}}},"when":{"type":"string"}}}`,
+		},
+	}
+	for _, test := range tests {
+		schema, err := analysis.InferSchema([]byte(test.src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := json.Marshal(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != test.expected {
+			t.Errorf("InferSchema(%q) =\n%s\nwant\n%s", test.src, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package analysis
+
+import (
+	"bytes"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// StandaloneTag is a mustache tag that is alone on its line. When rendering,
+// Mustache removes the whole line with the tag: its indentation and its line
+// ending.
+type StandaloneTag struct {
+	Node *tree_sitter.Node
+	// LineStart and LineEnd delimit the line removed with the tag. LineEnd is
+	// past the line ending, or the end of the source on the last line.
+	LineStart uint
+	LineEnd   uint
+	// Indent is the whitespace before the tag. A standalone partial indents
+	// every line of the partial by it.
+	Indent string
+}
+
+// bom is the UTF-8 byte order mark some editors save templates with. The
+// first line starts after it.
+var bom = []byte("\xef\xbb\xbf")
+
+// standaloneKinds are the tags that can be standalone. Interpolations never
+// are.
+var standaloneKinds = map[string]bool{
+	"mustache_section_begin": true, "mustache_section_end": true,
+	"mustache_inverted_section_begin": true, "mustache_inverted_section_end": true,
+	"mustache_erroneous_section_end": true, "mustache_erroneous_inverted_section_end": true,
+	"mustache_comment": true, "mustache_partial": true, "mustache_dynamic_partial": true,
+	"mustache_set_delimiter": true, "mustache_else": true,
+	"mustache_parent_begin": true, "mustache_parent_end": true, "mustache_erroneous_parent_end": true,
+	"mustache_block_begin": true, "mustache_block_end": true, "mustache_erroneous_block_end": true,
+}
+
+// inheritanceKinds are the parent and block tags, which may share their
+// line with each other and still be standalone, as in
+// {{<parent}}{{/parent}}.
+var inheritanceKinds = map[string]bool{
+	"mustache_parent_begin": true, "mustache_parent_end": true, "mustache_erroneous_parent_end": true,
+	"mustache_block_begin": true, "mustache_block_end": true, "mustache_erroneous_block_end": true,
+}
+
+// CanBeStandalone reports whether nodes of kind are treated as standalone
+// when they are alone on their line.
+func CanBeStandalone(kind string) bool {
+	return standaloneKinds[kind]
+}
+
+// Standalone reports whether n is a tag that only whitespace shares its line
+// with, and returns the line it occupies. Parent and block tags may also
+// share it with other parent and block tags.
+func Standalone(n *tree_sitter.Node, src []byte) (StandaloneTag, bool) {
+	if !standaloneKinds[n.Kind()] {
+		return StandaloneTag{}, false
+	}
+	floor := uint(0)
+	if bytes.HasPrefix(src, bom) {
+		floor = uint(len(bom))
+	}
+	var root *tree_sitter.Node
+	if inheritanceKinds[n.Kind()] {
+		root = n
+		for root.Parent() != nil {
+			root = root.Parent()
+		}
+	}
+	start, first := n.StartByte(), n.StartByte()
+	for start > floor && src[start-1] != '\n' {
+		if c := src[start-1]; c == ' ' || c == '\t' {
+			start--
+		} else if tag := inheritanceTagAt(root, start-1, start); tag != nil && tag.EndByte() == start {
+			start, first = tag.StartByte(), tag.StartByte()
+		} else {
+			return StandaloneTag{}, false
+		}
+	}
+	end := n.EndByte()
+	for end < uint(len(src)) {
+		c := src[end]
+		if tag := inheritanceTagAt(root, end, end+1); tag != nil && tag.StartByte() == end {
+			end = tag.EndByte()
+			continue
+		}
+		end++
+		if c == '\n' {
+			break
+		}
+		if c != ' ' && c != '\t' && c != '\r' {
+			return StandaloneTag{}, false
+		}
+	}
+	return StandaloneTag{Node: n, LineStart: start, LineEnd: end, Indent: string(src[start:first])}, true
+}
+
+// inheritanceTagAt returns the parent or block tag spanning src[start:end]
+// in the tree rooted at root, or nil. A nil root has none.
+func inheritanceTagAt(root *tree_sitter.Node, start, end uint) *tree_sitter.Node {
+	if root == nil {
+		return nil
+	}
+	for n := root.DescendantForByteRange(start, end); n != nil; n = n.Parent() {
+		if inheritanceKinds[n.Kind()] {
+			return n
+		}
+	}
+	return nil
+}
+
+// StandaloneTags returns the standalone tags under root in document order.
+func StandaloneTags(root *tree_sitter.Node, src []byte) []StandaloneTag {
+	var tags []StandaloneTag
+	walk.Visit(root, func(n *tree_sitter.Node) bool {
+		if standaloneKinds[n.Kind()] {
+			if tag, ok := Standalone(n, src); ok {
+				tags = append(tags, tag)
+			}
+			return false
+		}
+		return true
+	})
+	return tags
+}
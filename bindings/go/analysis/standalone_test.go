@@ -0,0 +1,101 @@
+package analysis_test
+
+import (
+	"reflect"
+	"testing"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+)
+
+func TestStandaloneTags(t *testing.T) {
+	src := []byte("<ul>\n  {{#items}}\r\n  <li>{{name}}</li>\n  {{/items}} {{! inline }}\n</ul>\n  {{> footer}}")
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		t.Fatal(err)
+	}
+	tree := parser.Parse(src, nil)
+	defer tree.Close()
+
+	type tag struct {
+		Text   string
+		Line   string
+		Indent string
+	}
+	var got []tag
+	for _, s := range analysis.StandaloneTags(tree.RootNode(), src) {
+		got = append(got, tag{s.Node.Utf8Text(src), string(src[s.LineStart:s.LineEnd]), s.Indent})
+	}
+	expected := []tag{
+		{"{{#items}}", "  {{#items}}\r\n", "  "},
+		{"{{> footer}}", "  {{> footer}}", "  "},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("StandaloneTags() =\n%q\nwant\n%q", got, expected)
+	}
+}
+
+// TestStandaloneTagsAfterBOM checks that a byte order mark does not count
+// as content on the first line.
+func TestStandaloneTagsAfterBOM(t *testing.T) {
+	src := []byte("\xef\xbb\xbf{{#a}}\r\nx\r\n{{/a}}\r\n")
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		t.Fatal(err)
+	}
+	tree := parser.Parse(src, nil)
+	defer tree.Close()
+
+	var got []string
+	for _, s := range analysis.StandaloneTags(tree.RootNode(), src) {
+		got = append(got, string(src[s.LineStart:s.LineEnd]))
+	}
+	expected := []string{"{{#a}}\r\n", "{{/a}}\r\n"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("StandaloneTags() = %q, want %q", got, expected)
+	}
+}
+
+func TestCanBeStandalone(t *testing.T) {
+	for kind, want := range map[string]bool{
+		"mustache_section_begin": true,
+		"mustache_partial":       true,
+		"mustache_interpolation": false,
+		"html_element":           false,
+	} {
+		if got := analysis.CanBeStandalone(kind); got != want {
+			t.Errorf("CanBeStandalone(%q) = %v, want %v", kind, got, want)
+		}
+	}
+}
+
+// TestStandaloneTagsInheritance checks that parent and block tags sharing a
+// line with each other are standalone, and other tags are not.
+func TestStandaloneTagsInheritance(t *testing.T) {
+	src := []byte("  {{<layout}}{{$title}}\nx\n{{/title}}{{/layout}}\n{{<a}}{{/a}}{{! no }}\n")
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		t.Fatal(err)
+	}
+	tree := parser.Parse(src, nil)
+	defer tree.Close()
+
+	var got []string
+	for _, s := range analysis.StandaloneTags(tree.RootNode(), src) {
+		got = append(got, s.Node.Utf8Text(src)+" "+string(src[s.LineStart:s.LineEnd])+" "+s.Indent)
+	}
+	expected := []string{
+		"{{<layout}}   {{<layout}}{{$title}}\n   ",
+		"{{$title}}   {{<layout}}{{$title}}\n   ",
+		"{{/title}} {{/title}}{{/layout}}\n ",
+		"{{/layout}} {{/title}}{{/layout}}\n ",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("StandaloneTags() =\n%q\nwant\n%q", got, expected)
+	}
+}
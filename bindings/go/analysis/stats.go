@@ -0,0 +1,152 @@
+package analysis
+
+import (
+	"sort"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// Report measures the size and complexity of a template, for enforcing
+// budgets on them.
+type Report struct {
+	// Elements is the number of HTML elements, including script, style and
+	// other raw text elements.
+	Elements int `json:"elements"`
+	// MaxDepth is the deepest nesting of HTML elements, and
+	// MaxSectionDepth that of sections and inverted sections. A top-level
+	// element or section has depth 1.
+	MaxDepth        int `json:"maxDepth"`
+	MaxSectionDepth int `json:"maxSectionDepth"`
+	// Variables are the distinct paths interpolated or opened as sections,
+	// sorted, without the implicit iterator.
+	Variables []string `json:"variables"`
+	// Partials are the distinct partials included, in order of first
+	// appearance. Their number is the template's fan-out.
+	Partials []string `json:"partials"`
+	Bytes    Sizes    `json:"bytes"`
+}
+
+// Sizes are the byte sizes of the regions of a template. Every byte belongs
+// to exactly one region, so the regions add up to Total.
+type Sizes struct {
+	Total int `json:"total"`
+	// Markup is HTML tags and doctypes, without the Mustache tags in them.
+	Markup int `json:"markup"`
+	// Text is text, entities, raw text other than scripts and styles, and
+	// the whitespace between nodes.
+	Text int `json:"text"`
+	// Mustache is interpolations, section tags and partials.
+	Mustache int `json:"mustache"`
+	Script   int `json:"script"`
+	Style    int `json:"style"`
+	// Comment is HTML and Mustache comments.
+	Comment int `json:"comment"`
+}
+
+// Stats returns the report of src.
+func Stats(src []byte) (Report, error) {
+	tree, err := parse(src)
+	if err != nil {
+		return Report{}, err
+	}
+	defer tree.Close()
+	root := tree.RootNode()
+
+	report := Report{Variables: []string{}, Partials: partialNamesIn(root, src)}
+	seen := map[string]bool{}
+	for _, v := range variablesIn(root, src) {
+		if v.Keys != nil && !seen[v.Path] {
+			seen[v.Path] = true
+			report.Variables = append(report.Variables, v.Path)
+		}
+	}
+	sort.Strings(report.Variables)
+
+	var visit func(n *tree_sitter.Node, depth, sectionDepth int, size *int)
+	visit = func(n *tree_sitter.Node, depth, sectionDepth int, size *int) {
+		switch n.Kind() {
+		case "html_element", "html_script_element", "html_style_element", "html_raw_element":
+			report.Elements++
+			depth++
+			report.MaxDepth = max(report.MaxDepth, depth)
+		case "mustache_section", "mustache_inverted_section":
+			sectionDepth++
+			report.MaxSectionDepth = max(report.MaxSectionDepth, sectionDepth)
+		}
+		if region := report.Bytes.region(n); region != nil {
+			size = region
+		}
+		covered := uint(0)
+		for i := uint(0); i < n.ChildCount(); i++ {
+			child := n.Child(i)
+			covered += child.EndByte() - child.StartByte()
+			visit(child, depth, sectionDepth, size)
+		}
+		*size += int(n.EndByte() - n.StartByte() - covered)
+	}
+	visit(root, 0, 0, &report.Bytes.Text)
+	// Whitespace around the document node is outside every node.
+	report.Bytes.Text += len(src) - int(root.EndByte()-root.StartByte())
+	report.Bytes.Total = len(src)
+	return report, nil
+}
+
+// region returns the size the bytes of n not covered by a more specific
+// region count towards, or nil if n does not start a region of its own.
+func (s *Sizes) region(n *tree_sitter.Node) *int {
+	switch n.Kind() {
+	case "html_start_tag", "html_end_tag", "html_self_closing_tag", "html_erroneous_end_tag", "html_doctype":
+		return &s.Markup
+	case "text", "html_entity":
+		return &s.Text
+	case "mustache_interpolation", "mustache_triple", "mustache_partial",
+		"mustache_section_begin", "mustache_section_end", "mustache_erroneous_section_end",
+		"mustache_inverted_section_begin", "mustache_inverted_section_end", "mustache_erroneous_inverted_section_end":
+		return &s.Mustache
+	case "html_comment", "mustache_comment":
+		return &s.Comment
+	case "html_raw_text":
+		switch n.Parent().Kind() {
+		case "html_script_element":
+			return &s.Script
+		case "html_style_element":
+			return &s.Style
+		}
+		return &s.Text
+	}
+	return nil
+}
+
+// Add merges other into r, as the report of a set of templates: counts are
+// summed, depths are the deepest of either, and the variables and partials
+// of both are kept once each.
+func (r *Report) Add(other Report) {
+	r.Elements += other.Elements
+	r.MaxDepth = max(r.MaxDepth, other.MaxDepth)
+	r.MaxSectionDepth = max(r.MaxSectionDepth, other.MaxSectionDepth)
+	r.Variables = union(r.Variables, other.Variables)
+	sort.Strings(r.Variables)
+	r.Partials = union(r.Partials, other.Partials)
+	r.Bytes.Total += other.Bytes.Total
+	r.Bytes.Markup += other.Bytes.Markup
+	r.Bytes.Text += other.Bytes.Text
+	r.Bytes.Mustache += other.Bytes.Mustache
+	r.Bytes.Script += other.Bytes.Script
+	r.Bytes.Style += other.Bytes.Style
+	r.Bytes.Comment += other.Bytes.Comment
+}
+
+// union returns a followed by the elements of b not in it.
+func union(a, b []string) []string {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, list := range [][]string{a, b} {
+		for _, s := range list {
+			if !seen[s] {
+				seen[s] = true
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
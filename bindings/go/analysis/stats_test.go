@@ -0,0 +1,56 @@
+package analysis_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+)
+
+func TestStats(t *testing.T) {
+	src := []byte("<ul class=\"{{c}}\">\n" +
+		"{{#items}}{{^empty}}<li>{{.}} &amp; {{user.name}}</li>{{/empty}}{{/items}}\n" +
+		"</ul>\n" +
+		"{{> footer}}{{> footer}}<!-- x -->{{! y }}\n" +
+		"<script>go()</script><style>p{}</style>\n")
+	report, err := analysis.Stats(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := analysis.Report{
+		Elements:        4,
+		MaxDepth:        2,
+		MaxSectionDepth: 2,
+		Variables:       []string{"c", "empty", "items", "user.name"},
+		Partials:        []string{"footer"},
+		Bytes: analysis.Sizes{
+			Total:    len(src),
+			Markup:   len(`<ul class="">`) + len("<li></li></ul><script></script><style></style>"),
+			Text:     len(" &amp; ") + len("\n\n\n\n\n"),
+			Mustache: len("{{c}}{{#items}}{{^empty}}{{.}}{{user.name}}{{/empty}}{{/items}}{{> footer}}{{> footer}}"),
+			Script:   len("go()"),
+			Style:    len("p{}"),
+			Comment:  len("<!-- x -->{{! y }}"),
+		},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("Stats() =\n%+v\nwant\n%+v", report, expected)
+	}
+}
+
+func TestReportAdd(t *testing.T) {
+	a := analysis.Report{Elements: 2, MaxDepth: 3, MaxSectionDepth: 1, Variables: []string{"b", "c"}, Partials: []string{"x"}, Bytes: analysis.Sizes{Total: 10, Text: 10}}
+	b := analysis.Report{Elements: 1, MaxDepth: 1, MaxSectionDepth: 2, Variables: []string{"a", "c"}, Partials: []string{"y", "x"}, Bytes: analysis.Sizes{Total: 5, Markup: 5}}
+	a.Add(b)
+	expected := analysis.Report{
+		Elements:        3,
+		MaxDepth:        3,
+		MaxSectionDepth: 2,
+		Variables:       []string{"a", "b", "c"},
+		Partials:        []string{"x", "y"},
+		Bytes:           analysis.Sizes{Total: 15, Markup: 5, Text: 10},
+	}
+	if !reflect.DeepEqual(a, expected) {
+		t.Errorf("Add() =\n%+v\nwant\n%+v", a, expected)
+	}
+}
@@ -0,0 +1,191 @@
+package analysis
+
+import (
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// Kind describes how a template references a variable.
+type Kind int
+
+const (
+	// Escaped is an HTML-escaped interpolation: {{name}}.
+	Escaped Kind = iota
+	// Unescaped is a raw interpolation: {{{name}}} or {{&name}}.
+	Unescaped
+	// Section opens a section: {{#name}}.
+	Section
+	// InvertedSection opens an inverted section: {{^name}}.
+	InvertedSection
+	// DynamicPartial names the partial to include: {{>*name}}.
+	DynamicPartial
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Escaped:
+		return "escaped"
+	case Unescaped:
+		return "unescaped"
+	case Section:
+		return "section"
+	case InvertedSection:
+		return "inverted"
+	case DynamicPartial:
+		return "dynamicPartial"
+	}
+	return "unknown"
+}
+
+// Variable is a single variable reference in a template.
+type Variable struct {
+	// Path is the dotted name as written, e.g. "user.name". The implicit
+	// iterator {{.}} has the path ".".
+	Path string
+	// Keys are the components of Path, e.g. ["user", "name"]. They are nil
+	// for the implicit iterator.
+	Keys []string
+	Kind Kind
+	// StartByte and EndByte delimit the name within the source.
+	StartByte uint
+	EndByte   uint
+}
+
+// ExtractVariables returns every interpolated, section and dynamic partial
+// variable in src, in document order.
+func ExtractVariables(src []byte) ([]Variable, error) {
+	tree, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+	return variablesIn(tree.RootNode(), src), nil
+}
+
+func variablesIn(root *tree_sitter.Node, src []byte) []Variable {
+	var variables []Variable
+	walk.Visit(root, func(node *tree_sitter.Node) bool {
+		switch node.Kind() {
+		case "mustache_interpolation", "mustache_triple":
+			kind := Escaped
+			if node.Kind() == "mustache_triple" {
+				kind = Unescaped
+			}
+			// {{helper arg}} references its arguments, not the helper.
+			if call := walk.ChildOfKind(node, "mustache_helper_call"); call != nil {
+				variables = appendArguments(variables, call, kind, src)
+			} else if name := expressionNode(node); name != nil {
+				variables = append(variables, newVariable(name, kind, src))
+			}
+			return false
+		case "mustache_section_begin", "mustache_inverted_section_begin":
+			kind := Section
+			if node.Kind() == "mustache_inverted_section_begin" {
+				kind = InvertedSection
+			}
+			// A section with arguments opens a block helper such as
+			// {{#if cond}}, whose name is not a variable.
+			if hasArguments(node) {
+				variables = appendArguments(variables, node, kind, src)
+			} else if name := walk.ChildOfKind(node, "mustache_tag_name"); name != nil {
+				variables = append(variables, newVariable(name, kind, src))
+			}
+			return false
+		case "mustache_else":
+			variables = appendArguments(variables, node, Section, src)
+			return false
+		case "mustache_dynamic_partial":
+			if name := node.ChildByFieldName("name"); name != nil {
+				variables = append(variables, newVariable(name, DynamicPartial, src))
+			}
+			return false
+		}
+		return true
+	})
+	return variables
+}
+
+func newVariable(name *tree_sitter.Node, kind Kind, src []byte) Variable {
+	return Variable{
+		Path:      name.Utf8Text(src),
+		Keys:      pathKeys(name, src),
+		Kind:      kind,
+		StartByte: name.StartByte(),
+		EndByte:   name.EndByte(),
+	}
+}
+
+// pathKeys returns the components of a name node. Path expressions carry
+// their keys as children; section tag names are a single token, so they are
+// split on ".".
+func pathKeys(name *tree_sitter.Node, src []byte) []string {
+	switch name.Kind() {
+	case "mustache_path_expression":
+		var keys []string
+		for i := uint(0); i < name.NamedChildCount(); i++ {
+			keys = append(keys, name.NamedChild(i).Utf8Text(src))
+		}
+		return keys
+	case "mustache_identifier":
+		return []string{name.Utf8Text(src)}
+	case "mustache_tag_name":
+		if text := name.Utf8Text(src); text != "." {
+			return strings.Split(text, ".")
+		}
+	}
+	return nil
+}
+
+// expressionNode returns the name child of an interpolation: a path
+// expression, an identifier, or the "." of the implicit iterator. It is nil
+// for a helper call.
+func expressionNode(node *tree_sitter.Node) *tree_sitter.Node {
+	for i := uint(0); i < node.ChildCount(); i++ {
+		child := node.Child(i)
+		switch child.Kind() {
+		case "mustache_path_expression", "mustache_identifier", "mustache_implicit_iterator", ".":
+			return child
+		}
+	}
+	return nil
+}
+
+// hasArguments reports whether node, a tag, helper call or subexpression,
+// has param or hash fields.
+func hasArguments(node *tree_sitter.Node) bool {
+	for i := uint(0); i < node.ChildCount(); i++ {
+		if field := node.FieldNameForChild(uint32(i)); field == "param" || field == "hash" {
+			return true
+		}
+	}
+	return false
+}
+
+// appendArguments appends the variables in the params and hash values of
+// node, a tag, helper call or subexpression, as references of kind. Helper
+// names, string literals and hash keys are not variables; subexpressions
+// are walked for their own arguments.
+func appendArguments(variables []Variable, node *tree_sitter.Node, kind Kind, src []byte) []Variable {
+	for i := uint(0); i < node.ChildCount(); i++ {
+		argument := node.Child(i)
+		switch node.FieldNameForChild(uint32(i)) {
+		case "param":
+		case "hash":
+			if argument = argument.ChildByFieldName("value"); argument == nil {
+				continue
+			}
+		default:
+			continue
+		}
+		switch argument.Kind() {
+		case "mustache_path_expression", "mustache_identifier", "mustache_implicit_iterator":
+			variables = append(variables, newVariable(argument, kind, src))
+		case "mustache_subexpression":
+			variables = appendArguments(variables, argument, kind, src)
+		}
+	}
+	return variables
+}
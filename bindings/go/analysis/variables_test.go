@@ -0,0 +1,117 @@
+package analysis_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+)
+
+func TestExtractVariables(t *testing.T) {
+	src := []byte(`<p title="{{title}}">{{user.name}}</p>{{#items}}{{{html}}}{{/items}}{{^empty}}{{.}}{{/empty}}`)
+	variables, err := analysis.ExtractVariables(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []analysis.Variable{
+		{Path: "title", Keys: []string{"title"}, Kind: analysis.Escaped, StartByte: 12, EndByte: 17},
+		{Path: "user.name", Keys: []string{"user", "name"}, Kind: analysis.Escaped, StartByte: 23, EndByte: 32},
+		{Path: "items", Keys: []string{"items"}, Kind: analysis.Section, StartByte: 41, EndByte: 46},
+		{Path: "html", Keys: []string{"html"}, Kind: analysis.Unescaped, StartByte: 51, EndByte: 55},
+		{Path: "empty", Keys: []string{"empty"}, Kind: analysis.InvertedSection, StartByte: 71, EndByte: 76},
+		{Path: ".", Kind: analysis.Escaped, StartByte: 80, EndByte: 81},
+	}
+	if !reflect.DeepEqual(variables, expected) {
+		t.Errorf("ExtractVariables() =\n%+v\nwant\n%+v", variables, expected)
+	}
+	for _, v := range variables {
+		if got := string(src[v.StartByte:v.EndByte]); got != v.Path {
+			t.Errorf("range of %q covers %q", v.Path, got)
+		}
+	}
+}
+
+func TestExtractVariablesKeys(t *testing.T) {
+	variables, err := analysis.ExtractVariables([]byte(`{{#order.items}}{{product.price.amount}}{{/order.items}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]string{{"order", "items"}, {"product", "price", "amount"}}
+	if len(variables) != len(expected) {
+		t.Fatalf("got %d variables, want %d", len(variables), len(expected))
+	}
+	for i, v := range variables {
+		if !reflect.DeepEqual(v.Keys, expected[i]) {
+			t.Errorf("Keys of %q = %q, want %q", v.Path, v.Keys, expected[i])
+		}
+	}
+}
+
+func TestExtractVariablesDynamicPartials(t *testing.T) {
+	variables, err := analysis.ExtractVariables([]byte(`{{>*layout}}{{> *page.kind }}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []analysis.Variable{
+		{Path: "layout", Keys: []string{"layout"}, Kind: analysis.DynamicPartial, StartByte: 4, EndByte: 10},
+		{Path: "page.kind", Keys: []string{"page", "kind"}, Kind: analysis.DynamicPartial, StartByte: 17, EndByte: 26},
+	}
+	if !reflect.DeepEqual(variables, expected) {
+		t.Errorf("ExtractVariables() =\n%+v\nwant\n%+v", variables, expected)
+	}
+}
+
+func TestExtractVariablesTagNames(t *testing.T) {
+	variables, err := analysis.ExtractVariables([]byte(`<{{tag}}><h{{level}}>x</h{{level}}></{{tag}}>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []analysis.Variable{
+		{Path: "tag", Keys: []string{"tag"}, Kind: analysis.Escaped, StartByte: 3, EndByte: 6},
+		{Path: "level", Keys: []string{"level"}, Kind: analysis.Escaped, StartByte: 13, EndByte: 18},
+		{Path: "level", Keys: []string{"level"}, Kind: analysis.Escaped, StartByte: 27, EndByte: 32},
+		{Path: "tag", Keys: []string{"tag"}, Kind: analysis.Escaped, StartByte: 39, EndByte: 42},
+	}
+	if !reflect.DeepEqual(variables, expected) {
+		t.Errorf("ExtractVariables() =\n%+v\nwant\n%+v", variables, expected)
+	}
+}
+
+func TestKindString(t *testing.T) {
+	if got := analysis.InvertedSection.String(); got != "inverted" {
+		t.Errorf("InvertedSection.String() = %q", got)
+	}
+}
+
+func TestExtractVariablesHelpers(t *testing.T) {
+	src := []byte(`{{format date "short" (lower user.name) locale=lang}}{{#if admin}}a{{else if (eq role "editor") x=.}}b{{/if}}{{^unless ok}}c{{/unless}}{{{raw html}}}`)
+	variables, err := analysis.ExtractVariables(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type reference struct {
+		Path string
+		Kind analysis.Kind
+	}
+	var got []reference
+	for _, v := range variables {
+		got = append(got, reference{v.Path, v.Kind})
+		if text := string(src[v.StartByte:v.EndByte]); text != v.Path {
+			t.Errorf("range of %q covers %q", v.Path, text)
+		}
+	}
+	expected := []reference{
+		{"date", analysis.Escaped},
+		{"user.name", analysis.Escaped},
+		{"lang", analysis.Escaped},
+		{"admin", analysis.Section},
+		{"role", analysis.Section},
+		{".", analysis.Section},
+		{"ok", analysis.InvertedSection},
+		{"html", analysis.Unescaped},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("ExtractVariables() =\n%v\nwant\n%v", got, expected)
+	}
+}
@@ -0,0 +1,25 @@
+// Package ast provides typed wrappers for the named nodes of the
+// htmlmustache grammar, generated from src/node-types.json.
+//
+// Each wrapper embeds the *tree_sitter.Node it wraps and adds accessors for
+// its fields and children, so that kinds are checked by the compiler
+// instead of spelled as strings:
+//
+//	element, _ := ast.AsElement(node)
+//	if start, ok := element.StartTag(); ok {
+//		for _, attribute := range start.Attributes() {
+//			...
+//		}
+//	}
+//
+// Type names drop the html_ and mustache_ prefixes (html_element is
+// Element, mustache_section is Section) unless both an HTML and a Mustache
+// node would get the same name, as with MustacheComment.
+//
+// Each supertype of the grammar, such as _node, is an interface named with
+// an Any prefix (AnyNode) that the wrappers of its subtypes implement. A
+// field holding a supertype returns the interface, to be narrowed with a
+// type switch.
+package ast
+
+//go:generate go run ./internal/gen
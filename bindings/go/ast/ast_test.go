@@ -0,0 +1,227 @@
+package ast_test
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/ast"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/ast/internal/astgen"
+)
+
+func TestNodesUpToDate(t *testing.T) {
+	want, err := astgen.Generate(tree_sitter_htmlmustache.NodeTypes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("nodes.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("nodes.go is out of date; run go generate")
+	}
+}
+
+// TestGenerateSupertypes checks that supertypes become interfaces, which
+// fields of the supertype return and whose subtypes are listed as children.
+func TestGenerateSupertypes(t *testing.T) {
+	nodeTypes := []byte(`[
+		{"type": "_node", "named": true, "subtypes": [{"type": "_mustache_node", "named": true}, {"type": "text", "named": true}]},
+		{"type": "_mustache_node", "named": true, "subtypes": [{"type": "mustache_interpolation", "named": true}]},
+		{"type": "document", "named": true, "fields": {}, "children": {"multiple": true, "required": false, "types": [{"type": "_node", "named": true}]}},
+		{"type": "html_element", "named": true, "fields": {"content": {"multiple": true, "required": false, "types": [{"type": "_node", "named": true}]}}},
+		{"type": "mustache_interpolation", "named": true, "fields": {}},
+		{"type": "text", "named": true}
+	]`)
+	src, err := astgen.Generate(nodeTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"type AnyNode interface {",
+		"func AsAnyMustacheNode(node *tree_sitter.Node) (AnyMustacheNode, bool) {",
+		"func (Interpolation) isAnyMustacheNode() {}",
+		"func (Interpolation) isAnyNode() {}",
+		"func (Text) isAnyNode() {}",
+		"func (n Element) Content() []AnyNode {",
+		"func (n Document) Interpolations() []Interpolation {",
+		"func (n Document) Texts() []Text {",
+	} {
+		if !bytes.Contains(src, []byte(want)) {
+			t.Errorf("generated code has no %q", want)
+		}
+	}
+}
+
+func TestAccessors(t *testing.T) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		t.Fatal(err)
+	}
+	src := []byte(`<a href="/" class=x>{{#items}}{{user.name}}{{/items}}</a>`)
+	tree := parser.Parse(src, nil)
+	defer tree.Close()
+
+	document, ok := ast.AsDocument(tree.RootNode())
+	if !ok {
+		t.Fatal("root is not a document")
+	}
+	element, ok := document.Element()
+	if !ok {
+		t.Fatal("no element")
+	}
+	open, _ := element.Open()
+	start, ok := ast.AsStartTag(open)
+	if !ok {
+		t.Fatal("no start tag")
+	}
+	if name, ok := start.Name(); !ok || name.Utf8Text(src) != "a" {
+		t.Errorf("Name() = %v, %v", name, ok)
+	}
+	var names []string
+	for _, attr := range start.Attribute() {
+		attribute, ok := attr.(ast.Attribute)
+		if !ok {
+			continue
+		}
+		if name, ok := attribute.Name(); ok {
+			names = append(names, name.Utf8Text(src))
+		}
+	}
+	if len(names) != 2 || names[0] != "href" || names[1] != "class" {
+		t.Errorf("attribute names = %q", names)
+	}
+
+	children := element.Content()
+	if len(children) != 1 {
+		t.Fatalf("element content = %v", children)
+	}
+	section, ok := ast.AsSection(children[0])
+	if !ok {
+		t.Fatal("no section")
+	}
+	begin, _ := section.Open()
+	if name, ok := begin.Name(); !ok || name.Utf8Text(src) != "items" {
+		t.Errorf("section name = %v, %v", name, ok)
+	}
+	content := section.Content()
+	if len(content) != 1 {
+		t.Fatalf("section content = %v", content)
+	}
+	interpolation, _ := ast.AsInterpolation(content[0])
+	expression, _ := interpolation.Expression()
+	path, _ := ast.AsPathExpression(expression)
+	var keys []string
+	for _, identifier := range path.Key() {
+		keys = append(keys, identifier.Utf8Text(src))
+	}
+	if len(keys) != 2 || keys[0] != "user" || keys[1] != "name" {
+		t.Errorf("path keys = %q", keys)
+	}
+
+	if _, ok := ast.AsSection(element.Node); ok {
+		t.Error("AsSection accepted an element")
+	}
+	if _, ok := ast.Wrap(section.Node).(ast.Section); !ok {
+		t.Errorf("Wrap(section) = %T", ast.Wrap(section.Node))
+	}
+}
+
+func TestElseChain(t *testing.T) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		t.Fatal(err)
+	}
+	src := []byte(`{{#each users as |user|}}{{user}}{{else each guests as |guest i|}}{{i}}{{else if admin}}root{{else}}-{{/each}}`)
+	tree := parser.Parse(src, nil)
+	defer tree.Close()
+
+	section, ok := ast.AsSection(tree.RootNode().NamedChild(0))
+	if !ok {
+		t.Fatal("no section")
+	}
+	var chain []string
+	for _, node := range section.Content() {
+		branch, ok := ast.AsElse(node)
+		if !ok {
+			continue
+		}
+		link := "else"
+		if name, ok := branch.Name(); ok {
+			link += " " + name.Utf8Text(src)
+		}
+		if params, ok := branch.BlockParams(); ok {
+			for _, identifier := range params.Identifiers() {
+				link += " " + identifier.Utf8Text(src)
+			}
+		}
+		chain = append(chain, link)
+	}
+	if len(chain) != 3 || chain[0] != "else each guest i" || chain[1] != "else if" || chain[2] != "else" {
+		t.Errorf("else chain = %q", chain)
+	}
+}
+
+func TestBlockContent(t *testing.T) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		t.Fatal(err)
+	}
+	src := []byte(`{{<layout}}{{$title}}<b>{{name}}</b>{{/title}}{{/layout}}`)
+	tree := parser.Parse(src, nil)
+	defer tree.Close()
+
+	parent, ok := ast.AsParent(tree.RootNode().NamedChild(0))
+	if !ok {
+		t.Fatal("no parent")
+	}
+	var blocks []ast.AnyNode = parent.Content()
+	if len(blocks) != 1 {
+		t.Fatalf("parent content = %v", blocks)
+	}
+	block, ok := blocks[0].(ast.Block)
+	if !ok {
+		t.Fatalf("parent content = %T", blocks[0])
+	}
+	var content []ast.AnyNode = block.Content()
+	if len(content) != 1 {
+		t.Fatalf("block content = %v", content)
+	}
+	if _, ok := content[0].(ast.Element); !ok {
+		t.Errorf("block content = %T", content[0])
+	}
+}
+
+func TestSinkKind(t *testing.T) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		t.Fatal(err)
+	}
+	src := []byte(`<a HREF="/" onClick="x()" style="color: red" title="t" srcset=a.png>x</a>`)
+	tree := parser.Parse(src, nil)
+	defer tree.Close()
+
+	document, _ := ast.AsDocument(tree.RootNode())
+	element, _ := document.Element()
+	open, _ := element.Open()
+	start, _ := ast.AsStartTag(open)
+	var got []ast.SinkKind
+	for _, attr := range start.Attribute() {
+		if attribute, ok := attr.(ast.Attribute); ok {
+			got = append(got, attribute.SinkKind(src))
+		}
+	}
+	want := []ast.SinkKind{ast.SinkURL, ast.SinkJS, ast.SinkCSS, ast.SinkNone, ast.SinkURL}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SinkKind() = %v, want %v", got, want)
+	}
+}
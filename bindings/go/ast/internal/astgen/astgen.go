@@ -0,0 +1,277 @@
+// Package astgen generates the typed node wrappers of package ast from the
+// grammar's node-types.json.
+package astgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+)
+
+type nodeType struct {
+	Type     string               `json:"type"`
+	Named    bool                 `json:"named"`
+	Fields   map[string]childInfo `json:"fields"`
+	Children *childInfo           `json:"children"`
+	Subtypes []typeRef            `json:"subtypes"`
+}
+
+type childInfo struct {
+	Multiple bool      `json:"multiple"`
+	Required bool      `json:"required"`
+	Types    []typeRef `json:"types"`
+}
+
+type typeRef struct {
+	Type  string `json:"type"`
+	Named bool   `json:"named"`
+}
+
+// Generate returns the Go source of package ast for the given
+// node-types.json content.
+func Generate(nodeTypesJSON []byte) ([]byte, error) {
+	var types []nodeType
+	if err := json.Unmarshal(nodeTypesJSON, &types); err != nil {
+		return nil, fmt.Errorf("astgen: parsing node types: %w", err)
+	}
+
+	var named, supertypes []nodeType
+	for _, t := range types {
+		switch {
+		case t.Named && !hidden(t.Type):
+			named = append(named, t)
+		case t.Named && len(t.Subtypes) > 0:
+			supertypes = append(supertypes, t)
+		}
+	}
+	sort.Slice(named, func(i, j int) bool { return named[i].Type < named[j].Type })
+	sort.Slice(supertypes, func(i, j int) bool { return supertypes[i].Type < supertypes[j].Type })
+	names := goNames(named)
+	subtypes := map[string][]typeRef{}
+	for _, t := range supertypes {
+		names[t.Type] = supertypeName(t.Type)
+		subtypes[t.Type] = t.Subtypes
+	}
+	// in maps each kind to the supertypes it is a subtype of, directly or
+	// through another supertype.
+	in := map[string][]string{}
+	for _, t := range supertypes {
+		for _, sub := range expand(t.Subtypes, subtypes) {
+			in[sub.Type] = append(in[sub.Type], t.Type)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("// Code generated by astgen from src/node-types.json. DO NOT EDIT.\n\n")
+	b.WriteString("package ast\n\n")
+	b.WriteString("import tree_sitter \"github.com/tree-sitter/go-tree-sitter\"\n\n")
+
+	b.WriteString("// Node kinds, as returned by tree_sitter.Node.Kind.\nconst (\n")
+	for _, t := range named {
+		fmt.Fprintf(&b, "\tKind%s = %q\n", names[t.Type], t.Type)
+	}
+	b.WriteString(")\n\n")
+
+	b.WriteString("// Wrap returns node as its typed wrapper, or node itself if its kind has\n")
+	b.WriteString("// no wrapper.\nfunc Wrap(node *tree_sitter.Node) any {\n\tswitch node.Kind() {\n")
+	for _, t := range named {
+		fmt.Fprintf(&b, "\tcase Kind%s:\n\t\treturn %s{node}\n", names[t.Type], names[t.Type])
+	}
+	b.WriteString("\t}\n\treturn node\n}\n")
+
+	for _, t := range supertypes {
+		writeSupertype(&b, t, subtypes, names)
+	}
+
+	for _, t := range named {
+		name := names[t.Type]
+		fmt.Fprintf(&b, "\n// %s is a %s node.\ntype %s struct{ *tree_sitter.Node }\n", name, t.Type, name)
+		fmt.Fprintf(&b, "\n// As%s returns node as a %s if it is one.\n", name, name)
+		fmt.Fprintf(&b, "func As%s(node *tree_sitter.Node) (%s, bool) {\n", name, name)
+		fmt.Fprintf(&b, "\tif node == nil || node.Kind() != Kind%s {\n\t\treturn %s{}, false\n\t}\n", name, name)
+		fmt.Fprintf(&b, "\treturn %s{node}, true\n}\n", name)
+		for _, super := range in[t.Type] {
+			fmt.Fprintf(&b, "\nfunc (%s) is%s() {}\n", name, names[super])
+		}
+
+		fieldNames := make([]string, 0, len(t.Fields))
+		for field := range t.Fields {
+			fieldNames = append(fieldNames, field)
+		}
+		sort.Strings(fieldNames)
+		for _, field := range fieldNames {
+			writeField(&b, name, field, t.Fields[field], names, subtypes)
+		}
+		if t.Children != nil {
+			for _, child := range expand(t.Children.Types, subtypes) {
+				childName, ok := names[child.Type]
+				if !ok || !child.Named {
+					continue
+				}
+				writeChild(&b, name, childName, t.Children.Multiple)
+			}
+		}
+	}
+
+	out, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("astgen: formatting: %w", err)
+	}
+	return out, nil
+}
+
+// writeField writes the accessor of a field. Fields with a single node type
+// return that type, or the interface of a supertype; others return the plain
+// node.
+func writeField(b *strings.Builder, parent, field string, info childInfo, names map[string]string, subtypes map[string][]typeRef) {
+	method := camel(field)
+	result, wrap := "*tree_sitter.Node", "%s"
+	super := false
+	if len(info.Types) == 1 {
+		if name, ok := names[info.Types[0].Type]; ok {
+			result, wrap = name, name+"{%s}"
+			super = subtypes[info.Types[0].Type] != nil
+		}
+	}
+	fmt.Fprintf(b, "\n// %s returns the %s field.\n", method, field)
+	if info.Multiple {
+		fmt.Fprintf(b, "func (n %s) %s() []%s {\n", parent, method, result)
+		fmt.Fprintf(b, "\tvar nodes []%s\n", result)
+		fmt.Fprintf(b, "\tcursor := n.Walk()\n\tdefer cursor.Close()\n")
+		fmt.Fprintf(b, "\tfor _, child := range n.ChildrenByFieldName(%q, cursor) {\n", field)
+		if super {
+			fmt.Fprintf(b, "\t\tif node, ok := As%s(&child); ok {\n\t\t\tnodes = append(nodes, node)\n\t\t}\n", result)
+			fmt.Fprintf(b, "\t}\n\treturn nodes\n}\n")
+			return
+		}
+		fmt.Fprintf(b, "\t\tnodes = append(nodes, "+wrap+")\n\t}\n\treturn nodes\n}\n", "&child")
+		return
+	}
+	fmt.Fprintf(b, "func (n %s) %s() (%s, bool) {\n", parent, method, result)
+	fmt.Fprintf(b, "\tchild := n.ChildByFieldName(%q)\n", field)
+	if super {
+		fmt.Fprintf(b, "\treturn As%s(child)\n}\n", result)
+		return
+	}
+	if result == "*tree_sitter.Node" {
+		fmt.Fprintf(b, "\treturn child, child != nil\n}\n")
+		return
+	}
+	fmt.Fprintf(b, "\tif child == nil {\n\t\treturn %s{}, false\n\t}\n", result)
+	fmt.Fprintf(b, "\treturn "+wrap+", true\n}\n", "child")
+}
+
+// writeSupertype writes the interface of a supertype, which the wrappers of
+// its subtypes implement, and the function converting a node to it.
+func writeSupertype(b *strings.Builder, t nodeType, subtypes map[string][]typeRef, names map[string]string) {
+	name := names[t.Type]
+	var kinds []string
+	for _, sub := range expand(t.Subtypes, subtypes) {
+		if sub.Named {
+			kinds = append(kinds, names[sub.Type])
+		}
+	}
+	fmt.Fprintf(b, "\n// %s is a node of the %s supertype: %s.\n", name, t.Type, strings.Join(kinds, ", "))
+	fmt.Fprintf(b, "type %s interface {\n\tKind() string\n\tis%s()\n}\n", name, name)
+	fmt.Fprintf(b, "\n// As%s returns node as a %s if it is one.\n", name, name)
+	fmt.Fprintf(b, "func As%s(node *tree_sitter.Node) (%s, bool) {\n", name, name)
+	fmt.Fprintf(b, "\tif node == nil {\n\t\treturn nil, false\n\t}\n")
+	fmt.Fprintf(b, "\tn, ok := Wrap(node).(%s)\n\treturn n, ok\n}\n", name)
+}
+
+// expand replaces the supertypes among types with their subtypes, sorted
+// and without duplicates.
+func expand(types []typeRef, subtypes map[string][]typeRef) []typeRef {
+	seen := map[typeRef]bool{}
+	var out []typeRef
+	var visit func(types []typeRef)
+	visit = func(types []typeRef) {
+		for _, t := range types {
+			if sub, ok := subtypes[t.Type]; ok {
+				visit(sub)
+			} else if !seen[t] {
+				seen[t] = true
+				out = append(out, t)
+			}
+		}
+	}
+	visit(types)
+	sort.Slice(out, func(i, j int) bool { return out[i].Type < out[j].Type })
+	return out
+}
+
+// writeChild writes the accessor of the first child of a kind, and of all
+// of them when the node can have several children.
+func writeChild(b *strings.Builder, parent, child string, multiple bool) {
+	fmt.Fprintf(b, "\n// %s returns the first %s child.\n", child, child)
+	fmt.Fprintf(b, "func (n %s) %s() (%s, bool) {\n", parent, child, child)
+	fmt.Fprintf(b, "\tfor i := uint(0); i < n.NamedChildCount(); i++ {\n")
+	fmt.Fprintf(b, "\t\tif c := n.NamedChild(i); c.Kind() == Kind%s {\n\t\t\treturn %s{c}, true\n\t\t}\n\t}\n", child, child)
+	fmt.Fprintf(b, "\treturn %s{}, false\n}\n", child)
+	if !multiple {
+		return
+	}
+	plural := pluralize(child)
+	fmt.Fprintf(b, "\n// %s returns the %s children.\n", plural, child)
+	fmt.Fprintf(b, "func (n %s) %s() []%s {\n", parent, plural, child)
+	fmt.Fprintf(b, "\tvar nodes []%s\n", child)
+	fmt.Fprintf(b, "\tfor i := uint(0); i < n.NamedChildCount(); i++ {\n")
+	fmt.Fprintf(b, "\t\tif c := n.NamedChild(i); c.Kind() == Kind%s {\n\t\t\tnodes = append(nodes, %s{c})\n\t\t}\n\t}\n", child, child)
+	fmt.Fprintf(b, "\treturn nodes\n}\n")
+}
+
+// goNames maps node types to Go type names. The html_ and mustache_
+// prefixes are dropped, except where two types would get the same name; the
+// HTML type then keeps the short name and the Mustache one is prefixed with
+// "Mustache".
+func goNames(types []nodeType) map[string]string {
+	short := map[string][]string{}
+	for _, t := range types {
+		name := camel(strings.TrimPrefix(strings.TrimPrefix(t.Type, "html_"), "mustache_"))
+		short[name] = append(short[name], t.Type)
+	}
+	names := map[string]string{}
+	for name, owners := range short {
+		for _, owner := range owners {
+			if len(owners) > 1 && strings.HasPrefix(owner, "mustache_") {
+				names[owner] = "Mustache" + name
+			} else {
+				names[owner] = name
+			}
+		}
+	}
+	return names
+}
+
+// supertypeName returns the Go name of a supertype's interface: its name
+// with an Any prefix, which keeps _attribute apart from html_attribute.
+func supertypeName(kind string) string {
+	return "Any" + camel(strings.TrimPrefix(kind, "_"))
+}
+
+func hidden(kind string) bool {
+	return strings.HasPrefix(kind, "_")
+}
+
+func camel(snake string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(snake, "_") {
+		if part != "" {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
+
+func pluralize(name string) string {
+	switch {
+	case strings.HasSuffix(name, "y") && !strings.ContainsAny(name[len(name)-2:len(name)-1], "aeiou"):
+		return name[:len(name)-1] + "ies"
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"),
+		strings.HasSuffix(name, "sh"), strings.HasSuffix(name, "ch"):
+		return name + "es"
+	}
+	return name + "s"
+}
@@ -0,0 +1,24 @@
+// Command gen regenerates nodes.go of package ast from src/node-types.json.
+// It is run by go generate from the ast package directory.
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/ast/internal/astgen"
+)
+
+func main() {
+	nodeTypes, err := os.ReadFile("../../../src/node-types.json")
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := astgen.Generate(nodeTypes)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("nodes.go", src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,2792 @@
+// Code generated by astgen from src/node-types.json. DO NOT EDIT.
+
+package ast
+
+import tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+// Node kinds, as returned by tree_sitter.Node.Kind.
+const (
+	KindDocument                    = "document"
+	KindFrontmatter                 = "frontmatter"
+	KindFrontmatterContent          = "frontmatter_content"
+	KindAttribute                   = "html_attribute"
+	KindAttributeName               = "html_attribute_name"
+	KindAttributeValue              = "html_attribute_value"
+	KindCdata                       = "html_cdata"
+	KindComment                     = "html_comment"
+	KindConditionalComment          = "html_conditional_comment"
+	KindConditionalCommentCondition = "html_conditional_comment_condition"
+	KindDoctype                     = "html_doctype"
+	KindElement                     = "html_element"
+	KindEndTag                      = "html_end_tag"
+	KindEntity                      = "html_entity"
+	KindErroneousEndTag             = "html_erroneous_end_tag"
+	KindErroneousEndTagName         = "html_erroneous_end_tag_name"
+	KindForcedEndTag                = "html_forced_end_tag"
+	KindProcessingInstruction       = "html_processing_instruction"
+	KindQuotedAttributeValue        = "html_quoted_attribute_value"
+	KindRawElement                  = "html_raw_element"
+	KindRawText                     = "html_raw_text"
+	KindScriptElement               = "html_script_element"
+	KindSelfClosingTag              = "html_self_closing_tag"
+	KindStartTag                    = "html_start_tag"
+	KindStyleElement                = "html_style_element"
+	KindTagName                     = "html_tag_name"
+	KindMustacheAttribute           = "mustache_attribute"
+	KindBlock                       = "mustache_block"
+	KindBlockBegin                  = "mustache_block_begin"
+	KindBlockEnd                    = "mustache_block_end"
+	KindBlockParams                 = "mustache_block_params"
+	KindMustacheComment             = "mustache_comment"
+	KindCommentContent              = "mustache_comment_content"
+	KindDelimiter                   = "mustache_delimiter"
+	KindDynamicPartial              = "mustache_dynamic_partial"
+	KindElse                        = "mustache_else"
+	KindErroneousBlockEnd           = "mustache_erroneous_block_end"
+	KindErroneousInvertedSectionEnd = "mustache_erroneous_inverted_section_end"
+	KindErroneousParentEnd          = "mustache_erroneous_parent_end"
+	KindErroneousSectionEnd         = "mustache_erroneous_section_end"
+	KindErroneousTagName            = "mustache_erroneous_tag_name"
+	KindHashPair                    = "mustache_hash_pair"
+	KindHelperCall                  = "mustache_helper_call"
+	KindIdentifier                  = "mustache_identifier"
+	KindImplicitIterator            = "mustache_implicit_iterator"
+	KindInterpolation               = "mustache_interpolation"
+	KindInvertedSection             = "mustache_inverted_section"
+	KindInvertedSectionBegin        = "mustache_inverted_section_begin"
+	KindInvertedSectionEnd          = "mustache_inverted_section_end"
+	KindParent                      = "mustache_parent"
+	KindParentBegin                 = "mustache_parent_begin"
+	KindParentEnd                   = "mustache_parent_end"
+	KindPartial                     = "mustache_partial"
+	KindPartialContent              = "mustache_partial_content"
+	KindPathExpression              = "mustache_path_expression"
+	KindSection                     = "mustache_section"
+	KindSectionBegin                = "mustache_section_begin"
+	KindSectionEnd                  = "mustache_section_end"
+	KindSetDelimiter                = "mustache_set_delimiter"
+	KindString                      = "mustache_string"
+	KindSubexpression               = "mustache_subexpression"
+	KindMustacheTagName             = "mustache_tag_name"
+	KindTriple                      = "mustache_triple"
+	KindText                        = "text"
+)
+
+// Wrap returns node as its typed wrapper, or node itself if its kind has
+// no wrapper.
+func Wrap(node *tree_sitter.Node) any {
+	switch node.Kind() {
+	case KindDocument:
+		return Document{node}
+	case KindFrontmatter:
+		return Frontmatter{node}
+	case KindFrontmatterContent:
+		return FrontmatterContent{node}
+	case KindAttribute:
+		return Attribute{node}
+	case KindAttributeName:
+		return AttributeName{node}
+	case KindAttributeValue:
+		return AttributeValue{node}
+	case KindCdata:
+		return Cdata{node}
+	case KindComment:
+		return Comment{node}
+	case KindConditionalComment:
+		return ConditionalComment{node}
+	case KindConditionalCommentCondition:
+		return ConditionalCommentCondition{node}
+	case KindDoctype:
+		return Doctype{node}
+	case KindElement:
+		return Element{node}
+	case KindEndTag:
+		return EndTag{node}
+	case KindEntity:
+		return Entity{node}
+	case KindErroneousEndTag:
+		return ErroneousEndTag{node}
+	case KindErroneousEndTagName:
+		return ErroneousEndTagName{node}
+	case KindForcedEndTag:
+		return ForcedEndTag{node}
+	case KindProcessingInstruction:
+		return ProcessingInstruction{node}
+	case KindQuotedAttributeValue:
+		return QuotedAttributeValue{node}
+	case KindRawElement:
+		return RawElement{node}
+	case KindRawText:
+		return RawText{node}
+	case KindScriptElement:
+		return ScriptElement{node}
+	case KindSelfClosingTag:
+		return SelfClosingTag{node}
+	case KindStartTag:
+		return StartTag{node}
+	case KindStyleElement:
+		return StyleElement{node}
+	case KindTagName:
+		return TagName{node}
+	case KindMustacheAttribute:
+		return MustacheAttribute{node}
+	case KindBlock:
+		return Block{node}
+	case KindBlockBegin:
+		return BlockBegin{node}
+	case KindBlockEnd:
+		return BlockEnd{node}
+	case KindBlockParams:
+		return BlockParams{node}
+	case KindMustacheComment:
+		return MustacheComment{node}
+	case KindCommentContent:
+		return CommentContent{node}
+	case KindDelimiter:
+		return Delimiter{node}
+	case KindDynamicPartial:
+		return DynamicPartial{node}
+	case KindElse:
+		return Else{node}
+	case KindErroneousBlockEnd:
+		return ErroneousBlockEnd{node}
+	case KindErroneousInvertedSectionEnd:
+		return ErroneousInvertedSectionEnd{node}
+	case KindErroneousParentEnd:
+		return ErroneousParentEnd{node}
+	case KindErroneousSectionEnd:
+		return ErroneousSectionEnd{node}
+	case KindErroneousTagName:
+		return ErroneousTagName{node}
+	case KindHashPair:
+		return HashPair{node}
+	case KindHelperCall:
+		return HelperCall{node}
+	case KindIdentifier:
+		return Identifier{node}
+	case KindImplicitIterator:
+		return ImplicitIterator{node}
+	case KindInterpolation:
+		return Interpolation{node}
+	case KindInvertedSection:
+		return InvertedSection{node}
+	case KindInvertedSectionBegin:
+		return InvertedSectionBegin{node}
+	case KindInvertedSectionEnd:
+		return InvertedSectionEnd{node}
+	case KindParent:
+		return Parent{node}
+	case KindParentBegin:
+		return ParentBegin{node}
+	case KindParentEnd:
+		return ParentEnd{node}
+	case KindPartial:
+		return Partial{node}
+	case KindPartialContent:
+		return PartialContent{node}
+	case KindPathExpression:
+		return PathExpression{node}
+	case KindSection:
+		return Section{node}
+	case KindSectionBegin:
+		return SectionBegin{node}
+	case KindSectionEnd:
+		return SectionEnd{node}
+	case KindSetDelimiter:
+		return SetDelimiter{node}
+	case KindString:
+		return String{node}
+	case KindSubexpression:
+		return Subexpression{node}
+	case KindMustacheTagName:
+		return MustacheTagName{node}
+	case KindTriple:
+		return Triple{node}
+	case KindText:
+		return Text{node}
+	}
+	return node
+}
+
+// AnyAttribute is a node of the _attribute supertype: Attribute, MustacheAttribute, Interpolation, Triple.
+type AnyAttribute interface {
+	Kind() string
+	isAnyAttribute()
+}
+
+// AsAnyAttribute returns node as a AnyAttribute if it is one.
+func AsAnyAttribute(node *tree_sitter.Node) (AnyAttribute, bool) {
+	if node == nil {
+		return nil, false
+	}
+	n, ok := Wrap(node).(AnyAttribute)
+	return n, ok
+}
+
+// AnyHtmlNode is a node of the _html_node supertype: Cdata, ConditionalComment, Doctype, Element, Entity, ErroneousEndTag, ProcessingInstruction, RawElement, ScriptElement, StyleElement, Text.
+type AnyHtmlNode interface {
+	Kind() string
+	isAnyHtmlNode()
+}
+
+// AsAnyHtmlNode returns node as a AnyHtmlNode if it is one.
+func AsAnyHtmlNode(node *tree_sitter.Node) (AnyHtmlNode, bool) {
+	if node == nil {
+		return nil, false
+	}
+	n, ok := Wrap(node).(AnyHtmlNode)
+	return n, ok
+}
+
+// AnyMustacheExpression is a node of the _mustache_expression supertype: Identifier, ImplicitIterator, PathExpression.
+type AnyMustacheExpression interface {
+	Kind() string
+	isAnyMustacheExpression()
+}
+
+// AsAnyMustacheExpression returns node as a AnyMustacheExpression if it is one.
+func AsAnyMustacheExpression(node *tree_sitter.Node) (AnyMustacheExpression, bool) {
+	if node == nil {
+		return nil, false
+	}
+	n, ok := Wrap(node).(AnyMustacheExpression)
+	return n, ok
+}
+
+// AnyMustacheNode is a node of the _mustache_node supertype: Block, MustacheComment, DynamicPartial, Interpolation, InvertedSection, Parent, Partial, Section, SetDelimiter, Triple.
+type AnyMustacheNode interface {
+	Kind() string
+	isAnyMustacheNode()
+}
+
+// AsAnyMustacheNode returns node as a AnyMustacheNode if it is one.
+func AsAnyMustacheNode(node *tree_sitter.Node) (AnyMustacheNode, bool) {
+	if node == nil {
+		return nil, false
+	}
+	n, ok := Wrap(node).(AnyMustacheNode)
+	return n, ok
+}
+
+// AnyNode is a node of the _node supertype: Cdata, ConditionalComment, Doctype, Element, Entity, ErroneousEndTag, ProcessingInstruction, RawElement, ScriptElement, StyleElement, Block, MustacheComment, DynamicPartial, Interpolation, InvertedSection, Parent, Partial, Section, SetDelimiter, Triple, Text.
+type AnyNode interface {
+	Kind() string
+	isAnyNode()
+}
+
+// AsAnyNode returns node as a AnyNode if it is one.
+func AsAnyNode(node *tree_sitter.Node) (AnyNode, bool) {
+	if node == nil {
+		return nil, false
+	}
+	n, ok := Wrap(node).(AnyNode)
+	return n, ok
+}
+
+// Document is a document node.
+type Document struct{ *tree_sitter.Node }
+
+// AsDocument returns node as a Document if it is one.
+func AsDocument(node *tree_sitter.Node) (Document, bool) {
+	if node == nil || node.Kind() != KindDocument {
+		return Document{}, false
+	}
+	return Document{node}, true
+}
+
+// Frontmatter returns the first Frontmatter child.
+func (n Document) Frontmatter() (Frontmatter, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindFrontmatter {
+			return Frontmatter{c}, true
+		}
+	}
+	return Frontmatter{}, false
+}
+
+// Frontmatters returns the Frontmatter children.
+func (n Document) Frontmatters() []Frontmatter {
+	var nodes []Frontmatter
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindFrontmatter {
+			nodes = append(nodes, Frontmatter{c})
+		}
+	}
+	return nodes
+}
+
+// Cdata returns the first Cdata child.
+func (n Document) Cdata() (Cdata, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindCdata {
+			return Cdata{c}, true
+		}
+	}
+	return Cdata{}, false
+}
+
+// Cdatas returns the Cdata children.
+func (n Document) Cdatas() []Cdata {
+	var nodes []Cdata
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindCdata {
+			nodes = append(nodes, Cdata{c})
+		}
+	}
+	return nodes
+}
+
+// ConditionalComment returns the first ConditionalComment child.
+func (n Document) ConditionalComment() (ConditionalComment, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindConditionalComment {
+			return ConditionalComment{c}, true
+		}
+	}
+	return ConditionalComment{}, false
+}
+
+// ConditionalComments returns the ConditionalComment children.
+func (n Document) ConditionalComments() []ConditionalComment {
+	var nodes []ConditionalComment
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindConditionalComment {
+			nodes = append(nodes, ConditionalComment{c})
+		}
+	}
+	return nodes
+}
+
+// Doctype returns the first Doctype child.
+func (n Document) Doctype() (Doctype, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindDoctype {
+			return Doctype{c}, true
+		}
+	}
+	return Doctype{}, false
+}
+
+// Doctypes returns the Doctype children.
+func (n Document) Doctypes() []Doctype {
+	var nodes []Doctype
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindDoctype {
+			nodes = append(nodes, Doctype{c})
+		}
+	}
+	return nodes
+}
+
+// Element returns the first Element child.
+func (n Document) Element() (Element, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindElement {
+			return Element{c}, true
+		}
+	}
+	return Element{}, false
+}
+
+// Elements returns the Element children.
+func (n Document) Elements() []Element {
+	var nodes []Element
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindElement {
+			nodes = append(nodes, Element{c})
+		}
+	}
+	return nodes
+}
+
+// Entity returns the first Entity child.
+func (n Document) Entity() (Entity, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindEntity {
+			return Entity{c}, true
+		}
+	}
+	return Entity{}, false
+}
+
+// Entities returns the Entity children.
+func (n Document) Entities() []Entity {
+	var nodes []Entity
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindEntity {
+			nodes = append(nodes, Entity{c})
+		}
+	}
+	return nodes
+}
+
+// ErroneousEndTag returns the first ErroneousEndTag child.
+func (n Document) ErroneousEndTag() (ErroneousEndTag, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindErroneousEndTag {
+			return ErroneousEndTag{c}, true
+		}
+	}
+	return ErroneousEndTag{}, false
+}
+
+// ErroneousEndTags returns the ErroneousEndTag children.
+func (n Document) ErroneousEndTags() []ErroneousEndTag {
+	var nodes []ErroneousEndTag
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindErroneousEndTag {
+			nodes = append(nodes, ErroneousEndTag{c})
+		}
+	}
+	return nodes
+}
+
+// ProcessingInstruction returns the first ProcessingInstruction child.
+func (n Document) ProcessingInstruction() (ProcessingInstruction, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindProcessingInstruction {
+			return ProcessingInstruction{c}, true
+		}
+	}
+	return ProcessingInstruction{}, false
+}
+
+// ProcessingInstructions returns the ProcessingInstruction children.
+func (n Document) ProcessingInstructions() []ProcessingInstruction {
+	var nodes []ProcessingInstruction
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindProcessingInstruction {
+			nodes = append(nodes, ProcessingInstruction{c})
+		}
+	}
+	return nodes
+}
+
+// RawElement returns the first RawElement child.
+func (n Document) RawElement() (RawElement, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindRawElement {
+			return RawElement{c}, true
+		}
+	}
+	return RawElement{}, false
+}
+
+// RawElements returns the RawElement children.
+func (n Document) RawElements() []RawElement {
+	var nodes []RawElement
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindRawElement {
+			nodes = append(nodes, RawElement{c})
+		}
+	}
+	return nodes
+}
+
+// ScriptElement returns the first ScriptElement child.
+func (n Document) ScriptElement() (ScriptElement, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindScriptElement {
+			return ScriptElement{c}, true
+		}
+	}
+	return ScriptElement{}, false
+}
+
+// ScriptElements returns the ScriptElement children.
+func (n Document) ScriptElements() []ScriptElement {
+	var nodes []ScriptElement
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindScriptElement {
+			nodes = append(nodes, ScriptElement{c})
+		}
+	}
+	return nodes
+}
+
+// StyleElement returns the first StyleElement child.
+func (n Document) StyleElement() (StyleElement, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindStyleElement {
+			return StyleElement{c}, true
+		}
+	}
+	return StyleElement{}, false
+}
+
+// StyleElements returns the StyleElement children.
+func (n Document) StyleElements() []StyleElement {
+	var nodes []StyleElement
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindStyleElement {
+			nodes = append(nodes, StyleElement{c})
+		}
+	}
+	return nodes
+}
+
+// Block returns the first Block child.
+func (n Document) Block() (Block, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindBlock {
+			return Block{c}, true
+		}
+	}
+	return Block{}, false
+}
+
+// Blocks returns the Block children.
+func (n Document) Blocks() []Block {
+	var nodes []Block
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindBlock {
+			nodes = append(nodes, Block{c})
+		}
+	}
+	return nodes
+}
+
+// MustacheComment returns the first MustacheComment child.
+func (n Document) MustacheComment() (MustacheComment, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindMustacheComment {
+			return MustacheComment{c}, true
+		}
+	}
+	return MustacheComment{}, false
+}
+
+// MustacheComments returns the MustacheComment children.
+func (n Document) MustacheComments() []MustacheComment {
+	var nodes []MustacheComment
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindMustacheComment {
+			nodes = append(nodes, MustacheComment{c})
+		}
+	}
+	return nodes
+}
+
+// DynamicPartial returns the first DynamicPartial child.
+func (n Document) DynamicPartial() (DynamicPartial, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindDynamicPartial {
+			return DynamicPartial{c}, true
+		}
+	}
+	return DynamicPartial{}, false
+}
+
+// DynamicPartials returns the DynamicPartial children.
+func (n Document) DynamicPartials() []DynamicPartial {
+	var nodes []DynamicPartial
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindDynamicPartial {
+			nodes = append(nodes, DynamicPartial{c})
+		}
+	}
+	return nodes
+}
+
+// Interpolation returns the first Interpolation child.
+func (n Document) Interpolation() (Interpolation, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindInterpolation {
+			return Interpolation{c}, true
+		}
+	}
+	return Interpolation{}, false
+}
+
+// Interpolations returns the Interpolation children.
+func (n Document) Interpolations() []Interpolation {
+	var nodes []Interpolation
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindInterpolation {
+			nodes = append(nodes, Interpolation{c})
+		}
+	}
+	return nodes
+}
+
+// InvertedSection returns the first InvertedSection child.
+func (n Document) InvertedSection() (InvertedSection, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindInvertedSection {
+			return InvertedSection{c}, true
+		}
+	}
+	return InvertedSection{}, false
+}
+
+// InvertedSections returns the InvertedSection children.
+func (n Document) InvertedSections() []InvertedSection {
+	var nodes []InvertedSection
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindInvertedSection {
+			nodes = append(nodes, InvertedSection{c})
+		}
+	}
+	return nodes
+}
+
+// Parent returns the first Parent child.
+func (n Document) Parent() (Parent, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindParent {
+			return Parent{c}, true
+		}
+	}
+	return Parent{}, false
+}
+
+// Parents returns the Parent children.
+func (n Document) Parents() []Parent {
+	var nodes []Parent
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindParent {
+			nodes = append(nodes, Parent{c})
+		}
+	}
+	return nodes
+}
+
+// Partial returns the first Partial child.
+func (n Document) Partial() (Partial, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindPartial {
+			return Partial{c}, true
+		}
+	}
+	return Partial{}, false
+}
+
+// Partials returns the Partial children.
+func (n Document) Partials() []Partial {
+	var nodes []Partial
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindPartial {
+			nodes = append(nodes, Partial{c})
+		}
+	}
+	return nodes
+}
+
+// Section returns the first Section child.
+func (n Document) Section() (Section, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindSection {
+			return Section{c}, true
+		}
+	}
+	return Section{}, false
+}
+
+// Sections returns the Section children.
+func (n Document) Sections() []Section {
+	var nodes []Section
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindSection {
+			nodes = append(nodes, Section{c})
+		}
+	}
+	return nodes
+}
+
+// SetDelimiter returns the first SetDelimiter child.
+func (n Document) SetDelimiter() (SetDelimiter, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindSetDelimiter {
+			return SetDelimiter{c}, true
+		}
+	}
+	return SetDelimiter{}, false
+}
+
+// SetDelimiters returns the SetDelimiter children.
+func (n Document) SetDelimiters() []SetDelimiter {
+	var nodes []SetDelimiter
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindSetDelimiter {
+			nodes = append(nodes, SetDelimiter{c})
+		}
+	}
+	return nodes
+}
+
+// Triple returns the first Triple child.
+func (n Document) Triple() (Triple, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindTriple {
+			return Triple{c}, true
+		}
+	}
+	return Triple{}, false
+}
+
+// Triples returns the Triple children.
+func (n Document) Triples() []Triple {
+	var nodes []Triple
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindTriple {
+			nodes = append(nodes, Triple{c})
+		}
+	}
+	return nodes
+}
+
+// Text returns the first Text child.
+func (n Document) Text() (Text, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindText {
+			return Text{c}, true
+		}
+	}
+	return Text{}, false
+}
+
+// Texts returns the Text children.
+func (n Document) Texts() []Text {
+	var nodes []Text
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindText {
+			nodes = append(nodes, Text{c})
+		}
+	}
+	return nodes
+}
+
+// Frontmatter is a frontmatter node.
+type Frontmatter struct{ *tree_sitter.Node }
+
+// AsFrontmatter returns node as a Frontmatter if it is one.
+func AsFrontmatter(node *tree_sitter.Node) (Frontmatter, bool) {
+	if node == nil || node.Kind() != KindFrontmatter {
+		return Frontmatter{}, false
+	}
+	return Frontmatter{node}, true
+}
+
+// FrontmatterContent returns the first FrontmatterContent child.
+func (n Frontmatter) FrontmatterContent() (FrontmatterContent, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindFrontmatterContent {
+			return FrontmatterContent{c}, true
+		}
+	}
+	return FrontmatterContent{}, false
+}
+
+// FrontmatterContent is a frontmatter_content node.
+type FrontmatterContent struct{ *tree_sitter.Node }
+
+// AsFrontmatterContent returns node as a FrontmatterContent if it is one.
+func AsFrontmatterContent(node *tree_sitter.Node) (FrontmatterContent, bool) {
+	if node == nil || node.Kind() != KindFrontmatterContent {
+		return FrontmatterContent{}, false
+	}
+	return FrontmatterContent{node}, true
+}
+
+// Attribute is a html_attribute node.
+type Attribute struct{ *tree_sitter.Node }
+
+// AsAttribute returns node as a Attribute if it is one.
+func AsAttribute(node *tree_sitter.Node) (Attribute, bool) {
+	if node == nil || node.Kind() != KindAttribute {
+		return Attribute{}, false
+	}
+	return Attribute{node}, true
+}
+
+func (Attribute) isAnyAttribute() {}
+
+// Name returns the name field.
+func (n Attribute) Name() (AttributeName, bool) {
+	child := n.ChildByFieldName("name")
+	if child == nil {
+		return AttributeName{}, false
+	}
+	return AttributeName{child}, true
+}
+
+// Value returns the value field.
+func (n Attribute) Value() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("value")
+	return child, child != nil
+}
+
+// AttributeName is a html_attribute_name node.
+type AttributeName struct{ *tree_sitter.Node }
+
+// AsAttributeName returns node as a AttributeName if it is one.
+func AsAttributeName(node *tree_sitter.Node) (AttributeName, bool) {
+	if node == nil || node.Kind() != KindAttributeName {
+		return AttributeName{}, false
+	}
+	return AttributeName{node}, true
+}
+
+// AttributeValue is a html_attribute_value node.
+type AttributeValue struct{ *tree_sitter.Node }
+
+// AsAttributeValue returns node as a AttributeValue if it is one.
+func AsAttributeValue(node *tree_sitter.Node) (AttributeValue, bool) {
+	if node == nil || node.Kind() != KindAttributeValue {
+		return AttributeValue{}, false
+	}
+	return AttributeValue{node}, true
+}
+
+// Cdata is a html_cdata node.
+type Cdata struct{ *tree_sitter.Node }
+
+// AsCdata returns node as a Cdata if it is one.
+func AsCdata(node *tree_sitter.Node) (Cdata, bool) {
+	if node == nil || node.Kind() != KindCdata {
+		return Cdata{}, false
+	}
+	return Cdata{node}, true
+}
+
+func (Cdata) isAnyHtmlNode() {}
+
+func (Cdata) isAnyNode() {}
+
+// Comment is a html_comment node.
+type Comment struct{ *tree_sitter.Node }
+
+// AsComment returns node as a Comment if it is one.
+func AsComment(node *tree_sitter.Node) (Comment, bool) {
+	if node == nil || node.Kind() != KindComment {
+		return Comment{}, false
+	}
+	return Comment{node}, true
+}
+
+// ConditionalComment is a html_conditional_comment node.
+type ConditionalComment struct{ *tree_sitter.Node }
+
+// AsConditionalComment returns node as a ConditionalComment if it is one.
+func AsConditionalComment(node *tree_sitter.Node) (ConditionalComment, bool) {
+	if node == nil || node.Kind() != KindConditionalComment {
+		return ConditionalComment{}, false
+	}
+	return ConditionalComment{node}, true
+}
+
+func (ConditionalComment) isAnyHtmlNode() {}
+
+func (ConditionalComment) isAnyNode() {}
+
+// Condition returns the condition field.
+func (n ConditionalComment) Condition() (ConditionalCommentCondition, bool) {
+	child := n.ChildByFieldName("condition")
+	if child == nil {
+		return ConditionalCommentCondition{}, false
+	}
+	return ConditionalCommentCondition{child}, true
+}
+
+// Content returns the content field.
+func (n ConditionalComment) Content() []AnyNode {
+	var nodes []AnyNode
+	cursor := n.Walk()
+	defer cursor.Close()
+	for _, child := range n.ChildrenByFieldName("content", cursor) {
+		if node, ok := AsAnyNode(&child); ok {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// Reveal returns the reveal field.
+func (n ConditionalComment) Reveal() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("reveal")
+	return child, child != nil
+}
+
+// ConditionalCommentCondition is a html_conditional_comment_condition node.
+type ConditionalCommentCondition struct{ *tree_sitter.Node }
+
+// AsConditionalCommentCondition returns node as a ConditionalCommentCondition if it is one.
+func AsConditionalCommentCondition(node *tree_sitter.Node) (ConditionalCommentCondition, bool) {
+	if node == nil || node.Kind() != KindConditionalCommentCondition {
+		return ConditionalCommentCondition{}, false
+	}
+	return ConditionalCommentCondition{node}, true
+}
+
+// Doctype is a html_doctype node.
+type Doctype struct{ *tree_sitter.Node }
+
+// AsDoctype returns node as a Doctype if it is one.
+func AsDoctype(node *tree_sitter.Node) (Doctype, bool) {
+	if node == nil || node.Kind() != KindDoctype {
+		return Doctype{}, false
+	}
+	return Doctype{node}, true
+}
+
+func (Doctype) isAnyHtmlNode() {}
+
+func (Doctype) isAnyNode() {}
+
+// Element is a html_element node.
+type Element struct{ *tree_sitter.Node }
+
+// AsElement returns node as a Element if it is one.
+func AsElement(node *tree_sitter.Node) (Element, bool) {
+	if node == nil || node.Kind() != KindElement {
+		return Element{}, false
+	}
+	return Element{node}, true
+}
+
+func (Element) isAnyHtmlNode() {}
+
+func (Element) isAnyNode() {}
+
+// Close returns the close field.
+func (n Element) Close() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("close")
+	return child, child != nil
+}
+
+// Content returns the content field.
+func (n Element) Content() []*tree_sitter.Node {
+	var nodes []*tree_sitter.Node
+	cursor := n.Walk()
+	defer cursor.Close()
+	for _, child := range n.ChildrenByFieldName("content", cursor) {
+		nodes = append(nodes, &child)
+	}
+	return nodes
+}
+
+// Open returns the open field.
+func (n Element) Open() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("open")
+	return child, child != nil
+}
+
+// EndTag is a html_end_tag node.
+type EndTag struct{ *tree_sitter.Node }
+
+// AsEndTag returns node as a EndTag if it is one.
+func AsEndTag(node *tree_sitter.Node) (EndTag, bool) {
+	if node == nil || node.Kind() != KindEndTag {
+		return EndTag{}, false
+	}
+	return EndTag{node}, true
+}
+
+// Name returns the name field.
+func (n EndTag) Name() (TagName, bool) {
+	child := n.ChildByFieldName("name")
+	if child == nil {
+		return TagName{}, false
+	}
+	return TagName{child}, true
+}
+
+// Entity is a html_entity node.
+type Entity struct{ *tree_sitter.Node }
+
+// AsEntity returns node as a Entity if it is one.
+func AsEntity(node *tree_sitter.Node) (Entity, bool) {
+	if node == nil || node.Kind() != KindEntity {
+		return Entity{}, false
+	}
+	return Entity{node}, true
+}
+
+func (Entity) isAnyHtmlNode() {}
+
+func (Entity) isAnyNode() {}
+
+// ErroneousEndTag is a html_erroneous_end_tag node.
+type ErroneousEndTag struct{ *tree_sitter.Node }
+
+// AsErroneousEndTag returns node as a ErroneousEndTag if it is one.
+func AsErroneousEndTag(node *tree_sitter.Node) (ErroneousEndTag, bool) {
+	if node == nil || node.Kind() != KindErroneousEndTag {
+		return ErroneousEndTag{}, false
+	}
+	return ErroneousEndTag{node}, true
+}
+
+func (ErroneousEndTag) isAnyHtmlNode() {}
+
+func (ErroneousEndTag) isAnyNode() {}
+
+// Name returns the name field.
+func (n ErroneousEndTag) Name() (ErroneousEndTagName, bool) {
+	child := n.ChildByFieldName("name")
+	if child == nil {
+		return ErroneousEndTagName{}, false
+	}
+	return ErroneousEndTagName{child}, true
+}
+
+// ErroneousEndTagName is a html_erroneous_end_tag_name node.
+type ErroneousEndTagName struct{ *tree_sitter.Node }
+
+// AsErroneousEndTagName returns node as a ErroneousEndTagName if it is one.
+func AsErroneousEndTagName(node *tree_sitter.Node) (ErroneousEndTagName, bool) {
+	if node == nil || node.Kind() != KindErroneousEndTagName {
+		return ErroneousEndTagName{}, false
+	}
+	return ErroneousEndTagName{node}, true
+}
+
+// Interpolation returns the first Interpolation child.
+func (n ErroneousEndTagName) Interpolation() (Interpolation, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindInterpolation {
+			return Interpolation{c}, true
+		}
+	}
+	return Interpolation{}, false
+}
+
+// Interpolations returns the Interpolation children.
+func (n ErroneousEndTagName) Interpolations() []Interpolation {
+	var nodes []Interpolation
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindInterpolation {
+			nodes = append(nodes, Interpolation{c})
+		}
+	}
+	return nodes
+}
+
+// ForcedEndTag is a html_forced_end_tag node.
+type ForcedEndTag struct{ *tree_sitter.Node }
+
+// AsForcedEndTag returns node as a ForcedEndTag if it is one.
+func AsForcedEndTag(node *tree_sitter.Node) (ForcedEndTag, bool) {
+	if node == nil || node.Kind() != KindForcedEndTag {
+		return ForcedEndTag{}, false
+	}
+	return ForcedEndTag{node}, true
+}
+
+// ProcessingInstruction is a html_processing_instruction node.
+type ProcessingInstruction struct{ *tree_sitter.Node }
+
+// AsProcessingInstruction returns node as a ProcessingInstruction if it is one.
+func AsProcessingInstruction(node *tree_sitter.Node) (ProcessingInstruction, bool) {
+	if node == nil || node.Kind() != KindProcessingInstruction {
+		return ProcessingInstruction{}, false
+	}
+	return ProcessingInstruction{node}, true
+}
+
+func (ProcessingInstruction) isAnyHtmlNode() {}
+
+func (ProcessingInstruction) isAnyNode() {}
+
+// QuotedAttributeValue is a html_quoted_attribute_value node.
+type QuotedAttributeValue struct{ *tree_sitter.Node }
+
+// AsQuotedAttributeValue returns node as a QuotedAttributeValue if it is one.
+func AsQuotedAttributeValue(node *tree_sitter.Node) (QuotedAttributeValue, bool) {
+	if node == nil || node.Kind() != KindQuotedAttributeValue {
+		return QuotedAttributeValue{}, false
+	}
+	return QuotedAttributeValue{node}, true
+}
+
+// AttributeValue returns the first AttributeValue child.
+func (n QuotedAttributeValue) AttributeValue() (AttributeValue, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindAttributeValue {
+			return AttributeValue{c}, true
+		}
+	}
+	return AttributeValue{}, false
+}
+
+// AttributeValues returns the AttributeValue children.
+func (n QuotedAttributeValue) AttributeValues() []AttributeValue {
+	var nodes []AttributeValue
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindAttributeValue {
+			nodes = append(nodes, AttributeValue{c})
+		}
+	}
+	return nodes
+}
+
+// Entity returns the first Entity child.
+func (n QuotedAttributeValue) Entity() (Entity, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindEntity {
+			return Entity{c}, true
+		}
+	}
+	return Entity{}, false
+}
+
+// Entities returns the Entity children.
+func (n QuotedAttributeValue) Entities() []Entity {
+	var nodes []Entity
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindEntity {
+			nodes = append(nodes, Entity{c})
+		}
+	}
+	return nodes
+}
+
+// MustacheComment returns the first MustacheComment child.
+func (n QuotedAttributeValue) MustacheComment() (MustacheComment, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindMustacheComment {
+			return MustacheComment{c}, true
+		}
+	}
+	return MustacheComment{}, false
+}
+
+// MustacheComments returns the MustacheComment children.
+func (n QuotedAttributeValue) MustacheComments() []MustacheComment {
+	var nodes []MustacheComment
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindMustacheComment {
+			nodes = append(nodes, MustacheComment{c})
+		}
+	}
+	return nodes
+}
+
+// Interpolation returns the first Interpolation child.
+func (n QuotedAttributeValue) Interpolation() (Interpolation, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindInterpolation {
+			return Interpolation{c}, true
+		}
+	}
+	return Interpolation{}, false
+}
+
+// Interpolations returns the Interpolation children.
+func (n QuotedAttributeValue) Interpolations() []Interpolation {
+	var nodes []Interpolation
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindInterpolation {
+			nodes = append(nodes, Interpolation{c})
+		}
+	}
+	return nodes
+}
+
+// InvertedSection returns the first InvertedSection child.
+func (n QuotedAttributeValue) InvertedSection() (InvertedSection, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindInvertedSection {
+			return InvertedSection{c}, true
+		}
+	}
+	return InvertedSection{}, false
+}
+
+// InvertedSections returns the InvertedSection children.
+func (n QuotedAttributeValue) InvertedSections() []InvertedSection {
+	var nodes []InvertedSection
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindInvertedSection {
+			nodes = append(nodes, InvertedSection{c})
+		}
+	}
+	return nodes
+}
+
+// Partial returns the first Partial child.
+func (n QuotedAttributeValue) Partial() (Partial, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindPartial {
+			return Partial{c}, true
+		}
+	}
+	return Partial{}, false
+}
+
+// Partials returns the Partial children.
+func (n QuotedAttributeValue) Partials() []Partial {
+	var nodes []Partial
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindPartial {
+			nodes = append(nodes, Partial{c})
+		}
+	}
+	return nodes
+}
+
+// Section returns the first Section child.
+func (n QuotedAttributeValue) Section() (Section, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindSection {
+			return Section{c}, true
+		}
+	}
+	return Section{}, false
+}
+
+// Sections returns the Section children.
+func (n QuotedAttributeValue) Sections() []Section {
+	var nodes []Section
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindSection {
+			nodes = append(nodes, Section{c})
+		}
+	}
+	return nodes
+}
+
+// Triple returns the first Triple child.
+func (n QuotedAttributeValue) Triple() (Triple, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindTriple {
+			return Triple{c}, true
+		}
+	}
+	return Triple{}, false
+}
+
+// Triples returns the Triple children.
+func (n QuotedAttributeValue) Triples() []Triple {
+	var nodes []Triple
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindTriple {
+			nodes = append(nodes, Triple{c})
+		}
+	}
+	return nodes
+}
+
+// RawElement is a html_raw_element node.
+type RawElement struct{ *tree_sitter.Node }
+
+// AsRawElement returns node as a RawElement if it is one.
+func AsRawElement(node *tree_sitter.Node) (RawElement, bool) {
+	if node == nil || node.Kind() != KindRawElement {
+		return RawElement{}, false
+	}
+	return RawElement{node}, true
+}
+
+func (RawElement) isAnyHtmlNode() {}
+
+func (RawElement) isAnyNode() {}
+
+// Close returns the close field.
+func (n RawElement) Close() (EndTag, bool) {
+	child := n.ChildByFieldName("close")
+	if child == nil {
+		return EndTag{}, false
+	}
+	return EndTag{child}, true
+}
+
+// Content returns the content field.
+func (n RawElement) Content() (RawText, bool) {
+	child := n.ChildByFieldName("content")
+	if child == nil {
+		return RawText{}, false
+	}
+	return RawText{child}, true
+}
+
+// Open returns the open field.
+func (n RawElement) Open() (StartTag, bool) {
+	child := n.ChildByFieldName("open")
+	if child == nil {
+		return StartTag{}, false
+	}
+	return StartTag{child}, true
+}
+
+// RawText is a html_raw_text node.
+type RawText struct{ *tree_sitter.Node }
+
+// AsRawText returns node as a RawText if it is one.
+func AsRawText(node *tree_sitter.Node) (RawText, bool) {
+	if node == nil || node.Kind() != KindRawText {
+		return RawText{}, false
+	}
+	return RawText{node}, true
+}
+
+// MustacheComment returns the first MustacheComment child.
+func (n RawText) MustacheComment() (MustacheComment, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindMustacheComment {
+			return MustacheComment{c}, true
+		}
+	}
+	return MustacheComment{}, false
+}
+
+// MustacheComments returns the MustacheComment children.
+func (n RawText) MustacheComments() []MustacheComment {
+	var nodes []MustacheComment
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindMustacheComment {
+			nodes = append(nodes, MustacheComment{c})
+		}
+	}
+	return nodes
+}
+
+// Interpolation returns the first Interpolation child.
+func (n RawText) Interpolation() (Interpolation, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindInterpolation {
+			return Interpolation{c}, true
+		}
+	}
+	return Interpolation{}, false
+}
+
+// Interpolations returns the Interpolation children.
+func (n RawText) Interpolations() []Interpolation {
+	var nodes []Interpolation
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindInterpolation {
+			nodes = append(nodes, Interpolation{c})
+		}
+	}
+	return nodes
+}
+
+// Partial returns the first Partial child.
+func (n RawText) Partial() (Partial, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindPartial {
+			return Partial{c}, true
+		}
+	}
+	return Partial{}, false
+}
+
+// Partials returns the Partial children.
+func (n RawText) Partials() []Partial {
+	var nodes []Partial
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindPartial {
+			nodes = append(nodes, Partial{c})
+		}
+	}
+	return nodes
+}
+
+// Triple returns the first Triple child.
+func (n RawText) Triple() (Triple, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindTriple {
+			return Triple{c}, true
+		}
+	}
+	return Triple{}, false
+}
+
+// Triples returns the Triple children.
+func (n RawText) Triples() []Triple {
+	var nodes []Triple
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindTriple {
+			nodes = append(nodes, Triple{c})
+		}
+	}
+	return nodes
+}
+
+// ScriptElement is a html_script_element node.
+type ScriptElement struct{ *tree_sitter.Node }
+
+// AsScriptElement returns node as a ScriptElement if it is one.
+func AsScriptElement(node *tree_sitter.Node) (ScriptElement, bool) {
+	if node == nil || node.Kind() != KindScriptElement {
+		return ScriptElement{}, false
+	}
+	return ScriptElement{node}, true
+}
+
+func (ScriptElement) isAnyHtmlNode() {}
+
+func (ScriptElement) isAnyNode() {}
+
+// Close returns the close field.
+func (n ScriptElement) Close() (EndTag, bool) {
+	child := n.ChildByFieldName("close")
+	if child == nil {
+		return EndTag{}, false
+	}
+	return EndTag{child}, true
+}
+
+// Content returns the content field.
+func (n ScriptElement) Content() (RawText, bool) {
+	child := n.ChildByFieldName("content")
+	if child == nil {
+		return RawText{}, false
+	}
+	return RawText{child}, true
+}
+
+// Open returns the open field.
+func (n ScriptElement) Open() (StartTag, bool) {
+	child := n.ChildByFieldName("open")
+	if child == nil {
+		return StartTag{}, false
+	}
+	return StartTag{child}, true
+}
+
+// SelfClosingTag is a html_self_closing_tag node.
+type SelfClosingTag struct{ *tree_sitter.Node }
+
+// AsSelfClosingTag returns node as a SelfClosingTag if it is one.
+func AsSelfClosingTag(node *tree_sitter.Node) (SelfClosingTag, bool) {
+	if node == nil || node.Kind() != KindSelfClosingTag {
+		return SelfClosingTag{}, false
+	}
+	return SelfClosingTag{node}, true
+}
+
+// Attribute returns the attribute field.
+func (n SelfClosingTag) Attribute() []AnyAttribute {
+	var nodes []AnyAttribute
+	cursor := n.Walk()
+	defer cursor.Close()
+	for _, child := range n.ChildrenByFieldName("attribute", cursor) {
+		if node, ok := AsAnyAttribute(&child); ok {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// Name returns the name field.
+func (n SelfClosingTag) Name() (TagName, bool) {
+	child := n.ChildByFieldName("name")
+	if child == nil {
+		return TagName{}, false
+	}
+	return TagName{child}, true
+}
+
+// StartTag is a html_start_tag node.
+type StartTag struct{ *tree_sitter.Node }
+
+// AsStartTag returns node as a StartTag if it is one.
+func AsStartTag(node *tree_sitter.Node) (StartTag, bool) {
+	if node == nil || node.Kind() != KindStartTag {
+		return StartTag{}, false
+	}
+	return StartTag{node}, true
+}
+
+// Attribute returns the attribute field.
+func (n StartTag) Attribute() []AnyAttribute {
+	var nodes []AnyAttribute
+	cursor := n.Walk()
+	defer cursor.Close()
+	for _, child := range n.ChildrenByFieldName("attribute", cursor) {
+		if node, ok := AsAnyAttribute(&child); ok {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// Name returns the name field.
+func (n StartTag) Name() (TagName, bool) {
+	child := n.ChildByFieldName("name")
+	if child == nil {
+		return TagName{}, false
+	}
+	return TagName{child}, true
+}
+
+// StyleElement is a html_style_element node.
+type StyleElement struct{ *tree_sitter.Node }
+
+// AsStyleElement returns node as a StyleElement if it is one.
+func AsStyleElement(node *tree_sitter.Node) (StyleElement, bool) {
+	if node == nil || node.Kind() != KindStyleElement {
+		return StyleElement{}, false
+	}
+	return StyleElement{node}, true
+}
+
+func (StyleElement) isAnyHtmlNode() {}
+
+func (StyleElement) isAnyNode() {}
+
+// Close returns the close field.
+func (n StyleElement) Close() (EndTag, bool) {
+	child := n.ChildByFieldName("close")
+	if child == nil {
+		return EndTag{}, false
+	}
+	return EndTag{child}, true
+}
+
+// Content returns the content field.
+func (n StyleElement) Content() (RawText, bool) {
+	child := n.ChildByFieldName("content")
+	if child == nil {
+		return RawText{}, false
+	}
+	return RawText{child}, true
+}
+
+// Open returns the open field.
+func (n StyleElement) Open() (StartTag, bool) {
+	child := n.ChildByFieldName("open")
+	if child == nil {
+		return StartTag{}, false
+	}
+	return StartTag{child}, true
+}
+
+// TagName is a html_tag_name node.
+type TagName struct{ *tree_sitter.Node }
+
+// AsTagName returns node as a TagName if it is one.
+func AsTagName(node *tree_sitter.Node) (TagName, bool) {
+	if node == nil || node.Kind() != KindTagName {
+		return TagName{}, false
+	}
+	return TagName{node}, true
+}
+
+// Interpolation returns the first Interpolation child.
+func (n TagName) Interpolation() (Interpolation, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindInterpolation {
+			return Interpolation{c}, true
+		}
+	}
+	return Interpolation{}, false
+}
+
+// Interpolations returns the Interpolation children.
+func (n TagName) Interpolations() []Interpolation {
+	var nodes []Interpolation
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindInterpolation {
+			nodes = append(nodes, Interpolation{c})
+		}
+	}
+	return nodes
+}
+
+// MustacheAttribute is a mustache_attribute node.
+type MustacheAttribute struct{ *tree_sitter.Node }
+
+// AsMustacheAttribute returns node as a MustacheAttribute if it is one.
+func AsMustacheAttribute(node *tree_sitter.Node) (MustacheAttribute, bool) {
+	if node == nil || node.Kind() != KindMustacheAttribute {
+		return MustacheAttribute{}, false
+	}
+	return MustacheAttribute{node}, true
+}
+
+func (MustacheAttribute) isAnyAttribute() {}
+
+// InvertedSection returns the first InvertedSection child.
+func (n MustacheAttribute) InvertedSection() (InvertedSection, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindInvertedSection {
+			return InvertedSection{c}, true
+		}
+	}
+	return InvertedSection{}, false
+}
+
+// Section returns the first Section child.
+func (n MustacheAttribute) Section() (Section, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindSection {
+			return Section{c}, true
+		}
+	}
+	return Section{}, false
+}
+
+// Block is a mustache_block node.
+type Block struct{ *tree_sitter.Node }
+
+// AsBlock returns node as a Block if it is one.
+func AsBlock(node *tree_sitter.Node) (Block, bool) {
+	if node == nil || node.Kind() != KindBlock {
+		return Block{}, false
+	}
+	return Block{node}, true
+}
+
+func (Block) isAnyMustacheNode() {}
+
+func (Block) isAnyNode() {}
+
+// Close returns the close field.
+func (n Block) Close() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("close")
+	return child, child != nil
+}
+
+// Content returns the content field.
+func (n Block) Content() []AnyNode {
+	var nodes []AnyNode
+	cursor := n.Walk()
+	defer cursor.Close()
+	for _, child := range n.ChildrenByFieldName("content", cursor) {
+		if node, ok := AsAnyNode(&child); ok {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// Open returns the open field.
+func (n Block) Open() (BlockBegin, bool) {
+	child := n.ChildByFieldName("open")
+	if child == nil {
+		return BlockBegin{}, false
+	}
+	return BlockBegin{child}, true
+}
+
+// BlockBegin is a mustache_block_begin node.
+type BlockBegin struct{ *tree_sitter.Node }
+
+// AsBlockBegin returns node as a BlockBegin if it is one.
+func AsBlockBegin(node *tree_sitter.Node) (BlockBegin, bool) {
+	if node == nil || node.Kind() != KindBlockBegin {
+		return BlockBegin{}, false
+	}
+	return BlockBegin{node}, true
+}
+
+// Name returns the name field.
+func (n BlockBegin) Name() (MustacheTagName, bool) {
+	child := n.ChildByFieldName("name")
+	if child == nil {
+		return MustacheTagName{}, false
+	}
+	return MustacheTagName{child}, true
+}
+
+// TrimAfter returns the trim_after field.
+func (n BlockBegin) TrimAfter() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_after")
+	return child, child != nil
+}
+
+// TrimBefore returns the trim_before field.
+func (n BlockBegin) TrimBefore() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_before")
+	return child, child != nil
+}
+
+// BlockEnd is a mustache_block_end node.
+type BlockEnd struct{ *tree_sitter.Node }
+
+// AsBlockEnd returns node as a BlockEnd if it is one.
+func AsBlockEnd(node *tree_sitter.Node) (BlockEnd, bool) {
+	if node == nil || node.Kind() != KindBlockEnd {
+		return BlockEnd{}, false
+	}
+	return BlockEnd{node}, true
+}
+
+// Name returns the name field.
+func (n BlockEnd) Name() (MustacheTagName, bool) {
+	child := n.ChildByFieldName("name")
+	if child == nil {
+		return MustacheTagName{}, false
+	}
+	return MustacheTagName{child}, true
+}
+
+// TrimAfter returns the trim_after field.
+func (n BlockEnd) TrimAfter() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_after")
+	return child, child != nil
+}
+
+// TrimBefore returns the trim_before field.
+func (n BlockEnd) TrimBefore() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_before")
+	return child, child != nil
+}
+
+// BlockParams is a mustache_block_params node.
+type BlockParams struct{ *tree_sitter.Node }
+
+// AsBlockParams returns node as a BlockParams if it is one.
+func AsBlockParams(node *tree_sitter.Node) (BlockParams, bool) {
+	if node == nil || node.Kind() != KindBlockParams {
+		return BlockParams{}, false
+	}
+	return BlockParams{node}, true
+}
+
+// Identifier returns the first Identifier child.
+func (n BlockParams) Identifier() (Identifier, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindIdentifier {
+			return Identifier{c}, true
+		}
+	}
+	return Identifier{}, false
+}
+
+// Identifiers returns the Identifier children.
+func (n BlockParams) Identifiers() []Identifier {
+	var nodes []Identifier
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindIdentifier {
+			nodes = append(nodes, Identifier{c})
+		}
+	}
+	return nodes
+}
+
+// MustacheComment is a mustache_comment node.
+type MustacheComment struct{ *tree_sitter.Node }
+
+// AsMustacheComment returns node as a MustacheComment if it is one.
+func AsMustacheComment(node *tree_sitter.Node) (MustacheComment, bool) {
+	if node == nil || node.Kind() != KindMustacheComment {
+		return MustacheComment{}, false
+	}
+	return MustacheComment{node}, true
+}
+
+func (MustacheComment) isAnyMustacheNode() {}
+
+func (MustacheComment) isAnyNode() {}
+
+// Content returns the content field.
+func (n MustacheComment) Content() (CommentContent, bool) {
+	child := n.ChildByFieldName("content")
+	if child == nil {
+		return CommentContent{}, false
+	}
+	return CommentContent{child}, true
+}
+
+// TrimAfter returns the trim_after field.
+func (n MustacheComment) TrimAfter() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_after")
+	return child, child != nil
+}
+
+// TrimBefore returns the trim_before field.
+func (n MustacheComment) TrimBefore() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_before")
+	return child, child != nil
+}
+
+// CommentContent returns the first CommentContent child.
+func (n MustacheComment) CommentContent() (CommentContent, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindCommentContent {
+			return CommentContent{c}, true
+		}
+	}
+	return CommentContent{}, false
+}
+
+// CommentContent is a mustache_comment_content node.
+type CommentContent struct{ *tree_sitter.Node }
+
+// AsCommentContent returns node as a CommentContent if it is one.
+func AsCommentContent(node *tree_sitter.Node) (CommentContent, bool) {
+	if node == nil || node.Kind() != KindCommentContent {
+		return CommentContent{}, false
+	}
+	return CommentContent{node}, true
+}
+
+// Delimiter is a mustache_delimiter node.
+type Delimiter struct{ *tree_sitter.Node }
+
+// AsDelimiter returns node as a Delimiter if it is one.
+func AsDelimiter(node *tree_sitter.Node) (Delimiter, bool) {
+	if node == nil || node.Kind() != KindDelimiter {
+		return Delimiter{}, false
+	}
+	return Delimiter{node}, true
+}
+
+// DynamicPartial is a mustache_dynamic_partial node.
+type DynamicPartial struct{ *tree_sitter.Node }
+
+// AsDynamicPartial returns node as a DynamicPartial if it is one.
+func AsDynamicPartial(node *tree_sitter.Node) (DynamicPartial, bool) {
+	if node == nil || node.Kind() != KindDynamicPartial {
+		return DynamicPartial{}, false
+	}
+	return DynamicPartial{node}, true
+}
+
+func (DynamicPartial) isAnyMustacheNode() {}
+
+func (DynamicPartial) isAnyNode() {}
+
+// Name returns the name field.
+func (n DynamicPartial) Name() (AnyMustacheExpression, bool) {
+	child := n.ChildByFieldName("name")
+	return AsAnyMustacheExpression(child)
+}
+
+// TrimAfter returns the trim_after field.
+func (n DynamicPartial) TrimAfter() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_after")
+	return child, child != nil
+}
+
+// TrimBefore returns the trim_before field.
+func (n DynamicPartial) TrimBefore() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_before")
+	return child, child != nil
+}
+
+// Else is a mustache_else node.
+type Else struct{ *tree_sitter.Node }
+
+// AsElse returns node as a Else if it is one.
+func AsElse(node *tree_sitter.Node) (Else, bool) {
+	if node == nil || node.Kind() != KindElse {
+		return Else{}, false
+	}
+	return Else{node}, true
+}
+
+// BlockParams returns the block_params field.
+func (n Else) BlockParams() (BlockParams, bool) {
+	child := n.ChildByFieldName("block_params")
+	if child == nil {
+		return BlockParams{}, false
+	}
+	return BlockParams{child}, true
+}
+
+// Hash returns the hash field.
+func (n Else) Hash() []HashPair {
+	var nodes []HashPair
+	cursor := n.Walk()
+	defer cursor.Close()
+	for _, child := range n.ChildrenByFieldName("hash", cursor) {
+		nodes = append(nodes, HashPair{&child})
+	}
+	return nodes
+}
+
+// Name returns the name field.
+func (n Else) Name() (MustacheTagName, bool) {
+	child := n.ChildByFieldName("name")
+	if child == nil {
+		return MustacheTagName{}, false
+	}
+	return MustacheTagName{child}, true
+}
+
+// Param returns the param field.
+func (n Else) Param() []*tree_sitter.Node {
+	var nodes []*tree_sitter.Node
+	cursor := n.Walk()
+	defer cursor.Close()
+	for _, child := range n.ChildrenByFieldName("param", cursor) {
+		nodes = append(nodes, &child)
+	}
+	return nodes
+}
+
+// TrimAfter returns the trim_after field.
+func (n Else) TrimAfter() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_after")
+	return child, child != nil
+}
+
+// TrimBefore returns the trim_before field.
+func (n Else) TrimBefore() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_before")
+	return child, child != nil
+}
+
+// ErroneousBlockEnd is a mustache_erroneous_block_end node.
+type ErroneousBlockEnd struct{ *tree_sitter.Node }
+
+// AsErroneousBlockEnd returns node as a ErroneousBlockEnd if it is one.
+func AsErroneousBlockEnd(node *tree_sitter.Node) (ErroneousBlockEnd, bool) {
+	if node == nil || node.Kind() != KindErroneousBlockEnd {
+		return ErroneousBlockEnd{}, false
+	}
+	return ErroneousBlockEnd{node}, true
+}
+
+// Name returns the name field.
+func (n ErroneousBlockEnd) Name() (ErroneousTagName, bool) {
+	child := n.ChildByFieldName("name")
+	if child == nil {
+		return ErroneousTagName{}, false
+	}
+	return ErroneousTagName{child}, true
+}
+
+// TrimAfter returns the trim_after field.
+func (n ErroneousBlockEnd) TrimAfter() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_after")
+	return child, child != nil
+}
+
+// TrimBefore returns the trim_before field.
+func (n ErroneousBlockEnd) TrimBefore() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_before")
+	return child, child != nil
+}
+
+// ErroneousInvertedSectionEnd is a mustache_erroneous_inverted_section_end node.
+type ErroneousInvertedSectionEnd struct{ *tree_sitter.Node }
+
+// AsErroneousInvertedSectionEnd returns node as a ErroneousInvertedSectionEnd if it is one.
+func AsErroneousInvertedSectionEnd(node *tree_sitter.Node) (ErroneousInvertedSectionEnd, bool) {
+	if node == nil || node.Kind() != KindErroneousInvertedSectionEnd {
+		return ErroneousInvertedSectionEnd{}, false
+	}
+	return ErroneousInvertedSectionEnd{node}, true
+}
+
+// Name returns the name field.
+func (n ErroneousInvertedSectionEnd) Name() (ErroneousTagName, bool) {
+	child := n.ChildByFieldName("name")
+	if child == nil {
+		return ErroneousTagName{}, false
+	}
+	return ErroneousTagName{child}, true
+}
+
+// TrimAfter returns the trim_after field.
+func (n ErroneousInvertedSectionEnd) TrimAfter() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_after")
+	return child, child != nil
+}
+
+// TrimBefore returns the trim_before field.
+func (n ErroneousInvertedSectionEnd) TrimBefore() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_before")
+	return child, child != nil
+}
+
+// ErroneousParentEnd is a mustache_erroneous_parent_end node.
+type ErroneousParentEnd struct{ *tree_sitter.Node }
+
+// AsErroneousParentEnd returns node as a ErroneousParentEnd if it is one.
+func AsErroneousParentEnd(node *tree_sitter.Node) (ErroneousParentEnd, bool) {
+	if node == nil || node.Kind() != KindErroneousParentEnd {
+		return ErroneousParentEnd{}, false
+	}
+	return ErroneousParentEnd{node}, true
+}
+
+// Name returns the name field.
+func (n ErroneousParentEnd) Name() (ErroneousTagName, bool) {
+	child := n.ChildByFieldName("name")
+	if child == nil {
+		return ErroneousTagName{}, false
+	}
+	return ErroneousTagName{child}, true
+}
+
+// TrimAfter returns the trim_after field.
+func (n ErroneousParentEnd) TrimAfter() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_after")
+	return child, child != nil
+}
+
+// TrimBefore returns the trim_before field.
+func (n ErroneousParentEnd) TrimBefore() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_before")
+	return child, child != nil
+}
+
+// ErroneousSectionEnd is a mustache_erroneous_section_end node.
+type ErroneousSectionEnd struct{ *tree_sitter.Node }
+
+// AsErroneousSectionEnd returns node as a ErroneousSectionEnd if it is one.
+func AsErroneousSectionEnd(node *tree_sitter.Node) (ErroneousSectionEnd, bool) {
+	if node == nil || node.Kind() != KindErroneousSectionEnd {
+		return ErroneousSectionEnd{}, false
+	}
+	return ErroneousSectionEnd{node}, true
+}
+
+// Name returns the name field.
+func (n ErroneousSectionEnd) Name() (ErroneousTagName, bool) {
+	child := n.ChildByFieldName("name")
+	if child == nil {
+		return ErroneousTagName{}, false
+	}
+	return ErroneousTagName{child}, true
+}
+
+// TrimAfter returns the trim_after field.
+func (n ErroneousSectionEnd) TrimAfter() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_after")
+	return child, child != nil
+}
+
+// TrimBefore returns the trim_before field.
+func (n ErroneousSectionEnd) TrimBefore() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_before")
+	return child, child != nil
+}
+
+// ErroneousTagName is a mustache_erroneous_tag_name node.
+type ErroneousTagName struct{ *tree_sitter.Node }
+
+// AsErroneousTagName returns node as a ErroneousTagName if it is one.
+func AsErroneousTagName(node *tree_sitter.Node) (ErroneousTagName, bool) {
+	if node == nil || node.Kind() != KindErroneousTagName {
+		return ErroneousTagName{}, false
+	}
+	return ErroneousTagName{node}, true
+}
+
+// HashPair is a mustache_hash_pair node.
+type HashPair struct{ *tree_sitter.Node }
+
+// AsHashPair returns node as a HashPair if it is one.
+func AsHashPair(node *tree_sitter.Node) (HashPair, bool) {
+	if node == nil || node.Kind() != KindHashPair {
+		return HashPair{}, false
+	}
+	return HashPair{node}, true
+}
+
+// Key returns the key field.
+func (n HashPair) Key() (Identifier, bool) {
+	child := n.ChildByFieldName("key")
+	if child == nil {
+		return Identifier{}, false
+	}
+	return Identifier{child}, true
+}
+
+// Value returns the value field.
+func (n HashPair) Value() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("value")
+	return child, child != nil
+}
+
+// HelperCall is a mustache_helper_call node.
+type HelperCall struct{ *tree_sitter.Node }
+
+// AsHelperCall returns node as a HelperCall if it is one.
+func AsHelperCall(node *tree_sitter.Node) (HelperCall, bool) {
+	if node == nil || node.Kind() != KindHelperCall {
+		return HelperCall{}, false
+	}
+	return HelperCall{node}, true
+}
+
+// Hash returns the hash field.
+func (n HelperCall) Hash() []HashPair {
+	var nodes []HashPair
+	cursor := n.Walk()
+	defer cursor.Close()
+	for _, child := range n.ChildrenByFieldName("hash", cursor) {
+		nodes = append(nodes, HashPair{&child})
+	}
+	return nodes
+}
+
+// Helper returns the helper field.
+func (n HelperCall) Helper() (AnyMustacheExpression, bool) {
+	child := n.ChildByFieldName("helper")
+	return AsAnyMustacheExpression(child)
+}
+
+// Param returns the param field.
+func (n HelperCall) Param() []*tree_sitter.Node {
+	var nodes []*tree_sitter.Node
+	cursor := n.Walk()
+	defer cursor.Close()
+	for _, child := range n.ChildrenByFieldName("param", cursor) {
+		nodes = append(nodes, &child)
+	}
+	return nodes
+}
+
+// Identifier is a mustache_identifier node.
+type Identifier struct{ *tree_sitter.Node }
+
+// AsIdentifier returns node as a Identifier if it is one.
+func AsIdentifier(node *tree_sitter.Node) (Identifier, bool) {
+	if node == nil || node.Kind() != KindIdentifier {
+		return Identifier{}, false
+	}
+	return Identifier{node}, true
+}
+
+func (Identifier) isAnyMustacheExpression() {}
+
+// ImplicitIterator is a mustache_implicit_iterator node.
+type ImplicitIterator struct{ *tree_sitter.Node }
+
+// AsImplicitIterator returns node as a ImplicitIterator if it is one.
+func AsImplicitIterator(node *tree_sitter.Node) (ImplicitIterator, bool) {
+	if node == nil || node.Kind() != KindImplicitIterator {
+		return ImplicitIterator{}, false
+	}
+	return ImplicitIterator{node}, true
+}
+
+func (ImplicitIterator) isAnyMustacheExpression() {}
+
+// Interpolation is a mustache_interpolation node.
+type Interpolation struct{ *tree_sitter.Node }
+
+// AsInterpolation returns node as a Interpolation if it is one.
+func AsInterpolation(node *tree_sitter.Node) (Interpolation, bool) {
+	if node == nil || node.Kind() != KindInterpolation {
+		return Interpolation{}, false
+	}
+	return Interpolation{node}, true
+}
+
+func (Interpolation) isAnyAttribute() {}
+
+func (Interpolation) isAnyMustacheNode() {}
+
+func (Interpolation) isAnyNode() {}
+
+// Expression returns the expression field.
+func (n Interpolation) Expression() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("expression")
+	return child, child != nil
+}
+
+// TrimAfter returns the trim_after field.
+func (n Interpolation) TrimAfter() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_after")
+	return child, child != nil
+}
+
+// TrimBefore returns the trim_before field.
+func (n Interpolation) TrimBefore() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_before")
+	return child, child != nil
+}
+
+// InvertedSection is a mustache_inverted_section node.
+type InvertedSection struct{ *tree_sitter.Node }
+
+// AsInvertedSection returns node as a InvertedSection if it is one.
+func AsInvertedSection(node *tree_sitter.Node) (InvertedSection, bool) {
+	if node == nil || node.Kind() != KindInvertedSection {
+		return InvertedSection{}, false
+	}
+	return InvertedSection{node}, true
+}
+
+func (InvertedSection) isAnyMustacheNode() {}
+
+func (InvertedSection) isAnyNode() {}
+
+// Close returns the close field.
+func (n InvertedSection) Close() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("close")
+	return child, child != nil
+}
+
+// Content returns the content field.
+func (n InvertedSection) Content() []*tree_sitter.Node {
+	var nodes []*tree_sitter.Node
+	cursor := n.Walk()
+	defer cursor.Close()
+	for _, child := range n.ChildrenByFieldName("content", cursor) {
+		nodes = append(nodes, &child)
+	}
+	return nodes
+}
+
+// Open returns the open field.
+func (n InvertedSection) Open() (InvertedSectionBegin, bool) {
+	child := n.ChildByFieldName("open")
+	if child == nil {
+		return InvertedSectionBegin{}, false
+	}
+	return InvertedSectionBegin{child}, true
+}
+
+// InvertedSectionBegin is a mustache_inverted_section_begin node.
+type InvertedSectionBegin struct{ *tree_sitter.Node }
+
+// AsInvertedSectionBegin returns node as a InvertedSectionBegin if it is one.
+func AsInvertedSectionBegin(node *tree_sitter.Node) (InvertedSectionBegin, bool) {
+	if node == nil || node.Kind() != KindInvertedSectionBegin {
+		return InvertedSectionBegin{}, false
+	}
+	return InvertedSectionBegin{node}, true
+}
+
+// BlockParams returns the block_params field.
+func (n InvertedSectionBegin) BlockParams() (BlockParams, bool) {
+	child := n.ChildByFieldName("block_params")
+	if child == nil {
+		return BlockParams{}, false
+	}
+	return BlockParams{child}, true
+}
+
+// Hash returns the hash field.
+func (n InvertedSectionBegin) Hash() []HashPair {
+	var nodes []HashPair
+	cursor := n.Walk()
+	defer cursor.Close()
+	for _, child := range n.ChildrenByFieldName("hash", cursor) {
+		nodes = append(nodes, HashPair{&child})
+	}
+	return nodes
+}
+
+// Name returns the name field.
+func (n InvertedSectionBegin) Name() (MustacheTagName, bool) {
+	child := n.ChildByFieldName("name")
+	if child == nil {
+		return MustacheTagName{}, false
+	}
+	return MustacheTagName{child}, true
+}
+
+// Param returns the param field.
+func (n InvertedSectionBegin) Param() []*tree_sitter.Node {
+	var nodes []*tree_sitter.Node
+	cursor := n.Walk()
+	defer cursor.Close()
+	for _, child := range n.ChildrenByFieldName("param", cursor) {
+		nodes = append(nodes, &child)
+	}
+	return nodes
+}
+
+// TrimAfter returns the trim_after field.
+func (n InvertedSectionBegin) TrimAfter() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_after")
+	return child, child != nil
+}
+
+// TrimBefore returns the trim_before field.
+func (n InvertedSectionBegin) TrimBefore() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_before")
+	return child, child != nil
+}
+
+// InvertedSectionEnd is a mustache_inverted_section_end node.
+type InvertedSectionEnd struct{ *tree_sitter.Node }
+
+// AsInvertedSectionEnd returns node as a InvertedSectionEnd if it is one.
+func AsInvertedSectionEnd(node *tree_sitter.Node) (InvertedSectionEnd, bool) {
+	if node == nil || node.Kind() != KindInvertedSectionEnd {
+		return InvertedSectionEnd{}, false
+	}
+	return InvertedSectionEnd{node}, true
+}
+
+// Name returns the name field.
+func (n InvertedSectionEnd) Name() (MustacheTagName, bool) {
+	child := n.ChildByFieldName("name")
+	if child == nil {
+		return MustacheTagName{}, false
+	}
+	return MustacheTagName{child}, true
+}
+
+// TrimAfter returns the trim_after field.
+func (n InvertedSectionEnd) TrimAfter() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_after")
+	return child, child != nil
+}
+
+// TrimBefore returns the trim_before field.
+func (n InvertedSectionEnd) TrimBefore() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_before")
+	return child, child != nil
+}
+
+// Parent is a mustache_parent node.
+type Parent struct{ *tree_sitter.Node }
+
+// AsParent returns node as a Parent if it is one.
+func AsParent(node *tree_sitter.Node) (Parent, bool) {
+	if node == nil || node.Kind() != KindParent {
+		return Parent{}, false
+	}
+	return Parent{node}, true
+}
+
+func (Parent) isAnyMustacheNode() {}
+
+func (Parent) isAnyNode() {}
+
+// Close returns the close field.
+func (n Parent) Close() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("close")
+	return child, child != nil
+}
+
+// Content returns the content field.
+func (n Parent) Content() []AnyNode {
+	var nodes []AnyNode
+	cursor := n.Walk()
+	defer cursor.Close()
+	for _, child := range n.ChildrenByFieldName("content", cursor) {
+		if node, ok := AsAnyNode(&child); ok {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// Open returns the open field.
+func (n Parent) Open() (ParentBegin, bool) {
+	child := n.ChildByFieldName("open")
+	if child == nil {
+		return ParentBegin{}, false
+	}
+	return ParentBegin{child}, true
+}
+
+// ParentBegin is a mustache_parent_begin node.
+type ParentBegin struct{ *tree_sitter.Node }
+
+// AsParentBegin returns node as a ParentBegin if it is one.
+func AsParentBegin(node *tree_sitter.Node) (ParentBegin, bool) {
+	if node == nil || node.Kind() != KindParentBegin {
+		return ParentBegin{}, false
+	}
+	return ParentBegin{node}, true
+}
+
+// Name returns the name field.
+func (n ParentBegin) Name() (MustacheTagName, bool) {
+	child := n.ChildByFieldName("name")
+	if child == nil {
+		return MustacheTagName{}, false
+	}
+	return MustacheTagName{child}, true
+}
+
+// TrimAfter returns the trim_after field.
+func (n ParentBegin) TrimAfter() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_after")
+	return child, child != nil
+}
+
+// TrimBefore returns the trim_before field.
+func (n ParentBegin) TrimBefore() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_before")
+	return child, child != nil
+}
+
+// ParentEnd is a mustache_parent_end node.
+type ParentEnd struct{ *tree_sitter.Node }
+
+// AsParentEnd returns node as a ParentEnd if it is one.
+func AsParentEnd(node *tree_sitter.Node) (ParentEnd, bool) {
+	if node == nil || node.Kind() != KindParentEnd {
+		return ParentEnd{}, false
+	}
+	return ParentEnd{node}, true
+}
+
+// Name returns the name field.
+func (n ParentEnd) Name() (MustacheTagName, bool) {
+	child := n.ChildByFieldName("name")
+	if child == nil {
+		return MustacheTagName{}, false
+	}
+	return MustacheTagName{child}, true
+}
+
+// TrimAfter returns the trim_after field.
+func (n ParentEnd) TrimAfter() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_after")
+	return child, child != nil
+}
+
+// TrimBefore returns the trim_before field.
+func (n ParentEnd) TrimBefore() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_before")
+	return child, child != nil
+}
+
+// Partial is a mustache_partial node.
+type Partial struct{ *tree_sitter.Node }
+
+// AsPartial returns node as a Partial if it is one.
+func AsPartial(node *tree_sitter.Node) (Partial, bool) {
+	if node == nil || node.Kind() != KindPartial {
+		return Partial{}, false
+	}
+	return Partial{node}, true
+}
+
+func (Partial) isAnyMustacheNode() {}
+
+func (Partial) isAnyNode() {}
+
+// Name returns the name field.
+func (n Partial) Name() (PartialContent, bool) {
+	child := n.ChildByFieldName("name")
+	if child == nil {
+		return PartialContent{}, false
+	}
+	return PartialContent{child}, true
+}
+
+// TrimAfter returns the trim_after field.
+func (n Partial) TrimAfter() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_after")
+	return child, child != nil
+}
+
+// TrimBefore returns the trim_before field.
+func (n Partial) TrimBefore() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_before")
+	return child, child != nil
+}
+
+// PartialContent returns the first PartialContent child.
+func (n Partial) PartialContent() (PartialContent, bool) {
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Kind() == KindPartialContent {
+			return PartialContent{c}, true
+		}
+	}
+	return PartialContent{}, false
+}
+
+// PartialContent is a mustache_partial_content node.
+type PartialContent struct{ *tree_sitter.Node }
+
+// AsPartialContent returns node as a PartialContent if it is one.
+func AsPartialContent(node *tree_sitter.Node) (PartialContent, bool) {
+	if node == nil || node.Kind() != KindPartialContent {
+		return PartialContent{}, false
+	}
+	return PartialContent{node}, true
+}
+
+// PathExpression is a mustache_path_expression node.
+type PathExpression struct{ *tree_sitter.Node }
+
+// AsPathExpression returns node as a PathExpression if it is one.
+func AsPathExpression(node *tree_sitter.Node) (PathExpression, bool) {
+	if node == nil || node.Kind() != KindPathExpression {
+		return PathExpression{}, false
+	}
+	return PathExpression{node}, true
+}
+
+func (PathExpression) isAnyMustacheExpression() {}
+
+// Key returns the key field.
+func (n PathExpression) Key() []Identifier {
+	var nodes []Identifier
+	cursor := n.Walk()
+	defer cursor.Close()
+	for _, child := range n.ChildrenByFieldName("key", cursor) {
+		nodes = append(nodes, Identifier{&child})
+	}
+	return nodes
+}
+
+// Section is a mustache_section node.
+type Section struct{ *tree_sitter.Node }
+
+// AsSection returns node as a Section if it is one.
+func AsSection(node *tree_sitter.Node) (Section, bool) {
+	if node == nil || node.Kind() != KindSection {
+		return Section{}, false
+	}
+	return Section{node}, true
+}
+
+func (Section) isAnyMustacheNode() {}
+
+func (Section) isAnyNode() {}
+
+// Close returns the close field.
+func (n Section) Close() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("close")
+	return child, child != nil
+}
+
+// Content returns the content field.
+func (n Section) Content() []*tree_sitter.Node {
+	var nodes []*tree_sitter.Node
+	cursor := n.Walk()
+	defer cursor.Close()
+	for _, child := range n.ChildrenByFieldName("content", cursor) {
+		nodes = append(nodes, &child)
+	}
+	return nodes
+}
+
+// Open returns the open field.
+func (n Section) Open() (SectionBegin, bool) {
+	child := n.ChildByFieldName("open")
+	if child == nil {
+		return SectionBegin{}, false
+	}
+	return SectionBegin{child}, true
+}
+
+// SectionBegin is a mustache_section_begin node.
+type SectionBegin struct{ *tree_sitter.Node }
+
+// AsSectionBegin returns node as a SectionBegin if it is one.
+func AsSectionBegin(node *tree_sitter.Node) (SectionBegin, bool) {
+	if node == nil || node.Kind() != KindSectionBegin {
+		return SectionBegin{}, false
+	}
+	return SectionBegin{node}, true
+}
+
+// BlockParams returns the block_params field.
+func (n SectionBegin) BlockParams() (BlockParams, bool) {
+	child := n.ChildByFieldName("block_params")
+	if child == nil {
+		return BlockParams{}, false
+	}
+	return BlockParams{child}, true
+}
+
+// Hash returns the hash field.
+func (n SectionBegin) Hash() []HashPair {
+	var nodes []HashPair
+	cursor := n.Walk()
+	defer cursor.Close()
+	for _, child := range n.ChildrenByFieldName("hash", cursor) {
+		nodes = append(nodes, HashPair{&child})
+	}
+	return nodes
+}
+
+// Name returns the name field.
+func (n SectionBegin) Name() (MustacheTagName, bool) {
+	child := n.ChildByFieldName("name")
+	if child == nil {
+		return MustacheTagName{}, false
+	}
+	return MustacheTagName{child}, true
+}
+
+// Param returns the param field.
+func (n SectionBegin) Param() []*tree_sitter.Node {
+	var nodes []*tree_sitter.Node
+	cursor := n.Walk()
+	defer cursor.Close()
+	for _, child := range n.ChildrenByFieldName("param", cursor) {
+		nodes = append(nodes, &child)
+	}
+	return nodes
+}
+
+// TrimAfter returns the trim_after field.
+func (n SectionBegin) TrimAfter() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_after")
+	return child, child != nil
+}
+
+// TrimBefore returns the trim_before field.
+func (n SectionBegin) TrimBefore() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_before")
+	return child, child != nil
+}
+
+// SectionEnd is a mustache_section_end node.
+type SectionEnd struct{ *tree_sitter.Node }
+
+// AsSectionEnd returns node as a SectionEnd if it is one.
+func AsSectionEnd(node *tree_sitter.Node) (SectionEnd, bool) {
+	if node == nil || node.Kind() != KindSectionEnd {
+		return SectionEnd{}, false
+	}
+	return SectionEnd{node}, true
+}
+
+// Name returns the name field.
+func (n SectionEnd) Name() (MustacheTagName, bool) {
+	child := n.ChildByFieldName("name")
+	if child == nil {
+		return MustacheTagName{}, false
+	}
+	return MustacheTagName{child}, true
+}
+
+// TrimAfter returns the trim_after field.
+func (n SectionEnd) TrimAfter() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_after")
+	return child, child != nil
+}
+
+// TrimBefore returns the trim_before field.
+func (n SectionEnd) TrimBefore() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_before")
+	return child, child != nil
+}
+
+// SetDelimiter is a mustache_set_delimiter node.
+type SetDelimiter struct{ *tree_sitter.Node }
+
+// AsSetDelimiter returns node as a SetDelimiter if it is one.
+func AsSetDelimiter(node *tree_sitter.Node) (SetDelimiter, bool) {
+	if node == nil || node.Kind() != KindSetDelimiter {
+		return SetDelimiter{}, false
+	}
+	return SetDelimiter{node}, true
+}
+
+func (SetDelimiter) isAnyMustacheNode() {}
+
+func (SetDelimiter) isAnyNode() {}
+
+// CloseDelimiter returns the close_delimiter field.
+func (n SetDelimiter) CloseDelimiter() (Delimiter, bool) {
+	child := n.ChildByFieldName("close_delimiter")
+	if child == nil {
+		return Delimiter{}, false
+	}
+	return Delimiter{child}, true
+}
+
+// OpenDelimiter returns the open_delimiter field.
+func (n SetDelimiter) OpenDelimiter() (Delimiter, bool) {
+	child := n.ChildByFieldName("open_delimiter")
+	if child == nil {
+		return Delimiter{}, false
+	}
+	return Delimiter{child}, true
+}
+
+// String is a mustache_string node.
+type String struct{ *tree_sitter.Node }
+
+// AsString returns node as a String if it is one.
+func AsString(node *tree_sitter.Node) (String, bool) {
+	if node == nil || node.Kind() != KindString {
+		return String{}, false
+	}
+	return String{node}, true
+}
+
+// Subexpression is a mustache_subexpression node.
+type Subexpression struct{ *tree_sitter.Node }
+
+// AsSubexpression returns node as a Subexpression if it is one.
+func AsSubexpression(node *tree_sitter.Node) (Subexpression, bool) {
+	if node == nil || node.Kind() != KindSubexpression {
+		return Subexpression{}, false
+	}
+	return Subexpression{node}, true
+}
+
+// Hash returns the hash field.
+func (n Subexpression) Hash() []HashPair {
+	var nodes []HashPair
+	cursor := n.Walk()
+	defer cursor.Close()
+	for _, child := range n.ChildrenByFieldName("hash", cursor) {
+		nodes = append(nodes, HashPair{&child})
+	}
+	return nodes
+}
+
+// Helper returns the helper field.
+func (n Subexpression) Helper() (AnyMustacheExpression, bool) {
+	child := n.ChildByFieldName("helper")
+	return AsAnyMustacheExpression(child)
+}
+
+// Param returns the param field.
+func (n Subexpression) Param() []*tree_sitter.Node {
+	var nodes []*tree_sitter.Node
+	cursor := n.Walk()
+	defer cursor.Close()
+	for _, child := range n.ChildrenByFieldName("param", cursor) {
+		nodes = append(nodes, &child)
+	}
+	return nodes
+}
+
+// MustacheTagName is a mustache_tag_name node.
+type MustacheTagName struct{ *tree_sitter.Node }
+
+// AsMustacheTagName returns node as a MustacheTagName if it is one.
+func AsMustacheTagName(node *tree_sitter.Node) (MustacheTagName, bool) {
+	if node == nil || node.Kind() != KindMustacheTagName {
+		return MustacheTagName{}, false
+	}
+	return MustacheTagName{node}, true
+}
+
+// Triple is a mustache_triple node.
+type Triple struct{ *tree_sitter.Node }
+
+// AsTriple returns node as a Triple if it is one.
+func AsTriple(node *tree_sitter.Node) (Triple, bool) {
+	if node == nil || node.Kind() != KindTriple {
+		return Triple{}, false
+	}
+	return Triple{node}, true
+}
+
+func (Triple) isAnyAttribute() {}
+
+func (Triple) isAnyMustacheNode() {}
+
+func (Triple) isAnyNode() {}
+
+// Expression returns the expression field.
+func (n Triple) Expression() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("expression")
+	return child, child != nil
+}
+
+// TrimAfter returns the trim_after field.
+func (n Triple) TrimAfter() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_after")
+	return child, child != nil
+}
+
+// TrimBefore returns the trim_before field.
+func (n Triple) TrimBefore() (*tree_sitter.Node, bool) {
+	child := n.ChildByFieldName("trim_before")
+	return child, child != nil
+}
+
+// Text is a text node.
+type Text struct{ *tree_sitter.Node }
+
+// AsText returns node as a Text if it is one.
+func AsText(node *tree_sitter.Node) (Text, bool) {
+	if node == nil || node.Kind() != KindText {
+		return Text{}, false
+	}
+	return Text{node}, true
+}
+
+func (Text) isAnyHtmlNode() {}
+
+func (Text) isAnyNode() {}
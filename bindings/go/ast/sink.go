@@ -0,0 +1,66 @@
+package ast
+
+import "strings"
+
+// SinkKind is the language an attribute's value is interpreted in, which
+// decides how interpolated data must be escaped to be safe there; HTML
+// escaping alone is enough only for SinkNone.
+type SinkKind int
+
+const (
+	// SinkNone is plain text, such as title or class.
+	SinkNone SinkKind = iota
+	// SinkURL is a URL that the browser loads or navigates to, such as
+	// href or src.
+	SinkURL
+	// SinkJS is JavaScript run as an event handler, such as onclick.
+	SinkJS
+	// SinkCSS is a CSS declaration list: the style attribute.
+	SinkCSS
+)
+
+func (k SinkKind) String() string {
+	switch k {
+	case SinkNone:
+		return "none"
+	case SinkURL:
+		return "url"
+	case SinkJS:
+		return "js"
+	case SinkCSS:
+		return "css"
+	}
+	return "unknown"
+}
+
+// urlAttributes are the attributes whose values browsers load or navigate
+// to as URLs.
+var urlAttributes = map[string]bool{
+	"href": true, "src": true, "action": true, "formaction": true, "poster": true,
+	"cite": true, "data": true, "background": true, "ping": true, "manifest": true,
+	"srcset": true, "xlink:href": true,
+}
+
+// AttributeSink returns the sink kind of the attribute called name, which
+// is matched case-insensitively.
+func AttributeSink(name string) SinkKind {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasPrefix(name, "on"):
+		return SinkJS
+	case name == "style":
+		return SinkCSS
+	case urlAttributes[name]:
+		return SinkURL
+	}
+	return SinkNone
+}
+
+// SinkKind returns the sink kind of the attribute, by its name in src.
+func (n Attribute) SinkKind(src []byte) SinkKind {
+	name, ok := n.Name()
+	if !ok {
+		return SinkNone
+	}
+	return AttributeSink(name.Utf8Text(src))
+}
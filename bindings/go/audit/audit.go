@@ -0,0 +1,181 @@
+// Package audit flags interpolations in contexts where HTML escaping does
+// not make data safe: unescaped output in attributes, data in URL, event
+// handler and style attributes, and data inside <script>. Attributes are
+// classified by ast.AttributeSink, as for render's contextual escaping.
+// Findings are lint diagnostics, and can be written as SARIF for code
+// scanning in CI.
+package audit
+
+import (
+	"fmt"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/ast"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/lint"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// Rule names of the audit checks.
+const (
+	UnescapedAttribute        = "unescapedAttribute"
+	EventHandlerInterpolation = "eventHandlerInterpolation"
+	URLInterpolation          = "urlInterpolation"
+	CSSInterpolation          = "cssInterpolation"
+	JavaScriptURL             = "javascriptURL"
+	ScriptInterpolation       = "scriptInterpolation"
+)
+
+// descriptions holds a one-line description of each rule, used in SARIF
+// output.
+var descriptions = map[string]string{
+	UnescapedAttribute:        "Unescaped interpolation in an attribute value",
+	EventHandlerInterpolation: "Interpolation in an event handler attribute",
+	URLInterpolation:          "Interpolation in a URL attribute",
+	CSSInterpolation:          "Interpolation in a style attribute",
+	JavaScriptURL:             "Interpolation that can produce a javascript: URL",
+	ScriptInterpolation:       "Interpolation inside a <script> element",
+}
+
+// Rules returns the audit checks. They are lint rules, so they can also run
+// alongside lint.DefaultRules, and each finding's rule name can be disabled
+// by lint directives such as {{! htmlmustache-disable-next-line
+// urlInterpolation }}.
+func Rules() []lint.Rule {
+	return []lint.Rule{attributes{}, scripts{}}
+}
+
+// Audit parses src and returns its findings in source order.
+func Audit(src []byte) ([]lint.Diagnostic, error) {
+	return lint.Lint(src, Rules())
+}
+
+// attributes checks interpolations in attribute values.
+type attributes struct{}
+
+func (attributes) Name() string { return "attributes" }
+
+func (attributes) Reports() []string {
+	return []string{UnescapedAttribute, EventHandlerInterpolation, URLInterpolation, JavaScriptURL, CSSInterpolation}
+}
+
+func (attributes) Check(root *tree_sitter.Node, src []byte) []lint.Diagnostic {
+	var findings []lint.Diagnostic
+	walk.Visit(root, func(node *tree_sitter.Node) bool {
+		if node.Kind() != "html_attribute" {
+			return true
+		}
+		attribute, _ := ast.AsAttribute(node)
+		sink := attribute.SinkKind(src)
+		name := ""
+		if n, ok := attribute.Name(); ok {
+			name = strings.ToLower(n.Utf8Text(src))
+		}
+		value, ok := attribute.Value()
+		if !ok {
+			return false
+		}
+		var literal strings.Builder
+		walk.Visit(value, func(n *tree_sitter.Node) bool {
+			switch n.Kind() {
+			case "html_attribute_value":
+				literal.WriteString(n.Utf8Text(src))
+			case "mustache_triple":
+				findings = append(findings, lint.At(n, UnescapedAttribute, lint.Error,
+					fmt.Sprintf("Unescaped interpolation %s in attribute %s can inject markup", n.Utf8Text(src), name)))
+				findings = append(findings, contextFindings(n, sink, name, literal.String(), src)...)
+			case "mustache_interpolation":
+				findings = append(findings, contextFindings(n, sink, name, literal.String(), src)...)
+			}
+			return true
+		})
+		return false
+	})
+	return findings
+}
+
+// contextFindings checks an interpolation in the value of the attribute
+// name, of kind sink, after the literal text prefix.
+func contextFindings(n *tree_sitter.Node, sink ast.SinkKind, name, prefix string, src []byte) []lint.Diagnostic {
+	tag := n.Utf8Text(src)
+	switch {
+	case sink == ast.SinkJS:
+		return []lint.Diagnostic{lint.At(n, EventHandlerInterpolation, lint.Error,
+			fmt.Sprintf("Interpolation %s in event handler %s: HTML escaping does not make data safe in JavaScript", tag, name))}
+	case sink == ast.SinkCSS:
+		return []lint.Diagnostic{lint.At(n, CSSInterpolation, lint.Warning,
+			fmt.Sprintf("Interpolation %s in attribute %s: HTML escaping does not make data safe in CSS", tag, name))}
+	case sink != ast.SinkURL:
+		return nil
+	case strings.TrimSpace(prefix) == "":
+		return []lint.Diagnostic{lint.At(n, JavaScriptURL, lint.Error,
+			fmt.Sprintf("Interpolation %s starts attribute %s, so data can set a javascript: URL", tag, name))}
+	case strings.HasPrefix(strings.ToLower(strings.TrimSpace(prefix)), "javascript:"):
+		return []lint.Diagnostic{lint.At(n, JavaScriptURL, lint.Error,
+			fmt.Sprintf("Interpolation %s inside a javascript: URL", tag))}
+	}
+	return []lint.Diagnostic{lint.At(n, URLInterpolation, lint.Warning,
+		fmt.Sprintf("Interpolation %s in URL attribute %s; make sure it is URL-encoded", tag, name))}
+}
+
+// scripts checks for mustache tags inside <script>. Script bodies are raw
+// text in the tree, so the tags are found by scanning it.
+type scripts struct{}
+
+func (scripts) Name() string { return "scripts" }
+
+func (scripts) Reports() []string { return []string{ScriptInterpolation} }
+
+func (scripts) Check(root *tree_sitter.Node, src []byte) []lint.Diagnostic {
+	var findings []lint.Diagnostic
+	walk.Visit(root, func(node *tree_sitter.Node) bool {
+		if node.Kind() != "html_script_element" {
+			return true
+		}
+		body := walk.ChildOfKind(node, "html_raw_text")
+		if body == nil {
+			return false
+		}
+		text := body.Utf8Text(src)
+		for offset := 0; ; {
+			open := strings.Index(text[offset:], "{{")
+			if open < 0 {
+				break
+			}
+			start := offset + open
+			closing := "}}"
+			if strings.HasPrefix(text[start:], "{{{") {
+				closing = "}}}"
+			}
+			end := len(text)
+			if i := strings.Index(text[start:], closing); i >= 0 {
+				end = start + i + len(closing)
+			}
+			tag := text[start:end]
+			offset = end
+			if len(tag) > 2 && strings.ContainsRune("!/", rune(tag[2])) {
+				continue
+			}
+			findings = append(findings, lint.Diagnostic{
+				Rule:       ScriptInterpolation,
+				Severity:   lint.Error,
+				Message:    fmt.Sprintf("Mustache tag %s inside <script>: HTML escaping does not make data safe in JavaScript", tag),
+				StartByte:  body.StartByte() + uint(start),
+				EndByte:    body.StartByte() + uint(end),
+				StartPoint: advance(body.StartPosition(), text[:start]),
+				EndPoint:   advance(body.StartPosition(), text[:end]),
+			})
+		}
+		return false
+	})
+	return findings
+}
+
+// advance returns the position reached from p after text.
+func advance(p tree_sitter.Point, text string) tree_sitter.Point {
+	if i := strings.LastIndexByte(text, '\n'); i >= 0 {
+		return tree_sitter.Point{Row: p.Row + uint(strings.Count(text, "\n")), Column: uint(len(text) - i - 1)}
+	}
+	return tree_sitter.Point{Row: p.Row, Column: p.Column + uint(len(text))}
+}
@@ -0,0 +1,147 @@
+package audit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/audit"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/lint"
+)
+
+func TestAudit(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "escaped interpolation in text and plain attributes",
+			src:  `<p title="{{title}}">{{body}}</p>`,
+		},
+		{
+			name: "unescaped interpolation in attribute",
+			src:  `<p title="{{{title}}}">x</p>`,
+			want: []string{audit.UnescapedAttribute},
+		},
+		{
+			name: "event handlers",
+			src:  `<button onclick="save({{id}})">x</button>`,
+			want: []string{audit.EventHandlerInterpolation},
+		},
+		{
+			name: "URL attributes",
+			src:  `<a href="/users/{{id}}">x</a><img src={{url}}><a href="javascript:{{code}}">y</a>`,
+			want: []string{audit.URLInterpolation, audit.JavaScriptURL, audit.JavaScriptURL},
+		},
+		{
+			name: "conditional attributes",
+			src:  `<a {{#external}}href="{{{url}}}"{{/external}}>x</a>`,
+			want: []string{audit.UnescapedAttribute, audit.JavaScriptURL},
+		},
+		{
+			name: "style attributes",
+			src:  `<p style="color: {{color}}" STYLE="{{{css}}}">x</p>`,
+			want: []string{audit.CSSInterpolation, audit.UnescapedAttribute, audit.CSSInterpolation},
+		},
+		{
+			name: "script bodies",
+			src:  "<script>\nvar user = {{user}};\n{{! fine }}\n</script>",
+			want: []string{audit.ScriptInterpolation},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			findings, err := audit.Audit([]byte(test.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.Rule)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Audit() rules = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAuditDirectives(t *testing.T) {
+	src := "{{! htmlmustache-disable-next-line urlInterpolation }}\n<a href=\"/u/{{id}}\">x</a>\n" +
+		"<a href=\"/u/{{id}}\">y</a>\n{{! htmlmustache-disable-next-line scriptInterpolation }}\n<p></p>"
+	findings, err := lint.Lint([]byte(src), append(audit.Rules(), lint.UnusedDirectives()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Rule)
+	}
+	want := []string{audit.URLInterpolation, "unusedDisableDirectives"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rules = %q, want %q", got, want)
+	}
+	if findings[0].StartPoint.Row != 2 {
+		t.Errorf("finding on row %d, want 2", findings[0].StartPoint.Row)
+	}
+}
+
+func TestAuditScriptRange(t *testing.T) {
+	src := "<script>\nvar user = {{user}};\n</script>"
+	findings, err := audit.Audit([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1", len(findings))
+	}
+	f := findings[0]
+	if got := src[f.StartByte:f.EndByte]; got != "{{user}}" {
+		t.Errorf("finding covers %q", got)
+	}
+	if f.StartPoint.Row != 1 || f.StartPoint.Column != 11 || f.EndPoint.Column != 19 {
+		t.Errorf("finding spans %v-%v", f.StartPoint, f.EndPoint)
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	findings, err := audit.Audit([]byte(`<a href="{{url}}">x</a>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := audit.WriteSARIF(&buf, []audit.File{{Path: "page.mustache", Findings: findings}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var log struct {
+		Version string
+		Runs    []struct {
+			Results []struct {
+				RuleID    string
+				Level     string
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string }
+						Region           struct{ StartLine, StartColumn, EndColumn int }
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Results) != 1 {
+		t.Fatalf("unexpected log:\n%s", buf.String())
+	}
+	result := log.Runs[0].Results[0]
+	location := result.Locations[0].PhysicalLocation
+	if result.RuleID != audit.JavaScriptURL || result.Level != lint.Error.String() ||
+		location.ArtifactLocation.URI != "page.mustache" ||
+		location.Region.StartLine != 1 || location.Region.StartColumn != 10 || location.Region.EndColumn != 17 {
+		t.Errorf("unexpected result:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,34 @@
+package audit
+
+import (
+	"io"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/lint"
+)
+
+// File is the findings of one audited file.
+type File struct {
+	// Path is the file's path or URI, as it should appear in the report.
+	Path     string
+	Findings []lint.Diagnostic
+}
+
+// WriteSARIF writes files as a SARIF 2.1.0 log, with every audit rule
+// described; see lint.WriteSARIF.
+func WriteSARIF(w io.Writer, files []File) error {
+	return lint.WriteSARIF(w, lint.Tool{Name: "htmlmustache-audit", Rules: descriptions}, lintFiles(files))
+}
+
+// WriteJSONLines writes the findings of files as JSON Lines; see
+// lint.WriteJSONLines.
+func WriteJSONLines(w io.Writer, files []File) error {
+	return lint.WriteJSONLines(w, lintFiles(files))
+}
+
+func lintFiles(files []File) []lint.File {
+	out := make([]lint.File, len(files))
+	for i, file := range files {
+		out[i] = lint.File{Path: file.Path, Diagnostics: file.Findings}
+	}
+	return out
+}
@@ -0,0 +1,70 @@
+package tree_sitter_htmlmustache_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// benchmarkTemplates are the templates in testdata, each standing for a kind
+// of input whose parse cost is worth tracking.
+var benchmarkTemplates = []struct {
+	name, file string
+}{
+	{"Partial", "partial.mustache"},
+	{"Page", "page.mustache"},
+	{"Nested", "nested.mustache"},
+	{"Attributes", "attributes.mustache"},
+}
+
+func BenchmarkParse(b *testing.B) {
+	language := tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())
+	for _, template := range benchmarkTemplates {
+		b.Run(template.name, func(b *testing.B) {
+			src, err := os.ReadFile(filepath.Join("testdata", template.file))
+			if err != nil {
+				b.Fatal(err)
+			}
+			parser := tree_sitter.NewParser()
+			defer parser.Close()
+			if err := parser.SetLanguage(language); err != nil {
+				b.Fatal(err)
+			}
+
+			b.SetBytes(int64(len(src)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				tree := parser.Parse(src, nil)
+				if tree == nil {
+					b.Fatal("parse failed")
+				}
+				tree.Close()
+			}
+		})
+	}
+}
+
+// TestBenchmarkTemplates checks that the benchmark templates parse without
+// errors, so the benchmarks measure the grammar rather than error recovery.
+func TestBenchmarkTemplates(t *testing.T) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		t.Fatal(err)
+	}
+	for _, template := range benchmarkTemplates {
+		src, err := os.ReadFile(filepath.Join("testdata", template.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tree := parser.Parse(src, nil)
+		if tree.RootNode().HasError() {
+			t.Errorf("%s has syntax errors", template.file)
+		}
+		tree.Close()
+	}
+}
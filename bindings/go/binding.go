@@ -7,9 +7,73 @@ package tree_sitter_htmlmustache
 // #endif
 import "C"
 
-import "unsafe"
+import (
+	"fmt"
+	"unsafe"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	grammar "github.com/reteps/tree-sitter-htmlmustache"
+	"github.com/reteps/tree-sitter-htmlmustache/queries"
+)
 
 // Get the tree-sitter Language for this grammar.
 func Language() unsafe.Pointer {
 	return unsafe.Pointer(C.tree_sitter_htmlmustache())
 }
+
+// NodeTypes returns the content of src/node-types.json, which describes every
+// node kind with its fields, children and supertypes.
+func NodeTypes() []byte {
+	return grammar.NodeTypesSource
+}
+
+// LanguageVersion returns the grammar version the parser was generated from,
+// e.g. "0.4.1".
+func LanguageVersion() string {
+	metadata := tree_sitter.NewLanguage(Language()).Metadata()
+	if metadata == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d.%d.%d", metadata.MajorVersion, metadata.MinorVersion, metadata.PatchVersion)
+}
+
+// ABIVersion returns the tree-sitter ABI version of the generated parser.
+func ABIVersion() uint32 {
+	return tree_sitter.NewLanguage(Language()).AbiVersion()
+}
+
+// HighlightsQuery returns the content of queries/highlights.scm.
+func HighlightsQuery() []byte {
+	return queries.HighlightsSource
+}
+
+// InjectionsQuery returns the content of queries/injections.scm.
+func InjectionsQuery() []byte {
+	return queries.InjectionsSource
+}
+
+// LocalsQuery returns the content of queries/locals.scm.
+func LocalsQuery() []byte {
+	return queries.LocalsSource
+}
+
+// TagsQuery returns the content of queries/tags.scm.
+func TagsQuery() []byte {
+	return queries.TagsSource
+}
+
+// FoldsQuery returns the content of queries/folds.scm.
+func FoldsQuery() []byte {
+	return queries.FoldsSource
+}
+
+// IndentsQuery returns the content of queries/indents.scm.
+func IndentsQuery() []byte {
+	return queries.IndentsSource
+}
+
+// TextobjectsQuery returns the content of queries/textobjects.scm.
+func TextobjectsQuery() []byte {
+	return queries.TextobjectsSource
+}
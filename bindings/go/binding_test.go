@@ -1,10 +1,16 @@
 package tree_sitter_htmlmustache_test
 
 import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
-	tree_sitter "github.com/tree-sitter/go-tree-sitter"
 	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
 )
 
 func TestCanLoadGrammar(t *testing.T) {
@@ -13,3 +19,374 @@ func TestCanLoadGrammar(t *testing.T) {
 		t.Errorf("Error loading Htmlmustache grammar")
 	}
 }
+
+func TestMetadata(t *testing.T) {
+	var nodeTypes []struct {
+		Type  string `json:"type"`
+		Named bool   `json:"named"`
+	}
+	if err := json.Unmarshal(tree_sitter_htmlmustache.NodeTypes(), &nodeTypes); err != nil {
+		t.Fatalf("Error decoding node types: %v", err)
+	}
+	found := false
+	for _, nodeType := range nodeTypes {
+		found = found || nodeType.Type == "mustache_section" && nodeType.Named
+	}
+	if !found {
+		t.Error("node types do not include mustache_section")
+	}
+
+	if version := tree_sitter_htmlmustache.LanguageVersion(); !regexp.MustCompile(`^\d+\.\d+\.\d+$`).MatchString(version) {
+		t.Errorf("Unexpected language version %q", version)
+	}
+	if abi := tree_sitter_htmlmustache.ABIVersion(); abi < tree_sitter.MIN_COMPATIBLE_LANGUAGE_VERSION || abi > tree_sitter.LANGUAGE_VERSION {
+		t.Errorf("Unexpected ABI version %d", abi)
+	}
+}
+
+func TestHighlightsQuery(t *testing.T) {
+	language := tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())
+	query, err := tree_sitter.NewQuery(language, string(tree_sitter_htmlmustache.HighlightsQuery()))
+	if err != nil {
+		t.Fatalf("Error compiling highlights query: %v", err)
+	}
+	defer query.Close()
+}
+
+func TestInjectionsQuery(t *testing.T) {
+	language := tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())
+	query, err := tree_sitter.NewQuery(language, string(tree_sitter_htmlmustache.InjectionsQuery()))
+	if err != nil {
+		t.Fatalf("Error compiling injections query: %v", err)
+	}
+	defer query.Close()
+
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(language)
+
+	source := []byte("<script>let a = 1;</script><style>a { color: red; }</style><markdown># Title</markdown>")
+	tree := parser.Parse(source, nil)
+	defer tree.Close()
+
+	cursor := tree_sitter.NewQueryCursor()
+	defer cursor.Close()
+
+	var contents []string
+	matches := cursor.Matches(query, tree.RootNode(), source)
+	for match := matches.Next(); match != nil; match = matches.Next() {
+		for _, capture := range match.Captures {
+			if query.CaptureNames()[capture.Index] == "injection.content" {
+				contents = append(contents, capture.Node.Utf8Text(source))
+			}
+		}
+	}
+	if len(contents) != 3 || contents[0] != "let a = 1;" || contents[1] != "a { color: red; }" || contents[2] != "# Title" {
+		t.Errorf("Unexpected injection contents: %q", contents)
+	}
+}
+
+func TestFrontmatterInjections(t *testing.T) {
+	language := tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())
+	query, err := tree_sitter.NewQuery(language, string(tree_sitter_htmlmustache.InjectionsQuery()))
+	if err != nil {
+		t.Fatalf("Error compiling injections query: %v", err)
+	}
+	defer query.Close()
+
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(language)
+
+	for source, want := range map[string]string{
+		"---\ntitle: Hi\n---\n<p>{{title}}</p>": "yaml",
+		"+++\ntitle = \"Hi\"\n+++\n{{title}}":   "toml",
+	} {
+		tree := parser.Parse([]byte(source), nil)
+		cursor := tree_sitter.NewQueryCursor()
+		var languages []string
+		matches := cursor.Matches(query, tree.RootNode(), []byte(source))
+		for match := matches.Next(); match != nil; match = matches.Next() {
+			for _, property := range query.PropertySettings(match.PatternIndex) {
+				if property.Key == "injection.language" && property.Value != nil {
+					languages = append(languages, *property.Value)
+				}
+			}
+		}
+		cursor.Close()
+		tree.Close()
+		if len(languages) != 1 || languages[0] != want {
+			t.Errorf("%q: got injection languages %q, want %q", source, languages, want)
+		}
+	}
+}
+
+func TestLocalsQuery(t *testing.T) {
+	language := tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())
+	query, err := tree_sitter.NewQuery(language, string(tree_sitter_htmlmustache.LocalsQuery()))
+	if err != nil {
+		t.Fatalf("Error compiling locals query: %v", err)
+	}
+	defer query.Close()
+
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(language)
+
+	source := []byte("{{#items}}{{name}}{{user.email}}{{/items}}{{^items}}{{{empty}}}{{/items}}")
+	tree := parser.Parse(source, nil)
+	defer tree.Close()
+
+	cursor := tree_sitter.NewQueryCursor()
+	defer cursor.Close()
+
+	var captures []string
+	matches := cursor.Captures(query, tree.RootNode(), source)
+	for match, index := matches.Next(); match != nil; match, index = matches.Next() {
+		capture := match.Captures[index]
+		captures = append(captures, query.CaptureNames()[capture.Index]+" "+capture.Node.Utf8Text(source))
+	}
+	expected := []string{
+		"local.scope {{#items}}{{name}}{{user.email}}{{/items}}",
+		"local.definition items",
+		"local.reference name",
+		"local.reference user",
+		"local.reference items",
+		"local.reference empty",
+	}
+	if len(captures) != len(expected) {
+		t.Fatalf("Unexpected locals captures: %q", captures)
+	}
+	for i := range expected {
+		if captures[i] != expected[i] {
+			t.Errorf("Unexpected locals captures: %q", captures)
+			break
+		}
+	}
+}
+
+func TestFoldsQuery(t *testing.T) {
+	language := tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())
+	query, err := tree_sitter.NewQuery(language, string(tree_sitter_htmlmustache.FoldsQuery()))
+	if err != nil {
+		t.Fatalf("Error compiling folds query: %v", err)
+	}
+	defer query.Close()
+
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(language)
+
+	source := []byte("<div>\n{{#items}}\n<p>{{name}}</p>\n{{/items}}\n</div>\n{{! note }}")
+	tree := parser.Parse(source, nil)
+	defer tree.Close()
+
+	cursor := tree_sitter.NewQueryCursor()
+	defer cursor.Close()
+
+	var kinds []string
+	captures := cursor.Captures(query, tree.RootNode(), source)
+	for match, index := captures.Next(); match != nil; match, index = captures.Next() {
+		kinds = append(kinds, match.Captures[index].Node.Kind())
+	}
+	expected := []string{"html_element", "mustache_section", "html_element", "mustache_comment"}
+	if len(kinds) != len(expected) {
+		t.Fatalf("Unexpected folds: %v", kinds)
+	}
+	for i := range expected {
+		if kinds[i] != expected[i] {
+			t.Errorf("Unexpected folds: %v", kinds)
+		}
+	}
+}
+
+func TestIndentsQuery(t *testing.T) {
+	language := tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())
+	query, err := tree_sitter.NewQuery(language, string(tree_sitter_htmlmustache.IndentsQuery()))
+	if err != nil {
+		t.Fatalf("Error compiling indents query: %v", err)
+	}
+	defer query.Close()
+
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(language)
+
+	source := []byte("<div><br>{{#a}}x{{/a}}</div>")
+	tree := parser.Parse(source, nil)
+	defer tree.Close()
+
+	cursor := tree_sitter.NewQueryCursor()
+	defer cursor.Close()
+
+	var begins []string
+	captures := cursor.Captures(query, tree.RootNode(), source)
+	for match, index := captures.Next(); match != nil; match, index = captures.Next() {
+		capture := match.Captures[index]
+		if query.CaptureNames()[capture.Index] == "indent.begin" {
+			begins = append(begins, capture.Node.Utf8Text(source))
+		}
+	}
+	if len(begins) != 2 || begins[0] != "<div><br>{{#a}}x{{/a}}</div>" || begins[1] != "{{#a}}x{{/a}}" {
+		t.Errorf("Unexpected indent.begin captures: %q", begins)
+	}
+}
+
+func TestTextobjectsQuery(t *testing.T) {
+	language := tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())
+	query, err := tree_sitter.NewQuery(language, string(tree_sitter_htmlmustache.TextobjectsQuery()))
+	if err != nil {
+		t.Fatalf("Error compiling textobjects query: %v", err)
+	}
+	defer query.Close()
+
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(language)
+
+	source := []byte(`{{#items}}<a href="x{{y}}">{{name}}</a>{{/items}}`)
+	tree := parser.Parse(source, nil)
+	defer tree.Close()
+
+	cursor := tree_sitter.NewQueryCursor()
+	defer cursor.Close()
+
+	captures := map[string][]string{}
+	matches := cursor.Matches(query, tree.RootNode(), source)
+	for match := matches.Next(); match != nil; match = matches.Next() {
+		for _, capture := range match.Captures {
+			name := query.CaptureNames()[capture.Index]
+			captures[name] = append(captures[name], capture.Node.Utf8Text(source))
+		}
+	}
+	expected := map[string]string{
+		"block.outer":     `{{#items}}<a href="x{{y}}">{{name}}</a>{{/items}}`,
+		"block.inner":     `<a href="x{{y}}">{{name}}</a>`,
+		"tag.outer":       `<a href="x{{y}}">{{name}}</a>`,
+		"tag.inner":       `{{name}}`,
+		"attribute.outer": `href="x{{y}}"`,
+	}
+	for name, text := range expected {
+		if len(captures[name]) != 1 || captures[name][0] != text {
+			t.Errorf("Unexpected %s captures: %q", name, captures[name])
+		}
+	}
+}
+
+func TestTagsQuery(t *testing.T) {
+	language := tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())
+	query, err := tree_sitter.NewQuery(language, string(tree_sitter_htmlmustache.TagsQuery()))
+	if err != nil {
+		t.Fatalf("Error compiling tags query: %v", err)
+	}
+	defer query.Close()
+}
+
+// The generated parser is compiled into every Go binary using the grammar,
+// so its size is kept in check. A grammar change that needs more than this
+// should be reworked first, e.g. by moving context-sensitive tokens into the
+// external scanner or by sharing hidden rules between the contexts a tag
+// appears in, and only then the budget raised.
+const (
+	maxParseStates  = 900
+	maxParserSource = 1400 << 10
+)
+
+func TestParserSize(t *testing.T) {
+	language := tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())
+	if states := language.ParseStateCount(); states > maxParseStates {
+		t.Errorf("parser has %d states, over the budget of %d", states, maxParseStates)
+	}
+	info, err := os.Stat("../../src/parser.c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() > maxParserSource {
+		t.Errorf("src/parser.c is %d bytes, over the budget of %d", info.Size(), maxParserSource)
+	}
+}
+
+// readmeSyntaxRE matches the example in a row of the README's table of
+// supported Mustache syntax.
+var readmeSyntaxRE = regexp.MustCompile("(?m)^\\| `([^`]*\\{\\{[^`]*)`")
+
+// TestReadmeSyntax checks that the examples in the README's table of
+// supported Mustache syntax parse without errors.
+func TestReadmeSyntax(t *testing.T) {
+	readme, err := os.ReadFile("../../README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, table, _ := bytes.Cut(readme, []byte("### Supported Mustache Syntax"))
+	table, _, _ = bytes.Cut(table, []byte("\n#"))
+	examples := readmeSyntaxRE.FindAllSubmatch(table, -1)
+	if len(examples) == 0 {
+		t.Fatal("no syntax table in README.md")
+	}
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		t.Fatal(err)
+	}
+	for _, example := range examples {
+		tree := parser.Parse(example[1], nil)
+		if root := tree.RootNode(); root.HasError() {
+			t.Errorf("%s parses with errors: %s", example[1], root.ToSexp())
+		}
+		tree.Close()
+	}
+}
+
+// nvimCaptures are the nvim-treesitter capture names the queries in
+// nvim/queries/htmlmustache use, as listed in its CONTRIBUTING.md.
+var nvimCaptures = map[string]bool{
+	"tag": true, "tag.attribute": true, "tag.delimiter": true, "keyword.directive": true,
+	"string": true, "character.special": true, "comment": true, "spell": true,
+	"operator": true, "variable": true, "variable.builtin": true, "variable.parameter": true,
+	"property": true, "module": true, "function.call": true, "keyword": true,
+	"keyword.conditional": true, "punctuation.delimiter": true, "punctuation.bracket": true,
+	"punctuation.special": true, "injection.content": true, "fold": true,
+	"local.scope": true, "local.definition": true, "local.reference": true,
+	"indent.begin": true, "indent.branch": true, "indent.end": true,
+	"indent.ignore": true, "indent.auto": true,
+}
+
+// TestNvimQueries checks that the nvim-treesitter queries compile, use only
+// nvim-treesitter capture names, and that those shared with queries/ are
+// copies of them.
+func TestNvimQueries(t *testing.T) {
+	language := tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())
+	files, err := filepath.Glob("../../nvim/queries/htmlmustache/*.scm")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no nvim queries: %v", err)
+	}
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := filepath.Base(file)
+		query, qerr := tree_sitter.NewQuery(language, string(source))
+		if qerr != nil {
+			t.Errorf("Error compiling %s: %v", name, qerr)
+			continue
+		}
+		for _, capture := range query.CaptureNames() {
+			if !strings.HasPrefix(capture, "_") && !nvimCaptures[capture] {
+				t.Errorf("%s: @%s is not an nvim-treesitter capture", name, capture)
+			}
+		}
+		query.Close()
+
+		switch name {
+		case "folds.scm", "indents.scm", "locals.scm":
+			shared, err := os.ReadFile(filepath.Join("../../queries", name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(source, shared) {
+				t.Errorf("nvim/queries/htmlmustache/%s differs from queries/%s", name, name)
+			}
+		}
+	}
+}
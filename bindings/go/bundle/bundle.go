@@ -0,0 +1,199 @@
+// Package bundle compiles an htmlmustache entry template and the partials
+// it includes into a single artifact for deployment. A bundle records the
+// content hash of every template it was built from, so that its own hash
+// changes exactly when one of them does, for cache-busting, and so that the
+// bundles a changed file affects can be found without rebuilding them all.
+//
+// A bundle either carries each template's source, for renderers that load
+// partials by name, or the entry template with its partials flattened into
+// it by expand.Inline. Building the same templates gives the same bytes.
+package bundle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"sort"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/expand"
+)
+
+// Template is one of the templates a bundle is built from.
+type Template struct {
+	Name string `json:"name"`
+	// Hash is the hex SHA-256 of the template's source.
+	Hash string `json:"hash"`
+	// Partials are the partials the template includes, in order of first
+	// appearance.
+	Partials []string `json:"partials,omitempty"`
+	// Source is the template's source. It is left out of flattened
+	// bundles, whose Flattened holds the sources instead.
+	Source string `json:"source,omitempty"`
+}
+
+// Bundle is a compiled entry template.
+type Bundle struct {
+	// Entry is the name of the template the bundle was built from.
+	Entry string `json:"entry"`
+	// Hash is the hex SHA-256 of the bundle's compact JSON encoding with
+	// Hash left empty. It changes whenever a template in the bundle, or how
+	// they are bundled, does.
+	Hash string `json:"hash"`
+	// Templates are the entry and every partial it includes directly or
+	// transitively, sorted by name.
+	Templates []Template `json:"templates"`
+	// Flattened is the entry with its partials inlined, if the bundle was
+	// built with Flatten.
+	Flattened string `json:"flattened,omitempty"`
+}
+
+// Option configures Build.
+type Option func(*options)
+
+type options struct {
+	flatten  bool
+	maxDepth int
+}
+
+// Flatten builds bundles whose Flattened is the entry with every partial
+// inlined, nesting at most maxDepth deep, instead of bundles carrying each
+// template's source. Templates with recursive partials cannot be flattened.
+func Flatten(maxDepth int) Option {
+	return func(o *options) { o.flatten, o.maxDepth = true, maxDepth }
+}
+
+// Build loads the template called entry and the partials it includes
+// through resolver and bundles them. Each template is loaded once. A
+// template that resolver fails to load is an error.
+func Build(entry string, resolver analysis.Resolver, opts ...Option) (*Bundle, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	sources := map[string][]byte{}
+	cached := func(name string) ([]byte, error) {
+		if src, ok := sources[name]; ok {
+			return src, nil
+		}
+		src, err := resolver(name)
+		if err != nil {
+			return nil, err
+		}
+		sources[name] = src
+		return src, nil
+	}
+	graph, err := analysis.ResolvePartials(entry, cached)
+	if err != nil {
+		return nil, fmt.Errorf("bundle: %w", err)
+	}
+
+	b := &Bundle{Entry: entry}
+	for _, name := range graph.Templates() {
+		src := sources[name]
+		t := Template{Name: name, Hash: hash(src)}
+		if partials := graph.Edges[name]; len(partials) > 0 {
+			t.Partials = partials
+		}
+		if !o.flatten {
+			t.Source = string(src)
+		}
+		b.Templates = append(b.Templates, t)
+	}
+	if o.flatten {
+		flattened, err := expand.Inline(entry, cached, o.maxDepth)
+		if err != nil {
+			return nil, fmt.Errorf("bundle: flattening %q: %w", entry, err)
+		}
+		b.Flattened = string(flattened)
+	}
+	encoded, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	b.Hash = hash(encoded)
+	return b, nil
+}
+
+func hash(src []byte) string {
+	sum := sha256.Sum256(src)
+	return hex.EncodeToString(sum[:])
+}
+
+// Encode writes the bundle to w as indented JSON. The same bundle always
+// encodes to the same bytes.
+func (b *Bundle) Encode(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(b)
+}
+
+// Decode reads a bundle written by Encode.
+func Decode(r io.Reader) (*Bundle, error) {
+	var b Bundle
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, fmt.Errorf("bundle: %w", err)
+	}
+	return &b, nil
+}
+
+// Template returns the template called name, if the bundle has it.
+func (b *Bundle) Template(name string) (Template, bool) {
+	i, found := sort.Find(len(b.Templates), func(i int) int {
+		switch {
+		case name < b.Templates[i].Name:
+			return -1
+		case name > b.Templates[i].Name:
+			return 1
+		}
+		return 0
+	})
+	if !found {
+		return Template{}, false
+	}
+	return b.Templates[i], true
+}
+
+// Changed returns the names of the bundle's templates whose source, as
+// loaded through resolver now, differs from the one the bundle was built
+// from, sorted. A template resolver cannot find has changed; other errors
+// are returned. Partials a changed template newly includes are not loaded,
+// as the template is reported already.
+func (b *Bundle) Changed(resolver analysis.Resolver) ([]string, error) {
+	var changed []string
+	for _, t := range b.Templates {
+		src, err := resolver(t.Name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				changed = append(changed, t.Name)
+				continue
+			}
+			return nil, fmt.Errorf("bundle: resolving %q: %w", t.Name, err)
+		}
+		if hash(src) != t.Hash {
+			changed = append(changed, t.Name)
+		}
+	}
+	return changed, nil
+}
+
+// Affected returns the bundles built from any of the templates called names,
+// in the order of bundles. Those are the bundles to rebuild when the
+// templates change.
+func Affected(bundles []*Bundle, names ...string) []*Bundle {
+	var affected []*Bundle
+	for _, b := range bundles {
+		if slices.ContainsFunc(names, func(name string) bool {
+			_, ok := b.Template(name)
+			return ok
+		}) {
+			affected = append(affected, b)
+		}
+	}
+	return affected
+}
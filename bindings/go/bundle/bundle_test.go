@@ -0,0 +1,186 @@
+package bundle_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/bundle"
+)
+
+func resolver(templates map[string]string) func(string) ([]byte, error) {
+	return func(name string) ([]byte, error) {
+		src, ok := templates[name]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return []byte(src), nil
+	}
+}
+
+var templates = map[string]string{
+	"page":   "<main>{{> header}}{{> list}}</main>",
+	"header": "<h1>{{title}}</h1>",
+	"list":   "<ul>{{#items}}{{> item}}{{/items}}</ul>",
+	"item":   "<li>{{name}}</li>",
+	"other":  "<p>{{> item}}</p>",
+	"post":   "{{<layout}}{{$main}}{{> item}}{{/main}}{{/layout}}",
+	"layout": "<main>{{$main}}{{/main}}</main>",
+}
+
+func TestBuild(t *testing.T) {
+	b, err := bundle.Build("page", resolver(templates))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tmpl := range b.Templates {
+		names = append(names, tmpl.Name)
+		if tmpl.Source != templates[tmpl.Name] {
+			t.Errorf("%s: Source = %q", tmpl.Name, tmpl.Source)
+		}
+	}
+	if want := []string{"header", "item", "list", "page"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("templates = %q, want %q", names, want)
+	}
+	page, ok := b.Template("page")
+	if !ok || !reflect.DeepEqual(page.Partials, []string{"header", "list"}) {
+		t.Errorf("Template(page) = %+v, %v", page, ok)
+	}
+	if _, ok := b.Template("other"); ok {
+		t.Error("Template(other) found a template not in the bundle")
+	}
+	if b.Flattened != "" {
+		t.Errorf("Flattened = %q without Flatten", b.Flattened)
+	}
+
+	again, err := bundle.Build("page", resolver(templates))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var first, second bytes.Buffer
+	if err := b.Encode(&first); err != nil {
+		t.Fatal(err)
+	}
+	if err := again.Encode(&second); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Error("building the same templates twice encoded differently")
+	}
+	decoded, err := bundle.Decode(&first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, b) {
+		t.Errorf("Decode() = %+v, want %+v", decoded, b)
+	}
+
+	edited := map[string]string{}
+	for name, src := range templates {
+		edited[name] = src
+	}
+	edited["item"] = "<li>{{label}}</li>"
+	changed, err := bundle.Build("page", resolver(edited))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed.Hash == b.Hash {
+		t.Error("editing a partial left the bundle hash unchanged")
+	}
+}
+
+func TestBuildFlatten(t *testing.T) {
+	b, err := bundle.Build("page", resolver(templates), bundle.Flatten(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "<main><h1>{{title}}</h1><ul>{{#items}}<li>{{name}}</li>{{/items}}</ul></main>"; b.Flattened != want {
+		t.Errorf("Flattened = %q, want %q", b.Flattened, want)
+	}
+	for _, tmpl := range b.Templates {
+		if tmpl.Source != "" || tmpl.Hash == "" {
+			t.Errorf("%s: Source = %q, Hash = %q", tmpl.Name, tmpl.Source, tmpl.Hash)
+		}
+	}
+	manifest, err := bundle.Build("page", resolver(templates))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Hash == b.Hash {
+		t.Error("flattened and manifest bundles have the same hash")
+	}
+
+	recursive := map[string]string{"tree": "{{#children}}{{> tree}}{{/children}}"}
+	if _, err := bundle.Build("tree", resolver(recursive), bundle.Flatten(10)); err == nil {
+		t.Error("flattening a recursive partial succeeded")
+	}
+	if _, err := bundle.Build("tree", resolver(recursive)); err != nil {
+		t.Errorf("bundling a recursive partial: %v", err)
+	}
+}
+
+func TestBuildParents(t *testing.T) {
+	b, err := bundle.Build("post", resolver(templates))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tmpl := range b.Templates {
+		names = append(names, tmpl.Name)
+	}
+	if want := []string{"item", "layout", "post"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("templates = %q, want %q", names, want)
+	}
+	if affected := bundle.Affected([]*bundle.Bundle{b}, "layout"); len(affected) != 1 {
+		t.Errorf("Affected(layout) = %+v", affected)
+	}
+}
+
+func TestBuildMissingPartial(t *testing.T) {
+	_, err := bundle.Build("main", resolver(map[string]string{"main": "{{> missing}}"}))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Build() error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestChangedAndAffected(t *testing.T) {
+	var bundles []*bundle.Bundle
+	for _, entry := range []string{"page", "other", "header"} {
+		b, err := bundle.Build(entry, resolver(templates))
+		if err != nil {
+			t.Fatal(err)
+		}
+		bundles = append(bundles, b)
+	}
+
+	edited := map[string]string{}
+	for name, src := range templates {
+		edited[name] = src
+	}
+	edited["item"] = "<li>{{label}}</li>"
+	delete(edited, "header")
+	changed, err := bundles[0].Changed(resolver(edited))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"header", "item"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("Changed() = %q, want %q", changed, want)
+	}
+	if changed, err := bundles[0].Changed(resolver(templates)); err != nil || changed != nil {
+		t.Errorf("Changed() of unchanged templates = %q, %v", changed, err)
+	}
+
+	var entries []string
+	for _, b := range bundle.Affected(bundles, "item") {
+		entries = append(entries, b.Entry)
+	}
+	if want := []string{"page", "other"}; !reflect.DeepEqual(entries, want) {
+		t.Errorf("Affected(item) = %q, want %q", entries, want)
+	}
+	if affected := bundle.Affected(bundles, "unused"); affected != nil {
+		t.Errorf("Affected(unused) = %+v", affected)
+	}
+}
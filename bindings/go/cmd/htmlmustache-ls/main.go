@@ -0,0 +1,17 @@
+// Command htmlmustache-ls is a language server for HTML Mustache templates.
+// It speaks the Language Server Protocol over stdin and stdout.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/lsp"
+)
+
+func main() {
+	if err := lsp.NewServer(os.Stdin, os.Stdout).Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
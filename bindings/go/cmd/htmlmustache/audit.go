@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/audit"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/lint"
+)
+
+const auditUsage = `Usage: htmlmustache audit [options] [files...]
+
+Report interpolations in contexts where HTML escaping does not make data
+safe. With no files, reads stdin. Exits 1 if any error-level finding is
+reported.
+
+Options:`
+
+func runAudit(args []string) int {
+	flags := flag.NewFlagSet("audit", flag.ContinueOnError)
+	sarif := flags.Bool("sarif", false, "write findings as a SARIF log to stdout")
+	jsonLines := flags.Bool("json", false, "write findings as JSON Lines to stdout")
+	unused := flags.Bool("report-unused-directives", false, "warn about htmlmustache-disable comments that disable nothing")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), auditUsage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+	if *sarif && *jsonLines {
+		fmt.Fprintln(os.Stderr, "audit: -sarif and -json are mutually exclusive")
+		return 1
+	}
+
+	rules := audit.Rules()
+	if *unused {
+		rules = append(rules, lint.UnusedDirectives())
+	}
+	inputs, status := readInputs(flags.Args())
+	var files []audit.File
+	for _, in := range inputs {
+		findings, err := lint.Lint(in.src, rules)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", in.path, err)
+			status = 1
+			continue
+		}
+		for _, f := range findings {
+			if f.Severity == lint.Error {
+				status = 1
+			}
+			if !*sarif && !*jsonLines {
+				fmt.Printf("%s:%d:%d: %s: %s [%s]\n", in.path, f.StartPoint.Row+1, f.StartPoint.Column+1, f.Severity, f.Message, f.Rule)
+			}
+		}
+		files = append(files, audit.File{Path: in.path, Findings: findings})
+	}
+	var err error
+	switch {
+	case *sarif:
+		err = audit.WriteSARIF(os.Stdout, files)
+	case *jsonLines:
+		err = audit.WriteJSONLines(os.Stdout, files)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return status
+}
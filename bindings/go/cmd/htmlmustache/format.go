@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/format"
+)
+
+const formatUsage = `Usage: htmlmustache format [options] [files...]
+
+Format HTML Mustache templates. With no files, reads stdin and writes stdout.
+With -watch and -write or -check, handles the files again each time they
+change, until interrupted.
+
+Files are formatted with the options of the .htmlmustache.toml file in
+their directory or above, and the indent settings of their .editorconfig
+files. Options given as flags override both; stdin uses only the flags.
+
+Options:`
+
+func runFormat(args []string) int {
+	flags := flag.NewFlagSet("format", flag.ContinueOnError)
+	write := flags.Bool("write", false, "modify files in-place (default: print to stdout)")
+	check := flags.Bool("check", false, "exit 1 if any files would change (for CI)")
+	watchFiles := flags.Bool("watch", false, "with -write or -check, handle the files again whenever they change")
+	var opts format.Options
+	flags.IntVar(&opts.IndentSize, "indent-size", 2, "spaces per indent level")
+	flags.BoolVar(&opts.UseTabs, "use-tabs", false, "indent with tabs")
+	flags.IntVar(&opts.PrintWidth, "print-width", 80, "max line width")
+	flags.IntVar(&opts.AttributeWrap, "attribute-wrap", 0, "put each attribute on its own line in block tags with more attributes than this (0: never)")
+	flags.TextVar(&opts.Quotes, "quotes", format.DoubleQuotes, "attribute value quotes: double, single or preserve")
+	flags.BoolVar(&opts.SortAttributes, "sort-attributes", false, "sort attributes by name")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), formatUsage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+	if *watchFiles && (flags.NArg() == 0 || !*write && !*check) {
+		fmt.Fprintln(os.Stderr, "format: -watch needs files and -write or -check")
+		return 1
+	}
+
+	if flags.NArg() == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		out, err := format.Format(src, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "<stdin>: %v\n", err)
+			return 1
+		}
+		if *check {
+			if !bytes.Equal(src, out) {
+				return 1
+			}
+			return 0
+		}
+		os.Stdout.Write(out)
+		return 0
+	}
+
+	// optionsFor returns the options for the file at path: those of its
+	// configuration files, overridden by the flags given.
+	optionsFor := func(path string) (format.Options, error) {
+		fileOpts, err := format.OptionsFor(path)
+		flags.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "indent-size":
+				fileOpts.IndentSize = opts.IndentSize
+			case "use-tabs":
+				fileOpts.UseTabs = opts.UseTabs
+			case "print-width":
+				fileOpts.PrintWidth = opts.PrintWidth
+			case "attribute-wrap":
+				fileOpts.AttributeWrap = opts.AttributeWrap
+			case "quotes":
+				fileOpts.Quotes = opts.Quotes
+			case "sort-attributes":
+				fileOpts.SortAttributes = opts.SortAttributes
+			}
+		})
+		return fileOpts, err
+	}
+
+	status := 0
+	for _, path := range flags.Args() {
+		if !formatFile(path, optionsFor, *write, *check, false) {
+			status = 1
+		}
+	}
+	if *watchFiles {
+		err := watch(flags.Args(), func(path string) {
+			formatFile(path, optionsFor, *write, *check, true)
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return status
+}
+
+// formatFile formats the file at path with the options optionsFor returns
+// for it: with check, printing its path if it is not formatted; with write,
+// rewriting it; and otherwise printing the result. With verbose, it also
+// prints the path of each file write rewrites. It reports whether the file
+// was read and, with check, formatted.
+func formatFile(path string, optionsFor func(path string) (format.Options, error), write, check, verbose bool) bool {
+	opts, err := optionsFor(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return false
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return false
+	}
+	out, err := format.Format(src, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return false
+	}
+	switch {
+	case check:
+		if !bytes.Equal(src, out) {
+			fmt.Println(path)
+			return false
+		}
+	case write:
+		// Unchanged files are not rewritten, so watching a file this writes
+		// settles after one more pass.
+		if !bytes.Equal(src, out) {
+			if err := os.WriteFile(path, out, 0o644); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return false
+			}
+			if verbose {
+				fmt.Printf("formatted %s\n", path)
+			}
+		}
+	default:
+		os.Stdout.Write(out)
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/highlight"
+)
+
+const highlightUsage = `Usage: htmlmustache highlight [options] [files...]
+
+Print templates with syntax highlighting from highlights.scm, as ANSI
+escapes for a terminal or as HTML. With no files, reads stdin.
+
+Options:`
+
+func runHighlight(args []string) int {
+	flags := flag.NewFlagSet("highlight", flag.ContinueOnError)
+	format := flags.String("format", "ansi", "output format: ansi or html")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), highlightUsage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+	var write func(io.Writer, []byte, []highlight.Span)
+	switch *format {
+	case "ansi":
+		write = func(w io.Writer, src []byte, spans []highlight.Span) { highlight.WriteANSI(w, src, spans, nil) }
+	case "html":
+		write = func(w io.Writer, src []byte, spans []highlight.Span) { highlight.WriteHTML(w, src, spans, nil) }
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		return 1
+	}
+
+	inputs, status := readInputs(flags.Args())
+	for _, in := range inputs {
+		tree, err := parse(in.src)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", in.path, err)
+			status = 1
+			continue
+		}
+		write(os.Stdout, in.src, highlight.Spans(tree.RootNode(), in.src))
+		tree.Close()
+	}
+	return status
+}
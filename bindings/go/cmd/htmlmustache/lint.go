@@ -0,0 +1,160 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/htmlmustache"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/lint"
+)
+
+const lintUsage = `Usage: htmlmustache lint [options] [files...]
+
+Check templates with the default lint rules. With no files, reads stdin.
+Exits 1 if anything is reported. With -watch, checks the files again each
+time they change, until interrupted.
+
+Options:`
+
+func runLint(args []string) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	partials := flags.String("partials", "", "directory to resolve partials in; enables the undefinedPartials rule")
+	ext := flags.String("partial-ext", ".mustache", "extension appended to partial names")
+	a11y := flags.Bool("a11y", false, "also run the accessibility rules")
+	email := flags.Bool("email", false, "also run the rules for HTML email")
+	strict := flags.Bool("strict", false, "also report HTML the parser recovers from silently, such as left out end tags")
+	sarif := flags.Bool("sarif", false, "write diagnostics as a SARIF log to stdout")
+	jsonLines := flags.Bool("json", false, "write diagnostics as JSON Lines to stdout")
+	watchFiles := flags.Bool("watch", false, "check the files again whenever they change")
+	unused := flags.Bool("report-unused-directives", false, "warn about htmlmustache-disable comments that disable nothing")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), lintUsage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+	if *sarif && *jsonLines {
+		fmt.Fprintln(os.Stderr, "lint: -sarif and -json are mutually exclusive")
+		return 1
+	}
+	if *watchFiles && (*sarif || flags.NArg() == 0) {
+		fmt.Fprintln(os.Stderr, "lint: -watch needs files and cannot be used with -sarif")
+		return 1
+	}
+
+	rules := lint.DefaultRules()
+	if *a11y {
+		rules = append(rules, lint.AccessibilityRules()...)
+	}
+	if *email {
+		rules = append(rules, lint.EmailRules()...)
+	}
+	if *strict {
+		rules = append(rules, lint.StrictRules()...)
+	}
+	if *unused {
+		rules = append(rules, lint.UnusedDirectives())
+	}
+	if *partials != "" {
+		dir := *partials
+		rules = append(rules, lint.UndefinedPartials(func(name string) ([]byte, error) {
+			if strings.Contains(name, "..") {
+				return nil, os.ErrNotExist
+			}
+			return os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)+*ext))
+		}))
+	}
+
+	if *watchFiles {
+		return watchLint(flags.Args(), rules, *jsonLines)
+	}
+
+	inputs, status := readInputs(flags.Args())
+	var files []lint.File
+	for _, in := range inputs {
+		found, err := lint.Lint(in.src, rules)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", in.path, err)
+			status = 1
+			continue
+		}
+		if len(found) > 0 {
+			status = 1
+		}
+		if !*sarif && !*jsonLines {
+			printDiagnostics(in.path, found)
+		}
+		files = append(files, lint.File{Path: in.path, Diagnostics: found})
+	}
+
+	var err error
+	switch {
+	case *sarif:
+		tool := lint.Tool{Name: "htmlmustache-lint", Rules: map[string]string{}}
+		for _, rule := range rules {
+			tool.Rules[rule.Name()] = ""
+		}
+		err = lint.WriteSARIF(os.Stdout, tool, files)
+	case *jsonLines:
+		err = lint.WriteJSONLines(os.Stdout, files)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return status
+}
+
+// watchLint checks paths, then checks each again when it changes. Files are
+// kept parsed between changes and re-parsed incrementally, so a small edit
+// to a large template only re-parses around the edit. Each check prints the
+// file's diagnostics, or that it has none.
+func watchLint(paths []string, rules []lint.Rule, jsonLines bool) int {
+	docs := map[string]*htmlmustache.Document{}
+	check := func(path string) {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+		doc, err := reparse(docs, path, src)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			return
+		}
+		found := lint.LintTree(doc.Root(), doc.Source(), rules)
+		switch {
+		case jsonLines:
+			err = lint.WriteJSONLines(os.Stdout, []lint.File{{Path: path, Diagnostics: found}})
+		case len(found) == 0:
+			fmt.Printf("%s: ok\n", path)
+		default:
+			printDiagnostics(path, found)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+	for _, path := range paths {
+		check(path)
+	}
+	if err := watch(paths, check); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// printDiagnostics prints the diagnostics of the file at path, one per line.
+func printDiagnostics(path string, diagnostics []lint.Diagnostic) {
+	for _, d := range diagnostics {
+		fmt.Printf("%s:%d:%d: %s: %s [%s]\n", path, d.StartPoint.Row+1, d.StartPoint.Column+1, d.Severity, d.Message, d.Rule)
+	}
+}
@@ -0,0 +1,105 @@
+// Command htmlmustache works with HTML Mustache templates using the Go
+// bindings. It mirrors the commands of the npm CLI.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+)
+
+const usage = `Usage: htmlmustache <command> [options]
+
+Commands:
+  parse      Print the syntax tree of templates
+  format     Format templates (alias: fmt)
+  lint       Check templates for mistakes
+  highlight  Print templates with syntax highlighting
+  audit      Report unsafe interpolation contexts
+  project    Report unused and undefined partials and variables
+  stats      Report the size and complexity of templates
+  serve      Preview templates in a browser with live reload
+
+Run 'htmlmustache <command> -help' for command-specific help.`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(1)
+	}
+
+	switch command := os.Args[1]; command {
+	case "parse":
+		os.Exit(runParse(os.Args[2:]))
+	case "format", "fmt":
+		os.Exit(runFormat(os.Args[2:]))
+	case "lint":
+		os.Exit(runLint(os.Args[2:]))
+	case "highlight":
+		os.Exit(runHighlight(os.Args[2:]))
+	case "audit":
+		os.Exit(runAudit(os.Args[2:]))
+	case "project":
+		os.Exit(runProject(os.Args[2:]))
+	case "stats":
+		os.Exit(runStats(os.Args[2:]))
+	case "serve":
+		os.Exit(runServe(os.Args[2:]))
+	case "-h", "-help", "--help":
+		fmt.Println(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", command)
+		fmt.Fprintln(os.Stderr, `Run "htmlmustache --help" for usage.`)
+		os.Exit(1)
+	}
+}
+
+// input is a template read from a file or stdin.
+type input struct {
+	path string
+	src  []byte
+}
+
+// readInputs reads the named files, or stdin if there are none. Files that
+// cannot be read are reported and make the returned status 1.
+func readInputs(paths []string) ([]input, int) {
+	if len(paths) == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return nil, 1
+		}
+		return []input{{"<stdin>", src}}, 0
+	}
+	var inputs []input
+	status := 0
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 1
+			continue
+		}
+		inputs = append(inputs, input{path, src})
+	}
+	return inputs, status
+}
+
+// parse parses src. The caller must close the tree.
+func parse(src []byte) (*tree_sitter.Tree, error) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		return nil, err
+	}
+	tree := parser.Parse(src, nil)
+	if tree == nil {
+		return nil, errors.New("parse failed")
+	}
+	return tree, nil
+}
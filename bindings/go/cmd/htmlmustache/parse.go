@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/export"
+)
+
+const parseUsage = `Usage: htmlmustache parse [options] [files...]
+
+Print the syntax tree of templates as an S-expression, as JSON with -json,
+or as a Graphviz graph with -dot. With no files, reads stdin. Exits 1 if any tree has errors.
+
+Options:`
+
+func runParse(args []string) int {
+	flags := flag.NewFlagSet("parse", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print the tree as JSON")
+	asDOT := flags.Bool("dot", false, "print the tree as a Graphviz DOT graph")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), parseUsage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+
+	inputs, status := readInputs(flags.Args())
+	for _, in := range inputs {
+		tree, err := parse(in.src)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", in.path, err)
+			status = 1
+			continue
+		}
+		root := tree.RootNode()
+		if root.HasError() {
+			status = 1
+		}
+		switch {
+		case *asJSON:
+			out, err := export.JSON(tree, in.src)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				tree.Close()
+				return 1
+			}
+			fmt.Println(string(out))
+		case *asDOT:
+			os.Stdout.Write(export.DOT(tree, in.src))
+		default:
+			fmt.Println(root.ToSexp())
+		}
+		tree.Close()
+	}
+	return status
+}
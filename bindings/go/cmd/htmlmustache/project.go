@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+)
+
+const projectUsage = `Usage: htmlmustache project [options] [dir]
+
+Check the templates under dir, or the current directory, as a whole:
+partials no template includes, includes of partials that do not exist and,
+with -schema, interpolations of names the data never provides. Prints the
+findings as a JSON array for CI annotations. Exits 1 if anything is
+reported.
+
+Options:`
+
+func runProject(args []string) int {
+	flags := flag.NewFlagSet("project", flag.ContinueOnError)
+	partials := flags.String("partials", "", "directory below dir that partial names resolve in; templates elsewhere are pages")
+	ext := flags.String("ext", ".mustache", "comma-separated template extensions, tried in order when resolving partials")
+	schemaPath := flags.String("schema", "", "JSON Schema file describing the data pages render with")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), projectUsage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return 1
+	}
+	dir := "."
+	if flags.NArg() == 1 {
+		dir = flags.Arg(0)
+	}
+
+	opts := analysis.ProjectOptions{Partials: *partials, Extensions: strings.Split(*ext, ",")}
+	if *schemaPath != "" {
+		raw, err := os.ReadFile(*schemaPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		opts.Schema = &analysis.Schema{}
+		if err := json.Unmarshal(raw, opts.Schema); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", *schemaPath, err)
+			return 1
+		}
+	}
+
+	findings, err := analysis.CheckProject(os.DirFS(dir), opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if findings == nil {
+		findings = []analysis.ProjectFinding{}
+	}
+	out, err := json.MarshalIndent(findings, "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Println(string(out))
+	if len(findings) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/lint"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/render"
+)
+
+const serveUsage = `Usage: htmlmustache serve [options] [dir]
+
+Serve the templates under dir, or the current directory, rendered with the
+sample data of -data. Each template is served at its path below dir, and /
+lists them; other files, such as stylesheets, are served as they are.
+Partials resolve below dir. Pages reload whenever a file under dir or the
+data changes, and show the template's syntax errors and lint diagnostics
+over the rendered page.
+
+Options:`
+
+// eventsPath is where pages listen for reloads. It starts with an
+// underscore directory so that it cannot be mistaken for a template.
+const eventsPath = "/_htmlmustache/events"
+
+func runServe(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	dataPath := flags.String("data", "", "JSON file with the data templates render with")
+	ext := flags.String("ext", ".mustache", "comma-separated template extensions, tried in order when resolving partials")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), serveUsage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return 1
+	}
+	dir := "."
+	if flags.NArg() == 1 {
+		dir = flags.Arg(0)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		fmt.Fprintf(os.Stderr, "serve: %s is not a directory\n", dir)
+		return 1
+	}
+
+	s := &server{dir: dir, exts: strings.Split(*ext, ","), dataPath: *dataPath, clients: map[chan struct{}]bool{}}
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	go func() {
+		if err := s.watch(); err != nil {
+			fmt.Fprintf(os.Stderr, "serve: live reload disabled: %v\n", err)
+		}
+	}()
+	fmt.Printf("Serving %s at http://%s/\n", dir, listener.Addr())
+	if err := http.Serve(listener, s); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// server serves the templates under dir.
+type server struct {
+	dir      string
+	exts     []string
+	dataPath string
+
+	mu sync.Mutex
+	// clients holds a channel per page listening for reloads.
+	clients map[chan struct{}]bool
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+	switch {
+	case r.URL.Path == eventsPath:
+		s.events(w, r)
+	case name == "":
+		s.index(w)
+	case s.isTemplate(name):
+		s.page(w, r, name)
+	default:
+		http.ServeFile(w, r, filepath.Join(s.dir, filepath.FromSlash(name)))
+	}
+}
+
+func (s *server) isTemplate(name string) bool {
+	return slices.Contains(s.exts, path.Ext(name))
+}
+
+var indexPage = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Dir}}</title></head>
+<body>
+<h1>{{.Dir}}</h1>
+{{if .Templates}}<ul>
+{{range .Templates}}<li><a href="/{{.}}">{{.}}</a></li>
+{{end}}</ul>{{else}}<p>No templates.</p>{{end}}
+</body>
+</html>
+`))
+
+// index lists the templates under the directory.
+func (s *server) index(w http.ResponseWriter) {
+	var templates []string
+	err := filepath.WalkDir(s.dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && p != s.dir && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(s.dir, p)
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && s.isTemplate(rel) {
+			templates = append(templates, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var b bytes.Buffer
+	indexPage.Execute(&b, struct {
+		Dir       string
+		Templates []string
+	}{s.dir, templates})
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(insertBeforeBodyEnd(b.Bytes(), []byte(reloadScript)))
+}
+
+// page renders the template called name, with the diagnostics and the
+// reload script added in front of its </body>.
+func (s *server) page(w http.ResponseWriter, r *http.Request, name string) {
+	src, err := os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(name)))
+	if errors.Is(err, fs.ErrNotExist) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var problems []string
+	rules := append(lint.DefaultRules(), lint.UndefinedPartials(s.partial))
+	diagnostics, err := lint.LintContext(r.Context(), src, rules)
+	if err != nil {
+		if r.Context().Err() == nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	for _, d := range diagnostics {
+		problems = append(problems, fmt.Sprintf("%s:%d:%d: %s: %s [%s]", name, d.StartPoint.Row+1, d.StartPoint.Column+1, d.Severity, d.Message, d.Rule))
+	}
+
+	var out []byte
+	data, err := s.data()
+	if err == nil {
+		out, err = render.RenderContext(r.Context(), src, data, render.WithPartials(s.partial))
+	}
+	if r.Context().Err() != nil {
+		return
+	}
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	var extra bytes.Buffer
+	if len(problems) > 0 {
+		diagnosticsOverlay.Execute(&extra, problems)
+	}
+	extra.WriteString(reloadScript)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(insertBeforeBodyEnd(out, extra.Bytes()))
+}
+
+// data reads the sample data, which is nil without -data.
+func (s *server) data() (any, error) {
+	if s.dataPath == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(s.dataPath)
+	if err != nil {
+		return nil, err
+	}
+	var data any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("%s: %v", s.dataPath, err)
+	}
+	return data, nil
+}
+
+// partial loads the partial called name from below the directory, trying
+// each template extension in turn.
+func (s *server) partial(name string) ([]byte, error) {
+	if strings.Contains(name, "..") {
+		return nil, os.ErrNotExist
+	}
+	for _, ext := range s.exts {
+		src, err := os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(name)+ext))
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return src, err
+		}
+	}
+	return nil, os.ErrNotExist
+}
+
+var diagnosticsOverlay = template.Must(template.New("diagnostics").Parse(`<div id="htmlmustache-diagnostics" style="position:fixed;left:0;right:0;bottom:0;z-index:2147483647;max-height:40vh;overflow:auto;margin:0;padding:8px 12px;background:#2b1111;color:#ffd7d7;font:12px/1.5 monospace;white-space:pre-wrap">
+{{- range .}}<div>{{.}}</div>{{end -}}
+</div>
+`))
+
+// reloadScript reloads the page when the server reports a change.
+const reloadScript = `<script>new EventSource("` + eventsPath + `").onmessage = function () { location.reload(); };</script>
+`
+
+// insertBeforeBodyEnd returns page with extra inserted before its last
+// </body>, or at its end if it has none.
+func insertBeforeBodyEnd(page, extra []byte) []byte {
+	i := bytes.LastIndex(bytes.ToLower(page), []byte("</body>"))
+	if i < 0 {
+		i = len(page)
+	}
+	out := make([]byte, 0, len(page)+len(extra))
+	out = append(out, page[:i]...)
+	out = append(out, extra...)
+	return append(out, page[i:]...)
+}
+
+// events streams a server-sent event to the page each time a file changes,
+// until the page goes away.
+func (s *server) events(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	changed := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.clients[changed] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, changed)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changed:
+			fmt.Fprint(w, "data: reload\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// reload tells every listening page to reload.
+func (s *server) reload() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for client := range s.clients {
+		select {
+		case client <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// watch calls reload once files under the directory, or the data, have
+// changed. Directories created later are watched too.
+func (s *server) watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	addTree := func(root string) error {
+		return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return err
+			}
+			if p != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return watcher.Add(p)
+		})
+	}
+	if err := addTree(s.dir); err != nil {
+		return err
+	}
+	dir, err := filepath.Abs(s.dir)
+	if err != nil {
+		return err
+	}
+	data := ""
+	if s.dataPath != "" {
+		if data, err = filepath.Abs(s.dataPath); err != nil {
+			return err
+		}
+		// Watch the data's directory, as watchers of files end when editors
+		// save by renaming over them.
+		if err := watcher.Add(filepath.Dir(data)); err != nil {
+			return err
+		}
+	}
+	// relevant reports whether a change to the file at p shows in pages:
+	// it is the data, or below the directory and not hidden, as editors'
+	// swap files are.
+	relevant := func(p string) bool {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return false
+		}
+		if abs == data {
+			return true
+		}
+		rel, err := filepath.Rel(dir, abs)
+		return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) &&
+			!strings.HasPrefix(filepath.Base(abs), ".")
+	}
+
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					addTree(event.Name)
+				}
+			}
+			if event.Has(fsnotify.Chmod) || !relevant(event.Name) {
+				continue
+			}
+			timer.Reset(debounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintln(os.Stderr, err)
+		case <-timer.C:
+			s.reload()
+		}
+	}
+}
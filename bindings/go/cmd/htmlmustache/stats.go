@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+)
+
+const statsUsage = `Usage: htmlmustache stats [options] [files...]
+
+Print the size and complexity of templates: elements, element and section
+nesting depth, distinct variables, partials included and bytes by region,
+per file and in total. With no files, reads stdin. With -max options, exits
+1 if a file exceeds a budget; 0 means no limit.
+
+Options:`
+
+// statsFile is a row of the -json output.
+type statsFile struct {
+	File string `json:"file"`
+	analysis.Report
+}
+
+func runStats(args []string) int {
+	flags := flag.NewFlagSet("stats", flag.ContinueOnError)
+	jsonOut := flags.Bool("json", false, "write the reports as a JSON array, with the total last")
+	budgets := []struct {
+		name  string
+		limit *int
+		value func(analysis.Report) int
+	}{
+		{"elements", flags.Int("max-elements", 0, "maximum elements per file"), func(r analysis.Report) int { return r.Elements }},
+		{"depth", flags.Int("max-depth", 0, "maximum element nesting depth"), func(r analysis.Report) int { return r.MaxDepth }},
+		{"section depth", flags.Int("max-section-depth", 0, "maximum section nesting depth"), func(r analysis.Report) int { return r.MaxSectionDepth }},
+		{"variables", flags.Int("max-variables", 0, "maximum distinct variables per file"), func(r analysis.Report) int { return len(r.Variables) }},
+		{"partials", flags.Int("max-partials", 0, "maximum distinct partials per file"), func(r analysis.Report) int { return len(r.Partials) }},
+		{"bytes", flags.Int("max-bytes", 0, "maximum size per file"), func(r analysis.Report) int { return r.Bytes.Total }},
+	}
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), statsUsage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+
+	inputs, status := readInputs(flags.Args())
+	var files []statsFile
+	total := analysis.Report{Variables: []string{}, Partials: []string{}}
+	for _, in := range inputs {
+		report, err := analysis.Stats(in.src)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", in.path, err)
+			status = 1
+			continue
+		}
+		for _, b := range budgets {
+			if v := b.value(report); *b.limit > 0 && v > *b.limit {
+				fmt.Fprintf(os.Stderr, "%s: %s %d exceeds the budget of %d\n", in.path, b.name, v, *b.limit)
+				status = 1
+			}
+		}
+		files = append(files, statsFile{in.path, report})
+		total.Add(report)
+	}
+
+	if *jsonOut {
+		out, err := json.MarshalIndent(append(files, statsFile{"total", total}), "", "  ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Println(string(out))
+		return status
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "file\telements\tdepth\tsections\tvariables\tpartials")
+	row := func(f statsFile) {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\n", f.File, f.Elements, f.MaxDepth, f.MaxSectionDepth, len(f.Variables), len(f.Partials))
+	}
+	for _, f := range files {
+		row(f)
+	}
+	if len(files) > 1 {
+		row(statsFile{"total", total})
+	}
+	w.Flush()
+	fmt.Println()
+	fmt.Fprintln(w, "file\tbytes\tmarkup\ttext\tmustache\tscript\tstyle\tcomment")
+	sizes := func(f statsFile) {
+		s := f.Bytes
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\n", f.File, s.Total, s.Markup, s.Text, s.Mustache, s.Script, s.Style, s.Comment)
+	}
+	for _, f := range files {
+		sizes(f)
+	}
+	if len(files) > 1 {
+		sizes(statsFile{"total", total})
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return status
+}
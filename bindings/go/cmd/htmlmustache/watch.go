@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/htmlmustache"
+)
+
+// debounce is how long watch waits after a change for more, since editors
+// often save a file in several writes.
+const debounce = 50 * time.Millisecond
+
+// watch calls changed with each of paths whose file is written or replaced,
+// until the process is interrupted. Changes that arrive together are
+// reported once per file, in the order of paths.
+func watch(paths []string, changed func(path string)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	// Watch the directories rather than the files: editors that save by
+	// renaming a new file over the old one would otherwise end the watch.
+	targets := map[string]string{}
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		targets[abs] = path
+	}
+	for abs := range targets {
+		if err := watcher.Add(filepath.Dir(abs)); err != nil {
+			return err
+		}
+	}
+
+	pending := map[string]bool{}
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			path, ok := targets[filepath.Clean(event.Name)]
+			if !ok || !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+			pending[path] = true
+			timer.Reset(debounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintln(os.Stderr, err)
+		case <-timer.C:
+			for _, path := range paths {
+				if pending[path] {
+					changed(path)
+				}
+			}
+			clear(pending)
+		}
+	}
+}
+
+// reparse returns the document in docs for path, updated to src. A path seen
+// for the first time is parsed in full; afterwards the document is re-parsed
+// incrementally from the one edit that turns its old source into src.
+func reparse(docs map[string]*htmlmustache.Document, path string, src []byte) (*htmlmustache.Document, error) {
+	doc, ok := docs[path]
+	if !ok {
+		doc, err := htmlmustache.Parse(context.Background(), src)
+		if err != nil {
+			return nil, err
+		}
+		docs[path] = doc
+		return doc, nil
+	}
+
+	old := doc.Source()
+	prefix := 0
+	for prefix < len(old) && prefix < len(src) && old[prefix] == src[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(src)-prefix && old[len(old)-1-suffix] == src[len(src)-1-suffix] {
+		suffix++
+	}
+	text := src[prefix : len(src)-suffix]
+	if _, err := doc.ApplyEdit(context.Background(), uint(prefix), uint(len(old)-suffix), uint(prefix+len(text)), text); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
@@ -0,0 +1,337 @@
+// Package convert rewrites htmlmustache templates as Go html/template
+// templates. HTML is copied verbatim; only mustache tags change:
+//
+//	{{name}}, {{a.b}}      {{.name}}, {{.a.b}}
+//	{{{name}}}             {{.name | raw}}
+//	{{#name}}...{{/name}}  {{range section .name}}...{{end}}
+//	{{^name}}...{{/name}}  {{if not (section .name)}}...{{end}}
+//	{{> name}}             {{template "name" .}}
+//	{{! text}}             {{/* text */}}
+//
+// and the Handlebars built-in block helpers become Go actions:
+//
+//	{{#if a}}, {{#unless a}}    {{if section .a}}, {{if not (section .a)}}
+//	{{#with a as |u|}}          {{with $u := .a}}
+//	{{#each a as |item i|}}     {{range $i, $item := section .a}}
+//	{{else}}, {{else if a}}     {{else}}, {{else if section .a}}
+//
+// Block params become variables, so {{item.name}} is {{$item.name}}. Other
+// helpers and dynamic partials, {{>*name}}, have no Go equivalent and are
+// errors.
+//
+// Converted templates need the functions from Funcs. Names that are not Go
+// identifiers are read with index. Standalone tags take their line with
+// them, as Mustache renders them.
+//
+// Mustache resolves a name inside a section up the context stack, while
+// html/template only sees the section's item as dot. Templates reading
+// outer names from inside sections need those names rewritten to $ or
+// passed down after conversion. Standalone partials keep their indentation
+// on their first line only, where Mustache indents every line.
+package convert
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// ErrSyntax is returned for templates whose parse tree contains errors.
+var ErrSyntax = errors.New("convert: template has syntax errors")
+
+// Funcs returns the functions converted templates call. Add them to a
+// template with Funcs before parsing it.
+//
+// section returns what a Mustache section iterates over: the elements of a
+// non-empty slice, the value itself if it is truthy, or nothing for nil,
+// false and empty slices. raw marks a string as safe HTML.
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"section": section,
+		"raw":     raw,
+	}
+}
+
+func section(value any) []any {
+	if value == nil {
+		return nil
+	}
+	if b, ok := value.(bool); ok {
+		if b {
+			return []any{value}
+		}
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		items := make([]any, v.Len())
+		for i := range items {
+			items[i] = v.Index(i).Interface()
+		}
+		return items
+	case reflect.Pointer, reflect.Map, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+	}
+	return []any{value}
+}
+
+func raw(value any) template.HTML {
+	if value == nil {
+		return ""
+	}
+	return template.HTML(fmt.Sprint(value))
+}
+
+// edit replaces src[start:end] with text.
+type edit struct {
+	start, end uint
+	text       string
+}
+
+// ToGoTemplate returns src rewritten as an html/template template.
+func ToGoTemplate(src []byte) ([]byte, error) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		return nil, err
+	}
+	tree := parser.Parse(src, nil)
+	if tree == nil {
+		return nil, errors.New("convert: parse failed")
+	}
+	defer tree.Close()
+
+	root := tree.RootNode()
+	if root.HasError() {
+		return nil, ErrSyntax
+	}
+	c := &converter{src: src, standalone: map[uint]analysis.StandaloneTag{}}
+	for _, tag := range analysis.StandaloneTags(root, src) {
+		c.standalone[tag.Node.StartByte()] = tag
+	}
+	c.visit(root)
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	sort.Slice(c.edits, func(i, j int) bool { return c.edits[i].start < c.edits[j].start })
+	var out bytes.Buffer
+	var last uint
+	for _, e := range c.edits {
+		out.Write(src[last:e.start])
+		out.WriteString(e.text)
+		last = e.end
+	}
+	out.Write(src[last:])
+	return out.Bytes(), nil
+}
+
+type converter struct {
+	src        []byte
+	standalone map[uint]analysis.StandaloneTag
+	edits      []edit
+	// params are the block params in scope, innermost last.
+	params []string
+	err    error
+}
+
+// fail records an error at n, if there is none yet.
+func (c *converter) fail(n *tree_sitter.Node, format string, args ...any) {
+	if c.err == nil {
+		c.err = fmt.Errorf("convert: line %d: "+format, append([]any{n.StartPosition().Row + 1}, args...)...)
+	}
+}
+
+// replace rewrites the tag n, with its line if it is standalone. A
+// standalone partial keeps its indentation.
+func (c *converter) replace(n *tree_sitter.Node, text string) {
+	start, end := n.StartByte(), n.EndByte()
+	if tag, ok := c.standalone[start]; ok {
+		end = tag.LineEnd
+		if n.Kind() != "mustache_partial" {
+			start = tag.LineStart
+		}
+	}
+	c.edits = append(c.edits, edit{start, end, text})
+}
+
+func (c *converter) visit(n *tree_sitter.Node) {
+	switch n.Kind() {
+	case "mustache_interpolation", "mustache_triple":
+		expr := c.expression(n)
+		if n.Kind() == "mustache_triple" {
+			expr += " | raw"
+		}
+		c.replace(n, "{{"+expr+"}}")
+		return
+	case "mustache_section":
+		// Block params are in scope after the tag that binds them.
+		c.visit(n.Child(0))
+		params := c.params
+		c.params = append(c.params[:len(c.params):len(c.params)], analysis.ParseSectionTag(n.Child(0), c.src).BlockParams...)
+		for i := uint(1); i < n.ChildCount(); i++ {
+			c.visit(n.Child(i))
+		}
+		c.params = params
+		return
+	case "mustache_section_begin", "mustache_inverted_section_begin":
+		c.replace(n, c.sectionBegin(n))
+		return
+	case "mustache_else":
+		c.replace(n, c.elseTag(n))
+		return
+	case "mustache_section_end", "mustache_inverted_section_end":
+		c.replace(n, "{{end}}")
+		return
+	case "mustache_partial":
+		var name string
+		if content := walk.ChildOfKind(n, "mustache_partial_content"); content != nil {
+			name = strings.TrimSpace(content.Utf8Text(c.src))
+		}
+		c.replace(n, "{{template "+strconv.Quote(name)+" .}}")
+		return
+	case "mustache_dynamic_partial":
+		c.fail(n, "dynamic partials have no Go equivalent")
+		return
+	case "mustache_comment":
+		var text string
+		if content := walk.ChildOfKind(n, "mustache_comment_content"); content != nil {
+			text = content.Utf8Text(c.src)
+		}
+		if strings.Contains(text, "*/") {
+			// The comment cannot be written as a Go comment; drop it.
+			c.replace(n, "")
+		} else {
+			c.replace(n, "{{/*"+text+"*/}}")
+		}
+		return
+	}
+	for i := uint(0); i < n.ChildCount(); i++ {
+		c.visit(n.Child(i))
+	}
+}
+
+// expression returns the Go expression for the name of an interpolation.
+func (c *converter) expression(n *tree_sitter.Node) string {
+	for i := uint(0); i < n.ChildCount(); i++ {
+		switch child := n.Child(i); child.Kind() {
+		case "mustache_implicit_iterator", ".":
+			return "."
+		case "mustache_identifier":
+			return c.path([]string{child.Utf8Text(c.src)})
+		case "mustache_path_expression":
+			var keys []string
+			for j := uint(0); j < child.NamedChildCount(); j++ {
+				keys = append(keys, child.NamedChild(j).Utf8Text(c.src))
+			}
+			return c.path(keys)
+		}
+	}
+	return "."
+}
+
+// sectionBegin returns the action opening the section or helper whose
+// begin tag is n.
+func (c *converter) sectionBegin(n *tree_sitter.Node) string {
+	tag := analysis.ParseSectionTag(n, c.src)
+	if tag.Helper == analysis.OtherHelper {
+		c.fail(n, "unknown helper %q", tag.Name)
+		return ""
+	}
+	for _, param := range tag.BlockParams {
+		if !identifier.MatchString(param) {
+			c.fail(n, "block param %q is not a Go identifier", param)
+			return ""
+		}
+	}
+	subject := c.path(tag.Keys)
+	inverted := n.Kind() == "mustache_inverted_section_begin"
+	if inverted != tag.Unless {
+		return "{{if not (section " + subject + ")}}"
+	}
+	switch tag.Helper {
+	case analysis.IfHelper:
+		return "{{if section " + subject + "}}"
+	case analysis.WithHelper:
+		if len(tag.BlockParams) > 0 {
+			return "{{with $" + tag.BlockParams[0] + " := " + subject + "}}"
+		}
+		return "{{with " + subject + "}}"
+	case analysis.EachHelper:
+		switch len(tag.BlockParams) {
+		case 0:
+		case 1:
+			return "{{range $" + tag.BlockParams[0] + " := section " + subject + "}}"
+		default:
+			return "{{range $" + tag.BlockParams[1] + ", $" + tag.BlockParams[0] + " := section " + subject + "}}"
+		}
+	}
+	return "{{range section " + subject + "}}"
+}
+
+// elseTag returns the action for n, an {{else}} or {{else if cond}}.
+func (c *converter) elseTag(n *tree_sitter.Node) string {
+	if n.ChildByFieldName("name") == nil {
+		return "{{else}}"
+	}
+	tag := analysis.ParseSectionTag(n, c.src)
+	if tag.Helper != analysis.IfHelper {
+		c.fail(n, "{{else %s}} has no Go equivalent", tag.Name)
+		return ""
+	}
+	if tag.Unless {
+		return "{{else if not (section " + c.path(tag.Keys) + ")}}"
+	}
+	return "{{else if section " + c.path(tag.Keys) + "}}"
+}
+
+// path returns the Go expression reading keys, from a block param if the
+// first key names one in scope and from dot otherwise. Nil keys are dot.
+func (c *converter) path(keys []string) string {
+	if len(keys) > 0 {
+		for i := len(c.params) - 1; i >= 0; i-- {
+			if c.params[i] == keys[0] {
+				return goPath("$"+keys[0], keys[1:])
+			}
+		}
+	}
+	return goPath(".", keys)
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// goPath returns the Go template expression reading keys from root, dot or
+// a variable.
+func goPath(root string, keys []string) string {
+	for _, key := range keys {
+		if !identifier.MatchString(key) {
+			args := make([]string, len(keys))
+			for i, key := range keys {
+				args[i] = strconv.Quote(key)
+			}
+			return "(index " + root + " " + strings.Join(args, " ") + ")"
+		}
+	}
+	if root == "." {
+		return "." + strings.Join(keys, ".")
+	}
+	if len(keys) == 0 {
+		return root
+	}
+	return root + "." + strings.Join(keys, ".")
+}
@@ -0,0 +1,105 @@
+package convert_test
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/convert"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/render"
+)
+
+func TestToGoTemplate(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{`<p class="x">{{ name }} {{user.email}} {{{html}}} {{.}}</p>`, `<p class="x">{{.name}} {{.user.email}} {{.html | raw}} {{.}}</p>`},
+		{"<ul>\n  {{#items}}\n  <li>{{label}}</li>\n  {{/items}}\n</ul>", "<ul>\n{{range section .items}}  <li>{{.label}}</li>\n{{end}}</ul>"},
+		{`{{^items}}none{{/items}}`, `{{if not (section .items)}}none{{end}}`},
+		{`{{> header}}{{! note }}{{first-name}}{{a.b-c}}`, `{{template "header" .}}{{/* note */}}{{(index . "first-name")}}{{(index . "a" "b-c")}}`},
+		{`{{! a */ b }}x`, `x`},
+		{`{{#if a}}x{{else if b}}y{{else}}z{{/if}}{{#unless a}}u{{/unless}}{{^if a}}n{{/if}}`,
+			`{{if section .a}}x{{else if section .b}}y{{else}}z{{end}}{{if not (section .a)}}u{{end}}{{if not (section .a)}}n{{end}}`},
+		{`{{#with user as |u|}}{{u.name}}{{/with}}{{#each items}}{{label}}{{/each}}{{#each items as |item i|}}{{i}}{{item.label}}{{/each}}{{item}}`,
+			`{{with $u := .user}}{{$u.name}}{{end}}{{range section .items}}{{.label}}{{end}}{{range $i, $item := section .items}}{{$i}}{{$item.label}}{{end}}{{.item}}`},
+	}
+	for _, test := range tests {
+		got, err := convert.ToGoTemplate([]byte(test.src))
+		if err != nil {
+			t.Fatalf("ToGoTemplate(%q): %v", test.src, err)
+		}
+		if string(got) != test.expected {
+			t.Errorf("ToGoTemplate(%q) =\n%s\nwant\n%s", test.src, got, test.expected)
+		}
+	}
+}
+
+// TestToGoTemplateRenders checks that converted templates render like the
+// originals.
+func TestToGoTemplateRenders(t *testing.T) {
+	data := map[string]any{
+		"title":  "A & B",
+		"html":   "<b>bold</b>",
+		"admin":  true,
+		"hidden": false,
+		"user":   map[string]any{"name": "Ann"},
+		"items":  []any{map[string]any{"name": "x"}, map[string]any{"name": "y"}},
+		"tags":   []any{"a", "b"},
+	}
+	partials := map[string]string{"item": "<li>{{name}}</li>"}
+	tests := []string{
+		`<h1>{{title}}</h1>{{{html}}}`,
+		"<ul>\n  {{#items}}\n  {{> item}}\n  {{/items}}\n</ul>\n",
+		`{{#admin}}<a href="/admin">admin</a>{{/admin}}{{^hidden}}<p>shown</p>{{/hidden}}`,
+		`{{#user}}<p>{{name}}</p>{{/user}}{{#tags}}<i>{{.}}</i>{{/tags}}{{^tags}}none{{/tags}}`,
+		`{{#if admin}}yes{{else}}no{{/if}}{{#unless hidden}}<p>shown</p>{{/unless}}{{#with user}}{{name}}{{/with}}`,
+		`{{#each items as |item i|}}{{i}}:{{item.name}} {{/each}}{{#each tags}}<i>{{.}}</i>{{/each}}`,
+	}
+	for _, src := range tests {
+		want, err := render.Render([]byte(src), data, render.WithPartials(func(name string) ([]byte, error) {
+			return []byte(partials[name]), nil
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tmpl := template.New("main").Funcs(convert.Funcs())
+		for name, partial := range partials {
+			converted, err := convert.ToGoTemplate([]byte(partial))
+			if err != nil {
+				t.Fatal(err)
+			}
+			template.Must(tmpl.New(name).Parse(string(converted)))
+		}
+		converted, err := convert.ToGoTemplate([]byte(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tmpl.Parse(string(converted)); err != nil {
+			t.Fatalf("parsing %q: %v", converted, err)
+		}
+		var got bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&got, "main", data); err != nil {
+			t.Fatalf("executing %q: %v", converted, err)
+		}
+		if got.String() != string(want) {
+			t.Errorf("%q converted to %q renders\n%q\nwant\n%q", src, converted, got.String(), want)
+		}
+	}
+}
+
+func TestToGoTemplateSyntaxError(t *testing.T) {
+	if _, err := convert.ToGoTemplate([]byte("{{#a}}<p>")); !errors.Is(err, convert.ErrSyntax) {
+		t.Errorf("ToGoTemplate() error = %v, want ErrSyntax", err)
+	}
+}
+
+func TestToGoTemplateUnsupported(t *testing.T) {
+	for _, src := range []string{"{{#format date}}x{{/format}}", "{{#if a}}x{{else each b}}y{{/if}}", "{{>*page}}"} {
+		if _, err := convert.ToGoTemplate([]byte(src)); err == nil || errors.Is(err, convert.ErrSyntax) {
+			t.Errorf("ToGoTemplate(%q) error = %v, want an unsupported tag", src, err)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+// Package corpus runs tree-sitter corpus tests, the test/corpus/*.txt
+// fixtures of a grammar, through the Go bindings, so that go test checks
+// the same expected trees as tree-sitter test.
+//
+// A corpus file is a sequence of cases, each a header, an input and the
+// expected S-expression of its tree:
+//
+//	==================
+//	Name of the case
+//	==================
+//	<p>{{x}}</p>
+//	---
+//	(document (html_element ...))
+//
+// The attributes :skip and :error may follow the name in the header; with
+// :error, the input is expected to parse with errors and the expected tree
+// is ignored. The header and divider lines of a file may carry a suffix,
+// as in ===||| and ---|||, to delimit cases whose input contains such
+// lines; the first header sets the suffix for the file.
+package corpus
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// Case is one test of a corpus file.
+type Case struct {
+	Name string
+	// File is the path of the file the case is from, and Line the
+	// one-based line of its header.
+	File string
+	Line int
+	// Input is the source to parse, with the line break before the divider
+	// removed.
+	Input []byte
+	// Expected is the expected S-expression, as written in the file.
+	Expected string
+	// Skip and Error are set by the :skip and :error attributes.
+	Skip  bool
+	Error bool
+}
+
+var (
+	headerRE  = regexp.MustCompile(`^(={3,})(\S*)$`)
+	dividerRE = regexp.MustCompile(`^-{3,}(\S*)$`)
+	fieldRE   = regexp.MustCompile(`[\w-]+: `)
+	// trailingFieldRE matches a field name at the end of a line of Pretty.
+	trailingFieldRE = regexp.MustCompile(` ([\w-]+: )$`)
+	spaceRE         = regexp.MustCompile(`\s+`)
+)
+
+// Parse returns the cases of the corpus file src, which was read from file.
+func Parse(file string, src []byte) ([]Case, error) {
+	lines := strings.SplitAfter(string(src), "\n")
+	line := func(i int) string {
+		return strings.TrimRight(lines[i], "\r\n")
+	}
+	// The suffix of the first header delimits all the cases in the file.
+	suffix, found := "", false
+	for i := range lines {
+		if m := headerRE.FindStringSubmatch(line(i)); m != nil {
+			suffix, found = m[2], true
+			break
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+	isHeader := func(i int) bool {
+		m := headerRE.FindStringSubmatch(line(i))
+		return m != nil && m[2] == suffix
+	}
+	isDivider := func(i int) bool {
+		m := dividerRE.FindStringSubmatch(line(i))
+		return m != nil && m[1] == suffix
+	}
+
+	var cases []Case
+	i := 0
+	for i < len(lines) && !isHeader(i) {
+		i++
+	}
+	for i < len(lines) {
+		c := Case{File: file, Line: i + 1}
+		i++
+		for ; i < len(lines) && !isHeader(i); i++ {
+			switch name := strings.TrimSpace(line(i)); {
+			case c.Name == "":
+				c.Name = name
+			case name == ":skip":
+				c.Skip = true
+			case name == ":error":
+				c.Error = true
+			}
+		}
+		if i == len(lines) {
+			return nil, fmt.Errorf("corpus: %s:%d: unterminated header", file, c.Line)
+		}
+
+		var input []string
+		for i++; i < len(lines) && !isDivider(i); i++ {
+			input = append(input, lines[i])
+		}
+		if i == len(lines) {
+			return nil, fmt.Errorf("corpus: %s:%d: %q has no divider", file, c.Line, c.Name)
+		}
+		c.Input = []byte(strings.TrimSuffix(strings.TrimSuffix(strings.Join(input, ""), "\n"), "\r"))
+
+		var expected []string
+		for i++; i < len(lines) && !isHeader(i); i++ {
+			expected = append(expected, line(i))
+		}
+		c.Expected = strings.TrimSpace(strings.Join(expected, "\n"))
+		cases = append(cases, c)
+	}
+	return cases, nil
+}
+
+// Load returns the cases of the corpus files matching the glob pattern, in
+// file name order.
+func Load(pattern string) ([]Case, error) {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var cases []Case
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		fileCases, err := Parse(file, src)
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, fileCases...)
+	}
+	return cases, nil
+}
+
+// ErrMismatch is wrapped by the errors Check returns for trees that differ
+// from the expected one.
+var ErrMismatch = errors.New("corpus: tree does not match")
+
+// Check parses the input of c with language and compares its tree with the
+// expected one. Whitespace is not significant, and field names are only
+// compared if the expected tree has some, as in tree-sitter test.
+func Check(language *tree_sitter.Language, c Case) error {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(language); err != nil {
+		return err
+	}
+	tree := parser.Parse(c.Input, nil)
+	if tree == nil {
+		return errors.New("corpus: parse failed")
+	}
+	defer tree.Close()
+
+	if c.Error {
+		if !tree.RootNode().HasError() {
+			return fmt.Errorf("%w: expected errors in\n%s", ErrMismatch, Pretty(tree.RootNode().ToSexp()))
+		}
+		return nil
+	}
+	got, want := Normalize(tree.RootNode().ToSexp()), Normalize(c.Expected)
+	if !fieldRE.MatchString(want) {
+		got = Normalize(fieldRE.ReplaceAllString(got, ""))
+	}
+	if got != want {
+		return fmt.Errorf("%w:\nwant\n%s\ngot\n%s", ErrMismatch, Pretty(want), Pretty(got))
+	}
+	return nil
+}
+
+// Normalize returns the S-expression sexp with comments removed and
+// whitespace collapsed, so that expressions that differ only in layout are
+// equal.
+func Normalize(sexp string) string {
+	var lines []string
+	for _, line := range strings.Split(sexp, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), ";") {
+			lines = append(lines, line)
+		}
+	}
+	s := spaceRE.ReplaceAllString(strings.Join(lines, " "), " ")
+	s = strings.ReplaceAll(s, "( ", "(")
+	s = strings.ReplaceAll(s, " )", ")")
+	return strings.TrimSpace(s)
+}
+
+// Pretty returns the normalized S-expression sexp with each node on its
+// own line, indented two spaces per level.
+func Pretty(sexp string) string {
+	var b bytes.Buffer
+	depth := 0
+	quoted := false
+	for i := 0; i < len(sexp); i++ {
+		c := sexp[i]
+		switch {
+		case quoted:
+			if c == '\\' && i+1 < len(sexp) {
+				b.WriteByte(c)
+				i++
+				c = sexp[i]
+			} else if c == '"' {
+				quoted = false
+			}
+		case c == '"':
+			quoted = true
+		case c == '(':
+			if i > 0 {
+				// Move a field name to the line of its node.
+				field := ""
+				if m := trailingFieldRE.FindSubmatch(b.Bytes()); m != nil {
+					field = string(m[1])
+					b.Truncate(b.Len() - len(m[0]))
+				}
+				b.Truncate(len(bytes.TrimRight(b.Bytes(), " ")))
+				b.WriteString("\n" + strings.Repeat("  ", depth) + field)
+			}
+			depth++
+		case c == ')':
+			depth--
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// Run runs the cases of the corpus files matching pattern as subtests of t,
+// named after the files and cases, parsing their inputs with language.
+func Run(t *testing.T, language *tree_sitter.Language, pattern string) {
+	t.Helper()
+	cases, err := Load(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) == 0 {
+		t.Fatalf("no corpus cases match %s", pattern)
+	}
+	for _, c := range cases {
+		t.Run(strings.TrimSuffix(filepath.Base(c.File), ".txt")+"/"+c.Name, func(t *testing.T) {
+			if c.Skip {
+				t.Skip(":skip")
+			}
+			if err := Check(language, c); err != nil {
+				t.Errorf("%s:%d: %v", c.File, c.Line, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package corpus_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/corpus"
+)
+
+const fixture = `=====
+Element
+=====
+
+<b>{{x}}</b>
+
+---
+
+; a comment
+(document
+  (html_element
+    (html_start_tag (html_tag_name))
+    (mustache_interpolation (mustache_identifier))
+    (html_end_tag (html_tag_name))))
+
+=====
+Skipped
+:skip
+=====
+a
+---
+
+(document (text))
+
+=====
+Errors
+:error
+=====
+<div>{{#a}}</div>
+---
+`
+
+func TestParse(t *testing.T) {
+	cases, err := corpus.Parse("fixture.txt", []byte(fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	type summary struct {
+		Name, Input string
+		Line        int
+		Skip, Error bool
+	}
+	var got []summary
+	for _, c := range cases {
+		got = append(got, summary{c.Name, string(c.Input), c.Line, c.Skip, c.Error})
+	}
+	expected := []summary{
+		{"Element", "\n<b>{{x}}</b>\n", 1, false, false},
+		{"Skipped", "a", 16, true, false},
+		{"Errors", "<div>{{#a}}</div>", 25, false, true},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Parse() =\n%+v\nwant\n%+v", got, expected)
+	}
+	if cases[1].Expected != "(document (text))" {
+		t.Errorf("Expected = %q", cases[1].Expected)
+	}
+
+	cases, err = corpus.Parse("suffix.txt", []byte("===|||\nDivider in input\n===|||\na\n---\nb\n---|||\n(document (text))\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) != 1 || string(cases[0].Input) != "a\n---\nb" || cases[0].Expected != "(document (text))" {
+		t.Errorf("Parse() with a suffix = %+v", cases)
+	}
+
+	if _, err := corpus.Parse("bad.txt", []byte("===\nNo divider\n===\ninput\n")); err == nil {
+		t.Error("Parse() accepted a case without a divider")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	language := tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())
+	cases, err := corpus.Parse("fixture.txt", []byte(fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []corpus.Case{cases[0], cases[2]} {
+		if err := corpus.Check(language, c); err != nil {
+			t.Errorf("Check(%q) = %v", c.Name, err)
+		}
+	}
+
+	c := cases[0]
+	c.Expected = "(document (html_element (html_start_tag name: (html_tag_name))))"
+	if err := corpus.Check(language, c); !errors.Is(err, corpus.ErrMismatch) {
+		t.Errorf("Check() of a wrong tree = %v, want ErrMismatch", err)
+	}
+	c = cases[2]
+	c.Input = []byte("<div></div>")
+	if err := corpus.Check(language, c); !errors.Is(err, corpus.ErrMismatch) {
+		t.Errorf("Check() of an :error case without errors = %v, want ErrMismatch", err)
+	}
+}
+
+func TestPretty(t *testing.T) {
+	got := corpus.Pretty(corpus.Normalize("(a\n  key: (b \"(\")  (c (d)))"))
+	want := "(a\n  key: (b \"(\")\n  (c\n    (d)))"
+	if got != want {
+		t.Errorf("Pretty() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "fixture.txt"), []byte(fixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	corpus.Run(t, tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language()), filepath.Join(dir, "*.txt"))
+}
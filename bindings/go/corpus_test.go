@@ -0,0 +1,32 @@
+package tree_sitter_htmlmustache_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/corpus"
+)
+
+func TestCorpus(t *testing.T) {
+	cases, err := corpus.Load(filepath.Join("..", "..", "test", "corpus", "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) == 0 {
+		t.Fatal("no corpus cases found")
+	}
+	language := tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())
+	for _, c := range cases {
+		t.Run(filepath.Base(c.File)+"/"+c.Name, func(t *testing.T) {
+			if c.Skip {
+				t.Skip(":skip")
+			}
+			if err := corpus.Check(language, c); err != nil {
+				t.Errorf("%s:%d: %v", c.File, c.Line, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+// Package diff compares two htmlmustache templates structurally. It reports
+// elements, attributes, mustache tags and text that were added, removed or
+// moved, and attributes whose values changed, without the noise of a line
+// diff: whitespace between nodes and runs of whitespace in text are
+// ignored, as are spaces inside mustache delimiters.
+//
+// Nodes are matched by a longest common subsequence over the templates in
+// document order. A node that is unmatched in both templates is reported as
+// moved, and its descendants move with it without being reported. A node
+// matched in place keeps its match even if its ancestors changed, so
+// wrapping content in a new element reports only the element.
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+)
+
+// Op is the kind of a change.
+type Op int
+
+const (
+	Added Op = iota
+	Removed
+	Moved
+	// Changed is an attribute whose value differs.
+	Changed
+)
+
+func (o Op) String() string {
+	switch o {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Moved:
+		return "moved"
+	case Changed:
+		return "changed"
+	}
+	return "unknown"
+}
+
+// Kind is what a change applies to.
+type Kind int
+
+const (
+	Element Kind = iota
+	Attribute
+	// Mustache is a mustache tag or section.
+	Mustache
+	// Text is text, an entity, a doctype or an HTML comment.
+	Text
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Element:
+		return "element"
+	case Attribute:
+		return "attribute"
+	case Mustache:
+		return "mustache"
+	case Text:
+		return "text"
+	}
+	return "unknown"
+}
+
+// Range is a byte range in one of the templates.
+type Range struct {
+	StartByte, EndByte uint
+}
+
+// Change is one difference between the templates.
+type Change struct {
+	Op   Op
+	Kind Kind
+	// Label identifies the node: "<li>" for an element, the attribute name,
+	// the normalized tag such as "{{#items}}", or the normalized text.
+	Label string
+	// OldPath and NewPath are the labels of the enclosing elements and
+	// sections, outermost first, in each template. Attribute paths end
+	// with the element the attribute belongs to. OldPath is empty for
+	// additions and NewPath for removals.
+	OldPath, NewPath []string
+	// Old and New are the node's ranges in each template, set for the same
+	// sides as the paths.
+	Old, New Range
+	// OldValue and NewValue are attribute values.
+	OldValue, NewValue string
+}
+
+func (c Change) String() string {
+	label := c.Label
+	if c.Kind == Attribute {
+		label = "attribute " + label
+	}
+	switch c.Op {
+	case Added:
+		return fmt.Sprintf("added %s%s", label, in(c.NewPath))
+	case Removed:
+		return fmt.Sprintf("removed %s%s", label, in(c.OldPath))
+	case Moved:
+		return fmt.Sprintf("moved %s from %s to %s", label, pathString(c.OldPath), pathString(c.NewPath))
+	case Changed:
+		return fmt.Sprintf("changed %s%s from %q to %q", label, in(c.NewPath), c.OldValue, c.NewValue)
+	}
+	return label
+}
+
+func in(path []string) string {
+	if len(path) == 0 {
+		return ""
+	}
+	return " in " + pathString(path)
+}
+
+func pathString(path []string) string {
+	if len(path) == 0 {
+		return "top level"
+	}
+	return strings.Join(path, " > ")
+}
+
+// Diff parses both templates and returns their differences in the order
+// they appear in the new template, with removals where they would have
+// been.
+func Diff(old, new []byte) ([]Change, error) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		return nil, err
+	}
+	oldTree := parser.Parse(old, nil)
+	if oldTree == nil {
+		return nil, errors.New("diff: parse failed")
+	}
+	defer oldTree.Close()
+	newTree := parser.Parse(new, nil)
+	if newTree == nil {
+		return nil, errors.New("diff: parse failed")
+	}
+	defer newTree.Close()
+	return DiffTrees(oldTree.RootNode(), old, newTree.RootNode(), new), nil
+}
+
+// DiffTrees compares already parsed templates.
+func DiffTrees(oldRoot *tree_sitter.Node, oldSrc []byte, newRoot *tree_sitter.Node, newSrc []byte) []Change {
+	a := flatten(oldRoot, oldSrc)
+	b := flatten(newRoot, newSrc)
+	steps := align(a, b)
+
+	// Pair each unmatched new entry with an unmatched old entry of the same
+	// kind and label; the pair is a move. An entry whose parent moved
+	// prefers the entry under the parent's old position, and moves with it
+	// silently.
+	movedFrom := map[int]int{}
+	moved := map[int]bool{}
+	var removed []int
+	for _, s := range steps {
+		if s.new < 0 {
+			removed = append(removed, s.old)
+		}
+	}
+	withParent := map[int]bool{}
+	for _, s := range steps {
+		if s.old >= 0 {
+			continue
+		}
+		e := b[s.new]
+		oldParent, parentMoved := movedFrom[e.parent]
+		match := -1
+		for _, r := range removed {
+			if moved[r] || a[r].kind != e.kind || a[r].label != e.label {
+				continue
+			}
+			if parentMoved && a[r].parent == oldParent {
+				match = r
+				break
+			}
+			if match < 0 {
+				match = r
+			}
+		}
+		if match >= 0 {
+			moved[match] = true
+			movedFrom[s.new] = match
+			withParent[s.new] = parentMoved && a[match].parent == oldParent
+		}
+	}
+
+	var changes []Change
+	for _, step := range steps {
+		switch {
+		case step.old >= 0 && step.new >= 0:
+			changes = append(changes, attributeChanges(a[step.old], b[step.new])...)
+		case step.old >= 0:
+			if !moved[step.old] {
+				e := a[step.old]
+				changes = append(changes, Change{Op: Removed, Kind: e.kind, Label: e.label, OldPath: e.path, Old: e.span})
+			}
+		default:
+			e := b[step.new]
+			if from, ok := movedFrom[step.new]; ok {
+				if !withParent[step.new] {
+					changes = append(changes, Change{Op: Moved, Kind: e.kind, Label: e.label, OldPath: a[from].path, NewPath: e.path, Old: a[from].span, New: e.span})
+				}
+				changes = append(changes, attributeChanges(a[from], e)...)
+			} else {
+				changes = append(changes, Change{Op: Added, Kind: e.kind, Label: e.label, NewPath: e.path, New: e.span})
+			}
+		}
+	}
+	return changes
+}
+
+// attributeChanges compares the attributes of two matched elements.
+func attributeChanges(a, b entry) []Change {
+	if a.kind != Element {
+		return nil
+	}
+	path := append(append([]string{}, b.path...), b.label)
+	oldPath := append(append([]string{}, a.path...), a.label)
+	var changes []Change
+	for _, attr := range b.attrs {
+		old, ok := findAttribute(a.attrs, attr.name)
+		switch {
+		case !ok:
+			changes = append(changes, Change{Op: Added, Kind: Attribute, Label: attr.name, NewPath: path, New: attr.span, NewValue: attr.value})
+		case old.value != attr.value:
+			changes = append(changes, Change{Op: Changed, Kind: Attribute, Label: attr.name, OldPath: oldPath, NewPath: path, Old: old.span, New: attr.span, OldValue: old.value, NewValue: attr.value})
+		}
+	}
+	for _, attr := range a.attrs {
+		if _, ok := findAttribute(b.attrs, attr.name); !ok {
+			changes = append(changes, Change{Op: Removed, Kind: Attribute, Label: attr.name, OldPath: oldPath, Old: attr.span, OldValue: attr.value})
+		}
+	}
+	return changes
+}
+
+func findAttribute(attrs []attribute, name string) (attribute, bool) {
+	for _, attr := range attrs {
+		if attr.name == name {
+			return attr, true
+		}
+	}
+	return attribute{}, false
+}
@@ -0,0 +1,103 @@
+package diff_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/diff"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		expected []string
+	}{
+		{
+			name:     "whitespace only",
+			old:      "<ul>\n  <li>{{ name }}</li>\n</ul>",
+			new:      "<ul><li>{{name}}</li></ul>",
+			expected: nil,
+		},
+		{
+			name: "added and removed elements",
+			old:  "<ul><li>a</li></ul><p>x</p>",
+			new:  "<ul><li>a</li><li>b</li></ul>",
+			expected: []string{
+				"removed <p>",
+				"removed x in <p>",
+				"added <li> in <ul>",
+				"added b in <ul> > <li>",
+			},
+		},
+		{
+			name: "attributes",
+			old:  `<a href="/x" class=a {{#on}}hidden{{/on}}>x</a>`,
+			new:  `<a href='/y' class="a" title=t>x</a>`,
+			expected: []string{
+				`changed attribute href in <a> from "/x" to "/y"`,
+				`added attribute title in <a>`,
+				`removed attribute {{#on}}hidden{{/on}} in <a>`,
+			},
+		},
+		{
+			name: "moved mustache tag",
+			old:  "<h1>{{title}}</h1><p>{{body}}</p>",
+			new:  "<h1></h1><p>{{body}}{{title}}</p>",
+			expected: []string{
+				"moved {{title}} from <h1> to <p>",
+			},
+		},
+		{
+			name: "wrapped in a section",
+			old:  "<p>{{name}}</p>",
+			new:  "{{#user}}<p>{{name}}</p>{{/user}}",
+			expected: []string{
+				"added {{#user}}",
+			},
+		},
+		{
+			name: "moved element keeps attribute changes",
+			old:  `<div><span id=a>x</span></div><p>y</p>`,
+			new:  `<div></div><p>y<span id=b>x</span></p>`,
+			expected: []string{
+				"moved <span> from <div> to <p>",
+				`changed attribute id in <p> > <span> from "a" to "b"`,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changes, err := diff.Diff([]byte(test.old), []byte(test.new))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, c := range changes {
+				got = append(got, c.String())
+			}
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("Diff() =\n%q\nwant\n%q", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestDiffRanges(t *testing.T) {
+	old := []byte("<p>{{a}}</p>")
+	new := []byte("<p>{{a}} {{b}}</p>")
+	changes, err := diff.Diff(old, new)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []diff.Change{{
+		Op:      diff.Added,
+		Kind:    diff.Mustache,
+		Label:   "{{b}}",
+		NewPath: []string{"<p>"},
+		New:     diff.Range{StartByte: 9, EndByte: 14},
+	}}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Diff() = %+v, want %+v", changes, expected)
+	}
+}
@@ -0,0 +1,223 @@
+package diff
+
+import (
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// entry is a node that takes part in the comparison.
+type entry struct {
+	kind  Kind
+	label string
+	path  []string
+	span  Range
+	attrs []attribute
+	// parent is the index of the enclosing element or section entry, or
+	// -1.
+	parent int
+}
+
+type attribute struct {
+	// name is the attribute name, or the normalized source of a mustache
+	// section or tag in attribute position.
+	name  string
+	value string
+	span  Range
+}
+
+// flatten lists the entries under root in document order.
+func flatten(root *tree_sitter.Node, src []byte) []entry {
+	var entries []entry
+	var visit func(n *tree_sitter.Node, path []string, parent int)
+	visit = func(n *tree_sitter.Node, path []string, parent int) {
+		span := Range{n.StartByte(), n.EndByte()}
+		switch n.Kind() {
+		case "html_element", "html_script_element", "html_style_element", "html_raw_element":
+			tag := n.Child(0)
+			label := "<" + strings.ToLower(childText(tag, "html_tag_name", src)) + ">"
+			entries = append(entries, entry{kind: Element, label: label, path: path, span: span, attrs: attributes(tag, src), parent: parent})
+			path = append(path[:len(path):len(path)], label)
+			parent = len(entries) - 1
+			for i := uint(1); i < n.ChildCount(); i++ {
+				visit(n.Child(i), path, parent)
+			}
+			return
+		case "mustache_section", "mustache_inverted_section":
+			label := normalizeTag(n.Child(0).Utf8Text(src))
+			entries = append(entries, entry{kind: Mustache, label: label, path: path, span: span, parent: parent})
+			path = append(path[:len(path):len(path)], label)
+			parent = len(entries) - 1
+			for i := uint(1); i < n.ChildCount(); i++ {
+				visit(n.Child(i), path, parent)
+			}
+			return
+		case "mustache_interpolation", "mustache_triple", "mustache_partial", "mustache_comment":
+			entries = append(entries, entry{kind: Mustache, label: normalizeTag(n.Utf8Text(src)), path: path, span: span, parent: parent})
+			return
+		case "text", "html_raw_text", "html_entity", "html_comment", "html_doctype":
+			if text := strings.Join(strings.Fields(n.Utf8Text(src)), " "); text != "" {
+				entries = append(entries, entry{kind: Text, label: text, path: path, span: span, parent: parent})
+			}
+			return
+		case "html_start_tag", "html_end_tag", "html_self_closing_tag", "mustache_section_end", "mustache_inverted_section_end":
+			return
+		}
+		for i := uint(0); i < n.ChildCount(); i++ {
+			visit(n.Child(i), path, parent)
+		}
+	}
+	visit(root, nil, -1)
+	return entries
+}
+
+// attributes returns the attributes of a start tag.
+func attributes(tag *tree_sitter.Node, src []byte) []attribute {
+	var attrs []attribute
+	for i := uint(0); i < tag.ChildCount(); i++ {
+		child := tag.Child(i)
+		span := Range{child.StartByte(), child.EndByte()}
+		switch child.Kind() {
+		case "html_attribute":
+			attr := attribute{name: strings.ToLower(childText(child, "html_attribute_name", src)), span: span}
+			for j := uint(0); j < child.ChildCount(); j++ {
+				switch value := child.Child(j); value.Kind() {
+				case "html_attribute_value":
+					attr.value = value.Utf8Text(src)
+				case "html_quoted_attribute_value":
+					text := value.Utf8Text(src)
+					attr.value = text[1 : len(text)-1]
+				}
+			}
+			attrs = append(attrs, attr)
+		case "mustache_attribute":
+			attrs = append(attrs, attribute{name: normalizeMustache(child.Utf8Text(src)), span: span})
+		}
+	}
+	return attrs
+}
+
+// normalizeTag drops the whitespace inside a mustache tag's delimiters and
+// collapses the rest, so {{ name }} and {{name}} compare equal.
+func normalizeTag(tag string) string {
+	open := "{{"
+	for _, prefix := range []string{"{{{", "{{#", "{{^", "{{/", "{{>", "{{!", "{{&", "{{"} {
+		if strings.HasPrefix(tag, prefix) {
+			open = prefix
+			break
+		}
+	}
+	close := "}}"
+	if open == "{{{" && strings.HasSuffix(tag, "}}}") {
+		close = "}}}"
+	}
+	if len(tag) < len(open)+len(close) || !strings.HasSuffix(tag, close) {
+		return strings.Join(strings.Fields(tag), " ")
+	}
+	inner := strings.Join(strings.Fields(tag[len(open):len(tag)-len(close)]), " ")
+	return open + inner + close
+}
+
+// normalizeMustache normalizes each mustache tag in text that may also hold
+// HTML, such as a section in attribute position.
+func normalizeMustache(text string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(text, "{{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(text[start:], "}}")
+		if end < 0 {
+			break
+		}
+		end += start + 2
+		if strings.HasPrefix(text[start:], "{{{") && strings.HasPrefix(text[end:], "}") {
+			end++
+		}
+		b.WriteString(strings.Join(strings.Fields(text[:start]), " "))
+		b.WriteString(normalizeTag(text[start:end]))
+		text = text[end:]
+	}
+	b.WriteString(strings.Join(strings.Fields(text), " "))
+	return b.String()
+}
+
+func childText(n *tree_sitter.Node, kind string, src []byte) string {
+	if n == nil {
+		return ""
+	}
+	for i := uint(0); i < n.ChildCount(); i++ {
+		if child := n.Child(i); child.Kind() == kind {
+			return child.Utf8Text(src)
+		}
+	}
+	return ""
+}
+
+// step pairs an old and a new entry, or holds -1 on the side an entry is
+// missing from.
+type step struct {
+	old, new int
+}
+
+// align returns an edit script between a and b, matching entries of the
+// same kind and label along a longest common subsequence.
+func align(a, b []entry) []step {
+	same := func(i, j int) bool { return a[i].kind == b[j].kind && a[i].label == b[j].label }
+
+	// The common prefix and suffix are matched directly, which keeps the
+	// quadratic table small for typical edits.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && same(prefix, prefix) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && same(len(a)-1-suffix, len(b)-1-suffix) {
+		suffix++
+	}
+	n, m := len(a)-prefix-suffix, len(b)-prefix-suffix
+
+	// lcs[i][j] is the length of the longest common subsequence of the
+	// middle parts of a and b from i and j on.
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			switch {
+			case same(prefix+i, prefix+j):
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var steps []step
+	for i := 0; i < prefix; i++ {
+		steps = append(steps, step{i, i})
+	}
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && same(prefix+i, prefix+j):
+			steps = append(steps, step{prefix + i, prefix + j})
+			i++
+			j++
+		case i < n && (j == m || lcs[i+1][j] >= lcs[i][j+1]):
+			steps = append(steps, step{prefix + i, -1})
+			i++
+		default:
+			steps = append(steps, step{-1, prefix + j})
+			j++
+		}
+	}
+	for k := 0; k < suffix; k++ {
+		steps = append(steps, step{len(a) - suffix + k, len(b) - suffix + k})
+	}
+	return steps
+}
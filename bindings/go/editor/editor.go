@@ -0,0 +1,169 @@
+// Package editor computes editor features from htmlmustache parse trees and
+// the bundled queries: folding ranges from folds.scm and semantic tokens
+// from highlights.scm. Results are shaped like their Language Server
+// Protocol counterparts, with zero-based lines and UTF-16 columns, for
+// embedders that are not language servers.
+package editor
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/highlight"
+	"github.com/reteps/tree-sitter-htmlmustache/queries"
+)
+
+// Range is a folding range.
+type Range struct {
+	// StartLine and EndLine are the zero-based lines of the first and last
+	// line of the folded node.
+	StartLine uint `json:"startLine"`
+	EndLine   uint `json:"endLine"`
+	// Kind is "comment" for comments and "region" for anything else.
+	Kind string `json:"kind"`
+}
+
+// FoldingRanges returns the ranges of the nodes captured by folds.scm that
+// span more than one line, in document order.
+func FoldingRanges(tree *tree_sitter.Tree) []Range {
+	folds, err := queries.Folds(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language()))
+	if err != nil {
+		// The bundled query is tested to compile.
+		panic("editor: " + err.Error())
+	}
+	cursor := tree_sitter.NewQueryCursor()
+	defer cursor.Close()
+
+	var ranges []Range
+	captures := cursor.Captures(folds, tree.RootNode(), nil)
+	for match, index := captures.Next(); match != nil; match, index = captures.Next() {
+		node := match.Captures[index].Node
+		start, end := node.StartPosition().Row, node.EndPosition().Row
+		if start == end {
+			continue
+		}
+		kind := "region"
+		if strings.HasSuffix(node.Kind(), "_comment") {
+			kind = "comment"
+		}
+		ranges = append(ranges, Range{StartLine: start, EndLine: end, Kind: kind})
+	}
+	return ranges
+}
+
+// TokenTypes is the semantic token legend: the token types SemanticTokens
+// reports, in the order Encode numbers them.
+var TokenTypes = []string{"comment", "keyword", "macro", "property", "string", "type", "variable"}
+
+// tokenTypes maps highlight captures to token types. A capture without an
+// entry falls back to its parent, so "tag.error" is a "type". Captures
+// without a type, such as punctuation, are left to the editor's own
+// highlighting.
+var tokenTypes = map[string]string{
+	"attribute": "property",
+	"comment":   "comment",
+	"constant":  "macro",
+	"keyword":   "keyword",
+	"string":    "string",
+	"tag":       "type",
+	"variable":  "variable",
+}
+
+// Token is a semantic token. Tokens never span lines; a multi-line comment
+// is one token per line.
+type Token struct {
+	// Line is the zero-based line of the token, and StartChar and Length
+	// are its start and length in UTF-16 code units.
+	Line      uint   `json:"line"`
+	StartChar uint   `json:"startChar"`
+	Length    uint   `json:"length"`
+	Type      string `json:"type"`
+}
+
+// SemanticTokens returns the tokens of the spans highlight.Spans finds, in
+// document order.
+func SemanticTokens(tree *tree_sitter.Tree, src []byte) []Token {
+	lineStarts := []int{0}
+	for i, c := range src {
+		if c == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	var tokens []Token
+	for _, s := range highlight.Spans(tree.RootNode(), src) {
+		kind := tokenType(s.Capture)
+		if kind == "" {
+			continue
+		}
+		// Split the span at line breaks.
+		for start := int(s.StartByte); start < int(s.EndByte); {
+			line := sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > start }) - 1
+			end := int(s.EndByte)
+			if i := bytes.IndexByte(src[start:end], '\n'); i >= 0 {
+				end = start + i
+			}
+			if end > start {
+				tokens = append(tokens, Token{
+					Line:      uint(line),
+					StartChar: utf16Len(src[lineStarts[line]:start]),
+					Length:    utf16Len(src[start:end]),
+					Type:      kind,
+				})
+			}
+			start = end + 1
+		}
+	}
+	return tokens
+}
+
+// Encode returns tokens in the LSP wire format: five integers per token,
+// the line and start relative to the previous token, the length, the index
+// of the type in TokenTypes and an empty modifier set. Tokens must be in
+// document order, as SemanticTokens returns them.
+func Encode(tokens []Token) []uint32 {
+	index := map[string]uint32{}
+	for i, t := range TokenTypes {
+		index[t] = uint32(i)
+	}
+	data := make([]uint32, 0, 5*len(tokens))
+	var line, char uint
+	for _, t := range tokens {
+		deltaChar := t.StartChar
+		if t.Line == line {
+			deltaChar -= char
+		}
+		data = append(data, uint32(t.Line-line), uint32(deltaChar), uint32(t.Length), index[t.Type], 0)
+		line, char = t.Line, t.StartChar
+	}
+	return data
+}
+
+// tokenType returns the token type of a capture, or "" if it has none.
+func tokenType(capture string) string {
+	for {
+		if t, ok := tokenTypes[capture]; ok {
+			return t
+		}
+		dot := strings.LastIndexByte(capture, '.')
+		if dot < 0 {
+			return ""
+		}
+		capture = capture[:dot]
+	}
+}
+
+func utf16Len(b []byte) uint {
+	n := uint(0)
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		n += uint(utf16.RuneLen(r))
+		b = b[size:]
+	}
+	return n
+}
@@ -0,0 +1,64 @@
+package editor_test
+
+import (
+	"reflect"
+	"testing"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/editor"
+)
+
+func parse(t *testing.T, src string) *tree_sitter.Tree {
+	t.Helper()
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		t.Fatal(err)
+	}
+	return parser.Parse([]byte(src), nil)
+}
+
+func TestFoldingRanges(t *testing.T) {
+	tree := parse(t, "<ul>\n{{#items}}\n  <li>{{name}}</li>\n{{/items}}\n</ul>\n<!--\nnote\n-->\n<p>one line</p>")
+	defer tree.Close()
+
+	expected := []editor.Range{
+		{StartLine: 0, EndLine: 4, Kind: "region"},
+		{StartLine: 1, EndLine: 3, Kind: "region"},
+		{StartLine: 5, EndLine: 7, Kind: "comment"},
+	}
+	if got := editor.FoldingRanges(tree); !reflect.DeepEqual(got, expected) {
+		t.Errorf("FoldingRanges() = %+v, want %+v", got, expected)
+	}
+}
+
+func TestSemanticTokens(t *testing.T) {
+	src := "<p class=\"é\">{{name}}</p>{{! a\nbc }}"
+	tree := parse(t, src)
+	defer tree.Close()
+
+	expected := []editor.Token{
+		{Line: 0, StartChar: 1, Length: 1, Type: "type"},
+		{Line: 0, StartChar: 3, Length: 5, Type: "property"},
+		{Line: 0, StartChar: 10, Length: 1, Type: "string"},
+		{Line: 0, StartChar: 13, Length: 2, Type: "keyword"},
+		{Line: 0, StartChar: 15, Length: 4, Type: "variable"},
+		{Line: 0, StartChar: 19, Length: 2, Type: "keyword"},
+		{Line: 0, StartChar: 23, Length: 1, Type: "type"},
+		{Line: 0, StartChar: 25, Length: 5, Type: "comment"},
+		{Line: 1, StartChar: 0, Length: 3, Type: "comment"},
+		{Line: 1, StartChar: 3, Length: 2, Type: "keyword"},
+	}
+	tokens := editor.SemanticTokens(tree, []byte(src))
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("SemanticTokens() =\n%+v\nwant\n%+v", tokens, expected)
+	}
+
+	data := editor.Encode(tokens[len(tokens)-3:])
+	expectedData := []uint32{0, 25, 5, 0, 0, 1, 0, 3, 0, 0, 0, 3, 2, 1, 0}
+	if !reflect.DeepEqual(data, expectedData) {
+		t.Errorf("Encode() = %v, want %v", data, expectedData)
+	}
+}
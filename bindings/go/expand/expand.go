@@ -0,0 +1,237 @@
+// Package expand flattens htmlmustache templates by splicing the partials
+// they include into them, for renderers that cannot load partials.
+//
+// Rendering a flattened template renders the same as the original with its
+// partials. A standalone partial replaces its whole line, with every line
+// of the partial indented by the whitespace before the tag, as the Mustache
+// spec has a renderer do. Standalone tags keep the indentation and line
+// ending around them where they are still alone on their line after
+// inlining. Where inlining changes that, the whitespace Mustache would have
+// removed is removed, and a tag that would otherwise become standalone is
+// followed by an empty comment, {{! }}, written with the delimiters active
+// where it goes.
+//
+// Section lambdas receive the flattened text of their section. Dynamic
+// partials, {{>*name}}, are left as they are.
+package expand
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// ErrSyntax is returned for templates whose parse tree contains errors.
+var ErrSyntax = errors.New("expand: template has syntax errors")
+
+// emptyComment returns what keeps a tag from becoming standalone, written
+// with delimiters.
+func emptyComment(delimiters analysis.Delimiters) []byte {
+	return []byte(delimiters.Open + "! " + delimiters.Close)
+}
+
+// Inline loads the template called entry through resolver and returns it
+// with every partial it includes replaced by the partial's content,
+// recursively. Partials may nest at most maxDepth deep. A partial that
+// resolver fails to load, a partial that includes itself or a template with
+// syntax errors is an error. So is a partial in a template after a set
+// delimiter tag, or one containing one, as the partial's delimiters would
+// change when inlined.
+func Inline(entry string, resolver analysis.Resolver, maxDepth int) ([]byte, error) {
+	src, err := resolver(entry)
+	if err != nil {
+		return nil, fmt.Errorf("expand: resolving %q: %w", entry, err)
+	}
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		return nil, err
+	}
+	e := &expander{parser: parser, resolver: resolver, maxDepth: maxDepth}
+	var out output
+	if err := e.template(&out, src, []string{entry}); err != nil {
+		return nil, err
+	}
+	return e.settle(out)
+}
+
+type expander struct {
+	parser   *tree_sitter.Parser
+	resolver analysis.Resolver
+	maxDepth int
+}
+
+// output is a flattened template along with the bytes that rendering the
+// templates they came from leaves out: the indentation and line endings of
+// their standalone tags.
+type output struct {
+	text []byte
+	skip []bool
+}
+
+func (o *output) write(text []byte, skip []bool) {
+	o.text = append(o.text, text...)
+	o.skip = append(o.skip, skip...)
+}
+
+// template writes src to out with its partials inlined. stack holds the
+// names of the templates being inlined, src's last.
+func (e *expander) template(out *output, src []byte, stack []string) error {
+	name := stack[len(stack)-1]
+	tree := e.parser.Parse(src, nil)
+	if tree == nil {
+		return errors.New("expand: parse failed")
+	}
+	defer tree.Close()
+	root := tree.RootNode()
+	if root.HasError() {
+		return fmt.Errorf("%w: %s", ErrSyntax, name)
+	}
+
+	skip := make([]bool, len(src))
+	standalone := map[uint]analysis.StandaloneTag{}
+	for _, tag := range analysis.StandaloneTags(root, src) {
+		markSkipped(skip, tag)
+		if tag.Node.Kind() == "mustache_partial" {
+			standalone[tag.Node.StartByte()] = tag
+		}
+	}
+
+	var partials []*tree_sitter.Node
+	delimiters := false
+	var err error
+	for n := range walk.KindNode(root, "mustache_set_delimiter", "mustache_partial") {
+		switch n.Kind() {
+		case "mustache_set_delimiter":
+			delimiters = true
+			if len(stack) > 1 && err == nil {
+				err = fmt.Errorf("expand: partial %q sets delimiters", name)
+			}
+		case "mustache_partial":
+			if delimiters && err == nil {
+				err = fmt.Errorf("expand: %q includes a partial after setting delimiters", name)
+			}
+			partials = append(partials, &n)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	pos := uint(0)
+	for _, n := range partials {
+		content := walk.ChildOfKind(n, "mustache_partial_content")
+		if content == nil {
+			continue
+		}
+		partial := strings.TrimSpace(content.Utf8Text(src))
+		for _, including := range stack {
+			if including == partial {
+				return fmt.Errorf("expand: partial %q includes itself", partial)
+			}
+		}
+		if len(stack) > e.maxDepth {
+			return fmt.Errorf("expand: partials nested more than %d deep", e.maxDepth)
+		}
+		partialSrc, err := e.resolver(partial)
+		if err != nil {
+			return fmt.Errorf("expand: resolving partial %q: %w", partial, err)
+		}
+
+		start, end := n.StartByte(), n.EndByte()
+		if tag, ok := standalone[start]; ok {
+			start, end = tag.LineStart, tag.LineEnd
+			partialSrc = indentLines(partialSrc, tag.Indent)
+		}
+		out.write(src[pos:start], skip[pos:start])
+		if err := e.template(out, partialSrc, append(stack[:len(stack):len(stack)], partial)); err != nil {
+			return err
+		}
+		pos = end
+	}
+	out.write(src[pos:], skip[pos:])
+	return nil
+}
+
+// settle makes the standalone tags of out those of the templates it was
+// flattened from. Removing whitespace only joins lines and adding comments
+// only fills them, so no tag becomes standalone again and the loop ends.
+func (e *expander) settle(out output) ([]byte, error) {
+	for {
+		tree := e.parser.Parse(out.text, nil)
+		if tree == nil {
+			return nil, errors.New("expand: parse failed")
+		}
+		regions := analysis.DelimiterRegions(tree.RootNode(), out.text)
+		skipped := make([]bool, len(out.text))
+		comments := map[uint]bool{}
+		for _, tag := range analysis.StandaloneTags(tree.RootNode(), out.text) {
+			markSkipped(skipped, tag)
+			for i := tag.LineStart; i < tag.LineEnd; i++ {
+				if skipped[i] && !out.skip[i] {
+					comments[tag.Node.EndByte()] = true
+					break
+				}
+			}
+		}
+		tree.Close()
+
+		var settled output
+		changed := false
+		for i := range out.text {
+			if comments[uint(i)] {
+				comment := emptyComment(analysis.DelimitersAt(regions, uint(i)))
+				settled.write(comment, make([]bool, len(comment)))
+				changed = true
+			}
+			if out.skip[i] && !skipped[i] {
+				changed = true
+				continue
+			}
+			settled.write(out.text[i:i+1], out.skip[i:i+1])
+		}
+		if comments[uint(len(out.text))] {
+			comment := emptyComment(analysis.DelimitersAt(regions, uint(len(out.text))))
+			settled.write(comment, make([]bool, len(comment)))
+			changed = true
+		}
+		if !changed {
+			return out.text, nil
+		}
+		out = settled
+	}
+}
+
+// markSkipped marks the indentation and line ending of tag in skip.
+func markSkipped(skip []bool, tag analysis.StandaloneTag) {
+	for i := tag.LineStart; i < tag.Node.StartByte(); i++ {
+		skip[i] = true
+	}
+	for i := tag.Node.EndByte(); i < tag.LineEnd; i++ {
+		skip[i] = true
+	}
+}
+
+// indentLines prefixes every line of src with indent, as a standalone
+// partial is indented by the whitespace before it.
+func indentLines(src []byte, indent string) []byte {
+	if indent == "" || len(src) == 0 {
+		return src
+	}
+	var b bytes.Buffer
+	b.WriteString(indent)
+	for i, c := range src {
+		b.WriteByte(c)
+		if c == '\n' && i < len(src)-1 {
+			b.WriteString(indent)
+		}
+	}
+	return b.Bytes()
+}
@@ -0,0 +1,185 @@
+package expand_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/expand"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/render"
+)
+
+func resolver(templates map[string]string) func(string) ([]byte, error) {
+	return func(name string) ([]byte, error) {
+		src, ok := templates[name]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return []byte(src), nil
+	}
+}
+
+func TestInline(t *testing.T) {
+	data := map[string]any{"a": true, "x": true, "name": "N"}
+	tests := []struct {
+		name      string
+		templates map[string]string
+		expected  string
+	}{
+		{
+			"inline partial",
+			map[string]string{"main": `<p>{{> greeting }}!</p>`, "greeting": "Hello, {{name}}"},
+			`<p>Hello, {{name}}!</p>`,
+		},
+		{
+			"standalone partial",
+			map[string]string{"main": "<ul>\n  {{> item}}\n</ul>\n", "item": "<li>\n  {{name}}\n</li>\n"},
+			"<ul>\n  <li>\n    {{name}}\n  </li>\n</ul>\n",
+		},
+		{
+			"nested partials",
+			map[string]string{"main": "<div>\n  {{>a}}\n</div>\n", "a": "<p>\n  {{>b}}\n</p>\n", "b": "{{name}}\n"},
+			"<div>\n  <p>\n    {{name}}\n  </p>\n</div>\n",
+		},
+		{
+			"standalone tags kept in the partial",
+			map[string]string{"main": "<ul>\n  {{>items}}\n</ul>\n", "items": "{{#a}}\n<li>{{name}}</li>\n{{/a}}\n"},
+			"<ul>\n  {{#a}}\n  <li>{{name}}</li>\n  {{/a}}\n</ul>\n",
+		},
+		{
+			"standalone tag joined to the line around an inline partial",
+			map[string]string{"main": "a {{>p}} b", "p": "{{#x}}\nhi\n{{/x}}\n"},
+			"a {{#x}}hi\n{{/x}}\n b",
+		},
+		{
+			"tag kept from becoming standalone",
+			map[string]string{"main": "  {{#a}}{{>p}}\n{{/a}}\n", "p": ""},
+			"  {{#a}}{{! }}\n{{/a}}\n",
+		},
+		{
+			"comment written with custom delimiters",
+			map[string]string{"main": "  {{>p}}{{=<% %>=}}\n<%name%>\n", "p": ""},
+			"  {{=<% %>=}}<%! %>\n<%name%>\n",
+		},
+	}
+	for _, test := range tests {
+		got, err := expand.Inline("main", resolver(test.templates), 10)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if string(got) != test.expected {
+			t.Errorf("%s: Inline() =\n%q\nwant\n%q", test.name, got, test.expected)
+		}
+
+		want, err := render.Render([]byte(test.templates["main"]), data, render.WithPartials(resolver(test.templates)))
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		rendered, err := render.Render(got, data)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if string(rendered) != string(want) {
+			t.Errorf("%s: flattened template renders\n%q\nwant\n%q", test.name, rendered, want)
+		}
+	}
+}
+
+// TestInlineSpec checks that the partials suite of the Mustache spec renders
+// the same flattened.
+func TestInlineSpec(t *testing.T) {
+	raw, err := os.ReadFile("../render/testdata/spec/partials.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var suite struct {
+		Tests []struct {
+			Name     string            `json:"name"`
+			Data     any               `json:"data"`
+			Template string            `json:"template"`
+			Partials map[string]string `json:"partials"`
+			Expected string            `json:"expected"`
+		} `json:"tests"`
+	}
+	if err := json.Unmarshal(raw, &suite); err != nil {
+		t.Fatal(err)
+	}
+	unflattenable := map[string]bool{"Failed Lookup": true, "Recursion": true}
+	for _, test := range suite.Tests {
+		templates := map[string]string{"\x00main": test.Template}
+		for name, src := range test.Partials {
+			templates[name] = src
+		}
+		got, err := expand.Inline("\x00main", resolver(templates), 10)
+		if unflattenable[test.Name] {
+			if err == nil {
+				t.Errorf("%s: Inline() = %q, want an error", test.Name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.Name, err)
+			continue
+		}
+		rendered, err := render.Render(got, test.Data)
+		if err != nil {
+			t.Errorf("%s: %v", test.Name, err)
+			continue
+		}
+		if string(rendered) != test.Expected {
+			t.Errorf("%s: flattened %q renders %q, want %q", test.Name, got, rendered, test.Expected)
+		}
+	}
+}
+
+func TestInlineErrors(t *testing.T) {
+	tests := []struct {
+		templates map[string]string
+		maxDepth  int
+		message   string
+	}{
+		{map[string]string{"main": "{{>missing}}"}, 10, `expand: resolving partial "missing": file does not exist`},
+		{map[string]string{"main": "{{>a}}", "a": "{{#x}}{{>a}}{{/x}}"}, 10, `expand: partial "a" includes itself`},
+		{map[string]string{"main": "{{>a}}", "a": "{{>b}}", "b": "{{>c}}", "c": ""}, 2, "expand: partials nested more than 2 deep"},
+		{map[string]string{"main": "{{#a}}"}, 10, "expand: template has syntax errors: main"},
+	}
+	for _, test := range tests {
+		_, err := expand.Inline("main", resolver(test.templates), test.maxDepth)
+		if err == nil || err.Error() != test.message {
+			t.Errorf("Inline(%v) error = %v, want %s", test.templates, err, test.message)
+		}
+	}
+
+	_, err := expand.Inline("main", resolver(map[string]string{"main": "<p>{{>a}}</p>", "a": "{{#x}}"}), 10)
+	if !errors.Is(err, expand.ErrSyntax) {
+		t.Errorf("Inline() error = %v, want ErrSyntax", err)
+	}
+}
+
+func ExampleInline() {
+	templates := map[string]string{
+		"page": "<ul>\n  {{#items}}\n  {{> item}}\n  {{/items}}\n</ul>\n",
+		"item": "<li>{{name}}</li>\n",
+	}
+	flat, err := expand.Inline("page", func(name string) ([]byte, error) {
+		src, ok := templates[name]
+		if !ok {
+			return nil, fmt.Errorf("no template %q", name)
+		}
+		return []byte(src), nil
+	}, 10)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Print(strings.ReplaceAll(string(flat), "\n", "⏎\n"))
+	// Output:
+	// <ul>⏎
+	//   {{#items}}⏎
+	//   <li>{{name}}</li>⏎
+	//   {{/items}}⏎
+	// </ul>⏎
+}
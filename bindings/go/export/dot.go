@@ -0,0 +1,85 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// maxSnippet is the number of characters of source shown in a DOT label.
+const maxSnippet = 24
+
+// DOT returns tree, whose source is src, as a Graphviz digraph. Each node is
+// labelled with its kind and a snippet of its source; edges into nodes that
+// occupy a field are labelled with the field name. Named nodes are boxes,
+// anonymous tokens are ellipses, and error and missing nodes are red.
+func DOT(tree *tree_sitter.Tree, src []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("digraph tree {\n")
+	buf.WriteString("  node [fontname=\"monospace\"];\n")
+	cursor := tree.Walk()
+	defer cursor.Close()
+	next := 0
+	var visit func(parent int)
+	visit = func(parent int) {
+		n := cursor.Node()
+		id := next
+		next++
+		fmt.Fprintf(&buf, "  n%d [label=%s%s];\n", id, dotString(n.Kind()+"\n"+snippet(n.Utf8Text(src))), dotStyle(n))
+		if parent >= 0 {
+			if field := cursor.FieldName(); field != "" {
+				fmt.Fprintf(&buf, "  n%d -> n%d [label=%s];\n", parent, id, dotString(field))
+			} else {
+				fmt.Fprintf(&buf, "  n%d -> n%d;\n", parent, id)
+			}
+		}
+		if cursor.GotoFirstChild() {
+			for {
+				visit(id)
+				if !cursor.GotoNextSibling() {
+					break
+				}
+			}
+			cursor.GotoParent()
+		}
+	}
+	visit(-1)
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+func dotStyle(n *tree_sitter.Node) string {
+	var attrs []string
+	if n.IsNamed() {
+		attrs = append(attrs, "shape=box")
+	} else {
+		attrs = append(attrs, "shape=ellipse")
+	}
+	if n.IsError() || n.IsMissing() {
+		attrs = append(attrs, "color=red", "fontcolor=red")
+	}
+	if n.IsMissing() {
+		attrs = append(attrs, "style=dashed")
+	}
+	return ", " + strings.Join(attrs, ", ")
+}
+
+// snippet shortens text to maxSnippet characters, with whitespace runs
+// collapsed to a single space.
+func snippet(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= maxSnippet {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:maxSnippet-1]) + "…"
+}
+
+// dotString quotes s as a DOT string, keeping newlines as line breaks.
+func dotString(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
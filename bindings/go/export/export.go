@@ -0,0 +1,86 @@
+// Package export converts htmlmustache parse trees to formats for tools
+// outside Go: a stable JSON form for jq or analysis pipelines in other
+// languages, Graphviz DOT for looking at the tree, and S-expressions to diff
+// against tree-sitter parse.
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// Node is the exported form of a syntax tree node. Fields are emitted in
+// declaration order, so the JSON is stable across runs.
+type Node struct {
+	Kind string `json:"kind"`
+	// Field is the name of the field the node occupies in its parent, if
+	// any.
+	Field      string `json:"field,omitempty"`
+	Named      bool   `json:"named"`
+	Error      bool   `json:"error,omitempty"`
+	Missing    bool   `json:"missing,omitempty"`
+	StartByte  uint   `json:"startByte"`
+	EndByte    uint   `json:"endByte"`
+	StartPoint Point  `json:"startPoint"`
+	EndPoint   Point  `json:"endPoint"`
+	// Text is the source of a leaf node. It is empty for nodes with
+	// children.
+	Text     string `json:"text,omitempty"`
+	Children []Node `json:"children,omitempty"`
+}
+
+// Point is a zero-based row and byte column.
+type Point struct {
+	Row    uint `json:"row"`
+	Column uint `json:"column"`
+}
+
+// FromNode converts the tree rooted at n.
+func FromNode(n *tree_sitter.Node, src []byte) Node {
+	cursor := n.Walk()
+	defer cursor.Close()
+	return fromCursor(cursor, src)
+}
+
+func fromCursor(cursor *tree_sitter.TreeCursor, src []byte) Node {
+	n := cursor.Node()
+	start, end := n.StartPosition(), n.EndPosition()
+	out := Node{
+		Kind:       n.Kind(),
+		Field:      cursor.FieldName(),
+		Named:      n.IsNamed(),
+		Error:      n.IsError(),
+		Missing:    n.IsMissing(),
+		StartByte:  n.StartByte(),
+		EndByte:    n.EndByte(),
+		StartPoint: Point{start.Row, start.Column},
+		EndPoint:   Point{end.Row, end.Column},
+	}
+	if !cursor.GotoFirstChild() {
+		out.Text = n.Utf8Text(src)
+		return out
+	}
+	for {
+		out.Children = append(out.Children, fromCursor(cursor, src))
+		if !cursor.GotoNextSibling() {
+			break
+		}
+	}
+	cursor.GotoParent()
+	return out
+}
+
+// JSON returns the JSON form of tree, whose source is src. Unlike
+// json.Marshal it leaves <, > and & unescaped, as they are common in
+// template text.
+func JSON(tree *tree_sitter.Tree, src []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(FromNode(tree.RootNode(), src)); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
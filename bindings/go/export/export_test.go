@@ -0,0 +1,162 @@
+package export_test
+
+import (
+	"strings"
+	"testing"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/export"
+)
+
+func parse(t *testing.T, src []byte) *tree_sitter.Tree {
+	t.Helper()
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		t.Fatal(err)
+	}
+	return parser.Parse(src, nil)
+}
+
+func TestJSON(t *testing.T) {
+	src := []byte("<b>\n{{x}}</b>")
+	tree := parse(t, src)
+	defer tree.Close()
+
+	got, err := export.JSON(tree, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"kind":"document","named":true,"startByte":0,"endByte":13,"startPoint":{"row":0,"column":0},"endPoint":{"row":1,"column":9},"children":[` +
+		`{"kind":"html_element","named":true,"startByte":0,"endByte":13,"startPoint":{"row":0,"column":0},"endPoint":{"row":1,"column":9},"children":[` +
+		`{"kind":"html_start_tag","field":"open","named":true,"startByte":0,"endByte":3,"startPoint":{"row":0,"column":0},"endPoint":{"row":0,"column":3},"children":[` +
+		`{"kind":"<","named":false,"startByte":0,"endByte":1,"startPoint":{"row":0,"column":0},"endPoint":{"row":0,"column":1},"text":"<"},` +
+		`{"kind":"html_tag_name","field":"name","named":true,"startByte":1,"endByte":2,"startPoint":{"row":0,"column":1},"endPoint":{"row":0,"column":2},"text":"b"},` +
+		`{"kind":">","named":false,"startByte":2,"endByte":3,"startPoint":{"row":0,"column":2},"endPoint":{"row":0,"column":3},"text":">"}]},` +
+		`{"kind":"mustache_interpolation","field":"content","named":true,"startByte":4,"endByte":9,"startPoint":{"row":1,"column":0},"endPoint":{"row":1,"column":5},"children":[` +
+		`{"kind":"{{","named":false,"startByte":4,"endByte":6,"startPoint":{"row":1,"column":0},"endPoint":{"row":1,"column":2},"text":"{{"},` +
+		`{"kind":"mustache_identifier","field":"expression","named":true,"startByte":6,"endByte":7,"startPoint":{"row":1,"column":2},"endPoint":{"row":1,"column":3},"text":"x"},` +
+		`{"kind":"}}","named":false,"startByte":7,"endByte":9,"startPoint":{"row":1,"column":3},"endPoint":{"row":1,"column":5},"text":"}}"}]},` +
+		`{"kind":"html_end_tag","field":"close","named":true,"startByte":9,"endByte":13,"startPoint":{"row":1,"column":5},"endPoint":{"row":1,"column":9},"children":[` +
+		`{"kind":"</","named":false,"startByte":9,"endByte":11,"startPoint":{"row":1,"column":5},"endPoint":{"row":1,"column":7},"text":"</"},` +
+		`{"kind":"html_tag_name","field":"name","named":true,"startByte":11,"endByte":12,"startPoint":{"row":1,"column":7},"endPoint":{"row":1,"column":8},"text":"b"},` +
+		`{"kind":">","named":false,"startByte":12,"endByte":13,"startPoint":{"row":1,"column":8},"endPoint":{"row":1,"column":9},"text":">"}]}]}]}`
+	if string(got) != expected {
+		t.Errorf("JSON() =\n%s\nwant\n%s", got, expected)
+	}
+}
+
+func TestFromNodeErrors(t *testing.T) {
+	src := []byte("<div>{{#a}}</div>")
+	tree := parse(t, src)
+	defer tree.Close()
+
+	var errors, missing int
+	var visit func(export.Node)
+	visit = func(n export.Node) {
+		if n.Error {
+			errors++
+		}
+		if n.Missing {
+			missing++
+		}
+		for _, child := range n.Children {
+			visit(child)
+		}
+	}
+	root := export.FromNode(tree.RootNode(), src)
+	visit(root)
+	if errors+missing == 0 {
+		t.Errorf("FromNode() found no error or missing nodes in %q", src)
+	}
+	if root.Children[0].StartByte != 0 {
+		t.Errorf("first child starts at %d", root.Children[0].StartByte)
+	}
+}
+
+func TestDOT(t *testing.T) {
+	src := []byte("<p class=\"a\">\n  {{x}}</p>")
+	tree := parse(t, src)
+	defer tree.Close()
+
+	got := string(export.DOT(tree, src))
+	for _, want := range []string{
+		"digraph tree {\n",
+		`  n0 [label="document\n<p class=\"a\"> {{x}}</p>", shape=box];`,
+		`  n1 [label="html_element\n<p class=\"a\"> {{x}}</p>", shape=box];`,
+		`  n3 [label="<\n<", shape=ellipse];`,
+		"  n0 -> n1;\n",
+		"  n1 -> n2 [label=\"open\"];\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("DOT() missing %q in\n%s", want, got)
+		}
+	}
+
+	src = []byte("<div>{{#section_with_a_long_name}}</div>")
+	tree = parse(t, src)
+	defer tree.Close()
+	got = string(export.DOT(tree, src))
+	if !strings.Contains(got, `{{#section_with_a_long_…"`) {
+		t.Errorf("DOT() did not truncate snippets:\n%s", got)
+	}
+	if !strings.Contains(got, "color=red") {
+		t.Errorf("DOT() did not mark errors:\n%s", got)
+	}
+}
+
+func TestSexp(t *testing.T) {
+	src := []byte("<p class=\"a\">\n  {{#x}}{{y}}{{/x}}</p>")
+	tree := parse(t, src)
+	defer tree.Close()
+
+	if got, want := string(export.Sexp(tree, export.SexpOptions{Fields: true})), tree.RootNode().ToSexp(); got != want {
+		t.Errorf("Sexp() =\n%s\nwant ToSexp()\n%s", got, want)
+	}
+
+	got := string(export.Sexp(tree, export.SexpOptions{Points: true, Pretty: true}))
+	want := `(document [0, 0] - [1, 23]
+  (html_element [0, 0] - [1, 23]
+    (html_start_tag [0, 0] - [0, 13]
+      (html_tag_name [0, 1] - [0, 2])
+      (html_attribute [0, 3] - [0, 12]
+        (html_attribute_name [0, 3] - [0, 8])
+        (html_quoted_attribute_value [0, 9] - [0, 12]
+          (html_attribute_value [0, 10] - [0, 11]))))
+    (mustache_section [1, 2] - [1, 19]
+      (mustache_section_begin [1, 2] - [1, 8]
+        (mustache_tag_name [1, 5] - [1, 6]))
+      (mustache_interpolation [1, 8] - [1, 13]
+        (mustache_identifier [1, 10] - [1, 11]))
+      (mustache_section_end [1, 13] - [1, 19]
+        (mustache_tag_name [1, 16] - [1, 17])))
+    (html_end_tag [1, 19] - [1, 23]
+      (html_tag_name [1, 21] - [1, 22]))))`
+	if got != want {
+		t.Errorf("Sexp(Points, Pretty) =\n%s\nwant\n%s", got, want)
+	}
+
+	src = []byte("<b>{{x}}</b>")
+	tree = parse(t, src)
+	defer tree.Close()
+	got = string(export.Sexp(tree, export.SexpOptions{Anonymous: true, Bytes: true}))
+	want = `(document [0..12] (html_element [0..12] (html_start_tag [0..3] ("<" [0..1]) (html_tag_name [1..2]) (">" [2..3])) ` +
+		`(mustache_interpolation [3..8] ("{{" [3..5]) (mustache_identifier [5..6]) ("}}" [6..8])) ` +
+		`(html_end_tag [8..12] ("</" [8..10]) (html_tag_name [10..11]) (">" [11..12]))))`
+	if got != want {
+		t.Errorf("Sexp(Anonymous, Bytes) =\n%s\nwant\n%s", got, want)
+	}
+	got = string(export.Sexp(tree, export.SexpOptions{Anonymous: true}))
+	if want := `(document (html_element (html_start_tag "<" (html_tag_name) ">") (mustache_interpolation "{{" (mustache_identifier) "}}") (html_end_tag "</" (html_tag_name) ">")))`; got != want {
+		t.Errorf("Sexp(Anonymous) =\n%s\nwant\n%s", got, want)
+	}
+
+	src = []byte("<div>{{#a}}</div>")
+	tree = parse(t, src)
+	defer tree.Close()
+	if got, want := string(export.Sexp(tree, export.SexpOptions{Fields: true})), tree.RootNode().ToSexp(); got != want {
+		t.Errorf("Sexp() of an error tree =\n%s\nwant ToSexp()\n%s", got, want)
+	}
+}
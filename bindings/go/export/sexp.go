@@ -0,0 +1,105 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// SexpOptions configures Sexp. The zero value writes named nodes only, on
+// one line.
+type SexpOptions struct {
+	// Anonymous includes anonymous nodes, written as their quoted kind.
+	Anonymous bool
+	// Fields writes "name: " before nodes that occupy a field.
+	Fields bool
+	// Points writes the start and end position of each node after its kind,
+	// as "[row, column] - [row, column]".
+	Points bool
+	// Bytes writes the byte range of each node after its kind, and after its
+	// position if Points is set, as "[start..end]".
+	Bytes bool
+	// Pretty puts each node on a line of its own, indented two spaces per
+	// level of nesting.
+	Pretty bool
+}
+
+// Sexp returns tree as an S-expression. With Fields set, it matches
+// Node.ToSexp, and with Fields, Points and Pretty set, the output of
+// tree-sitter parse, except that error nodes without children are written
+// as (ERROR) rather than with the character they skipped.
+func Sexp(tree *tree_sitter.Tree, opts SexpOptions) []byte {
+	var buf bytes.Buffer
+	cursor := tree.Walk()
+	defer cursor.Close()
+	writeSexp(&buf, cursor, opts, 0)
+	return buf.Bytes()
+}
+
+// writeSexp writes the node at cursor and its descendants. depth is the
+// nesting of the node among those written.
+func writeSexp(buf *bytes.Buffer, cursor *tree_sitter.TreeCursor, opts SexpOptions, depth int) {
+	n := cursor.Node()
+	if depth > 0 {
+		if opts.Pretty {
+			buf.WriteByte('\n')
+			buf.WriteString(strings.Repeat("  ", depth))
+		} else {
+			buf.WriteByte(' ')
+		}
+	}
+	if field := cursor.FieldName(); opts.Fields && field != "" {
+		buf.WriteString(field)
+		buf.WriteString(": ")
+	}
+
+	ranged := opts.Points || opts.Bytes
+	switch {
+	case n.IsMissing():
+		buf.WriteString("(MISSING ")
+		writeKind(buf, n)
+	case n.IsNamed():
+		buf.WriteByte('(')
+		buf.WriteString(n.Kind())
+	case ranged:
+		buf.WriteByte('(')
+		writeKind(buf, n)
+	default:
+		writeKind(buf, n)
+	}
+	if opts.Points {
+		start, end := n.StartPosition(), n.EndPosition()
+		fmt.Fprintf(buf, " [%d, %d] - [%d, %d]", start.Row, start.Column, end.Row, end.Column)
+	}
+	if opts.Bytes {
+		fmt.Fprintf(buf, " [%d..%d]", n.StartByte(), n.EndByte())
+	}
+
+	if cursor.GotoFirstChild() {
+		for {
+			if child := cursor.Node(); opts.Anonymous || child.IsNamed() || child.IsMissing() {
+				writeSexp(buf, cursor, opts, depth+1)
+			}
+			if !cursor.GotoNextSibling() {
+				break
+			}
+		}
+		cursor.GotoParent()
+	}
+	if n.IsNamed() || n.IsMissing() || ranged {
+		buf.WriteByte(')')
+	}
+}
+
+// writeKind writes the kind of n, quoted if n is anonymous.
+func writeKind(buf *bytes.Buffer, n *tree_sitter.Node) {
+	if n.IsNamed() {
+		buf.WriteString(n.Kind())
+		return
+	}
+	buf.WriteByte('"')
+	buf.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(n.Kind()))
+	buf.WriteByte('"')
+}
@@ -0,0 +1,149 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// blockElements are the elements whose default CSS display is not inline,
+// so whitespace around them does not render.
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"body": true, "caption": true, "center": true, "col": true,
+	"colgroup": true, "dd": true, "details": true, "dialog": true,
+	"dir": true, "div": true, "dl": true, "dt": true, "fieldset": true,
+	"figcaption": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"head": true, "header": true, "hgroup": true, "hr": true, "html": true,
+	"legend": true, "li": true, "link": true, "listing": true, "main": true,
+	"menu": true, "meta": true, "nav": true, "ol": true, "p": true,
+	"plaintext": true, "pre": true, "script": true, "search": true,
+	"section": true, "style": true,
+	"summary": true, "table": true, "tbody": true, "td": true,
+	"template": true, "tfoot": true, "th": true, "thead": true,
+	"title": true, "tr": true, "ul": true, "xmp": true,
+}
+
+// preservesContent reports whether the element's content is whitespace
+// sensitive and must be copied as written.
+func preservesContent(tag string) bool {
+	return tag == "pre" || tag == "textarea"
+}
+
+// isBlock reports whether n is written on lines of its own.
+func (f *formatter) isBlock(n *tree_sitter.Node) bool {
+	switch n.Kind() {
+	case "html_element":
+		if blockElements[tagName(n, f.src)] {
+			return true
+		}
+		return f.hasBlockChild(n)
+	case "html_script_element", "html_style_element", "html_raw_element",
+		"html_doctype", "html_processing_instruction", "frontmatter":
+		return true
+	case "html_comment", "html_conditional_comment", "mustache_comment":
+		return strings.Contains(f.text(n), "\n") || f.standalone(n)
+	case "mustache_partial", "mustache_set_delimiter":
+		return f.standalone(n)
+	case "mustache_section", "mustache_inverted_section":
+		return f.standaloneSection(n) || f.hasStandaloneTag(n) || f.hasBlockChild(n)
+	}
+	return false
+}
+
+// sectionTags returns the open, close and {{else}} tags of a section.
+func sectionTags(n *tree_sitter.Node) []*tree_sitter.Node {
+	var tags []*tree_sitter.Node
+	for _, child := range walk.Children(n) {
+		switch child.Kind() {
+		case "mustache_section_begin", "mustache_inverted_section_begin",
+			"mustache_section_end", "mustache_inverted_section_end",
+			"mustache_erroneous_section_end", "mustache_erroneous_inverted_section_end",
+			"mustache_else":
+			tags = append(tags, child)
+		}
+	}
+	return tags
+}
+
+func (f *formatter) hasBlockChild(n *tree_sitter.Node) bool {
+	for _, child := range walk.Children(n) {
+		if f.isBlock(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// standaloneSection reports whether every tag of a section is alone on its
+// source line.
+func (f *formatter) standaloneSection(n *tree_sitter.Node) bool {
+	tags := sectionTags(n)
+	for _, tag := range tags {
+		if !f.standalone(tag) {
+			return false
+		}
+	}
+	return len(tags) > 0
+}
+
+// hasStandaloneTag reports whether any tag of a section is alone on its
+// source line. Such a section can't be joined into a line of inline content.
+func (f *formatter) hasStandaloneTag(n *tree_sitter.Node) bool {
+	for _, tag := range sectionTags(n) {
+		if f.standalone(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// standalone reports whether n is the only thing on its source line, which
+// makes Mustache drop the whole line when rendering.
+func (f *formatter) standalone(n *tree_sitter.Node) bool {
+	floor := 0
+	if bytes.HasPrefix(f.src, bom) {
+		floor = len(bom)
+	}
+	for i := int(n.StartByte()) - 1; i >= floor && f.src[i] != '\n'; i-- {
+		if f.src[i] != ' ' && f.src[i] != '\t' {
+			return false
+		}
+	}
+	for i := int(n.EndByte()); i < len(f.src) && f.src[i] != '\n'; i++ {
+		if f.src[i] != ' ' && f.src[i] != '\t' && f.src[i] != '\r' {
+			return false
+		}
+	}
+	return true
+}
+
+// mustStayInline reports whether breaking an element's content onto its own
+// line would turn a lone mustache tag into a standalone one.
+func mustStayInline(content []*tree_sitter.Node) bool {
+	if len(content) != 1 {
+		return false
+	}
+	switch content[0].Kind() {
+	case "mustache_comment", "mustache_partial", "mustache_set_delimiter":
+		return true
+	}
+	return false
+}
+
+// tagName returns the lowercased tag name of an element.
+func tagName(n *tree_sitter.Node, src []byte) string {
+	for _, child := range walk.Children(n) {
+		switch child.Kind() {
+		case "html_start_tag", "html_self_closing_tag":
+			if name := walk.ChildOfKind(child, "html_tag_name"); name != nil {
+				return strings.ToLower(string(src[name.StartByte():name.EndByte()]))
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,299 @@
+package format
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ConfigFile is the name of the file OptionsFor reads options from.
+const ConfigFile = ".htmlmustache.toml"
+
+// OptionsFor returns the options for formatting the file at path. They come
+// from the nearest ConfigFile in the file's directory or above, whose keys
+// are those of the npm package's .htmlmustache.jsonc:
+//
+//	indentSize = 4
+//	useTabs = false
+//	printWidth = 100
+//	attributeWrap = 3
+//	quotes = "single" # or "double", "preserve"
+//	sortAttributes = true
+//
+// The indent_style, indent_size and tab_width properties of the
+// .editorconfig files that apply to path take precedence over the
+// ConfigFile, as they do in the language server. Options neither sets keep
+// their zero value.
+func OptionsFor(path string) (Options, error) {
+	var opts Options
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return opts, err
+	}
+	dir := filepath.Dir(abs)
+
+	for d := dir; ; {
+		src, err := os.ReadFile(filepath.Join(d, ConfigFile))
+		if err == nil {
+			if err := decodeConfig(src, &opts); err != nil {
+				return opts, fmt.Errorf("format: %s: %w", filepath.Join(d, ConfigFile), err)
+			}
+			break
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return opts, err
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			break
+		}
+		d = parent
+	}
+
+	props, err := editorConfig(abs)
+	if err != nil {
+		return opts, err
+	}
+	switch props["indent_style"] {
+	case "tab":
+		opts.UseTabs = true
+	case "space":
+		opts.UseTabs = false
+	}
+	size := props["indent_size"]
+	if size == "tab" {
+		size = props["tab_width"]
+	}
+	if n, err := strconv.Atoi(size); err == nil && n > 0 {
+		opts.IndentSize = n
+	}
+	return opts, nil
+}
+
+// config is the contents of a ConfigFile. Keys it does not set are nil.
+type config struct {
+	IndentSize     *int        `toml:"indentSize"`
+	UseTabs        *bool       `toml:"useTabs"`
+	PrintWidth     *int        `toml:"printWidth"`
+	AttributeWrap  *int        `toml:"attributeWrap"`
+	Quotes         *QuoteStyle `toml:"quotes"`
+	SortAttributes *bool       `toml:"sortAttributes"`
+}
+
+// decodeConfig sets the options src, a ConfigFile, sets in opts.
+func decodeConfig(src []byte, opts *Options) error {
+	var c config
+	meta, err := toml.Decode(string(src), &c)
+	if err != nil {
+		return err
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return fmt.Errorf("unknown key %q", undecoded[0].String())
+	}
+	if c.IndentSize != nil {
+		opts.IndentSize = *c.IndentSize
+	}
+	if c.UseTabs != nil {
+		opts.UseTabs = *c.UseTabs
+	}
+	if c.PrintWidth != nil {
+		opts.PrintWidth = *c.PrintWidth
+	}
+	if c.AttributeWrap != nil {
+		opts.AttributeWrap = *c.AttributeWrap
+	}
+	if c.Quotes != nil {
+		opts.Quotes = *c.Quotes
+	}
+	if c.SortAttributes != nil {
+		opts.SortAttributes = *c.SortAttributes
+	}
+	return nil
+}
+
+// MarshalText returns the name of s in a ConfigFile: "double", "single" or
+// "preserve".
+func (s QuoteStyle) MarshalText() ([]byte, error) {
+	switch s {
+	case DoubleQuotes:
+		return []byte("double"), nil
+	case SingleQuotes:
+		return []byte("single"), nil
+	case PreserveQuotes:
+		return []byte("preserve"), nil
+	}
+	return nil, fmt.Errorf("format: unknown quote style %d", int(s))
+}
+
+// UnmarshalText sets s from a name returned by MarshalText.
+func (s *QuoteStyle) UnmarshalText(text []byte) error {
+	for _, style := range []QuoteStyle{DoubleQuotes, SingleQuotes, PreserveQuotes} {
+		if name, _ := style.MarshalText(); bytes.Equal(text, name) {
+			*s = style
+			return nil
+		}
+	}
+	return fmt.Errorf("format: unknown quote style %q", text)
+}
+
+// editorConfig returns the EditorConfig properties that apply to the file
+// at path, an absolute path, with keys and values lowercased. Files nearer
+// to path, and later sections in a file, take precedence.
+func editorConfig(path string) (map[string]string, error) {
+	type file struct {
+		dir      string
+		sections []editorConfigSection
+	}
+	var files []file
+	for dir := filepath.Dir(path); ; {
+		src, err := os.ReadFile(filepath.Join(dir, ".editorconfig"))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if err == nil {
+			root, sections := parseEditorConfig(src)
+			files = append(files, file{dir, sections})
+			if root {
+				break
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	props := map[string]string{}
+	for i := len(files) - 1; i >= 0; i-- {
+		rel, err := filepath.Rel(files[i].dir, path)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range files[i].sections {
+			if !s.glob.MatchString(filepath.ToSlash(rel)) {
+				continue
+			}
+			for key, value := range s.props {
+				if value == "unset" {
+					delete(props, key)
+				} else {
+					props[key] = value
+				}
+			}
+		}
+	}
+	return props, nil
+}
+
+type editorConfigSection struct {
+	glob  *regexp.Regexp
+	props map[string]string
+}
+
+// parseEditorConfig returns whether src, an .editorconfig file, has
+// root = true in its preamble, and its sections. Malformed lines and
+// sections are skipped.
+func parseEditorConfig(src []byte) (root bool, sections []editorConfigSection) {
+	var current *editorConfigSection
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';':
+		case line[0] == '[':
+			current = nil
+			if end := strings.LastIndexByte(line, ']'); end > 0 {
+				if glob, err := editorConfigGlob(line[1:end]); err == nil {
+					sections = append(sections, editorConfigSection{glob: glob, props: map[string]string{}})
+					current = &sections[len(sections)-1]
+				}
+			}
+		default:
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				continue
+			}
+			key = strings.ToLower(strings.TrimSpace(key))
+			value = strings.ToLower(strings.TrimSpace(value))
+			if current != nil {
+				current.props[key] = value
+			} else if len(sections) == 0 && key == "root" {
+				root = value == "true"
+			}
+		}
+	}
+	return root, sections
+}
+
+// editorConfigGlob compiles an EditorConfig section name, matched against
+// slash-separated paths relative to the .editorconfig file. Names without
+// a slash match files in any directory.
+func editorConfigGlob(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteByte('^')
+	if !strings.Contains(glob, "/") {
+		b.WriteString("(?:.*/)?")
+	}
+	glob = strings.TrimPrefix(glob, "/")
+	depth := 0
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end
+		case '{':
+			depth++
+			b.WriteString("(?:")
+		case '}':
+			if depth == 0 {
+				b.WriteString(`\}`)
+				continue
+			}
+			depth--
+			b.WriteByte(')')
+		case ',':
+			if depth == 0 {
+				b.WriteByte(',')
+				continue
+			}
+			b.WriteByte('|')
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	b.WriteByte('$')
+	return regexp.Compile(b.String())
+}
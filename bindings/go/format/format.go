@@ -0,0 +1,300 @@
+// Package format pretty-prints htmlmustache templates from their parse tree.
+//
+// Block-level elements and standalone mustache tags go on their own lines,
+// with their contents indented one level. Inline content stays on one line.
+// Attribute values are quoted, and raw text (<pre>, <textarea>, <script>,
+// <style>, multi-line comments) is copied as written. A mustache tag that
+// was standalone (alone on its line) stays standalone, and an inline one
+// stays inline, so rendered whitespace does not change.
+//
+// OptionsFor reads the options for a file from .htmlmustache.toml and
+// .editorconfig files.
+package format
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// ErrSyntax is returned for templates whose parse tree contains errors.
+// Such templates are not formatted, as the result could change their meaning.
+var ErrSyntax = errors.New("format: template has syntax errors")
+
+// Options configures Format. The zero value selects the defaults.
+type Options struct {
+	// IndentSize is the number of spaces per indent level. Defaults to 2.
+	IndentSize int
+	// UseTabs indents with one tab per level instead of spaces.
+	UseTabs bool
+	// PrintWidth is the line width up to which an element with only inline
+	// content is kept on a single line. Defaults to 80.
+	PrintWidth int
+	// AttributeWrap, if positive, is the number of attributes above which
+	// the start tag of a block-level element puts each attribute on a line
+	// of its own. Zero keeps start tags on one line.
+	AttributeWrap int
+	// Quotes selects the quotes attribute values are written with.
+	Quotes QuoteStyle
+	// SortAttributes orders the attributes of start tags by name. Mustache
+	// tags among the attributes keep their place, and attributes are only
+	// sorted among the others between the same tags.
+	SortAttributes bool
+}
+
+// QuoteStyle is how Format quotes attribute values.
+type QuoteStyle int
+
+const (
+	// DoubleQuotes writes values in double quotes, or in single quotes if
+	// they contain a double quote.
+	DoubleQuotes QuoteStyle = iota
+	// SingleQuotes writes values in single quotes, or in double quotes if
+	// they contain a single quote.
+	SingleQuotes
+	// PreserveQuotes keeps quoted values as written and double-quotes
+	// unquoted ones.
+	PreserveQuotes
+)
+
+// Format returns src pretty-printed according to opts.
+func Format(src []byte, opts Options) ([]byte, error) {
+	return FormatContext(context.Background(), src, opts)
+}
+
+// FormatContext is like Format, but stops and returns ctx.Err() if ctx is
+// done before src is parsed.
+func FormatContext(ctx context.Context, src []byte, opts Options) ([]byte, error) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		return nil, err
+	}
+	tree, err := tree_sitter_htmlmustache.ParseContext(ctx, parser, src)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+
+	root := tree.RootNode()
+	if root.HasError() {
+		return nil, ErrSyntax
+	}
+
+	f := newFormatter(src, opts)
+	f.children(walk.Children(root), 0)
+	return f.bytes(), nil
+}
+
+type formatter struct {
+	src    []byte
+	indent string
+	width  int
+	opts   Options
+	out    bytes.Buffer
+	// lastEnd is the source offset where the previously written line
+	// ended, used to carry blank lines over from the source.
+	lastEnd int
+}
+
+func newFormatter(src []byte, opts Options) *formatter {
+	size := opts.IndentSize
+	if size <= 0 {
+		size = 2
+	}
+	width := opts.PrintWidth
+	if width <= 0 {
+		width = 80
+	}
+	indent := strings.Repeat(" ", size)
+	if opts.UseTabs {
+		indent = "\t"
+	}
+	return &formatter{src: src, indent: indent, width: width, opts: opts, lastEnd: -1}
+}
+
+// bom is the UTF-8 byte order mark some editors save templates with.
+var bom = []byte("\xef\xbb\xbf")
+
+// bytes returns the output. A template with a byte order mark keeps it,
+// and one whose first line ends in CRLF has CRLF line endings throughout.
+func (f *formatter) bytes() []byte {
+	if f.out.Len() == 0 {
+		return nil
+	}
+	f.out.WriteByte('\n')
+	out := f.out.Bytes()
+	if i := bytes.IndexByte(f.src, '\n'); i > 0 && f.src[i-1] == '\r' {
+		var b bytes.Buffer
+		for j, c := range out {
+			if c == '\n' && (j == 0 || out[j-1] != '\r') {
+				b.WriteByte('\r')
+			}
+			b.WriteByte(c)
+		}
+		out = b.Bytes()
+	}
+	if bytes.HasPrefix(f.src, bom) {
+		out = append(append([]byte{}, bom...), out...)
+	}
+	return out
+}
+
+// line writes text as its own line at depth. start is the source offset of
+// the line's first node; a blank line before it in the source is kept.
+func (f *formatter) line(depth int, text string, start uint) {
+	if f.out.Len() > 0 {
+		f.out.WriteByte('\n')
+		if f.lastEnd >= 0 && int(start) > f.lastEnd &&
+			bytes.Count(f.src[f.lastEnd:start], []byte("\n")) > 1 {
+			f.out.WriteByte('\n')
+		}
+	}
+	f.out.WriteString(strings.Repeat(f.indent, depth))
+	f.out.WriteString(text)
+}
+
+// verbatim writes the source of n unchanged, starting at depth.
+func (f *formatter) verbatim(n *tree_sitter.Node, depth int) {
+	f.line(depth, f.text(n), n.StartByte())
+	f.lastEnd = int(n.EndByte())
+}
+
+// children writes a list of sibling nodes at depth. Consecutive inline nodes
+// are joined into one line; block nodes get lines of their own.
+func (f *formatter) children(nodes []*tree_sitter.Node, depth int) {
+	var run []*tree_sitter.Node
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if text := f.join(f.atomsOf(run)); text != "" {
+			f.line(depth, text, run[0].StartByte())
+			f.lastEnd = int(run[len(run)-1].EndByte())
+		}
+		run = nil
+	}
+	for _, n := range nodes {
+		if f.isBlock(n) {
+			flush()
+			f.block(n, depth)
+		} else {
+			run = append(run, n)
+		}
+	}
+	flush()
+}
+
+// block writes a block-level node at depth.
+func (f *formatter) block(n *tree_sitter.Node, depth int) {
+	switch n.Kind() {
+	case "html_element":
+		if preservesContent(tagName(n, f.src)) {
+			f.verbatim(n, depth)
+			return
+		}
+		f.element(n, depth)
+	case "mustache_section", "mustache_inverted_section":
+		// A section whose tags are not all standalone is copied as written:
+		// moving its tags onto lines of their own would change the
+		// whitespace it renders.
+		if !f.standaloneSection(n) {
+			f.verbatim(n, depth)
+			return
+		}
+		f.section(n, depth)
+	default:
+		f.verbatim(n, depth)
+	}
+}
+
+func (f *formatter) element(n *tree_sitter.Node, depth int) {
+	var start, end *tree_sitter.Node
+	var content []*tree_sitter.Node
+	for _, child := range walk.Children(n) {
+		switch child.Kind() {
+		case "html_start_tag", "html_self_closing_tag":
+			start = child
+		case "html_end_tag":
+			end = child
+		case "html_forced_end_tag":
+		default:
+			content = append(content, child)
+		}
+	}
+	name, attributes, selfClosing := f.tagParts(start)
+	wrap := f.opts.AttributeWrap > 0 && len(attributes) > f.opts.AttributeWrap
+
+	inline := !wrap
+	for _, child := range content {
+		if f.isBlock(child) {
+			inline = false
+			break
+		}
+	}
+	if inline {
+		atoms := f.atoms(n, nil)
+		text := f.join(atoms)
+		if len(content) == 0 || f.fits(depth, text) || mustStayInline(content) {
+			f.line(depth, text, n.StartByte())
+			f.lastEnd = int(n.EndByte())
+			return
+		}
+	}
+
+	if wrap {
+		f.line(depth, "<"+name, n.StartByte())
+		f.lastEnd = int(n.StartByte())
+		for _, attribute := range attributes {
+			f.line(depth+1, attribute, n.StartByte())
+		}
+		close := ">"
+		if selfClosing {
+			close = "/>"
+		}
+		f.line(depth, close, n.StartByte())
+	} else {
+		f.line(depth, joinTag(name, attributes, selfClosing), n.StartByte())
+	}
+	f.lastEnd = int(start.EndByte())
+	f.children(content, depth+1)
+	if end != nil {
+		f.line(depth, f.endTag(end), end.StartByte())
+		f.lastEnd = int(end.EndByte())
+	}
+}
+
+// section writes a standalone section: its tags on their own lines and its
+// content indented between them.
+func (f *formatter) section(n *tree_sitter.Node, depth int) {
+	var content []*tree_sitter.Node
+	flush := func() {
+		f.children(content, depth+1)
+		content = nil
+	}
+	tags := sectionTags(n)
+	for _, child := range walk.Children(n) {
+		if len(tags) > 0 && child.Id() == tags[0].Id() {
+			flush()
+			f.verbatim(child, depth)
+			tags = tags[1:]
+		} else {
+			content = append(content, child)
+		}
+	}
+	flush()
+}
+
+func (f *formatter) fits(depth int, text string) bool {
+	return len(strings.Repeat(f.indent, depth))+len(text) <= f.width && !strings.Contains(text, "\n")
+}
+
+func (f *formatter) text(n *tree_sitter.Node) string {
+	return string(f.src[n.StartByte():n.EndByte()])
+}
@@ -0,0 +1,250 @@
+package format_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/format"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "nested elements",
+			src:  "<div><ul><li>one</li><li>two</li></ul></div>",
+			want: "<div>\n  <ul>\n    <li>one</li>\n    <li>two</li>\n  </ul>\n</div>\n",
+		},
+		{
+			name: "inline content stays on one line",
+			src:  "<p>Hello,   <b>{{name}}</b>!</p>",
+			want: "<p>Hello, <b>{{name}}</b>!</p>\n",
+		},
+		{
+			name: "attribute quoting",
+			src:  `<a href=/home class='nav' title='say "hi"' hidden>x</a>`,
+			want: `<a href="/home" class="nav" title='say "hi"' hidden>x</a>` + "\n",
+		},
+		{
+			name: "boolean attributes keep their form",
+			src:  `<input disabled checked="" {{#sel}}selected{{/sel}}>`,
+			want: `<input disabled checked="" {{#sel}}selected{{/sel}}>` + "\n",
+		},
+		{
+			name: "self-closing elements",
+			src:  "<div><my-widget/><script src=\"a.js\" /></div>",
+			want: "<div>\n  <my-widget />\n  <script src=\"a.js\" />\n</div>\n",
+		},
+		{
+			name: "CRLF line endings and byte order marks are kept",
+			src:  "\xef\xbb\xbf{{#a}}\r\n<ul><li>x</li></ul>\r\n{{/a}}\r\n",
+			want: "\xef\xbb\xbf{{#a}}\r\n  <ul>\r\n    <li>x</li>\r\n  </ul>\r\n{{/a}}\r\n",
+		},
+		{
+			name: "standalone sections are indented",
+			src:  "<ul>\n{{#items}}\n<li>{{name}}</li>\n{{/items}}\n</ul>",
+			want: "<ul>\n  {{#items}}\n    <li>{{name}}</li>\n  {{/items}}\n</ul>\n",
+		},
+		{
+			name: "inline sections stay inline",
+			src:  "<p>{{#a}}yes{{/a}}{{^a}}no{{/a}}</p>",
+			want: "<p>{{#a}}yes{{/a}}{{^a}}no{{/a}}</p>\n",
+		},
+		{
+			name: "pre content is preserved",
+			src:  "<div><pre>  a\n    b</pre></div>",
+			want: "<div>\n  <pre>  a\n    b</pre>\n</div>\n",
+		},
+		{
+			name: "pre elements are preserved",
+			src:  "<div><pre>  <b class=x>a</b>\n    {{#s}}  <i>b</i>{{/s}}\n</pre></div>",
+			want: "<div>\n  <pre>  <b class=x>a</b>\n    {{#s}}  <i>b</i>{{/s}}\n</pre>\n</div>\n",
+		},
+		{
+			name: "blank lines are kept",
+			src:  "<p>a</p>\n\n\n<p>b</p>",
+			want: "<p>a</p>\n\n<p>b</p>\n",
+		},
+		{
+			name: "self-closing tags",
+			src:  "<div><br/><img src=x></div>",
+			want: "<div><br /><img src=\"x\"></div>\n",
+		},
+		{
+			name: "conditional comments are copied as written",
+			src:  "<body>\n<!--[if mso]><table><tr><td><![endif]-->\n<div>{{x}}</div>\n<!--[if mso]></td></tr></table><![endif]-->\n</body>",
+			want: "<body>\n  <!--[if mso]><table><tr><td><![endif]-->\n  <div>{{x}}</div>\n  <!--[if mso]></td></tr></table><![endif]-->\n</body>\n",
+		},
+		{
+			name: "custom delimiters",
+			src:  "{{=<% %>=}}\n<ul>\n<%#items%>\n<li><%name%> {{literal}}</li>\n<%/items%>\n<%! note %>\n</ul>",
+			want: "{{=<% %>=}}\n<ul>\n  <%#items%>\n    <li><%name%> {{literal}}</li>\n  <%/items%>\n  <%! note %>\n</ul>\n",
+		},
+		{
+			name: "inline tags with custom delimiters stay inline",
+			src:  "{{=<% %>=}}\n<p><%#a%>yes<%/a%>  <%> p %>!</p>",
+			want: "{{=<% %>=}}\n<p><%#a%>yes<%/a%> <%> p %>!</p>\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := format.Format([]byte(test.src), format.Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("Format() =\n%s\nwant\n%s", got, test.want)
+			}
+			again, err := format.Format(got, format.Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(again) != string(got) {
+				t.Errorf("Format() is not idempotent:\n%s\nthen\n%s", got, again)
+			}
+		})
+	}
+}
+
+func TestFormatOptions(t *testing.T) {
+	src := []byte("<div><p>a</p></div>")
+	got, err := format.Format(src, format.Options{UseTabs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "<div>\n\t<p>a</p>\n</div>\n"; string(got) != want {
+		t.Errorf("UseTabs: got %q, want %q", got, want)
+	}
+
+	got, err = format.Format([]byte("<div>some text that is long</div>"), format.Options{IndentSize: 4, PrintWidth: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "<div>\n    some text that is long\n</div>\n"; string(got) != want {
+		t.Errorf("PrintWidth: got %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		name string
+		opts format.Options
+		src  string
+		want string
+	}{
+		{
+			name: "attributes wrap above the threshold",
+			opts: format.Options{AttributeWrap: 2},
+			src:  `<div><form a=1 b=2 c=3><input type=text name=q /></form><p x y>z</p></div>`,
+			want: "<div>\n  <form\n    a=\"1\"\n    b=\"2\"\n    c=\"3\"\n  >\n    <input type=\"text\" name=\"q\" />\n  </form>\n  <p x y>z</p>\n</div>\n",
+		},
+		{
+			name: "self-closing tags wrap",
+			opts: format.Options{AttributeWrap: 1},
+			src:  `<div a b/>`,
+			want: "<div\n  a\n  b\n/>\n",
+		},
+		{
+			name: "single quotes",
+			opts: format.Options{Quotes: format.SingleQuotes},
+			src:  `<a href="/" title=x alt='it"s' data-x="it's">x</a>`,
+			want: `<a href='/' title='x' alt='it"s' data-x="it's">x</a>` + "\n",
+		},
+		{
+			name: "preserved quotes",
+			opts: format.Options{Quotes: format.PreserveQuotes},
+			src:  `<a href='/' title="t" alt=x>x</a>`,
+			want: `<a href='/' title="t" alt="x">x</a>` + "\n",
+		},
+		{
+			name: "sorted attributes",
+			opts: format.Options{SortAttributes: true},
+			src:  `<a id=b Class=c {{#x}}z=1{{/x}} title=t href=h>x</a>`,
+			want: `<a Class="c" id="b" {{#x}}z="1"{{/x}} href="h" title="t">x</a>` + "\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := format.Format([]byte(test.src), test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("Format() =\n%s\nwant\n%s", got, test.want)
+			}
+			again, err := format.Format(got, test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(again) != string(got) {
+				t.Errorf("Format() is not idempotent:\n%s\nthen\n%s", got, again)
+			}
+		})
+	}
+}
+
+func TestOptionsFor(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".editorconfig":               "root = true\n[*]\nindent_style = space\n[*.{mustache,hbs}]\nindent_size = 4\n[lib/**.mustache]\nindent_style = tab\n",
+		format.ConfigFile:             "printWidth = 100\nindentSize = 3\nquotes = \"single\"\nsortAttributes = true\n",
+		"lib/" + format.ConfigFile:    "attributeWrap = 2\nuseTabs = false\n",
+		"lib/deep/.editorconfig":      "[*]\nindent_size = unset\n",
+		"bad/" + format.ConfigFile:    "indentSize = 2\nwidth = 3\n",
+		"quotes/" + format.ConfigFile: "quotes = \"smart\"\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		path string
+		want format.Options
+	}{
+		{"page.mustache", format.Options{IndentSize: 4, PrintWidth: 100, Quotes: format.SingleQuotes, SortAttributes: true}},
+		{"page.html", format.Options{IndentSize: 3, PrintWidth: 100, Quotes: format.SingleQuotes, SortAttributes: true}},
+		{"lib/a.mustache", format.Options{IndentSize: 4, UseTabs: true, AttributeWrap: 2}},
+		{"lib/deep/a.mustache", format.Options{UseTabs: true, AttributeWrap: 2}},
+	}
+	for _, test := range tests {
+		got, err := format.OptionsFor(filepath.Join(dir, filepath.FromSlash(test.path)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("OptionsFor(%q) = %+v, want %+v", test.path, got, test.want)
+		}
+	}
+
+	for _, path := range []string{"bad/a.mustache", "quotes/a.mustache"} {
+		if _, err := format.OptionsFor(filepath.Join(dir, path)); err == nil {
+			t.Errorf("OptionsFor(%q) succeeded", path)
+		}
+	}
+}
+
+func TestFormatSyntaxError(t *testing.T) {
+	_, err := format.Format([]byte("<div>{{#a}}<p>x</p></div>"), format.Options{})
+	if !errors.Is(err, format.ErrSyntax) {
+		t.Errorf("Format() error = %v, want ErrSyntax", err)
+	}
+}
+
+func TestFormatContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := format.FormatContext(ctx, []byte("<p>x</p>"), format.Options{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("FormatContext() error = %v, want context.Canceled", err)
+	}
+}
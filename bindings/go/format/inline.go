@@ -0,0 +1,197 @@
+package format
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// atom is a piece of an inline line along with the source range it came
+// from. Adjacent atoms are separated by a space only where the source had
+// whitespace between them.
+type atom struct {
+	text       string
+	start, end uint
+}
+
+func (f *formatter) join(atoms []atom) string {
+	var b strings.Builder
+	for i, a := range atoms {
+		if i > 0 && a.start > atoms[i-1].end && hasSpace(f.src[atoms[i-1].end:a.start]) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(a.text)
+	}
+	return b.String()
+}
+
+func (f *formatter) atomsOf(nodes []*tree_sitter.Node) []atom {
+	var atoms []atom
+	for _, n := range nodes {
+		atoms = f.atoms(n, atoms)
+	}
+	return atoms
+}
+
+// atoms appends the inline rendering of n to atoms.
+func (f *formatter) atoms(n *tree_sitter.Node, atoms []atom) []atom {
+	switch n.Kind() {
+	case "html_element":
+		if preservesContent(tagName(n, f.src)) {
+			break
+		}
+		for _, child := range walk.Children(n) {
+			switch child.Kind() {
+			case "html_start_tag", "html_self_closing_tag":
+				atoms = append(atoms, f.atom(child, f.startTag(child)))
+			case "html_end_tag":
+				atoms = append(atoms, f.atom(child, f.endTag(child)))
+			case "html_forced_end_tag":
+			default:
+				atoms = f.atoms(child, atoms)
+			}
+		}
+		return atoms
+	case "mustache_section", "mustache_inverted_section":
+		for _, child := range walk.Children(n) {
+			atoms = f.atoms(child, atoms)
+		}
+		return atoms
+	case "text":
+		return append(atoms, f.atom(n, strings.Join(strings.Fields(f.text(n)), " ")))
+	}
+	return append(atoms, f.atom(n, f.text(n)))
+}
+
+func (f *formatter) atom(n *tree_sitter.Node, text string) atom {
+	return atom{text: text, start: n.StartByte(), end: n.EndByte()}
+}
+
+// startTag renders a start or self-closing tag with one space between
+// attributes and quoted attribute values.
+func (f *formatter) startTag(n *tree_sitter.Node) string {
+	return joinTag(f.tagParts(n))
+}
+
+// tagParts returns the name of a start or self-closing tag, its rendered
+// attributes in output order, and whether it is self-closing.
+func (f *formatter) tagParts(n *tree_sitter.Node) (name string, attributes []string, selfClosing bool) {
+	var run []*tree_sitter.Node
+	flush := func() {
+		if f.opts.SortAttributes {
+			sort.SliceStable(run, func(i, j int) bool {
+				return strings.ToLower(f.attributeName(run[i])) < strings.ToLower(f.attributeName(run[j]))
+			})
+		}
+		for _, attribute := range run {
+			attributes = append(attributes, f.attribute(attribute))
+		}
+		run = nil
+	}
+	for _, child := range walk.Children(n) {
+		switch child.Kind() {
+		case "<", ">":
+		case "/>":
+			selfClosing = true
+		case "html_tag_name":
+			name = f.text(child)
+		case "html_attribute":
+			run = append(run, child)
+		default:
+			flush()
+			attributes = append(attributes, f.attribute(child))
+		}
+	}
+	flush()
+	return name, attributes, selfClosing
+}
+
+func joinTag(name string, attributes []string, selfClosing bool) string {
+	var b strings.Builder
+	b.WriteByte('<')
+	b.WriteString(name)
+	for _, attribute := range attributes {
+		b.WriteByte(' ')
+		b.WriteString(attribute)
+	}
+	if selfClosing {
+		b.WriteString(" />")
+	} else {
+		b.WriteByte('>')
+	}
+	return b.String()
+}
+
+func (f *formatter) endTag(n *tree_sitter.Node) string {
+	if name := walk.ChildOfKind(n, "html_tag_name"); name != nil {
+		return "</" + f.text(name) + ">"
+	}
+	return f.text(n)
+}
+
+func (f *formatter) attribute(n *tree_sitter.Node) string {
+	switch n.Kind() {
+	case "html_attribute":
+		name := walk.ChildOfKind(n, "html_attribute_name")
+		if name == nil {
+			return f.text(n)
+		}
+		value := n.Child(n.ChildCount() - 1)
+		switch value.Kind() {
+		case "html_attribute_value", "mustache_interpolation":
+			return f.text(name) + "=" + f.quote(f.text(value))
+		case "html_quoted_attribute_value":
+			return f.text(name) + "=" + f.requote(f.text(value))
+		}
+		return f.text(name)
+	case "mustache_attribute":
+		if n.ChildCount() > 0 {
+			return f.attribute(n.Child(0))
+		}
+	case "mustache_section", "mustache_inverted_section":
+		var atoms []atom
+		for _, child := range walk.Children(n) {
+			atoms = append(atoms, f.atom(child, f.attribute(child)))
+		}
+		return f.join(atoms)
+	}
+	return f.text(n)
+}
+
+// attributeName returns the name of an html_attribute.
+func (f *formatter) attributeName(n *tree_sitter.Node) string {
+	if name := walk.ChildOfKind(n, "html_attribute_name"); name != nil {
+		return f.text(name)
+	}
+	return f.text(n)
+}
+
+// quote wraps an unquoted attribute value in the quotes of f.opts.Quotes,
+// or in the other quotes if it contains those.
+func (f *formatter) quote(value string) string {
+	preferred, other := `"`, "'"
+	if f.opts.Quotes == SingleQuotes {
+		preferred, other = other, preferred
+	}
+	if strings.Contains(value, preferred) {
+		return other + value + other
+	}
+	return preferred + value + preferred
+}
+
+// requote switches a quoted value to the quotes of f.opts.Quotes when it
+// does not contain any.
+func (f *formatter) requote(value string) string {
+	if f.opts.Quotes == PreserveQuotes || len(value) < 2 {
+		return value
+	}
+	return f.quote(value[1 : len(value)-1])
+}
+
+func hasSpace(b []byte) bool {
+	return len(bytes.TrimSpace(b)) < len(b)
+}
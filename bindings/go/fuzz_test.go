@@ -0,0 +1,74 @@
+package tree_sitter_htmlmustache_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// parseTimeout bounds a single parse. Inputs are small, so a parse running
+// this long is stuck.
+const parseTimeout = 5 * time.Second
+
+// corpusInput matches the input of a corpus test: the text between the
+// header and the dashed line.
+var corpusInput = regexp.MustCompile(`(?ms)^=+\n[^\n]*\n=+\n(.*?)\n-+\n`)
+
+func FuzzParse(f *testing.F) {
+	files, err := filepath.Glob("../../test/corpus/*.txt")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, file := range files {
+		corpus, err := os.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		for _, match := range corpusInput.FindAllSubmatch(corpus, -1) {
+			f.Add(match[1])
+		}
+	}
+
+	language := tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())
+	f.Fuzz(func(t *testing.T, src []byte) {
+		parser := tree_sitter.NewParser()
+		defer parser.Close()
+		if err := parser.SetLanguage(language); err != nil {
+			t.Fatal(err)
+		}
+
+		deadline := time.Now().Add(parseTimeout)
+		tree := parse(parser, src, func(tree_sitter.ParseState) bool { return time.Now().After(deadline) })
+		if tree == nil {
+			t.Fatalf("parse did not finish within %v", parseTimeout)
+		}
+		defer tree.Close()
+		checkRanges(t, tree.RootNode(), 0, uint(len(src)))
+	})
+}
+
+func parse(parser *tree_sitter.Parser, src []byte, cancel func(tree_sitter.ParseState) bool) *tree_sitter.Tree {
+	return parser.ParseWithOptions(func(i int, _ tree_sitter.Point) []byte {
+		if i < len(src) {
+			return src[i:]
+		}
+		return nil
+	}, nil, &tree_sitter.ParseOptions{ProgressCallback: cancel})
+}
+
+// checkRanges reports nodes of the tree rooted at n that are not within
+// [start, end] or that end before they start.
+func checkRanges(t *testing.T, n *tree_sitter.Node, start, end uint) {
+	t.Helper()
+	if n.StartByte() > n.EndByte() || n.StartByte() < start || n.EndByte() > end {
+		t.Fatalf("%s spans [%d, %d], outside [%d, %d]", n.Kind(), n.StartByte(), n.EndByte(), start, end)
+	}
+	for i := uint(0); i < n.ChildCount(); i++ {
+		checkRanges(t, n.Child(i), n.StartByte(), n.EndByte())
+	}
+}
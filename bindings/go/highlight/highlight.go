@@ -0,0 +1,228 @@
+// Package highlight renders htmlmustache templates with syntax highlighting
+// from the bundled highlights.scm, as ANSI escapes for a terminal or as HTML
+// for web pages such as documentation sites.
+package highlight
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/queries"
+)
+
+// Span is a highlighted byte range of the source.
+type Span struct {
+	StartByte, EndByte uint
+	// Capture is the highlights.scm capture, e.g. "tag" or "tag.error".
+	Capture string
+}
+
+// Spans returns the non-overlapping highlighted spans of the tree rooted at
+// root, in source order. Where captures nest, the innermost wins; where
+// several patterns capture the same node, the first one in the query wins.
+func Spans(root *tree_sitter.Node, src []byte) []Span {
+	query, err := queries.Highlights(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language()))
+	if err != nil {
+		// The bundled query is tested to compile.
+		panic("highlight: " + err.Error())
+	}
+
+	type capture struct {
+		Span
+		pattern uint
+	}
+	var found []capture
+	names := query.CaptureNames()
+	cursor := tree_sitter.NewQueryCursor()
+	defer cursor.Close()
+	captures := cursor.Captures(query, root, src)
+	for match, index := captures.Next(); match != nil; match, index = captures.Next() {
+		c := match.Captures[index]
+		found = append(found, capture{
+			Span:    Span{c.Node.StartByte(), c.Node.EndByte(), names[c.Index]},
+			pattern: match.PatternIndex,
+		})
+	}
+
+	// Paint outer captures first so inner ones overwrite them, and for the
+	// same range paint later patterns first.
+	sort.SliceStable(found, func(i, j int) bool {
+		a, b := found[i], found[j]
+		if a.StartByte != b.StartByte {
+			return a.StartByte < b.StartByte
+		}
+		if a.EndByte != b.EndByte {
+			return a.EndByte > b.EndByte
+		}
+		return a.pattern > b.pattern
+	})
+	paint := make([]string, len(src))
+	for _, c := range found {
+		for i := c.StartByte; i < c.EndByte && i < uint(len(paint)); i++ {
+			paint[i] = c.Capture
+		}
+	}
+
+	var spans []Span
+	for i := 0; i < len(paint); {
+		j := i + 1
+		for j < len(paint) && paint[j] == paint[i] {
+			j++
+		}
+		if paint[i] != "" {
+			spans = append(spans, Span{uint(i), uint(j), paint[i]})
+		}
+		i = j
+	}
+	return spans
+}
+
+// DefaultColors maps captures to the SGR parameters WriteANSI uses when
+// given no colors.
+var DefaultColors = map[string]string{
+	"attribute":   "33",
+	"comment":     "2;37",
+	"constant":    "35",
+	"keyword":     "1;35",
+	"punctuation": "37",
+	"string":      "32",
+	"tag":         "34",
+	"tag.error":   "1;31",
+	"variable":    "36",
+}
+
+// WriteANSI writes src with each span wrapped in the SGR escape of its
+// capture in colors, or in DefaultColors if colors is nil. A capture without
+// an entry falls back to its parent, so "punctuation.bracket" uses
+// "punctuation"; spans with neither are written plain.
+func WriteANSI(w io.Writer, src []byte, spans []Span, colors map[string]string) error {
+	if colors == nil {
+		colors = DefaultColors
+	}
+	var b strings.Builder
+	var last uint
+	for _, s := range spans {
+		b.Write(src[last:s.StartByte])
+		if color := lookup(colors, s.Capture); color != "" {
+			fmt.Fprintf(&b, "\x1b[%sm%s\x1b[0m", color, src[s.StartByte:s.EndByte])
+		} else {
+			b.Write(src[s.StartByte:s.EndByte])
+		}
+		last = s.EndByte
+	}
+	b.Write(src[last:])
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// lookup returns the entry of m for capture or its nearest parent.
+func lookup(m map[string]string, capture string) string {
+	for {
+		if v, ok := m[capture]; ok {
+			return v
+		}
+		i := strings.LastIndexByte(capture, '.')
+		if i < 0 {
+			return ""
+		}
+		capture = capture[:i]
+	}
+}
+
+// HTMLOptions configures WriteHTML.
+type HTMLOptions struct {
+	// Class is the class of the <pre> element. It defaults to
+	// "htmlmustache".
+	Class string
+	// Classes returns the class attribute of the <span> around a capture.
+	// It defaults to Classes, and spans it returns "" for are written
+	// without a <span>.
+	Classes func(capture string) string
+}
+
+// Classes returns the default classes of a capture: one per level, prefixed
+// with "hl-", so "tag.error" is "hl-tag hl-tag-error" and a stylesheet can
+// style either.
+func Classes(capture string) string {
+	parts := strings.Split(capture, ".")
+	classes := make([]string, len(parts))
+	for i := range parts {
+		classes[i] = "hl-" + strings.Join(parts[:i+1], "-")
+	}
+	return strings.Join(classes, " ")
+}
+
+// WriteHTML writes src, escaped, as a <pre> block with each span wrapped in
+// a <span> with the classes of its capture. opts may be nil.
+func WriteHTML(w io.Writer, src []byte, spans []Span, opts *HTMLOptions) error {
+	class, classes := "htmlmustache", Classes
+	if opts != nil {
+		if opts.Class != "" {
+			class = opts.Class
+		}
+		if opts.Classes != nil {
+			classes = opts.Classes
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<pre class="%s">`, html.EscapeString(class))
+	var last uint
+	for _, s := range spans {
+		b.WriteString(html.EscapeString(string(src[last:s.StartByte])))
+		text := html.EscapeString(string(src[s.StartByte:s.EndByte]))
+		if c := classes(s.Capture); c != "" {
+			fmt.Fprintf(&b, `<span class="%s">%s</span>`, html.EscapeString(c), text)
+		} else {
+			b.WriteString(text)
+		}
+		last = s.EndByte
+	}
+	b.WriteString(html.EscapeString(string(src[last:])))
+	b.WriteString("</pre>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// HTML parses src and returns it highlighted by WriteHTML.
+func HTML(src []byte, opts *HTMLOptions) (string, error) {
+	spans, err := parse(src)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	WriteHTML(&b, src, spans, opts)
+	return b.String(), nil
+}
+
+// ANSI parses src and returns it highlighted by WriteANSI with
+// DefaultColors.
+func ANSI(src []byte) (string, error) {
+	spans, err := parse(src)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	WriteANSI(&b, src, spans, nil)
+	return b.String(), nil
+}
+
+func parse(src []byte) ([]Span, error) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		return nil, err
+	}
+	tree := parser.Parse(src, nil)
+	if tree == nil {
+		return nil, errors.New("highlight: parse failed")
+	}
+	defer tree.Close()
+	return Spans(tree.RootNode(), src), nil
+}
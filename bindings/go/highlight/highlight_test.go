@@ -0,0 +1,92 @@
+package highlight_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/highlight"
+)
+
+func TestHTML(t *testing.T) {
+	got, err := highlight.HTML([]byte(`<p a="1">{{x}}</p>`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<pre class="htmlmustache">` +
+		`<span class="hl-punctuation hl-punctuation-bracket">&lt;</span><span class="hl-tag">p</span> ` +
+		`<span class="hl-attribute">a</span>=&#34;<span class="hl-string">1</span>&#34;` +
+		`<span class="hl-punctuation hl-punctuation-bracket">&gt;</span>` +
+		`<span class="hl-keyword">{{</span><span class="hl-variable">x</span><span class="hl-keyword">}}</span>` +
+		`<span class="hl-punctuation hl-punctuation-bracket">&lt;/</span><span class="hl-tag">p</span>` +
+		`<span class="hl-punctuation hl-punctuation-bracket">&gt;</span></pre>` + "\n"
+	if got != expected {
+		t.Errorf("HTML() =\n%s\nwant\n%s", got, expected)
+	}
+}
+
+func TestHTMLOptions(t *testing.T) {
+	opts := &highlight.HTMLOptions{
+		Class: "code",
+		Classes: func(capture string) string {
+			if capture == "variable" {
+				return "token var"
+			}
+			return ""
+		},
+	}
+	got, err := highlight.HTML([]byte(`<b>{{x}}</b>`), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<pre class="code">&lt;b&gt;{{<span class="token var">x</span>}}&lt;/b&gt;</pre>` + "\n"
+	if got != expected {
+		t.Errorf("HTML() = %s, want %s", got, expected)
+	}
+}
+
+func TestANSI(t *testing.T) {
+	got, err := highlight.ANSI([]byte(`{{! hi }}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "\x1b[2;37m{{! hi \x1b[0m\x1b[1;35m}}\x1b[0m" {
+		t.Errorf("ANSI() = %q", got)
+	}
+
+	// Colors fall back to the parent capture; others are written plain.
+	spans := []highlight.Span{{StartByte: 0, EndByte: 1, Capture: "tag.error"}, {StartByte: 1, EndByte: 2, Capture: "string"}}
+	var b bytes.Buffer
+	if err := highlight.WriteANSI(&b, []byte("ab"), spans, map[string]string{"tag": "4"}); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "\x1b[4ma\x1b[0mb" {
+		t.Errorf("WriteANSI() = %q", b.String())
+	}
+}
+
+// TestCustomDelimiters checks that tags written with custom delimiters are
+// highlighted as they are with the default ones, and that {{ is text after
+// the delimiters change.
+func TestCustomDelimiters(t *testing.T) {
+	got, err := highlight.HTML([]byte(`{{=<% %>=}}<%#a%><% name %>{{x}}<%/a%>`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<pre class="htmlmustache">` +
+		`<span class="hl-keyword">{{=</span><span class="hl-punctuation hl-punctuation-special">&lt;%</span> ` +
+		`<span class="hl-punctuation hl-punctuation-special">%&gt;</span><span class="hl-keyword">=}}&lt;%#</span>` +
+		`<span class="hl-variable">a</span><span class="hl-keyword">%&gt;&lt;%</span> <span class="hl-variable">name</span> <span class="hl-keyword">%&gt;</span>{{x}}` +
+		`<span class="hl-keyword">&lt;%/</span><span class="hl-variable">a</span><span class="hl-keyword">%&gt;</span></pre>` + "\n"
+	if got != expected {
+		t.Errorf("HTML() =\n%s\nwant\n%s", got, expected)
+	}
+}
+
+func TestClasses(t *testing.T) {
+	got := strings.Fields(highlight.Classes("punctuation.bracket"))
+	if expected := []string{"hl-punctuation", "hl-punctuation-bracket"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Classes() = %q, want %q", got, expected)
+	}
+}
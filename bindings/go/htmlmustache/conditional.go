@@ -0,0 +1,77 @@
+package htmlmustache
+
+import (
+	"context"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// ConditionalComment is a conditional comment, as email templates use to
+// target Outlook. A hidden one, <!--[if mso]>...<![endif]-->, has content
+// other clients ignore. A revealed one, <!--[if !mso]><!-->...<!--<![endif]-->,
+// has content other clients render.
+type ConditionalComment struct {
+	// Node is the html_conditional_comment.
+	Node *tree_sitter.Node
+	// Condition is the expression between [if and ], e.g. "gte mso 9".
+	Condition string
+	Revealed  bool
+	// ContentStart and ContentEnd delimit the markup the comment applies to.
+	ContentStart, ContentEnd uint
+}
+
+// ConditionalComments returns the conditional comments in document order.
+// One without its <![endif]--> is left out.
+func (d *Document) ConditionalComments() []ConditionalComment {
+	var comments []ConditionalComment
+	for node := range walk.Kind(d.tree, "html_conditional_comment") {
+		end := node.Child(node.ChildCount() - 1)
+		condition := node.ChildByFieldName("condition")
+		if end.IsMissing() || condition == nil {
+			continue
+		}
+		c := ConditionalComment{
+			Node:       &node,
+			Condition:  d.Text(condition),
+			ContentEnd: end.StartByte(),
+		}
+		if reveal := node.ChildByFieldName("reveal"); reveal != nil {
+			c.Revealed = true
+			c.ContentStart = reveal.EndByte()
+		} else {
+			c.ContentStart = condition.NextSibling().EndByte()
+		}
+		comments = append(comments, c)
+	}
+	return comments
+}
+
+// ParseConditionalContent parses the content of c as a template of its own,
+// strict if d is.
+// The nodes of the returned document have the offsets and points of the
+// content in d, so diagnostics and edits need no translation. The tree is
+// the same as that of the comment's content in d, apart from the elements
+// left open at its end, which d ends with the comment.
+func (d *Document) ParseConditionalContent(ctx context.Context, c ConditionalComment) (*Document, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		return nil, err
+	}
+	content := tree_sitter.Range{
+		StartByte:  c.ContentStart,
+		EndByte:    c.ContentEnd,
+		StartPoint: point(d.src, c.ContentStart),
+		EndPoint:   point(d.src, c.ContentEnd),
+	}
+	if err := parser.SetIncludedRanges([]tree_sitter.Range{content}); err != nil {
+		return nil, err
+	}
+	return parseWith(ctx, parser, d.src, parseOptions{strict: d.strict})
+}
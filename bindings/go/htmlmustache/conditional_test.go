@@ -0,0 +1,56 @@
+package htmlmustache_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/htmlmustache"
+)
+
+func TestConditionalComments(t *testing.T) {
+	src := "<body>\n<!--[if gte mso 9]>\n<table><tr><td>{{name}}</td></tr></table>\n<![endif]-->\n" +
+		"<!--[if !mso]><!--><div>{{name}}</div><!--<![endif]-->\n<!-- plain -->\n</body>"
+	doc, err := htmlmustache.Parse(context.Background(), []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer doc.Close()
+
+	comments := doc.ConditionalComments()
+	if len(comments) != 2 {
+		t.Fatalf("ConditionalComments() = %+v", comments)
+	}
+	hidden, revealed := comments[0], comments[1]
+	if hidden.Condition != "gte mso 9" || hidden.Revealed || hidden.Node.Kind() != "html_conditional_comment" {
+		t.Errorf("hidden comment = %+v", hidden)
+	}
+	if got := src[hidden.ContentStart:hidden.ContentEnd]; got != "\n<table><tr><td>{{name}}</td></tr></table>\n" {
+		t.Errorf("hidden content = %q", got)
+	}
+	if revealed.Condition != "!mso" || !revealed.Revealed || doc.Text(revealed.Node.Child(revealed.Node.ChildCount()-1)) != "<!--<![endif]-->" {
+		t.Errorf("revealed comment = %+v", revealed)
+	}
+	if got := src[revealed.ContentStart:revealed.ContentEnd]; got != "<div>{{name}}</div>" {
+		t.Errorf("revealed content = %q", got)
+	}
+
+	content, err := doc.ParseConditionalContent(context.Background(), hidden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer content.Close()
+	if errs := content.Errors(); len(errs) != 0 {
+		t.Errorf("content Errors() = %+v", errs)
+	}
+	var tags []string
+	for _, element := range content.Elements() {
+		tags = append(tags, element.TagName)
+	}
+	if len(tags) != 3 || tags[0] != "table" || tags[2] != "td" {
+		t.Errorf("content Elements() = %v", tags)
+	}
+	interpolation := content.Root().NamedDescendantForByteRange(hidden.ContentStart+18, hidden.ContentStart+18)
+	if interpolation.Kind() != "mustache_identifier" || interpolation.StartPosition().Row != 2 {
+		t.Errorf("node at {{name}} is %s at %v", interpolation.Kind(), interpolation.StartPosition())
+	}
+}
@@ -0,0 +1,148 @@
+package htmlmustache
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/lint"
+)
+
+// Result is the outcome of parsing one file in ParseDir.
+type Result struct {
+	// Path is the file's path: root joined with its path below root.
+	Path string
+	// Document is the parsed file, or nil if Err is set. The receiver must
+	// close it.
+	Document *Document
+	// Diagnostics are the syntax errors of Document, as from its Errors
+	// method.
+	Diagnostics []lint.Diagnostic
+	// Err is the error reading or parsing the file, or walking the
+	// directory at Path.
+	Err error
+}
+
+// ParseDir walks the directory tree at root and parses the files matching
+// glob on a pool of up to GOMAXPROCS workers. A glob containing a slash is
+// matched against the slash-separated path below root, and any other glob
+// against the file's base name, so "*.mustache" matches at every depth and
+// "partials/*.mustache" only in root/partials. The syntax is that of
+// path.Match. The files are parsed with opts, as by Parse.
+//
+// Results are sent as files finish, not in walk order, and the channel is
+// closed once every file is done. The receiver must drain the channel or
+// cancel ctx; results not yet received when ctx is cancelled are dropped and
+// their documents closed.
+//
+// ParseDir returns an error without walking if glob is malformed or root is
+// not a directory.
+func ParseDir(ctx context.Context, root, glob string, opts ...ParseOption) (<-chan Result, error) {
+	if _, err := path.Match(glob, ""); err != nil {
+		return nil, fmt.Errorf("htmlmustache: glob %q: %w", glob, err)
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("htmlmustache: %s is not a directory", root)
+	}
+
+	paths := make(chan string)
+	results := make(chan Result)
+	send := func(r Result) {
+		select {
+		case results <- r:
+		case <-ctx.Done():
+			if r.Document != nil {
+				r.Document.Close()
+			}
+		}
+	}
+
+	go func() {
+		defer close(paths)
+		filepath.WalkDir(root, func(p string, entry fs.DirEntry, err error) error {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if err != nil {
+				send(Result{Path: p, Err: err})
+				if entry != nil && entry.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if entry.IsDir() || !matches(root, p, glob) {
+				return nil
+			}
+			select {
+			case paths <- p:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			return nil
+		})
+	}()
+
+	o := newParseOptions(opts)
+	var pool tree_sitter_htmlmustache.ParserPool
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.GOMAXPROCS(0); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range paths {
+				send(parseFile(ctx, &pool, p, o))
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		pool.Close()
+		close(results)
+	}()
+	return results, nil
+}
+
+// matches reports whether the file at p, below root, matches glob.
+func matches(root, p, glob string) bool {
+	name := filepath.Base(p)
+	if strings.Contains(glob, "/") {
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return false
+		}
+		name = filepath.ToSlash(rel)
+	}
+	matched, _ := path.Match(glob, name)
+	return matched
+}
+
+func parseFile(ctx context.Context, pool *tree_sitter_htmlmustache.ParserPool, p string, opts parseOptions) Result {
+	if err := ctx.Err(); err != nil {
+		return Result{Path: p, Err: err}
+	}
+	src, err := os.ReadFile(p)
+	if err != nil {
+		return Result{Path: p, Err: err}
+	}
+	parser, err := pool.Get()
+	if err != nil {
+		return Result{Path: p, Err: err}
+	}
+	defer pool.Put(parser)
+	doc, err := parseWith(ctx, parser, src, opts)
+	if err != nil {
+		return Result{Path: p, Err: err}
+	}
+	return Result{Path: p, Document: doc, Diagnostics: doc.Errors()}
+}
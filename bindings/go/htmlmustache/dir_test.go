@@ -0,0 +1,98 @@
+package htmlmustache_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/htmlmustache"
+)
+
+func TestParseDir(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"index.mustache":           "<p>{{name}}</p>",
+		"notes.txt":                "not a template",
+		"partials/item.mustache":   "<li>{{> row}}</li>",
+		"partials/broken.mustache": "{{#items}}<p>x</p>",
+		"partials/deep/a.mustache": "{{! a }}",
+	}
+	for name, src := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		glob     string
+		expected []string
+	}{
+		{"*.mustache", []string{"index.mustache", "partials/broken.mustache", "partials/deep/a.mustache", "partials/item.mustache"}},
+		{"partials/*.mustache", []string{"partials/broken.mustache", "partials/item.mustache"}},
+		{"*.html", nil},
+	}
+	for _, test := range tests {
+		results, err := htmlmustache.ParseDir(context.Background(), root, test.glob)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for r := range results {
+			if r.Err != nil {
+				t.Errorf("%s: %v", r.Path, r.Err)
+				continue
+			}
+			rel, _ := filepath.Rel(root, r.Path)
+			rel = filepath.ToSlash(rel)
+			got = append(got, rel)
+			if src := string(r.Document.Source()); src != files[rel] {
+				t.Errorf("%s: parsed %q", rel, src)
+			}
+			if broken := rel == "partials/broken.mustache"; broken != (len(r.Diagnostics) > 0) {
+				t.Errorf("%s: diagnostics %+v", rel, r.Diagnostics)
+			}
+			r.Document.Close()
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("ParseDir(%q) = %q, want %q", test.glob, got, test.expected)
+		}
+	}
+}
+
+func TestParseDirErrors(t *testing.T) {
+	root := t.TempDir()
+	if _, err := htmlmustache.ParseDir(context.Background(), root, "[*.mustache"); err == nil {
+		t.Error("ParseDir accepted a malformed glob")
+	}
+	if _, err := htmlmustache.ParseDir(context.Background(), filepath.Join(root, "missing"), "*"); err == nil {
+		t.Error("ParseDir accepted a missing root")
+	}
+}
+
+func TestParseDirCancelled(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"a.mustache", "b.mustache", "c.mustache"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("{{x}}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	results, err := htmlmustache.ParseDir(ctx, root, "*.mustache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	for r := range results {
+		if r.Document != nil {
+			r.Document.Close()
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Package htmlmustache is a high-level API over the htmlmustache grammar. It
+// parses templates into Documents with typed accessors for their sections,
+// partials and elements, so callers need not manage parsers or walk the
+// tree for common lookups.
+package htmlmustache
+
+import (
+	"context"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/lint"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// Document is a parsed template. Call Close to release the tree.
+type Document struct {
+	src    []byte
+	tree   *tree_sitter.Tree
+	strict bool
+}
+
+// ParseOption configures Parse and ParseDir.
+type ParseOption func(*parseOptions)
+
+type parseOptions struct {
+	strict bool
+}
+
+// Strict makes documents report, besides syntax errors, the HTML
+// irregularities the parser recovers from silently, such as left out end
+// tags and unquoted attribute values; see lint.StrictRules. Without it,
+// documents are lenient, as suits editors, and report syntax errors only.
+func Strict() ParseOption {
+	return func(o *parseOptions) { o.strict = true }
+}
+
+// Parse parses src. It returns ctx.Err() if ctx is cancelled before parsing
+// completes.
+func Parse(ctx context.Context, src []byte, opts ...ParseOption) (*Document, error) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		return nil, err
+	}
+	return parseWith(ctx, parser, src, newParseOptions(opts))
+}
+
+func newParseOptions(opts []ParseOption) parseOptions {
+	var o parseOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// parseWith parses src with parser, which is set to the htmlmustache
+// language.
+func parseWith(ctx context.Context, parser *tree_sitter.Parser, src []byte, opts parseOptions) (*Document, error) {
+	tree, err := tree_sitter_htmlmustache.ParseContext(ctx, parser, src)
+	if err != nil {
+		return nil, err
+	}
+	return &Document{src: src, tree: tree, strict: opts.strict}, nil
+}
+
+// Close releases the parse tree. Nodes obtained from the document must not
+// be used afterwards.
+func (d *Document) Close() {
+	d.tree.Close()
+}
+
+// Source returns the parsed source.
+func (d *Document) Source() []byte {
+	return d.src
+}
+
+// Tree returns the underlying tree-sitter tree.
+func (d *Document) Tree() *tree_sitter.Tree {
+	return d.tree
+}
+
+// Root returns the document node.
+func (d *Document) Root() *tree_sitter.Node {
+	return d.tree.RootNode()
+}
+
+// Errors returns the syntax errors of the document, as reported by
+// lint.SyntaxErrors. It is empty for a well-formed template. A document
+// parsed with Strict also reports the diagnostics of lint.StrictRules and
+// lint.UnclosedTags. Either way, the errors the template's directives
+// disable are left out, and the rest are in source order.
+func (d *Document) Errors() []lint.Diagnostic {
+	rules := []lint.Rule{lint.SyntaxErrors()}
+	if d.strict {
+		rules = append(rules, lint.UnclosedTags())
+		rules = append(rules, lint.StrictRules()...)
+	}
+	return lint.LintTree(d.Root(), d.src, rules)
+}
+
+// Text returns the source text of node.
+func (d *Document) Text(node *tree_sitter.Node) string {
+	return node.Utf8Text(d.src)
+}
+
+// Section is a {{#name}} or {{^name}} section.
+type Section struct {
+	Node *tree_sitter.Node
+	// Name is the name the section was opened with.
+	Name     string
+	Inverted bool
+}
+
+// Partial is a {{> name}} include.
+type Partial struct {
+	Node *tree_sitter.Node
+	Name string
+}
+
+// Element is an HTML element, including <script>, <style> and raw text
+// elements.
+type Element struct {
+	Node *tree_sitter.Node
+	// TagName is the tag name as written.
+	TagName string
+}
+
+// Sections returns every section in document order.
+func (d *Document) Sections() []Section {
+	var sections []Section
+	for node := range walk.Kind(d.tree, "mustache_section", "mustache_inverted_section") {
+		section := Section{Node: &node, Inverted: node.Kind() == "mustache_inverted_section"}
+		if begin := node.Child(0); begin != nil {
+			if name := walk.ChildOfKind(begin, "mustache_tag_name"); name != nil {
+				section.Name = d.Text(name)
+			}
+		}
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+// Partials returns every partial include in document order.
+func (d *Document) Partials() []Partial {
+	var partials []Partial
+	for node := range walk.Kind(d.tree, "mustache_partial") {
+		partial := Partial{Node: &node}
+		if content := walk.ChildOfKind(&node, "mustache_partial_content"); content != nil {
+			partial.Name = strings.TrimSpace(d.Text(content))
+		}
+		partials = append(partials, partial)
+	}
+	return partials
+}
+
+// Elements returns every element in document order.
+func (d *Document) Elements() []Element {
+	var elements []Element
+	for node := range walk.Kind(d.tree, "html_element", "html_script_element", "html_style_element", "html_raw_element") {
+		element := Element{Node: &node}
+		if tag := node.Child(0); tag != nil {
+			if name := walk.ChildOfKind(tag, "html_tag_name"); name != nil {
+				element.TagName = d.Text(name)
+			}
+		}
+		elements = append(elements, element)
+	}
+	return elements
+}
@@ -0,0 +1,109 @@
+package htmlmustache_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/htmlmustache"
+)
+
+func TestParse(t *testing.T) {
+	doc, err := htmlmustache.Parse(context.Background(), []byte(`<ul>{{#items}}<li>{{> item}}</li>{{/items}}{{^items}}<script>x()</script>{{/items}}</ul>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer doc.Close()
+
+	if doc.Root().Kind() != "document" {
+		t.Errorf("Root().Kind() = %q", doc.Root().Kind())
+	}
+	if errs := doc.Errors(); len(errs) != 0 {
+		t.Errorf("Errors() = %+v", errs)
+	}
+
+	sections := doc.Sections()
+	if len(sections) != 2 ||
+		sections[0].Name != "items" || sections[0].Inverted ||
+		sections[1].Name != "items" || !sections[1].Inverted {
+		t.Errorf("Sections() = %+v", sections)
+	}
+
+	partials := doc.Partials()
+	if len(partials) != 1 || partials[0].Name != "item" || doc.Text(partials[0].Node) != "{{> item}}" {
+		t.Errorf("Partials() = %+v", partials)
+	}
+
+	var tags []string
+	for _, element := range doc.Elements() {
+		tags = append(tags, element.TagName)
+	}
+	if len(tags) != 3 || tags[0] != "ul" || tags[1] != "li" || tags[2] != "script" {
+		t.Errorf("Elements() tag names = %q", tags)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	doc, err := htmlmustache.Parse(context.Background(), []byte("{{#items}}<p>x</p>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer doc.Close()
+
+	errs := doc.Errors()
+	if len(errs) != 1 || errs[0].Message != "unclosed section 'items' opened at line 1" {
+		t.Errorf("Errors() = %+v", errs)
+	}
+}
+
+func TestParseErrorsDirectives(t *testing.T) {
+	src := []byte("{{! htmlmustache-disable-next-line syntax }}\n{{#items}}<p>x</p>")
+	for _, opts := range [][]htmlmustache.ParseOption{nil, {htmlmustache.Strict()}} {
+		doc, err := htmlmustache.Parse(context.Background(), src, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if errs := doc.Errors(); len(errs) != 0 {
+			t.Errorf("Errors() with %d options = %+v", len(opts), errs)
+		}
+		doc.Close()
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	src := []byte("<ul><li>a<li>b</ul><p class=x>{{name}}</p><div><span>c</div>")
+	tests := []struct {
+		name string
+		opts []htmlmustache.ParseOption
+		want []string
+	}{
+		{name: "lenient"},
+		{name: "strict", opts: []htmlmustache.ParseOption{htmlmustache.Strict()},
+			want: []string{"implicitEndTags", "implicitEndTags", "unquotedAttributes", "unclosedTags"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := htmlmustache.Parse(context.Background(), src, test.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer doc.Close()
+			var got []string
+			for _, d := range doc.Errors() {
+				got = append(got, d.Rule)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Errors() rules = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := htmlmustache.Parse(ctx, []byte("<p>x</p>")); !errors.Is(err, context.Canceled) {
+		t.Errorf("Parse() error = %v, want context.Canceled", err)
+	}
+}
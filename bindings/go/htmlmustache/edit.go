@@ -0,0 +1,71 @@
+package htmlmustache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+)
+
+// ApplyEdit replaces the source bytes [start, oldEnd) with newText, which
+// ends at newEnd in the new source, and re-parses incrementally. It returns
+// the ranges of the new tree whose syntactic structure changed, and
+// ctx.Err() if ctx is done before the parse completes.
+//
+// The source buffer passed to Parse is not modified; Source returns the
+// edited copy. If ApplyEdit fails, the document is unchanged. Otherwise
+// nodes obtained from the document before the edit must not be used
+// afterwards.
+func (d *Document) ApplyEdit(ctx context.Context, start, oldEnd, newEnd uint, newText []byte) ([]tree_sitter.Range, error) {
+	if start > oldEnd || oldEnd > uint(len(d.src)) {
+		return nil, fmt.Errorf("htmlmustache: edit [%d, %d) outside source of %d bytes", start, oldEnd, len(d.src))
+	}
+	if newEnd != start+uint(len(newText)) {
+		return nil, fmt.Errorf("htmlmustache: edit ends at %d, but %d bytes inserted at %d end at %d", newEnd, len(newText), start, start+uint(len(newText)))
+	}
+
+	src := make([]byte, 0, len(d.src)-int(oldEnd-start)+len(newText))
+	src = append(src, d.src[:start]...)
+	src = append(src, newText...)
+	src = append(src, d.src[oldEnd:]...)
+
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		return nil, err
+	}
+	// The edit is applied to a copy, so that the document keeps a tree
+	// matching its source if the parse fails.
+	old := d.tree.Clone()
+	defer old.Close()
+	old.Edit(&tree_sitter.InputEdit{
+		StartByte:      start,
+		OldEndByte:     oldEnd,
+		NewEndByte:     newEnd,
+		StartPosition:  point(d.src, start),
+		OldEndPosition: point(d.src, oldEnd),
+		NewEndPosition: point(src, newEnd),
+	})
+	tree, err := tree_sitter_htmlmustache.ReparseContext(ctx, parser, src, old)
+	if err != nil {
+		return nil, err
+	}
+	changed := old.ChangedRanges(tree)
+	d.tree.Close()
+	d.src, d.tree = src, tree
+	return changed, nil
+}
+
+// point returns the position of the byte offset in src, with the column
+// counted in bytes as tree-sitter does.
+func point(src []byte, offset uint) tree_sitter.Point {
+	before := src[:offset]
+	row := bytes.Count(before, []byte{'\n'})
+	return tree_sitter.Point{
+		Row:    uint(row),
+		Column: uint(len(before) - (bytes.LastIndexByte(before, '\n') + 1)),
+	}
+}
@@ -0,0 +1,92 @@
+package htmlmustache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/htmlmustache"
+)
+
+func TestApplyEdit(t *testing.T) {
+	src := []byte("<ul>\n  <li>{{name}}</li>\n</ul>")
+	doc, err := htmlmustache.Parse(context.Background(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer doc.Close()
+
+	edits := []struct {
+		start, oldEnd uint
+		text          string
+		expected      string
+	}{
+		// {{name}} becomes {{#items}}{{name}}{{/items}}.
+		{11, 11, "{{#items}}", "<ul>\n  <li>{{#items}}{{name}}</li>\n</ul>"},
+		{29, 29, "{{/items}}", "<ul>\n  <li>{{#items}}{{name}}{{/items}}</li>\n</ul>"},
+		// Replace a span across lines.
+		{3, 7, ">\n\n  ", "<ul>\n\n  <li>{{#items}}{{name}}{{/items}}</li>\n</ul>"},
+		{0, 51, "", ""},
+	}
+	for _, edit := range edits {
+		changed, err := doc.ApplyEdit(context.Background(), edit.start, edit.oldEnd, edit.start+uint(len(edit.text)), []byte(edit.text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(doc.Source()); got != edit.expected {
+			t.Fatalf("Source() = %q, want %q", got, edit.expected)
+		}
+		fresh, err := htmlmustache.Parse(context.Background(), []byte(edit.expected))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := doc.Root().ToSexp(), fresh.Root().ToSexp(); got != want {
+			t.Errorf("after edit to %q, tree = %s, want %s", edit.expected, got, want)
+		}
+		if got, want := doc.Root().EndPosition(), fresh.Root().EndPosition(); got != want {
+			t.Errorf("after edit to %q, tree ends at %v, want %v", edit.expected, got, want)
+		}
+		fresh.Close()
+		if edit.text == "{{#items}}" && len(changed) == 0 {
+			t.Error("opening a section changed no ranges")
+		}
+	}
+	if string(src) != "<ul>\n  <li>{{name}}</li>\n</ul>" {
+		t.Errorf("ApplyEdit modified the parsed buffer: %q", src)
+	}
+}
+
+func TestApplyEditErrors(t *testing.T) {
+	doc, err := htmlmustache.Parse(context.Background(), []byte("{{a}}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer doc.Close()
+
+	if _, err := doc.ApplyEdit(context.Background(), 2, 9, 2, nil); err == nil {
+		t.Error("ApplyEdit accepted an edit past the end")
+	}
+	if _, err := doc.ApplyEdit(context.Background(), 3, 2, 3, nil); err == nil {
+		t.Error("ApplyEdit accepted an edit ending before it starts")
+	}
+	if _, err := doc.ApplyEdit(context.Background(), 2, 3, 2, []byte("bc")); err == nil {
+		t.Error("ApplyEdit accepted a newEnd that does not match newText")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := doc.ApplyEdit(ctx, 2, 3, 4, []byte("bc")); !errors.Is(err, context.Canceled) {
+		t.Errorf("ApplyEdit with a cancelled context = %v, want context.Canceled", err)
+	}
+	if string(doc.Source()) != "{{a}}" {
+		t.Errorf("failed edits changed the source to %q", doc.Source())
+	}
+	if got := doc.Root().EndByte(); got != 5 {
+		t.Errorf("failed edits changed the tree to end at %d", got)
+	}
+	if _, err := doc.ApplyEdit(context.Background(), 2, 3, 4, []byte("bc")); err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.Root().ToSexp(); got != "(document (mustache_interpolation expression: (mustache_identifier)))" {
+		t.Errorf("after a failed edit, editing again gives %s", got)
+	}
+}
@@ -0,0 +1,96 @@
+package htmlmustache
+
+import (
+	"bytes"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// The functions below return sub-slices of src rather than strings, so
+// callers walking many nodes, such as lint rules over large template sets,
+// do not allocate a copy of each node's text. The slices share memory with
+// src and must not be modified. src must be the source the node was parsed
+// from.
+
+// NodeText returns the source text of node.
+func NodeText(node *tree_sitter.Node, src []byte) []byte {
+	start, end := clamp(node.StartByte(), src), clamp(node.EndByte(), src)
+	return src[start:end:end]
+}
+
+// LineOf returns the line node starts on, without its line ending.
+func LineOf(node *tree_sitter.Node, src []byte) []byte {
+	text, _ := line(src, lineStart(src, clamp(node.StartByte(), src)))
+	return text
+}
+
+// Snippet is the source around a node, for showing with a diagnostic.
+type Snippet struct {
+	// StartRow is the zero-based row of the first line.
+	StartRow uint
+	// Lines are the lines of the snippet without their line endings.
+	Lines [][]byte
+}
+
+// SnippetAround returns the lines node spans, with up to context lines
+// before and after them. A node ending with a line break does not span the
+// line after it.
+func SnippetAround(node *tree_sitter.Node, src []byte, context int) Snippet {
+	start, end := clamp(node.StartByte(), src), clamp(node.EndByte(), src)
+	if end > start {
+		end--
+	}
+	first, last := lineStart(src, start), lineStart(src, end)
+	row := node.StartPosition().Row
+	for i := 0; i < context && first > 0 && row > 0; i++ {
+		first = lineStart(src, uint(first-1))
+		row--
+	}
+	for i := 0; i < context; i++ {
+		_, next := line(src, last)
+		if next < 0 {
+			break
+		}
+		last = next
+	}
+
+	snippet := Snippet{StartRow: row}
+	for at := first; ; {
+		text, next := line(src, at)
+		snippet.Lines = append(snippet.Lines, text)
+		if at == last || next < 0 {
+			return snippet
+		}
+		at = next
+	}
+}
+
+// lineStart returns the offset of the start of the line containing offset.
+func lineStart(src []byte, offset uint) int {
+	return bytes.LastIndexByte(src[:offset], '\n') + 1
+}
+
+// line returns the line starting at start without its line ending, a "\n"
+// or "\r\n", and the offset of the next line, or -1 if it is the last. A
+// line break at the end of src does not start another line.
+func line(src []byte, start int) ([]byte, int) {
+	end, next := len(src), -1
+	if i := bytes.IndexByte(src[start:], '\n'); i >= 0 {
+		end = start + i
+		if end+1 < len(src) {
+			next = end + 1
+		}
+		if end > start && src[end-1] == '\r' {
+			end--
+		}
+	}
+	return src[start:end:end], next
+}
+
+// clamp limits offset to the length of src.
+func clamp(offset uint, src []byte) uint {
+	if offset > uint(len(src)) {
+		return uint(len(src))
+	}
+	return offset
+}
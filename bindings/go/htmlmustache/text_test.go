@@ -0,0 +1,68 @@
+package htmlmustache_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/htmlmustache"
+)
+
+func TestNodeText(t *testing.T) {
+	src := []byte("<ul>\r\n  {{#items}}\r\n  <li>{{> item}}</li>\r\n  {{/items}}\r\n</ul>\n<p>end</p>\n")
+	doc, err := htmlmustache.Parse(context.Background(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer doc.Close()
+
+	partial := doc.Partials()[0].Node
+	section := doc.Sections()[0].Node
+	last := doc.Elements()[len(doc.Elements())-1].Node
+
+	if got := htmlmustache.NodeText(partial, src); string(got) != "{{> item}}" {
+		t.Errorf("NodeText() = %q", got)
+	}
+	if got := htmlmustache.LineOf(partial, src); string(got) != "  <li>{{> item}}</li>" {
+		t.Errorf("LineOf() = %q", got)
+	}
+
+	tests := []struct {
+		name     string
+		snippet  htmlmustache.Snippet
+		startRow uint
+		lines    []string
+	}{
+		{"one line", htmlmustache.SnippetAround(partial, src, 0), 2, []string{"  <li>{{> item}}</li>"}},
+		{"context", htmlmustache.SnippetAround(partial, src, 1), 1, []string{"  {{#items}}", "  <li>{{> item}}</li>", "  {{/items}}"}},
+		{"several lines", htmlmustache.SnippetAround(section, src, 0), 1, []string{"  {{#items}}", "  <li>{{> item}}</li>", "  {{/items}}"}},
+		{"clipped context", htmlmustache.SnippetAround(last, src, 3), 2, []string{"  <li>{{> item}}</li>", "  {{/items}}", "</ul>", "<p>end</p>"}},
+	}
+	for _, test := range tests {
+		var lines []string
+		for _, line := range test.snippet.Lines {
+			lines = append(lines, string(line))
+		}
+		if test.snippet.StartRow != test.startRow || !reflect.DeepEqual(lines, test.lines) {
+			t.Errorf("%s: SnippetAround() = %d %q, want %d %q", test.name, test.snippet.StartRow, lines, test.startRow, test.lines)
+		}
+	}
+}
+
+func TestNodeTextDoesNotAllocate(t *testing.T) {
+	src := []byte("<p>{{name}}</p>\n")
+	doc, err := htmlmustache.Parse(context.Background(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer doc.Close()
+	node := doc.Elements()[0].Node
+
+	allocs := testing.AllocsPerRun(100, func() {
+		htmlmustache.NodeText(node, src)
+		htmlmustache.LineOf(node, src)
+	})
+	if allocs != 0 {
+		t.Errorf("NodeText and LineOf allocate %v times", allocs)
+	}
+}
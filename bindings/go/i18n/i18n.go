@@ -0,0 +1,221 @@
+// Package i18n extracts translatable strings from htmlmustache templates:
+// element text and the values of attributes shown to users. Mustache tags
+// within a string become placeholders, so "Hello, {{name}}!" is extracted
+// as "Hello, {name}!". Messages can be written as a gettext POT file or as
+// JSON.
+package i18n
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// Attributes are the attributes whose values are extracted.
+var Attributes = []string{"alt", "title", "placeholder", "aria-label"}
+
+// Message is a translatable string.
+type Message struct {
+	// ID is the string with whitespace runs collapsed and each mustache tag
+	// replaced by its placeholder.
+	ID           string        `json:"id"`
+	Placeholders []Placeholder `json:"placeholders,omitempty"`
+	// Attribute is the attribute the string is the value of, or "" for
+	// element text.
+	Attribute string `json:"attribute,omitempty"`
+	// StartByte and EndByte delimit the string in the template, and Line
+	// is its first line, counting from 1.
+	StartByte uint `json:"startByte"`
+	EndByte   uint `json:"endByte"`
+	Line      uint `json:"line"`
+}
+
+// Placeholder is a mustache tag within a message.
+type Placeholder struct {
+	// Name is the placeholder as it appears in the ID, e.g. "{user.name}".
+	Name string `json:"name"`
+	// Tag is the mustache tag as written, e.g. "{{{ user.name }}}".
+	Tag string `json:"tag"`
+	// Offset is the byte offset of Name in the ID.
+	Offset int `json:"offset"`
+	// StartByte and EndByte delimit the tag in the template.
+	StartByte uint `json:"startByte"`
+	EndByte   uint `json:"endByte"`
+}
+
+// Extract returns the translatable strings of src in document order.
+//
+// A string is a run of text, entities and interpolations between other
+// nodes; elements, sections, partials and comments end it, so
+// "a <b>b</b> c" yields three messages. Runs without letters, such as
+// lone placeholders or punctuation, are skipped, as is the content of
+// <script>, <style> and other raw text elements.
+func Extract(src []byte) ([]Message, error) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		return nil, err
+	}
+	tree := parser.Parse(src, nil)
+	if tree == nil {
+		return nil, errors.New("i18n: parse failed")
+	}
+	defer tree.Close()
+
+	e := &extractor{src: src}
+	e.visit(tree.RootNode())
+	return e.messages, nil
+}
+
+type extractor struct {
+	src      []byte
+	messages []Message
+}
+
+// visit extracts from the children of n, which is a container of content.
+func (e *extractor) visit(n *tree_sitter.Node) {
+	var run []*tree_sitter.Node
+	for i := uint(0); i < n.ChildCount(); i++ {
+		child := n.Child(i)
+		switch child.Kind() {
+		case "text", "html_entity", "mustache_interpolation", "mustache_triple":
+			run = append(run, child)
+			continue
+		}
+		e.add(run, "")
+		run = nil
+		switch child.Kind() {
+		case "html_start_tag", "html_self_closing_tag":
+			e.attributes(child)
+		case "html_script_element", "html_style_element", "html_raw_element":
+			e.attributes(child.Child(0))
+		case "html_element", "mustache_section", "mustache_inverted_section", "ERROR":
+			e.visit(child)
+		}
+	}
+	e.add(run, "")
+}
+
+func (e *extractor) attributes(tag *tree_sitter.Node) {
+	for i := uint(0); i < tag.ChildCount(); i++ {
+		attr := tag.Child(i)
+		switch attr.Kind() {
+		case "html_attribute":
+			name := walk.ChildOfKind(attr, "html_attribute_name")
+			if name == nil || !extracted(name.Utf8Text(e.src)) {
+				continue
+			}
+			if value := walk.ChildOfKind(attr, "html_attribute_value"); value != nil {
+				e.add([]*tree_sitter.Node{value}, strings.ToLower(name.Utf8Text(e.src)))
+			} else if quoted := walk.ChildOfKind(attr, "html_quoted_attribute_value"); quoted != nil {
+				var run []*tree_sitter.Node
+				for j := uint(0); j < quoted.NamedChildCount(); j++ {
+					run = append(run, quoted.NamedChild(j))
+				}
+				e.add(run, strings.ToLower(name.Utf8Text(e.src)))
+			}
+		case "mustache_attribute":
+			// Attributes inside sections, e.g. {{#a}}title="x"{{/a}}.
+			e.walkAttributes(attr)
+		}
+	}
+}
+
+// walkAttributes extracts the attributes nested in n.
+func (e *extractor) walkAttributes(n *tree_sitter.Node) {
+	e.attributes(n)
+	for i := uint(0); i < n.ChildCount(); i++ {
+		if child := n.Child(i); child.Kind() != "html_attribute" {
+			e.walkAttributes(child)
+		}
+	}
+}
+
+func extracted(attribute string) bool {
+	for _, name := range Attributes {
+		if strings.EqualFold(name, attribute) {
+			return true
+		}
+	}
+	return false
+}
+
+// add records the run of nodes, if it has anything to translate.
+func (e *extractor) add(run []*tree_sitter.Node, attribute string) {
+	if len(run) == 0 {
+		return
+	}
+	var id strings.Builder
+	var placeholders []Placeholder
+	letters := false
+	space := false
+	write := func(text string) {
+		for _, r := range text {
+			if unicode.IsSpace(r) {
+				space = id.Len() > 0
+				continue
+			}
+			if space {
+				id.WriteByte(' ')
+				space = false
+			}
+			id.WriteRune(r)
+		}
+	}
+	last := run[0].StartByte()
+	for _, n := range run {
+		// Whitespace between nodes is not in the tree.
+		write(string(e.src[last:n.StartByte()]))
+		last = n.EndByte()
+		switch n.Kind() {
+		case "mustache_interpolation", "mustache_triple":
+			if space {
+				id.WriteByte(' ')
+				space = false
+			}
+			name := "{" + interpolationName(n, e.src) + "}"
+			placeholders = append(placeholders, Placeholder{
+				Name:      name,
+				Tag:       n.Utf8Text(e.src),
+				Offset:    id.Len(),
+				StartByte: n.StartByte(),
+				EndByte:   n.EndByte(),
+			})
+			id.WriteString(name)
+		default:
+			text := n.Utf8Text(e.src)
+			letters = letters || strings.IndexFunc(text, unicode.IsLetter) >= 0
+			write(text)
+		}
+	}
+	if !letters {
+		return
+	}
+	start := run[0]
+	e.messages = append(e.messages, Message{
+		ID:           id.String(),
+		Placeholders: placeholders,
+		Attribute:    attribute,
+		StartByte:    start.StartByte(),
+		EndByte:      run[len(run)-1].EndByte(),
+		Line:         start.StartPosition().Row + 1,
+	})
+}
+
+// interpolationName returns the name inside an interpolation, read from the
+// tree so that custom delimiters need no special handling.
+func interpolationName(n *tree_sitter.Node, src []byte) string {
+	for i := uint(0); i < n.ChildCount(); i++ {
+		child := n.Child(i)
+		switch child.Kind() {
+		case "mustache_path_expression", "mustache_identifier", "mustache_implicit_iterator", ".":
+			return child.Utf8Text(src)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,111 @@
+package i18n_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/i18n"
+)
+
+func TestExtract(t *testing.T) {
+	src := []byte("<h1>Welcome,\n  {{ user.name }}!</h1>\n" +
+		`<p>Fish &amp; chips <b>today</b></p>` +
+		`<img src="{{src}}" alt="Photo of {{{place}}}" title=Hi>` +
+		`<input placeholder="{{hint}}">` +
+		`<script>var s = "not extracted";</script>` +
+		`{{#items}}<li>{{name}}</li><li>Item {{n}}</li>{{/items}}` +
+		`<p>{{! note }}After</p><span>42 - 7</span>`)
+	messages, err := i18n.Extract(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type message struct {
+		ID        string
+		Attribute string
+		Line      uint
+	}
+	var got []message
+	for _, m := range messages {
+		got = append(got, message{m.ID, m.Attribute, m.Line})
+	}
+	expected := []message{
+		{"Welcome, {user.name}!", "", 1},
+		{"Fish &amp; chips", "", 3},
+		{"today", "", 3},
+		{"Photo of {place}", "alt", 3},
+		{"Hi", "title", 3},
+		{"Item {n}", "", 3},
+		{"After", "", 3},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Extract() =\n%q\nwant\n%q", got, expected)
+	}
+
+	first := messages[0]
+	if got := string(src[first.StartByte:first.EndByte]); got != "Welcome,\n  {{ user.name }}!" {
+		t.Errorf("first message covers %q", got)
+	}
+	expectedPlaceholder := i18n.Placeholder{Name: "{user.name}", Tag: "{{ user.name }}", Offset: 9, StartByte: 15, EndByte: 30}
+	if len(first.Placeholders) != 1 || first.Placeholders[0] != expectedPlaceholder {
+		t.Errorf("placeholders = %+v, want %+v", first.Placeholders, expectedPlaceholder)
+	}
+}
+
+func TestWritePOT(t *testing.T) {
+	files := []i18n.File{
+		{Path: "a.mustache", Messages: mustExtract(t, `<p>Hello "{{name}}"</p><img alt="Logo">`)},
+		{Path: "b.mustache", Messages: mustExtract(t, "\n<img title=Logo>")},
+	}
+	var out bytes.Buffer
+	if err := i18n.WritePOT(&out, files); err != nil {
+		t.Fatal(err)
+	}
+	expected := `msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+
+#. {name} is {{name}}
+#: a.mustache:1
+msgid "Hello \"{name}\""
+msgstr ""
+
+#. attribute: alt
+#. attribute: title
+#: a.mustache:1 b.mustache:2
+msgid "Logo"
+msgstr ""
+`
+	if out.String() != expected {
+		t.Errorf("WritePOT() =\n%s\nwant\n%s", out.String(), expected)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	files := []i18n.File{{Path: "a.mustache", Messages: mustExtract(t, `<p>Hi {{name}}</p>`)}, {Path: "empty.mustache"}}
+	var out bytes.Buffer
+	if err := i18n.WriteJSON(&out, files); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []struct {
+		Path     string
+		Messages []i18n.Message
+	}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 2 || decoded[0].Messages[0].ID != "Hi {name}" || decoded[0].Messages[0].Placeholders[0].Tag != "{{name}}" || decoded[1].Messages == nil {
+		t.Errorf("WriteJSON() = %s", out.String())
+	}
+}
+
+func mustExtract(t *testing.T, src string) []i18n.Message {
+	t.Helper()
+	messages, err := i18n.Extract([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return messages
+}
@@ -0,0 +1,93 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// File is the messages extracted from one template.
+type File struct {
+	// Path is the template's path, as it should appear in references.
+	Path     string
+	Messages []Message
+}
+
+// WritePOT writes the messages of files as a gettext template. Identical
+// strings are merged into one entry listing every reference. Attribute
+// strings carry an extracted comment naming the attribute, and strings
+// with placeholders carry one listing the mustache tags.
+func WritePOT(w io.Writer, files []File) error {
+	type entry struct {
+		id         string
+		references []string
+		comments   []string
+	}
+	var entries []*entry
+	byID := map[string]*entry{}
+	for _, file := range files {
+		for _, m := range file.Messages {
+			e, ok := byID[m.ID]
+			if !ok {
+				e = &entry{id: m.ID}
+				byID[m.ID] = e
+				entries = append(entries, e)
+			}
+			e.references = append(e.references, fmt.Sprintf("%s:%d", file.Path, m.Line))
+			if m.Attribute != "" {
+				e.comments = appendUnique(e.comments, "attribute: "+m.Attribute)
+			}
+			for _, p := range m.Placeholders {
+				e.comments = appendUnique(e.comments, p.Name+" is "+p.Tag)
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("msgid \"\"\nmsgstr \"\"\n\"Content-Type: text/plain; charset=UTF-8\\n\"\n")
+	for _, e := range entries {
+		b.WriteByte('\n')
+		for _, comment := range e.comments {
+			fmt.Fprintf(&b, "#. %s\n", comment)
+		}
+		fmt.Fprintf(&b, "#: %s\n", strings.Join(e.references, " "))
+		fmt.Fprintf(&b, "msgid %s\nmsgstr \"\"\n", poString(e.id))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, existing := range list {
+		if existing == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// poString quotes s as a PO string.
+func poString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`).Replace(s) + `"`
+}
+
+// WriteJSON writes the messages of files as a JSON array of objects with
+// the file path and its messages.
+func WriteJSON(w io.Writer, files []File) error {
+	type jsonFile struct {
+		Path     string    `json:"path"`
+		Messages []Message `json:"messages"`
+	}
+	out := make([]jsonFile, len(files))
+	for i, file := range files {
+		out[i] = jsonFile{file.Path, file.Messages}
+		if out[i].Messages == nil {
+			out[i].Messages = []Message{}
+		}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
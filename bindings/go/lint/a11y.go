@@ -0,0 +1,268 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// AccessibilityRules returns the accessibility rules. They are not among
+// DefaultRules.
+//
+// The rules read attributes the way a template renders them: an attribute
+// whose value is an interpolation, such as alt="{{alt}}", is present, and so
+// is one inside an attribute section, such as {{#alt}}alt="{{.}}"{{/alt}}. A
+// tag with an interpolation in its attribute list, such as <img {{{attrs}}}>,
+// might have any attribute and is not reported as missing one.
+func AccessibilityRules() []Rule {
+	return []Rule{
+		MissingAlt(),
+		UnlabeledFormControls(),
+		HeadingLevels(),
+		DuplicateIDs(),
+	}
+}
+
+// MissingAlt reports <img> tags without an alt attribute. Decorative
+// images should have alt="".
+func MissingAlt() Rule {
+	const name = "missingAlt"
+	return ruleFunc{name, func(root *tree_sitter.Node, src []byte) []Diagnostic {
+		var diagnostics []Diagnostic
+		walk.Visit(root, func(node *tree_sitter.Node) bool {
+			if tagName(node, src) != "img" {
+				return true
+			}
+			if attrs := tagAttributes(node, src); !attrs.spread && attrs.named("alt") == nil {
+				diagnostics = append(diagnostics, At(node, name, Warning, "Missing alt attribute on <img>"))
+			}
+			return false
+		})
+		return diagnostics
+	}}
+}
+
+// unlabeledInputTypes are the <input> types that need no label: they are
+// hidden or labelled by their value or alt text.
+var unlabeledInputTypes = map[string]bool{
+	"hidden": true, "submit": true, "reset": true, "button": true, "image": true,
+}
+
+// UnlabeledFormControls reports <input>, <select> and <textarea> controls
+// without a label. A control is labelled by aria-label, aria-labelledby or
+// title, by an enclosing <label>, or by a <label for> naming its id. An id
+// with an interpolation in it is matched against for attributes as written,
+// and is not reported if none match, as the label may be rendered
+// elsewhere.
+func UnlabeledFormControls() Rule {
+	const name = "unlabeledFormControls"
+	return ruleFunc{name, func(root *tree_sitter.Node, src []byte) []Diagnostic {
+		labelled := map[string]bool{}
+		walk.Visit(root, func(node *tree_sitter.Node) bool {
+			if tagName(node, src) == "label" {
+				if attr := tagAttributes(node, src).named("for"); attr != nil {
+					value, _ := attributeValue(attr, src)
+					labelled[value] = true
+				}
+			}
+			return true
+		})
+
+		var diagnostics []Diagnostic
+		walk.Visit(root, func(node *tree_sitter.Node) bool {
+			tag := tagName(node, src)
+			switch tag {
+			case "input", "select", "textarea":
+			default:
+				return true
+			}
+			attrs := tagAttributes(node, src)
+			if attrs.spread || insideLabel(node, src) ||
+				attrs.named("aria-label") != nil || attrs.named("aria-labelledby") != nil || attrs.named("title") != nil {
+				return false
+			}
+			if tag == "input" {
+				if attr := attrs.named("type"); attr != nil {
+					inputType, dynamic := attributeValue(attr, src)
+					if dynamic || unlabeledInputTypes[strings.ToLower(inputType)] {
+						return false
+					}
+				}
+			}
+			if attr := attrs.named("id"); attr != nil {
+				id, dynamic := attributeValue(attr, src)
+				if dynamic || labelled[id] {
+					return false
+				}
+			}
+			diagnostics = append(diagnostics, At(node, name, Warning, fmt.Sprintf("Form control <%s> has no label", tag)))
+			return false
+		})
+		return diagnostics
+	}}
+}
+
+// insideLabel reports whether the tag is the start tag of an element inside
+// a <label>.
+func insideLabel(tag *tree_sitter.Node, src []byte) bool {
+	for n := tag.Parent(); n != nil; n = n.Parent() {
+		if n.Kind() == "html_element" && n.Child(0) != nil && tagName(n.Child(0), src) == "label" && n.Child(0) != tag {
+			return true
+		}
+	}
+	return false
+}
+
+// HeadingLevels reports headings that are more than one level below the
+// heading before them, e.g. an <h4> after an <h2>, which breaks the outline
+// screen readers navigate by.
+func HeadingLevels() Rule {
+	const name = "headingLevels"
+	return ruleFunc{name, func(root *tree_sitter.Node, src []byte) []Diagnostic {
+		var diagnostics []Diagnostic
+		previous := 0
+		walk.Visit(root, func(node *tree_sitter.Node) bool {
+			tag := tagName(node, src)
+			if len(tag) != 2 || tag[0] != 'h' || tag[1] < '1' || tag[1] > '6' {
+				return true
+			}
+			level := int(tag[1] - '0')
+			if previous > 0 && level > previous+1 {
+				message := fmt.Sprintf("Heading <%s> skips a level after <h%d>", tag, previous)
+				diagnostics = append(diagnostics, At(node, name, Warning, message))
+			}
+			previous = level
+			return true
+		})
+		return diagnostics
+	}}
+}
+
+// DuplicateIDs reports id attributes with the same value as an earlier one.
+// Ids with an interpolation in them, and ids in mutually exclusive sections,
+// such as {{#a}}<p id="x">{{/a}}{{^a}}<p id="x">{{/a}}, are not compared.
+func DuplicateIDs() Rule {
+	const name = "duplicateIds"
+	return ruleFunc{name, func(root *tree_sitter.Node, src []byte) []Diagnostic {
+		var diagnostics []Diagnostic
+		seen := map[string][][]condition{}
+		walk.Visit(root, func(node *tree_sitter.Node) bool {
+			switch node.Kind() {
+			case "html_start_tag", "html_self_closing_tag":
+			default:
+				return true
+			}
+			for _, attr := range tagAttributes(node, src).list {
+				if attr.name != "id" {
+					continue
+				}
+				id, dynamic := attributeValue(attr.node, src)
+				if dynamic || id == "" {
+					continue
+				}
+				conditions := append(enclosingSections(node, src), attr.conditions...)
+				for _, previous := range seen[id] {
+					if !exclusive(previous, conditions) {
+						diagnostics = append(diagnostics, At(attr.node, name, Warning, fmt.Sprintf("Duplicate id %q", id)))
+						break
+					}
+				}
+				seen[id] = append(seen[id], conditions)
+			}
+			return false
+		})
+		return diagnostics
+	}}
+}
+
+// enclosingSections returns the sections around node, outermost first.
+func enclosingSections(node *tree_sitter.Node, src []byte) []condition {
+	var conditions []condition
+	for n := node.Parent(); n != nil; n = n.Parent() {
+		switch n.Kind() {
+		case "mustache_section", "mustache_inverted_section":
+			if section := sectionName(n, src); section != "" {
+				conditions = append([]condition{{section, n.Kind() == "mustache_inverted_section"}}, conditions...)
+			}
+		}
+	}
+	return conditions
+}
+
+// tagName returns the lowercased name of a start or self-closing tag, or ""
+// for other nodes.
+func tagName(node *tree_sitter.Node, src []byte) string {
+	switch node.Kind() {
+	case "html_start_tag", "html_self_closing_tag":
+		if name := walk.ChildOfKind(node, "html_tag_name"); name != nil {
+			return strings.ToLower(name.Utf8Text(src))
+		}
+	}
+	return ""
+}
+
+// attributes are the attributes of a tag.
+type attributes struct {
+	// list holds the attributes, including those in sections.
+	list []attribute
+	// spread is set if the tag has an interpolation among its attributes.
+	spread bool
+}
+
+// attribute is an html_attribute node, its lowercased name and the
+// sections it is in.
+type attribute struct {
+	node       *tree_sitter.Node
+	name       string
+	conditions []condition
+}
+
+func tagAttributes(tag *tree_sitter.Node, src []byte) attributes {
+	var attrs attributes
+	for _, name := range attributeNames(tag, nil, src, nil) {
+		attrs.list = append(attrs.list, attribute{name.node.Parent(), strings.ToLower(name.node.Utf8Text(src)), name.conditions})
+	}
+	for i := uint(0); i < tag.ChildCount(); i++ {
+		switch tag.Child(i).Kind() {
+		case "mustache_interpolation", "mustache_triple":
+			attrs.spread = true
+		}
+	}
+	return attrs
+}
+
+// named returns the first html_attribute called name, or nil.
+func (a attributes) named(name string) *tree_sitter.Node {
+	for _, attr := range a.list {
+		if attr.name == name {
+			return attr.node
+		}
+	}
+	return nil
+}
+
+// attributeValue returns the value of an html_attribute without its
+// quotes, and whether it has mustache tags in it.
+func attributeValue(attr *tree_sitter.Node, src []byte) (string, bool) {
+	for i := uint(0); i < attr.ChildCount(); i++ {
+		switch child := attr.Child(i); child.Kind() {
+		case "html_attribute_value":
+			return child.Utf8Text(src), false
+		case "html_quoted_attribute_value":
+			text := child.Utf8Text(src)
+			dynamic := false
+			for j := uint(0); j < child.NamedChildCount(); j++ {
+				if strings.HasPrefix(child.NamedChild(j).Kind(), "mustache_") {
+					dynamic = true
+				}
+			}
+			return text[1 : len(text)-1], dynamic
+		case "mustache_interpolation":
+			return child.Utf8Text(src), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,138 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// syntaxRule is the rule name of diagnostics produced by Diagnose.
+const syntaxRule = "syntax"
+
+// Diagnose turns the ERROR and MISSING nodes of tree into readable
+// diagnostics, such as "unclosed section 'items' opened at line 12" or
+// "expected closing tag </div>".
+func Diagnose(tree *tree_sitter.Tree, src []byte) []Diagnostic {
+	diagnostics := diagnose(tree.RootNode(), src)
+	sortDiagnostics(diagnostics)
+	return diagnostics
+}
+
+// SyntaxErrors returns a rule reporting the diagnostics of Diagnose.
+func SyntaxErrors() Rule {
+	return ruleFunc{syntaxRule, diagnose}
+}
+
+func diagnose(root *tree_sitter.Node, src []byte) []Diagnostic {
+	var diagnostics []Diagnostic
+	walk.Visit(root, func(node *tree_sitter.Node) bool {
+		switch {
+		case node.IsMissing():
+			diagnostics = append(diagnostics, At(node, syntaxRule, Error, missingMessage(node, src)))
+			return false
+		case node.IsError():
+			diagnostics = append(diagnostics, errorDiagnostics(node, src)...)
+			// Nested ERROR nodes describe the same problem.
+			for i := uint(0); i < node.ChildCount(); i++ {
+				if child := node.Child(i); !child.IsError() {
+					diagnostics = append(diagnostics, diagnose(child, src)...)
+				}
+			}
+			return false
+		case node.HasError() && !childHasError(node):
+			// The error is a hidden MISSING node, which is not visible as a
+			// child. The only hidden token the parser inserts is the
+			// implicit end tag of an element.
+			diagnostics = append(diagnostics, atEnd(node, missingMessage(node, src)))
+			return false
+		}
+		return node.HasError()
+	})
+	return diagnostics
+}
+
+func childHasError(node *tree_sitter.Node) bool {
+	for i := uint(0); i < node.ChildCount(); i++ {
+		if node.Child(i).HasError() {
+			return true
+		}
+	}
+	return false
+}
+
+// atEnd returns an empty syntax diagnostic at the end of node.
+func atEnd(node *tree_sitter.Node, message string) Diagnostic {
+	return Diagnostic{
+		Rule:       syntaxRule,
+		Severity:   Error,
+		Message:    message,
+		StartByte:  node.EndByte(),
+		EndByte:    node.EndByte(),
+		StartPoint: node.EndPosition(),
+		EndPoint:   node.EndPosition(),
+	}
+}
+
+// missingMessage describes the token missing from node.
+func missingMessage(node *tree_sitter.Node, src []byte) string {
+	element := node
+	if node.IsMissing() {
+		if node.Kind() != "_html_implicit_end_tag" {
+			return fmt.Sprintf("expected %q", node.Kind())
+		}
+		element = node.Parent()
+	}
+	if element != nil && element.Kind() == "html_element" {
+		if start := walk.ChildOfKind(element, "html_start_tag"); start != nil {
+			if tag := walk.ChildOfKind(start, "html_tag_name"); tag != nil {
+				return fmt.Sprintf("expected closing tag </%s> for <%s> opened at line %d",
+					tag.Utf8Text(src), tag.Utf8Text(src), start.StartPosition().Row+1)
+			}
+		}
+	}
+	return fmt.Sprintf("syntax error in %s", node.Kind())
+}
+
+// errorDiagnostics explains an ERROR node. Parse errors usually come from a
+// section or element that is never closed, whose opening tag is left as a
+// direct child of the ERROR node. Unclosed sections are reported first, as an
+// element around them is often closed but looks unclosed to the parser.
+func errorDiagnostics(node *tree_sitter.Node, src []byte) []Diagnostic {
+	var sections, tags []Diagnostic
+	for i := uint(0); i < node.ChildCount(); i++ {
+		child := node.Child(i)
+		switch child.Kind() {
+		case "mustache_section_begin", "mustache_inverted_section_begin":
+			if tag := walk.ChildOfKind(child, "mustache_tag_name"); tag != nil {
+				message := fmt.Sprintf("unclosed section '%s' opened at line %d", tag.Utf8Text(src), child.StartPosition().Row+1)
+				sections = append(sections, At(child, syntaxRule, Error, message))
+			}
+		case "html_start_tag":
+			if tag := walk.ChildOfKind(child, "html_tag_name"); tag != nil {
+				message := fmt.Sprintf("unclosed tag <%s> opened at line %d", tag.Utf8Text(src), child.StartPosition().Row+1)
+				tags = append(tags, At(child, syntaxRule, Error, message))
+			}
+		}
+	}
+	switch {
+	case len(sections) > 0:
+		return sections
+	case len(tags) > 0:
+		return tags
+	}
+	return []Diagnostic{At(node, syntaxRule, Error, fmt.Sprintf("syntax error near %q", snippet(node.Utf8Text(src))))}
+}
+
+// snippet shortens text to its first line, capped at 20 bytes.
+func snippet(text string) string {
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	if len(text) > 20 {
+		text = text[:20] + "..."
+	}
+	return text
+}
@@ -0,0 +1,204 @@
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// DirectiveKind says which diagnostics a directive disables.
+type DirectiveKind int
+
+const (
+	// DisableFile disables rules in the whole file:
+	// {{! htmlmustache-disable rule }}.
+	DisableFile DirectiveKind = iota
+	// DisableLine disables rules on the line the directive starts on:
+	// {{! htmlmustache-disable-line rule }}.
+	DisableLine
+	// DisableNextLine disables rules on the line after the directive:
+	// {{! htmlmustache-disable-next-line rule }}.
+	DisableNextLine
+)
+
+// directivePrefixes maps the word a directive comment starts with to its
+// kind.
+var directivePrefixes = map[string]DirectiveKind{
+	"htmlmustache-disable":           DisableFile,
+	"htmlmustache-disable-line":      DisableLine,
+	"htmlmustache-disable-next-line": DisableNextLine,
+}
+
+func (k DirectiveKind) String() string {
+	for prefix, kind := range directivePrefixes {
+		if kind == k {
+			return prefix
+		}
+	}
+	return "unknown"
+}
+
+// Directive is a mustache or HTML comment that disables lint rules, such as
+// {{! htmlmustache-disable-next-line unescapedAttribute }}. Rules are
+// separated by spaces or commas, and text after " -- " is a reason, which is
+// ignored.
+type Directive struct {
+	Kind DirectiveKind
+	// Rules are the rule names the directive disables; if there are none,
+	// it disables every rule.
+	Rules []string
+	// Row is the zero-based line the directive applies to; it is unused
+	// for DisableFile.
+	Row uint
+	// StartByte and EndByte delimit the comment, and StartPoint and
+	// EndPoint give the same range as rows and columns.
+	StartByte  uint
+	EndByte    uint
+	StartPoint tree_sitter.Point
+	EndPoint   tree_sitter.Point
+}
+
+// Directives returns the directives in the tree rooted at root, in document
+// order.
+func Directives(root *tree_sitter.Node, src []byte) []Directive {
+	var directives []Directive
+	walk.Visit(root, func(comment *tree_sitter.Node) bool {
+		var text string
+		switch comment.Kind() {
+		case "html_comment":
+			text = strings.TrimSuffix(strings.TrimPrefix(comment.Utf8Text(src), "<!--"), "-->")
+		case "mustache_comment":
+			if content := walk.ChildOfKind(comment, "mustache_comment_content"); content != nil {
+				text = content.Utf8Text(src)
+			}
+		default:
+			return true
+		}
+		if d, ok := parseDirective(text); ok {
+			d.StartByte, d.EndByte = comment.StartByte(), comment.EndByte()
+			d.StartPoint, d.EndPoint = comment.StartPosition(), comment.EndPosition()
+			switch d.Kind {
+			case DisableLine:
+				d.Row = d.StartPoint.Row
+			case DisableNextLine:
+				d.Row = d.EndPoint.Row + 1
+			}
+			directives = append(directives, d)
+		}
+		return false
+	})
+	return directives
+}
+
+// parseDirective parses the text of a comment, without its delimiters.
+func parseDirective(text string) (Directive, bool) {
+	text, _, _ = strings.Cut(text, " -- ")
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || strings.ContainsRune(" \t\r\n", r)
+	})
+	if len(fields) == 0 {
+		return Directive{}, false
+	}
+	kind, ok := directivePrefixes[fields[0]]
+	if !ok {
+		return Directive{}, false
+	}
+	return Directive{Kind: kind, Rules: fields[1:]}, true
+}
+
+// disables reports whether the directive disables d.
+func (dir Directive) disables(d Diagnostic) bool {
+	if len(dir.Rules) > 0 && !slices.Contains(dir.Rules, d.Rule) {
+		return false
+	}
+	return dir.Kind == DisableFile || d.StartPoint.Row == dir.Row
+}
+
+// Suppress returns the diagnostics that none of directives disables, and the
+// directives that disable none of diagnostics.
+func Suppress(diagnostics []Diagnostic, directives []Directive) (kept []Diagnostic, unused []Directive) {
+	used := make([]bool, len(directives))
+	for _, d := range diagnostics {
+		suppressed := false
+		for i, dir := range directives {
+			if dir.disables(d) {
+				used[i], suppressed = true, true
+			}
+		}
+		if !suppressed {
+			kept = append(kept, d)
+		}
+	}
+	for i, dir := range directives {
+		if !used[i] {
+			unused = append(unused, dir)
+		}
+	}
+	return kept, unused
+}
+
+// unusedDirectivesRule is the rule name of diagnostics about directives
+// that disable nothing.
+const unusedDirectivesRule = "unusedDisableDirectives"
+
+// unusedDirectives is the rule returned by UnusedDirectives. It has no check
+// of its own: LintTree reports the unused directives when it is among the
+// rules, as only then are the other rules' diagnostics known.
+type unusedDirectives struct{}
+
+func (unusedDirectives) Name() string { return unusedDirectivesRule }
+
+func (unusedDirectives) Check(*tree_sitter.Node, []byte) []Diagnostic { return nil }
+
+// UnusedDirectives returns a rule reporting directives that disable none of
+// the diagnostics of the rules run with it. Rules a directive names that
+// none of those rules report are left out, as the directive may be for
+// another run, such as an audit; directives listing only such rules are not
+// reported.
+func UnusedDirectives() Rule {
+	return unusedDirectives{}
+}
+
+// Reporter is implemented by rules that report diagnostics under names other
+// than their own Name, such as the audit checks. Each of the names can be
+// disabled by directives and is known to UnusedDirectives.
+type Reporter interface {
+	Rule
+	// Reports returns the rule names of the rule's diagnostics.
+	Reports() []string
+}
+
+// unusedDiagnostics returns a diagnostic for each unused directive that
+// names no rules, or names a rule among known.
+func unusedDiagnostics(unused []Directive, known map[string]bool) []Diagnostic {
+	var diagnostics []Diagnostic
+	for _, dir := range unused {
+		var rules []string
+		for _, rule := range dir.Rules {
+			if known[rule] {
+				rules = append(rules, rule)
+			}
+		}
+		message := fmt.Sprintf("Unused %s directive: nothing is reported here", dir.Kind)
+		switch {
+		case len(dir.Rules) > 0 && len(rules) == 0:
+			continue
+		case len(rules) > 0:
+			message = fmt.Sprintf("Unused %s directive: %s reports nothing here", dir.Kind, strings.Join(rules, ", "))
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			Rule:       unusedDirectivesRule,
+			Severity:   Warning,
+			Message:    message,
+			StartByte:  dir.StartByte,
+			EndByte:    dir.EndByte,
+			StartPoint: dir.StartPoint,
+			EndPoint:   dir.EndPoint,
+		})
+	}
+	return diagnostics
+}
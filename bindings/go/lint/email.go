@@ -0,0 +1,434 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// EmailRules returns the rules for HTML email, which clients render with
+// far less of HTML and CSS than browsers. They are not among DefaultRules.
+//
+// Like the accessibility rules, they read a template the way it renders:
+// Mustache tags in a style attribute are left out of the CSS checked, and
+// attributes inside attribute sections count as present. The markup of a
+// hidden conditional comment, <!--[if mso]>...<![endif]-->, is for Outlook
+// alone, so only EmailConditionalComments looks inside it.
+func EmailRules() []Rule {
+	return []Rule{
+		EmailUnsupportedTags(),
+		EmailUnsupportedCSS(),
+		EmailTableLayout(),
+		EmailInlineStyles(),
+		EmailConditionalComments(),
+	}
+}
+
+// emailUnsupportedTags maps the tags email clients do not support to
+// whether clients remove the element with its content, rather than just
+// not rendering it as intended.
+var emailUnsupportedTags = map[string]bool{
+	"script": true, "iframe": true, "object": true, "embed": true, "applet": true,
+	"frame": true, "frameset": true, "noscript": true, "base": true,
+	"form": false, "input": false, "button": false, "select": false, "textarea": false,
+	"video": false, "audio": false, "canvas": false, "svg": false,
+}
+
+// EmailUnsupportedTags reports elements that email clients remove or do
+// not render, such as <script> and <form>, and <link> stylesheets, which
+// most clients do not load. Tags whose content is removed with them are
+// errors.
+func EmailUnsupportedTags() Rule {
+	const name = "emailUnsupportedTags"
+	return ruleFunc{name, func(root *tree_sitter.Node, src []byte) []Diagnostic {
+		var diagnostics []Diagnostic
+		walkEmail(root, func(node *tree_sitter.Node) bool {
+			tag := tagName(node, src)
+			if tag == "link" {
+				if rel := tagAttributes(node, src).named("rel"); rel != nil {
+					if value, _ := attributeValue(rel, src); strings.EqualFold(value, "stylesheet") {
+						diagnostics = append(diagnostics, At(node, name, Warning, "Email clients do not load linked stylesheets; inline the styles"))
+					}
+				}
+				return false
+			}
+			removed, unsupported := emailUnsupportedTags[tag]
+			if !unsupported {
+				return true
+			}
+			severity := Warning
+			if removed {
+				severity = Error
+			}
+			diagnostics = append(diagnostics, At(node, name, severity, fmt.Sprintf("<%s> is not supported by email clients", tag)))
+			return false
+		})
+		return diagnostics
+	}}
+}
+
+// emailUnsupportedCSS are CSS patterns email clients ignore, with what to
+// call them in messages.
+var emailUnsupportedCSS = []struct {
+	pattern *regexp.Regexp
+	what    string
+}{
+	{regexp.MustCompile(`(?i)(?:^|[;{\s"'])position\s*:\s*(?:absolute|fixed|sticky)\b`), "position"},
+	{regexp.MustCompile(`(?i)(?:^|[;{\s"'])display\s*:\s*(?:inline-)?(?:flex|grid)\b`), "flex and grid layout"},
+	{regexp.MustCompile(`(?i)(?:^|[;{\s"'])float\s*:`), "float"},
+	{regexp.MustCompile(`(?i)(?:^|[;{\s"'])(?:transform|animation|transition)(?:-[a-z-]+)?\s*:`), "transforms and animations"},
+	{regexp.MustCompile(`(?i)\bvar\(\s*--`), "custom properties"},
+	{regexp.MustCompile(`(?i)\bcalc\(`), "calc()"},
+	{regexp.MustCompile(`(?i)@import\b`), "@import"},
+}
+
+// EmailUnsupportedCSS reports CSS that many email clients ignore, such as
+// flex layout, positioning and custom properties, in style attributes and
+// <style> elements.
+func EmailUnsupportedCSS() Rule {
+	const name = "emailUnsupportedCss"
+	return ruleFunc{name, func(root *tree_sitter.Node, src []byte) []Diagnostic {
+		var diagnostics []Diagnostic
+		report := func(css string, start uint, startPoint tree_sitter.Point) {
+			for _, c := range emailUnsupportedCSS {
+				for _, m := range c.pattern.FindAllStringIndex(css, -1) {
+					// Leave out the separator the match starts with.
+					from := m[0] + len(css[m[0]:m[1]]) - len(strings.TrimLeft(css[m[0]:m[1]], "; {\t\r\n\"'"))
+					diagnostics = append(diagnostics, Diagnostic{
+						Rule:       name,
+						Severity:   Warning,
+						Message:    fmt.Sprintf("Many email clients do not support %s", c.what),
+						StartByte:  start + uint(from),
+						EndByte:    start + uint(m[1]),
+						StartPoint: advance(startPoint, css[:from]),
+						EndPoint:   advance(startPoint, css[:m[1]]),
+					})
+				}
+			}
+		}
+		walkEmail(root, func(node *tree_sitter.Node) bool {
+			switch node.Kind() {
+			case "html_style_element":
+				if text := walk.ChildOfKind(node, "html_raw_text"); text != nil {
+					report(text.Utf8Text(src), text.StartByte(), text.StartPosition())
+				}
+				return false
+			case "html_start_tag", "html_self_closing_tag":
+				for _, attr := range tagAttributes(node, src).list {
+					if attr.name != "style" {
+						continue
+					}
+					if value := walk.ChildOfKind(attr.node, "html_quoted_attribute_value"); value != nil {
+						report(staticText(value, src), value.StartByte(), value.StartPosition())
+					} else if value := walk.ChildOfKind(attr.node, "html_attribute_value"); value != nil {
+						report(value.Utf8Text(src), value.StartByte(), value.StartPosition())
+					}
+				}
+				return false
+			}
+			return true
+		})
+		return diagnostics
+	}}
+}
+
+// staticText returns the text of node with its Mustache tags blanked out,
+// so that offsets in it are offsets in node.
+func staticText(node *tree_sitter.Node, src []byte) string {
+	text := []byte(node.Utf8Text(src))
+	for i := uint(0); i < node.NamedChildCount(); i++ {
+		child := node.NamedChild(i)
+		if strings.HasPrefix(child.Kind(), "mustache_") {
+			start, end := child.StartByte()-node.StartByte(), child.EndByte()-node.StartByte()
+			for j := start; j < end; j++ {
+				if text[j] != '\n' {
+					text[j] = ' '
+				}
+			}
+		}
+	}
+	return string(text)
+}
+
+// advance returns the point after text, starting at p.
+func advance(p tree_sitter.Point, text string) tree_sitter.Point {
+	if i := strings.LastIndexByte(text, '\n'); i >= 0 {
+		return tree_sitter.Point{Row: p.Row + uint(strings.Count(text, "\n")), Column: uint(len(text) - i - 1)}
+	}
+	return tree_sitter.Point{Row: p.Row, Column: p.Column + uint(len(text))}
+}
+
+// EmailTableLayout reports templates laid out without tables, and layout
+// tables without the markers email clients need. A template with a <body>
+// but no <table> is laid out with other elements, which Outlook does not
+// size. A table without <th> cells is taken for a layout table, which
+// needs role="presentation" for screen readers and border, cellpadding and
+// cellspacing attributes for clients that add their own spacing.
+func EmailTableLayout() Rule {
+	const name = "emailTableLayout"
+	return ruleFunc{name, func(root *tree_sitter.Node, src []byte) []Diagnostic {
+		var diagnostics []Diagnostic
+		var body *tree_sitter.Node
+		tables := 0
+		walkEmail(root, func(node *tree_sitter.Node) bool {
+			switch tagName(node, src) {
+			case "body":
+				body = node
+			case "table":
+				tables++
+				attrs := tagAttributes(node, src)
+				if attrs.spread || hasHeaderCells(node.Parent(), src) {
+					return false
+				}
+				if role := attrs.named("role"); role == nil {
+					diagnostics = append(diagnostics, At(node, name, Warning, `Layout table should have role="presentation"`))
+				}
+				var missing []string
+				for _, marker := range []string{"border", "cellpadding", "cellspacing"} {
+					if attrs.named(marker) == nil {
+						missing = append(missing, marker)
+					}
+				}
+				if len(missing) > 0 {
+					diagnostics = append(diagnostics, At(node, name, Warning,
+						fmt.Sprintf("Layout table should set %s", strings.Join(missing, ", "))))
+				}
+				return false
+			}
+			return true
+		})
+		if body != nil && tables == 0 {
+			diagnostics = append(diagnostics, At(body, name, Warning, "Email layout should use tables"))
+		}
+		return diagnostics
+	}}
+}
+
+// hasHeaderCells reports whether the table element has <th> cells of its
+// own, outside nested tables.
+func hasHeaderCells(table *tree_sitter.Node, src []byte) bool {
+	found := false
+	walkEmail(table, func(node *tree_sitter.Node) bool {
+		if node.Kind() == "html_element" && node.Id() != table.Id() && elementName(node, src) == "table" {
+			return false
+		}
+		found = found || tagName(node, src) == "th"
+		return !found
+	})
+	return found
+}
+
+// EmailInlineStyles reports elements styled by class alone. Several
+// clients remove <style> elements, so email styles are inlined, with
+// classes left for the media queries of clients that keep them.
+func EmailInlineStyles() Rule {
+	const name = "emailInlineStyles"
+	return ruleFunc{name, func(root *tree_sitter.Node, src []byte) []Diagnostic {
+		var diagnostics []Diagnostic
+		walkEmail(root, func(node *tree_sitter.Node) bool {
+			tag := tagName(node, src)
+			if tag == "" {
+				return true
+			}
+			attrs := tagAttributes(node, src)
+			if !attrs.spread && attrs.named("class") != nil && attrs.named("style") == nil {
+				diagnostics = append(diagnostics, At(node, name, Warning, fmt.Sprintf("<%s> is styled by class only; inline its styles", tag)))
+			}
+			return true
+		})
+		return diagnostics
+	}}
+}
+
+var (
+	revealedClose      = regexp.MustCompile(`^<!--\s*<!\[endif\]\s*-->$`)
+	conditionToken     = regexp.MustCompile(`(?i)^(?:mso|ie|gte|gt|lte|lt|true|false|!|&|\||\(|\)|\d+(?:\.\d+)?)$`)
+	conditionTokenizer = regexp.MustCompile(`[!&|()]|[^\s!&|()]+`)
+)
+
+// walkEmail is walk for the email rules. It leaves out the markup of hidden
+// conditional comments, which only Outlook renders.
+func walkEmail(root *tree_sitter.Node, visit func(node *tree_sitter.Node) bool) {
+	walk.Visit(root, func(node *tree_sitter.Node) bool {
+		if node.Kind() == "html_conditional_comment" && node.ChildByFieldName("reveal") == nil {
+			return false
+		}
+		return visit(node)
+	})
+}
+
+// EmailConditionalComments checks the conditional comments that target
+// Outlook: <!--[if mso]>...<![endif]--> hides markup from other clients,
+// and <!--[if !mso]><!-->...<!--<![endif]--> hides markup from Outlook. It
+// reports comments without their <![endif]>, conditions other than the
+// mso and IE version tests, and syntax errors in the template as Outlook
+// sees it, with the markup of mso comments in it and that of !mso ones
+// left out. Ghost tables, whose tags open in one mso comment and close in
+// another, are checked that way.
+func EmailConditionalComments() Rule {
+	const name = "emailConditionalComments"
+	return ruleFunc{name, func(root *tree_sitter.Node, src []byte) []Diagnostic {
+		var diagnostics []Diagnostic
+		// outlook and others are the ranges of src that Outlook and the
+		// other clients leave out: the delimiters of the conditional
+		// comments whose markup they render and the whole of the rest.
+		var outlook, others [][2]uint
+		walk.Visit(root, func(node *tree_sitter.Node) bool {
+			if node.Kind() == "html_comment" {
+				if revealedClose.MatchString(node.Utf8Text(src)) {
+					diagnostics = append(diagnostics, At(node, name, Error, "<![endif]> without a conditional comment to close"))
+				}
+				return false
+			}
+			if node.Kind() != "html_conditional_comment" {
+				return true
+			}
+			condition := ""
+			if n := node.ChildByFieldName("condition"); n != nil {
+				condition = n.Utf8Text(src)
+			}
+			for _, token := range conditionTokenizer.FindAllString(condition, -1) {
+				if !conditionToken.MatchString(token) {
+					diagnostics = append(diagnostics, At(node, name, Warning, fmt.Sprintf("Unknown condition %q in conditional comment", condition)))
+					break
+				}
+			}
+
+			reveal := node.ChildByFieldName("reveal")
+			open := [2]uint{node.StartByte(), conditionalOpenEnd(node, reveal)}
+			end := node.Child(node.ChildCount() - 1)
+			switch {
+			case end.IsMissing() && reveal != nil:
+				diagnostics = append(diagnostics, atRange(src, open, name, Error, "Conditional comment is not closed by <!--<![endif]-->"))
+			case end.IsMissing():
+				diagnostics = append(diagnostics, atRange(src, open, name, Error, "Conditional comment has no <![endif]>"))
+			case reveal != nil && !strings.HasPrefix(end.Utf8Text(src), "<!--"):
+				diagnostics = append(diagnostics, At(end, name, Error, "Conditional comment is closed by <![endif]--> rather than <!--<![endif]-->"))
+			case reveal == nil && strings.HasPrefix(end.Utf8Text(src), "<!--"):
+				diagnostics = append(diagnostics, At(end, name, Error, "Conditional comment is closed by <!--<![endif]--> rather than <![endif]-->"))
+			}
+
+			whole := [2]uint{node.StartByte(), node.EndByte()}
+			closing := [2]uint{end.StartByte(), node.EndByte()}
+			if outlookCondition(condition) {
+				outlook = append(outlook, open, closing)
+			} else {
+				outlook = append(outlook, whole)
+			}
+			if reveal != nil {
+				others = append(others, open, closing)
+			} else {
+				others = append(others, whole)
+			}
+			return false
+		})
+		if len(outlook) > 0 {
+			diagnostics = append(diagnostics, outlookDiagnostics(name, src, outlook, others)...)
+		}
+		return diagnostics
+	}}
+}
+
+// conditionalOpenEnd returns the end of the delimiter the conditional
+// comment node starts with, <!--[if condition]>, followed by reveal, the
+// <!--> of a revealed comment, if that is not nil.
+func conditionalOpenEnd(node, reveal *tree_sitter.Node) uint {
+	if reveal != nil {
+		return reveal.EndByte()
+	}
+	for i := uint(0); i < node.ChildCount(); i++ {
+		if child := node.Child(i); child.Kind() == "]>" {
+			return child.EndByte()
+		}
+	}
+	return node.StartByte()
+}
+
+// atRange returns a diagnostic of rule for the range r of src.
+func atRange(src []byte, r [2]uint, rule string, severity Severity, message string) Diagnostic {
+	return Diagnostic{
+		Rule:       rule,
+		Severity:   severity,
+		Message:    message,
+		StartByte:  r[0],
+		EndByte:    r[1],
+		StartPoint: advance(tree_sitter.Point{}, string(src[:r[0]])),
+		EndPoint:   advance(tree_sitter.Point{}, string(src[:r[1]])),
+	}
+}
+
+// outlookCondition reports whether Outlook takes the condition of a
+// conditional comment to be true: whether it tests for mso without
+// negating it. Other conditions, which target old versions of Internet
+// Explorer, are false.
+func outlookCondition(condition string) bool {
+	condition = strings.ToLower(strings.TrimSpace(condition))
+	return strings.Contains(condition, "mso") && !strings.HasPrefix(condition, "!")
+}
+
+// outlookDiagnostics returns the syntax errors in src as Outlook sees it,
+// without the ranges in outlook, that are not in it as other clients see
+// it, without the ranges in others, as diagnostics of rule. Both lists are
+// in document order.
+func outlookDiagnostics(rule string, src []byte, outlook, others [][2]uint) []Diagnostic {
+	outlookTree := parseWithout(src, outlook)
+	if outlookTree == nil {
+		return nil
+	}
+	defer outlookTree.Close()
+	othersTree := parseWithout(src, others)
+	if othersTree == nil {
+		return nil
+	}
+	defer othersTree.Close()
+
+	known := map[string]bool{}
+	for _, d := range diagnose(othersTree.RootNode(), src) {
+		known[fmt.Sprint(d.StartByte, d.Message)] = true
+	}
+	var diagnostics []Diagnostic
+	for _, d := range diagnose(outlookTree.RootNode(), src) {
+		if !known[fmt.Sprint(d.StartByte, d.Message)] {
+			d.Rule = rule
+			d.Message = "In Outlook: " + d.Message
+			diagnostics = append(diagnostics, d)
+		}
+	}
+	return diagnostics
+}
+
+// parseWithout parses src without the ranges in hidden, which are in
+// document order. It returns nil if nothing is left.
+func parseWithout(src []byte, hidden [][2]uint) *tree_sitter.Tree {
+	var ranges []tree_sitter.Range
+	start := uint(0)
+	for _, h := range append(hidden, [2]uint{uint(len(src)), uint(len(src))}) {
+		if h[0] > start {
+			ranges = append(ranges, tree_sitter.Range{
+				StartByte:  start,
+				EndByte:    h[0],
+				StartPoint: advance(tree_sitter.Point{}, string(src[:start])),
+				EndPoint:   advance(tree_sitter.Point{}, string(src[:h[0]])),
+			})
+		}
+		start = max(start, h[1])
+	}
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		return nil
+	}
+	if err := parser.SetIncludedRanges(ranges); err != nil {
+		return nil
+	}
+	return parser.Parse(src, nil)
+}
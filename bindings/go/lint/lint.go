@@ -0,0 +1,146 @@
+// Package lint checks htmlmustache templates for common mistakes using
+// pluggable rules over the tree-sitter parse tree.
+package lint
+
+import (
+	"context"
+	"sort"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+)
+
+// Severity is how serious a diagnostic is.
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	}
+	return "unknown"
+}
+
+// Diagnostic is a single problem found by a rule.
+type Diagnostic struct {
+	// Rule is the name of the rule that reported the problem.
+	Rule     string
+	Severity Severity
+	Message  string
+	// StartByte and EndByte delimit the offending source, and StartPoint
+	// and EndPoint give the same range as zero-based rows and columns.
+	StartByte  uint
+	EndByte    uint
+	StartPoint tree_sitter.Point
+	EndPoint   tree_sitter.Point
+}
+
+// At returns a diagnostic covering node.
+func At(node *tree_sitter.Node, rule string, severity Severity, message string) Diagnostic {
+	return Diagnostic{
+		Rule:       rule,
+		Severity:   severity,
+		Message:    message,
+		StartByte:  node.StartByte(),
+		EndByte:    node.EndByte(),
+		StartPoint: node.StartPosition(),
+		EndPoint:   node.EndPosition(),
+	}
+}
+
+// Rule is a single lint check.
+type Rule interface {
+	// Name identifies the rule in diagnostics, e.g. "duplicateAttributes".
+	Name() string
+	// Check returns the problems the rule finds in the tree rooted at root.
+	Check(root *tree_sitter.Node, src []byte) []Diagnostic
+}
+
+// DefaultRules returns the built-in rules that need no configuration.
+// UndefinedPartials is left out as it needs a resolver.
+func DefaultRules() []Rule {
+	return []Rule{
+		SyntaxErrors(),
+		MismatchedSections(),
+		UnclosedTags(),
+		DuplicateAttributes(),
+		InvalidNesting(),
+		UnescapedAttributeInterpolation(),
+		BooleanAttributes(),
+	}
+}
+
+// Lint parses src and runs rules over it.
+func Lint(src []byte, rules []Rule) ([]Diagnostic, error) {
+	return LintContext(context.Background(), src, rules)
+}
+
+// LintContext is like Lint, but stops and returns ctx.Err() if ctx is done
+// before the parse or a rule completes.
+func LintContext(ctx context.Context, src []byte, rules []Rule) ([]Diagnostic, error) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		return nil, err
+	}
+	tree, err := tree_sitter_htmlmustache.ParseContext(ctx, parser, src)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+	return LintTreeContext(ctx, tree.RootNode(), src, rules)
+}
+
+// LintTree runs rules over an already parsed tree. Diagnostics that the
+// template's directives disable are left out, and the rest are sorted by
+// position.
+func LintTree(root *tree_sitter.Node, src []byte, rules []Rule) []Diagnostic {
+	diagnostics, _ := LintTreeContext(context.Background(), root, src, rules)
+	return diagnostics
+}
+
+// LintTreeContext is like LintTree, but checks ctx before each rule and
+// returns ctx.Err() once it is done.
+func LintTreeContext(ctx context.Context, root *tree_sitter.Node, src []byte, rules []Rule) ([]Diagnostic, error) {
+	var diagnostics []Diagnostic
+	known := map[string]bool{}
+	reportUnused := false
+	for _, rule := range rules {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if _, ok := rule.(unusedDirectives); ok {
+			reportUnused = true
+		}
+		known[rule.Name()] = true
+		if r, ok := rule.(Reporter); ok {
+			for _, name := range r.Reports() {
+				known[name] = true
+			}
+		}
+		diagnostics = append(diagnostics, rule.Check(root, src)...)
+	}
+	for _, d := range diagnostics {
+		known[d.Rule] = true
+	}
+	diagnostics, unused := Suppress(diagnostics, Directives(root, src))
+	if reportUnused {
+		diagnostics = append(diagnostics, unusedDiagnostics(unused, known)...)
+	}
+	sortDiagnostics(diagnostics)
+	return diagnostics, nil
+}
+
+func sortDiagnostics(diagnostics []Diagnostic) {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return diagnostics[i].StartByte < diagnostics[j].StartByte
+	})
+}
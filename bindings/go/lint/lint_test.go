@@ -0,0 +1,604 @@
+package lint_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/lint"
+)
+
+type result struct {
+	Rule     string
+	Severity lint.Severity
+	Message  string
+	Text     string
+}
+
+func run(t *testing.T, src string, rules ...lint.Rule) []result {
+	t.Helper()
+	diagnostics, err := lint.Lint([]byte(src), rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []result
+	for _, d := range diagnostics {
+		results = append(results, result{d.Rule, d.Severity, d.Message, src[d.StartByte:d.EndByte]})
+	}
+	return results
+}
+
+func TestMismatchedSections(t *testing.T) {
+	got := run(t, "{{#a}}x{{/b}}", lint.MismatchedSections())
+	want := []result{
+		{"mismatchedSections", lint.Error, "Mismatched mustache section: {{/b}}, expected {{/a}}", "{{/b}}"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestUnclosedTags(t *testing.T) {
+	got := run(t, "<div><span></div><ul><li>a<li>b</ul><br>", lint.UnclosedTags())
+	want := []result{
+		{"unclosedTags", lint.Error, "Unclosed HTML tag: <span>", "<span>"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestDuplicateAttributes(t *testing.T) {
+	src := `<a class="x" href="/" CLASS="y"></a>` +
+		`<b {{#on}}id="a"{{/on}}{{^on}}id="b"{{/on}}></b>` +
+		`<i {{#on}}title="a"{{/on}} title="b"></i>`
+	got := run(t, src, lint.DuplicateAttributes())
+	want := []result{
+		{"duplicateAttributes", lint.Error, `Duplicate attribute "CLASS"`, "CLASS"},
+		{"duplicateAttributes", lint.Error, `Duplicate attribute "title"`, "title"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestUndefinedPartials(t *testing.T) {
+	calls := 0
+	resolver := func(name string) ([]byte, error) {
+		calls++
+		if name == "header" {
+			return []byte("<h1>hi</h1>"), nil
+		}
+		return nil, errors.New("not found")
+	}
+	got := run(t, "{{> header}}{{> footer}}{{> footer}}{{>*dynamic}}", lint.UndefinedPartials(resolver))
+	want := []result{
+		{"undefinedPartials", lint.Error, `Undefined partial "footer"`, "{{> footer}}"},
+		{"undefinedPartials", lint.Error, `Undefined partial "footer"`, "{{> footer}}"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if calls != 2 {
+		t.Errorf("resolver called %d times, want 2", calls)
+	}
+}
+
+func TestUnescapedAttributeInterpolation(t *testing.T) {
+	got := run(t, `<a href="{{{url}}}" {{{attrs}}}>{{{body}}}</a>`, lint.UnescapedAttributeInterpolation())
+	want := []result{
+		{"unescapedAttributeInterpolation", lint.Warning, "Unescaped interpolation {{{url}}} in attribute value", "{{{url}}}"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestBooleanAttributes(t *testing.T) {
+	got := run(t, `<input disabled checked="" readonly="readonly" required="false" hidden="true" selected="{{on}}" multiple={{many}} open="{{a}} {{b}}" value="false">`, lint.BooleanAttributes())
+	want := []result{
+		{"booleanAttributes", lint.Warning, `Boolean attribute required="false" is still set; remove it to turn it off`, `required="false"`},
+		{"booleanAttributes", lint.Warning, `Boolean attribute hidden ignores its value "true"`, `hidden="true"`},
+		{"booleanAttributes", lint.Warning, "Boolean attribute selected is set whatever {{on}} renders; write {{#on}}selected{{/on}} instead", `selected="{{on}}"`},
+		{"booleanAttributes", lint.Warning, "Boolean attribute multiple is set whatever {{many}} renders; write {{#many}}multiple{{/many}} instead", "multiple={{many}}"},
+		{"booleanAttributes", lint.Warning, "Boolean attribute open is set whatever {{a}} {{b}} renders; put it in a section instead", `open="{{a}} {{b}}"`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestLintSortsDiagnostics(t *testing.T) {
+	diagnostics, err := lint.Lint([]byte("<p title=\"{{{t}}}\" title=\"x\"></p>\n{{#a}}{{/b}}"), lint.DefaultRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rules []string
+	for _, d := range diagnostics {
+		rules = append(rules, d.Rule)
+	}
+	want := []string{"unescapedAttributeInterpolation", "duplicateAttributes", "mismatchedSections"}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("rules = %v, want %v", rules, want)
+	}
+	last := diagnostics[len(diagnostics)-1]
+	if last.StartPoint.Row != 1 || last.StartPoint.Column != 6 {
+		t.Errorf("StartPoint = %+v, want row 1 column 6", last.StartPoint)
+	}
+}
+
+// cancelRule cancels the lint it runs in.
+type cancelRule struct{ cancel context.CancelFunc }
+
+func (r cancelRule) Name() string { return "cancel" }
+
+func (r cancelRule) Check(*tree_sitter.Node, []byte) []lint.Diagnostic {
+	r.cancel()
+	return nil
+}
+
+func TestLintContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rules := append([]lint.Rule{cancelRule{cancel}}, lint.DefaultRules()...)
+	if _, err := lint.LintContext(ctx, []byte("{{#a}}{{/b}}"), rules); !errors.Is(err, context.Canceled) {
+		t.Errorf("LintContext() error = %v, want context.Canceled", err)
+	}
+	if _, err := lint.LintContext(ctx, []byte("{{#a}}{{/b}}"), lint.DefaultRules()); !errors.Is(err, context.Canceled) {
+		t.Errorf("LintContext() after cancel error = %v, want context.Canceled", err)
+	}
+}
+
+func TestDirectives(t *testing.T) {
+	src := "{{! htmlmustache-disable-next-line duplicateAttributes, booleanAttributes -- legacy }}\n" +
+		"<p>\n<!-- htmlmustache-disable -->\n{{!htmlmustache-disable-line}}{{! not a directive }}"
+	source := []byte(src)
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		t.Fatal(err)
+	}
+	tree := parser.Parse(source, nil)
+	defer tree.Close()
+	type directive struct {
+		Kind  lint.DirectiveKind
+		Rules []string
+		Row   uint
+		Text  string
+	}
+	var got []directive
+	for _, d := range lint.Directives(tree.RootNode(), source) {
+		got = append(got, directive{d.Kind, d.Rules, d.Row, src[d.StartByte:d.EndByte]})
+	}
+	want := []directive{
+		{lint.DisableNextLine, []string{"duplicateAttributes", "booleanAttributes"}, 1, "{{! htmlmustache-disable-next-line duplicateAttributes, booleanAttributes -- legacy }}"},
+		{lint.DisableFile, []string{}, 0, "<!-- htmlmustache-disable -->"},
+		{lint.DisableLine, []string{}, 3, "{{!htmlmustache-disable-line}}"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Directives() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestLintHonorsDirectives(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "next line",
+			src:  "{{! htmlmustache-disable-next-line duplicateAttributes }}\n<p id=a id=b></p>\n<p id=a id=b></p>",
+			want: []string{"duplicateAttributes"},
+		},
+		{
+			name: "same line",
+			src:  `<p id=a id=b></p>{{! htmlmustache-disable-line }}{{#a}}{{/b}}`,
+		},
+		{
+			name: "other rule",
+			src:  "<!-- htmlmustache-disable-next-line booleanAttributes -->\n<p id=a id=b></p>",
+			want: []string{"duplicateAttributes"},
+		},
+		{
+			name: "file",
+			src:  "<p id=a id=b></p>\n{{#a}}{{/b}}\n{{! htmlmustache-disable mismatchedSections duplicateAttributes }}",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []string
+			for _, r := range run(t, test.src, lint.DefaultRules()...) {
+				got = append(got, r.Rule)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("rules = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUnusedDirectives(t *testing.T) {
+	src := "{{! htmlmustache-disable-next-line }}\n<p></p>\n" +
+		"{{! htmlmustache-disable-next-line duplicateAttributes urlInterpolation }}\n<p id=a></p>\n" +
+		"{{! htmlmustache-disable-next-line urlInterpolation }}\n<a href={{x}}></a>\n" +
+		"{{! htmlmustache-disable-line duplicateAttributes }}<p id=a id=b></p>"
+	got := run(t, src, append(lint.DefaultRules(), lint.UnusedDirectives())...)
+	want := []result{
+		{"unusedDisableDirectives", lint.Warning, "Unused htmlmustache-disable-next-line directive: nothing is reported here", "{{! htmlmustache-disable-next-line }}"},
+		{"unusedDisableDirectives", lint.Warning, "Unused htmlmustache-disable-next-line directive: duplicateAttributes reports nothing here", "{{! htmlmustache-disable-next-line duplicateAttributes urlInterpolation }}"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if got := run(t, src, lint.DefaultRules()...); got != nil {
+		t.Errorf("without UnusedDirectives got %+v", got)
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []result
+	}{
+		{
+			"<ul>\n{{#items}}\n<li>x</li>\n</ul>",
+			[]result{{"syntax", lint.Error, "unclosed section 'items' opened at line 2", "{{#items}}"}},
+		},
+		{
+			"<div><span>",
+			[]result{
+				{"syntax", lint.Error, "unclosed tag <div> opened at line 1", "<div>"},
+				{"syntax", lint.Error, "unclosed tag <span> opened at line 1", "<span>"},
+			},
+		},
+		{
+			"<div>x",
+			[]result{{"syntax", lint.Error, "expected closing tag </div> for <div> opened at line 1", ""}},
+		},
+		{
+			"<p>{{x</p>",
+			[]result{{"syntax", lint.Error, `expected "}}"`, ""}},
+		},
+		{
+			"{{/a}}",
+			[]result{{"syntax", lint.Error, `syntax error near "{{/a"`, "{{/a"}},
+		},
+		{"<p>{{x}}</p>", nil},
+	}
+	for _, test := range tests {
+		if got := run(t, test.src, lint.SyntaxErrors()); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q: got %+v, want %+v", test.src, got, test.want)
+		}
+	}
+}
+
+func TestDiagnoseTree(t *testing.T) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		t.Fatal(err)
+	}
+	src := []byte("<p>ok</p>\n{{^empty}}\n<p>x</p>")
+	tree := parser.Parse(src, nil)
+	defer tree.Close()
+
+	diagnostics := lint.Diagnose(tree, src)
+	if len(diagnostics) != 1 {
+		t.Fatalf("Diagnose() = %+v, want one diagnostic", diagnostics)
+	}
+	d := diagnostics[0]
+	if d.Message != "unclosed section 'empty' opened at line 2" || string(src[d.StartByte:d.EndByte]) != "{{^empty}}" {
+		t.Errorf("Diagnose() = %+v", d)
+	}
+}
+
+func TestMissingAlt(t *testing.T) {
+	src := `<img src="a.png"><img alt="" src="b.png"><img alt="{{alt}}">` +
+		`<img {{#alt}}alt="{{.}}"{{/alt}}><img {{{attrs}}}><IMG SRC="c.png"/>`
+	got := run(t, src, lint.MissingAlt())
+	want := []result{
+		{"missingAlt", lint.Warning, "Missing alt attribute on <img>", `<img src="a.png">`},
+		{"missingAlt", lint.Warning, "Missing alt attribute on <img>", `<IMG SRC="c.png"/>`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestUnlabeledFormControls(t *testing.T) {
+	src := `<input name="q">` +
+		`<input aria-label="Search"><input title="{{title}}"><input type="hidden"><input type="{{type}}">` +
+		`<label>Name <input name="name"></label>` +
+		`<label for="email">Email</label><input id="email">` +
+		`<label for="{{id}}">X</label><input id="{{id}}"><input id="{{other}}">` +
+		`<input id="phone"><select></select><textarea {{#label}}aria-label="{{.}}"{{/label}}></textarea>`
+	got := run(t, src, lint.UnlabeledFormControls())
+	want := []result{
+		{"unlabeledFormControls", lint.Warning, "Form control <input> has no label", `<input name="q">`},
+		{"unlabeledFormControls", lint.Warning, "Form control <input> has no label", `<input id="phone">`},
+		{"unlabeledFormControls", lint.Warning, "Form control <select> has no label", `<select>`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestHeadingLevels(t *testing.T) {
+	got := run(t, "<h2>a</h2><h3>b</h3><h1>c</h1><h3>d</h3><h4>e</h4>", lint.HeadingLevels())
+	want := []result{
+		{"headingLevels", lint.Warning, "Heading <h3> skips a level after <h1>", "<h3>"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestDuplicateIDs(t *testing.T) {
+	src := `<div id="main"></div><p id="{{id}}"></p><p id="{{id}}"></p>` +
+		`{{#a}}<p id="x"></p>{{/a}}{{^a}}<p id="x"></p>{{/a}}` +
+		`<b {{#on}}id="y"{{/on}}></b><i {{^on}}id="y"{{/on}}></i>` +
+		`<section id="main"></section>`
+	got := run(t, src, lint.DuplicateIDs())
+	want := []result{
+		{"duplicateIds", lint.Warning, `Duplicate id "main"`, `id="main"`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestEmailUnsupportedTags(t *testing.T) {
+	src := `<script>x()</script><form action="/"><input name="q"></form>` +
+		`<link rel="stylesheet" href="a.css"><link rel="icon" href="a.ico">` +
+		`<!--[if mso]><script>y()</script><![endif]--><p>ok</p>`
+	got := run(t, src, lint.EmailUnsupportedTags())
+	want := []result{
+		{"emailUnsupportedTags", lint.Error, "<script> is not supported by email clients", "<script>"},
+		{"emailUnsupportedTags", lint.Warning, "<form> is not supported by email clients", `<form action="/">`},
+		{"emailUnsupportedTags", lint.Warning, "<input> is not supported by email clients", `<input name="q">`},
+		{"emailUnsupportedTags", lint.Warning, "Email clients do not load linked stylesheets; inline the styles", `<link rel="stylesheet" href="a.css">`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestEmailUnsupportedCSS(t *testing.T) {
+	src := `<div style="display: flex; color: {{color}}; margin: 0"></div>` +
+		`<p style="{{#fixed}}position:fixed;{{/fixed}}width: calc(100% - {{gutter}}px)"></p>` +
+		`<td style="font-family: {{font}}"></td><b style=float:left></b>` +
+		"<style>\n  .a { transition: all 1s }\n  @media (max-width: 600px) { .b { width: 100% } }\n</style>"
+	got := run(t, src, lint.EmailUnsupportedCSS())
+	want := []result{
+		{"emailUnsupportedCss", lint.Warning, "Many email clients do not support flex and grid layout", "display: flex"},
+		{"emailUnsupportedCss", lint.Warning, "Many email clients do not support calc()", "calc("},
+		{"emailUnsupportedCss", lint.Warning, "Many email clients do not support float", "float:"},
+		{"emailUnsupportedCss", lint.Warning, "Many email clients do not support transforms and animations", "transition:"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestEmailUnsupportedCSSPoints(t *testing.T) {
+	src := "<style>\n  .a { position: absolute }\n</style>"
+	diagnostics, err := lint.Lint([]byte(src), []lint.Rule{lint.EmailUnsupportedCSS()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diagnostics) != 1 {
+		t.Fatalf("got %+v, want one diagnostic", diagnostics)
+	}
+	d := diagnostics[0]
+	if want := (tree_sitter.Point{Row: 1, Column: 7}); d.StartPoint != want {
+		t.Errorf("StartPoint = %+v, want %+v", d.StartPoint, want)
+	}
+	if want := (tree_sitter.Point{Row: 1, Column: 25}); d.EndPoint != want {
+		t.Errorf("EndPoint = %+v, want %+v", d.EndPoint, want)
+	}
+}
+
+func TestEmailTableLayout(t *testing.T) {
+	src := `<body><table role="presentation" border="0" cellpadding="0" cellspacing="0"><tr><td>` +
+		`<table><tr><td>a</td></tr></table>` +
+		`<table role="presentation" {{#wide}}width="100%"{{/wide}}><tr><td>b</td></tr></table>` +
+		`<table><tr><th>Item</th></tr></table>` +
+		`</td></tr></table></body>`
+	got := run(t, src, lint.EmailTableLayout())
+	want := []result{
+		{"emailTableLayout", lint.Warning, `Layout table should have role="presentation"`, "<table>"},
+		{"emailTableLayout", lint.Warning, "Layout table should set border, cellpadding, cellspacing", "<table>"},
+		{"emailTableLayout", lint.Warning, "Layout table should set border, cellpadding, cellspacing", `<table role="presentation" {{#wide}}width="100%"{{/wide}}>`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	got = run(t, `<body><div>{{content}}</div></body>`, lint.EmailTableLayout())
+	want = []result{{"emailTableLayout", lint.Warning, "Email layout should use tables", "<body>"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestEmailInlineStyles(t *testing.T) {
+	src := `<p class="lead">a</p><p class="lead" style="margin:0">b</p>` +
+		`<td class="x" {{#s}}style="{{s}}"{{/s}}></td><span class="y" {{{attrs}}}></span><b>c</b>`
+	got := run(t, src, lint.EmailInlineStyles())
+	want := []result{
+		{"emailInlineStyles", lint.Warning, "<p> is styled by class only; inline its styles", `<p class="lead">`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestEmailConditionalComments(t *testing.T) {
+	src := `<!--[if mso]><table role="presentation"><tr><td><![endif]-->` +
+		`<div>{{body}}</div>` +
+		`<!--[if mso]></td></tr></table><![endif]-->` +
+		`<!--[if !mso]><!--><p>not outlook</p><!--<![endif]-->` +
+		`<!--[if gte mso 9]><xml></xml><![endif]-->` +
+		`<!--[if mso]><div><![endif]-->` +
+		`<!--[if outlook]><![endif]-->` +
+		`<!--<![endif]-->` +
+		`<!--[if mso]><p>x</p>-->` +
+		`<!--<![endif]-->` +
+		`<!--[if !mso]><!--><p>y</p>`
+	got := run(t, src, lint.EmailConditionalComments())
+	want := []result{
+		{"emailConditionalComments", lint.Warning, `Unknown condition "outlook" in conditional comment`, "<!--[if outlook]><![endif]-->"},
+		{"emailConditionalComments", lint.Error, "<![endif]> without a conditional comment to close", "<!--<![endif]-->"},
+		{"emailConditionalComments", lint.Error, "Conditional comment is closed by <!--<![endif]--> rather than <![endif]-->", "<!--<![endif]-->"},
+		// The <div> of the mso comment is never closed where Outlook sees it.
+		{"emailConditionalComments", lint.Error, "In Outlook: expected closing tag </div> for <div> opened at line 1", ""},
+		{"emailConditionalComments", lint.Error, "Conditional comment is not closed by <!--<![endif]-->", "<!--[if !mso]><!-->"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	got = run(t, `<!--[if mso]><p>x</p>`, lint.EmailConditionalComments())
+	want = []result{
+		{"emailConditionalComments", lint.Error, "Conditional comment has no <![endif]>", "<!--[if mso]>"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestInvalidNesting(t *testing.T) {
+	src := `<p>{{#a}}<div>x</div>{{/a}}</p>` +
+		`<span><section></section></span>` +
+		`<ul><li>a</li>{{#items}}<li>{{.}}</li>{{/items}}<div>b</div></ul>` +
+		`<div><li>c</li></div>` +
+		`<table><tr><td>d</td></tr></table>` +
+		`<a href="/"><span><button>e</button></span></a>` +
+		`<form><div><form></form></div></form>` +
+		`<li>partial</li><div><p>ok</p></div>` +
+		`<p><svg><a><text>f</text></a><foreignObject><div>g</div></foreignObject></svg></p>` +
+		`<p><div>h</div></p><p>i<p>j</p><p>k<pre>l</pre>`
+	got := run(t, src, lint.InvalidNesting())
+	want := []result{
+		{"invalidNesting", lint.Error, "Invalid nesting: <div> cannot be inside <p>", "<div>"},
+		{"invalidNesting", lint.Error, "Invalid nesting: <section> cannot be inside <span>", "<section>"},
+		{"invalidNesting", lint.Error, "Invalid nesting: <div> cannot be a child of <ul>", "<div>"},
+		{"invalidNesting", lint.Error, "Invalid nesting: <li> cannot be a child of <div>", "<li>"},
+		{"invalidNesting", lint.Error, "Invalid nesting: <button> cannot be inside <a>", "<button>"},
+		{"invalidNesting", lint.Error, "Invalid nesting: <form> cannot be inside <form>", "<form>"},
+		{"invalidNesting", lint.Error, "Invalid nesting: <div> cannot be inside <p>", "<div>"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	got = run(t, `{{#a}}<p><div>x</div></p>{{/a}}`, lint.InvalidNesting())
+	want = []result{{"invalidNesting", lint.Error, "Invalid nesting: <div> cannot be inside <p>", "<div>"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestStrictRules(t *testing.T) {
+	src := "<ul><li>a<li>b</li></ul>\n<p id=x title='t' data-n={{n}} class=\"{{c}}\">x</br>a }} b"
+	got := run(t, src, lint.StrictRules()...)
+	want := []result{
+		{"implicitEndTags", lint.Warning, "<li> is closed implicitly; add </li>", "<li>"},
+		{"implicitEndTags", lint.Warning, "<p> is closed implicitly; add </p>", `<p id=x title='t' data-n={{n}} class="{{c}}">`},
+		{"unquotedAttributes", lint.Warning, "Value of attribute id is not quoted", "x"},
+		{"unquotedAttributes", lint.Warning, "Value of attribute data-n is not quoted", "{{n}}"},
+		{"strayEndTags", lint.Warning, "End tag </br> closes no open element", "</br>"},
+		{"strayDelimiters", lint.Warning, "Stray }} outside a mustache tag", "}}"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if got := run(t, "<ul><li>a</li></ul><p id=\"x\" title=\"{{! }}\">b</p>", lint.StrictRules()...); got != nil {
+		t.Errorf("well-formed template got %+v", got)
+	}
+}
+
+func TestStrayDelimitersCustomDelimiters(t *testing.T) {
+	got := run(t, "{{=<% %>=}}<i>{{literal}}</i> %> <%={{ }}=%>", lint.StrayDelimiters())
+	want := []result{{"strayDelimiters", lint.Warning, "Stray %> outside a mustache tag", "%>"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestWriteJSONLines(t *testing.T) {
+	src := []byte("<p>\n<img src=\"a.png\"></p>")
+	found, err := lint.Lint(src, lint.AccessibilityRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := lint.WriteJSONLines(&buf, []lint.File{{Path: "a.mustache", Diagnostics: found}, {Path: "b.mustache"}}); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"file":"a.mustache","rule":"missingAlt","severity":"warning","message":"Missing alt attribute on <img>","startLine":2,"startColumn":1,"endLine":2,"endColumn":18,"startByte":4,"endByte":21}` + "\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	found, err := lint.Lint([]byte(`<div a="1" a="2"></div>`), lint.DefaultRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool := lint.Tool{Name: "test", Rules: map[string]string{"syntax": "Syntax errors", "unclosedTags": ""}}
+	var buf bytes.Buffer
+	if err := lint.WriteSARIF(&buf, tool, []lint.File{{Path: "page.mustache", Diagnostics: found}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var log struct {
+		Runs []struct {
+			Tool struct {
+				Driver struct {
+					Name  string
+					Rules []struct {
+						ID               string
+						ShortDescription *struct{ Text string }
+					}
+				}
+			}
+			Results []struct {
+				RuleID    string
+				Locations []struct {
+					PhysicalLocation struct {
+						Region struct{ StartLine, StartColumn, EndColumn int }
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if len(log.Runs) != 1 || log.Runs[0].Tool.Driver.Name != "test" || len(log.Runs[0].Results) != 1 {
+		t.Fatalf("unexpected log:\n%s", buf.String())
+	}
+	var ids []string
+	for _, rule := range log.Runs[0].Tool.Driver.Rules {
+		ids = append(ids, rule.ID)
+		if (rule.ShortDescription != nil) != (rule.ID == "syntax") {
+			t.Errorf("rule %s has description %v", rule.ID, rule.ShortDescription)
+		}
+	}
+	if want := []string{"duplicateAttributes", "syntax", "unclosedTags"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("rules = %v, want %v", ids, want)
+	}
+	result := log.Runs[0].Results[0]
+	if region := result.Locations[0].PhysicalLocation.Region; result.RuleID != "duplicateAttributes" || region.StartLine != 1 || region.StartColumn != 12 {
+		t.Errorf("unexpected result:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,195 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// flowElements are the elements that are flow but not phrasing content, so
+// may not be inside phrasingParents. They are the tags that end a <p>
+// implicitly (TAG_TYPES_NOT_ALLOWED_IN_PARAGRAPHS in src/tag.h) and the
+// lists and tables.
+var flowElements = setOf(
+	"address", "article", "aside", "blockquote", "details", "dialog", "div",
+	"dl", "fieldset", "figcaption", "figure", "footer", "form", "h1", "h2",
+	"h3", "h4", "h5", "h6", "header", "hgroup", "hr", "main", "menu", "nav",
+	"ol", "p", "pre", "section", "table", "ul",
+)
+
+// phrasingParents are the elements whose content model is phrasing
+// content.
+var phrasingParents = setOf(
+	"abbr", "b", "bdi", "bdo", "button", "cite", "code", "data", "dfn", "em",
+	"h1", "h2", "h3", "h4", "h5", "h6", "i", "kbd", "label", "legend", "mark",
+	"meter", "output", "p", "pre", "progress", "q", "s", "samp", "small",
+	"span", "strong", "sub", "sup", "time", "u", "var",
+)
+
+// interactiveElements may not be inside <a> or <button>.
+var interactiveElements = setOf(
+	"a", "button", "details", "embed", "iframe", "input", "label", "select", "textarea",
+)
+
+// allowedChildren lists, for elements with a restricted content model, the
+// elements they may have as children.
+var allowedChildren = map[string]map[string]bool{
+	"ul":       setOf("li", "script", "template"),
+	"ol":       setOf("li", "script", "template"),
+	"menu":     setOf("li", "script", "template"),
+	"dl":       setOf("dt", "dd", "div", "script", "template"),
+	"table":    setOf("caption", "colgroup", "thead", "tbody", "tfoot", "tr", "script", "template"),
+	"thead":    setOf("tr", "script", "template"),
+	"tbody":    setOf("tr", "script", "template"),
+	"tfoot":    setOf("tr", "script", "template"),
+	"tr":       setOf("td", "th", "script", "template"),
+	"select":   setOf("option", "optgroup", "hr", "script", "template"),
+	"optgroup": setOf("option", "script", "template"),
+}
+
+// requiredParents lists, for elements that may only be children of certain
+// elements, those elements.
+var requiredParents = map[string]map[string]bool{
+	"li":       setOf("ul", "ol", "menu"),
+	"dt":       setOf("dl", "div"),
+	"dd":       setOf("dl", "div"),
+	"tr":       setOf("table", "thead", "tbody", "tfoot"),
+	"td":       setOf("tr"),
+	"th":       setOf("tr"),
+	"thead":    setOf("table"),
+	"tbody":    setOf("table"),
+	"tfoot":    setOf("table"),
+	"caption":  setOf("table"),
+	"colgroup": setOf("table"),
+	"option":   setOf("select", "optgroup", "datalist"),
+	"optgroup": setOf("select"),
+}
+
+func setOf(names ...string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+// InvalidNesting reports elements the HTML5 content model does not allow
+// where they are, such as a <div> inside a <p>, an <li> outside a list or
+// an <a> inside another. Sections are transparent, so the parent of an
+// element in {{#a}}...{{/a}} is the element around the section. Elements
+// with no parent element in the template, as at the top level of a
+// partial, are not checked against their parent. Nor are elements in
+// <svg> and <math>, which have content models of their own.
+//
+// The scanner ends a <p> implicitly at the start tag of a flow element, as
+// a browser does, so in <p><div>x</div></p> the <div> follows the <p> and
+// the </p> closes no open element, which only the strict rules report. A
+// stray </p> after a <p> closed that way reports the element that closed
+// it, as if the parse tree had kept it inside.
+func InvalidNesting() Rule {
+	const name = "invalidNesting"
+	return ruleFunc{name, func(root *tree_sitter.Node, src []byte) []Diagnostic {
+		var diagnostics []Diagnostic
+		walk.Visit(root, func(node *tree_sitter.Node) bool {
+			if node.Kind() == "html_erroneous_end_tag" {
+				if closer := paragraphCloser(node, src); closer != nil {
+					diagnostics = append(diagnostics, At(closer.Child(0), name, Error,
+						fmt.Sprintf("Invalid nesting: <%s> cannot be inside <p>", elementName(closer, src))))
+				}
+				return false
+			}
+			if node.Kind() != "html_element" {
+				return true
+			}
+			tag := elementName(node, src)
+			switch tag {
+			case "":
+				return true
+			case "svg", "math":
+				return false
+			}
+			report := func(message string) {
+				diagnostics = append(diagnostics, At(node.Child(0), name, Error, message))
+			}
+			parent := parentElement(node)
+			parentTag := ""
+			if parent != nil {
+				parentTag = elementName(parent, src)
+			}
+			switch {
+			case parentTag != "" && phrasingParents[parentTag] && flowElements[tag]:
+				report(fmt.Sprintf("Invalid nesting: <%s> cannot be inside <%s>", tag, parentTag))
+			case allowedChildren[parentTag] != nil && !allowedChildren[parentTag][tag]:
+				report(fmt.Sprintf("Invalid nesting: <%s> cannot be a child of <%s>", tag, parentTag))
+			case parentTag != "" && requiredParents[tag] != nil && !requiredParents[tag][parentTag]:
+				report(fmt.Sprintf("Invalid nesting: <%s> cannot be a child of <%s>", tag, parentTag))
+			default:
+				for ancestor := parent; ancestor != nil; ancestor = parentElement(ancestor) {
+					ancestorTag := elementName(ancestor, src)
+					if (ancestorTag == "a" || ancestorTag == "button") && interactiveElements[tag] ||
+						ancestorTag == "form" && tag == "form" {
+						report(fmt.Sprintf("Invalid nesting: <%s> cannot be inside <%s>", tag, ancestorTag))
+						break
+					}
+				}
+			}
+			return true
+		})
+		return diagnostics
+	}}
+}
+
+// paragraphCloser returns the flow element that implicitly ended the <p>
+// the stray end tag </p> was meant to close, or nil if endTag is another
+// stray end tag or the <p> before it was closed some other way.
+func paragraphCloser(endTag *tree_sitter.Node, src []byte) *tree_sitter.Node {
+	n := walk.ChildOfKind(endTag, "html_erroneous_end_tag_name")
+	if n == nil || strings.ToLower(n.Utf8Text(src)) != "p" {
+		return nil
+	}
+	for prev := endTag.PrevSibling(); prev != nil; prev = prev.PrevSibling() {
+		if prev.Kind() != "html_element" || elementName(prev, src) != "p" {
+			continue
+		}
+		if walk.ChildOfKind(prev, "html_end_tag") != nil {
+			return nil
+		}
+		for next := prev.NextSibling(); next != nil; next = next.NextSibling() {
+			if next.Kind() == "html_element" {
+				if flowElements[elementName(next, src)] {
+					return next
+				}
+				return nil
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// elementName returns the lowercased tag name of an html_element.
+func elementName(element *tree_sitter.Node, src []byte) string {
+	if element.ChildCount() == 0 {
+		return ""
+	}
+	return tagName(element.Child(0), src)
+}
+
+// parentElement returns the html_element around node, looking through
+// sections, or nil if there is none.
+func parentElement(node *tree_sitter.Node) *tree_sitter.Node {
+	for n := node.Parent(); n != nil; n = n.Parent() {
+		switch {
+		case n.Kind() == "html_element":
+			return n
+		case strings.HasPrefix(n.Kind(), "mustache_"):
+			continue
+		default:
+			return nil
+		}
+	}
+	return nil
+}
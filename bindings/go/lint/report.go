@@ -0,0 +1,186 @@
+package lint
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// File is the diagnostics of one file, as written by WriteSARIF and
+// WriteJSONLines.
+type File struct {
+	// Path is the file's path or URI, as it should appear in the report.
+	Path        string
+	Diagnostics []Diagnostic
+}
+
+// Tool describes the program a SARIF log comes from.
+type Tool struct {
+	Name string
+	// Rules maps rule names to one-line descriptions. Rules reported
+	// without one are listed without a description.
+	Rules map[string]string
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string        `json:"id"`
+	ShortDescription *sarifMessage `json:"shortDescription,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   uint `json:"startLine"`
+	StartColumn uint `json:"startColumn"`
+	EndLine     uint `json:"endLine"`
+	EndColumn   uint `json:"endColumn"`
+}
+
+// WriteSARIF writes files as a SARIF 2.1.0 log from tool, the format code
+// scanning services such as GitHub's accept. Lines and columns are
+// one-based; columns count bytes, as tree-sitter positions do, so they are
+// exact for ASCII lines.
+func WriteSARIF(w io.Writer, tool Tool, files []File) error {
+	descriptions := map[string]string{}
+	for id, description := range tool.Rules {
+		descriptions[id] = description
+	}
+	results := []sarifResult{}
+	for _, file := range files {
+		for _, d := range file.Diagnostics {
+			if _, ok := descriptions[d.Rule]; !ok {
+				descriptions[d.Rule] = ""
+			}
+			results = append(results, sarifResult{
+				RuleID:  d.Rule,
+				Level:   d.Severity.String(),
+				Message: sarifMessage{d.Message},
+				Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: file.Path},
+					Region: sarifRegion{
+						StartLine:   d.StartPoint.Row + 1,
+						StartColumn: d.StartPoint.Column + 1,
+						EndLine:     d.EndPoint.Row + 1,
+						EndColumn:   d.EndPoint.Column + 1,
+					},
+				}}},
+			})
+		}
+	}
+
+	ids := make([]string, 0, len(descriptions))
+	for id := range descriptions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	rules := make([]sarifRule, 0, len(ids))
+	for _, id := range ids {
+		rule := sarifRule{ID: id}
+		if description := descriptions[id]; description != "" {
+			rule.ShortDescription = &sarifMessage{description}
+		}
+		rules = append(rules, rule)
+	}
+
+	log := sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           tool.Name,
+				InformationURI: "https://github.com/reteps/tree-sitter-htmlmustache",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+// jsonDiagnostic is a line of WriteJSONLines.
+type jsonDiagnostic struct {
+	File        string `json:"file"`
+	Rule        string `json:"rule"`
+	Severity    string `json:"severity"`
+	Message     string `json:"message"`
+	StartLine   uint   `json:"startLine"`
+	StartColumn uint   `json:"startColumn"`
+	EndLine     uint   `json:"endLine"`
+	EndColumn   uint   `json:"endColumn"`
+	StartByte   uint   `json:"startByte"`
+	EndByte     uint   `json:"endByte"`
+}
+
+// WriteJSONLines writes the diagnostics of files as JSON Lines: one JSON
+// object per diagnostic, with its file, rule, severity, message and range.
+// Lines and columns are one-based as in WriteSARIF, and the byte offsets
+// are zero-based.
+func WriteJSONLines(w io.Writer, files []File) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	for _, file := range files {
+		for _, d := range file.Diagnostics {
+			err := encoder.Encode(jsonDiagnostic{
+				File:        file.Path,
+				Rule:        d.Rule,
+				Severity:    d.Severity.String(),
+				Message:     d.Message,
+				StartLine:   d.StartPoint.Row + 1,
+				StartColumn: d.StartPoint.Column + 1,
+				EndLine:     d.EndPoint.Row + 1,
+				EndColumn:   d.EndPoint.Column + 1,
+				StartByte:   d.StartByte,
+				EndByte:     d.EndByte,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
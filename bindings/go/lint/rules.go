@@ -0,0 +1,315 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// ruleFunc adapts a name and a check function to the Rule interface.
+type ruleFunc struct {
+	name  string
+	check func(root *tree_sitter.Node, src []byte) []Diagnostic
+}
+
+func (r ruleFunc) Name() string { return r.name }
+
+func (r ruleFunc) Check(root *tree_sitter.Node, src []byte) []Diagnostic {
+	return r.check(root, src)
+}
+
+// MismatchedSections reports section end tags that close a different name
+// than the section they end, e.g. {{#a}}...{{/b}}.
+func MismatchedSections() Rule {
+	const name = "mismatchedSections"
+	return ruleFunc{name, func(root *tree_sitter.Node, src []byte) []Diagnostic {
+		var diagnostics []Diagnostic
+		walk.Visit(root, func(node *tree_sitter.Node) bool {
+			switch node.Kind() {
+			case "mustache_erroneous_section_end", "mustache_erroneous_inverted_section_end":
+				closed := "?"
+				if tag := walk.ChildOfKind(node, "mustache_erroneous_tag_name"); tag != nil {
+					closed = tag.Utf8Text(src)
+				}
+				message := fmt.Sprintf("Mismatched mustache section: {{/%s}}", closed)
+				if opened := sectionName(node.Parent(), src); opened != "" {
+					message += fmt.Sprintf(", expected {{/%s}}", opened)
+				}
+				diagnostics = append(diagnostics, At(node, name, Error, message))
+			}
+			return true
+		})
+		return diagnostics
+	}}
+}
+
+// voidElements never have content or an end tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "basefont": true, "bgsound": true, "br": true,
+	"col": true, "command": true, "embed": true, "frame": true, "hr": true,
+	"image": true, "img": true, "input": true, "isindex": true, "keygen": true,
+	"link": true, "menuitem": true, "meta": true, "nextid": true, "param": true,
+	"source": true, "track": true, "wbr": true,
+}
+
+// optionalEndTagElements may be closed implicitly by a sibling or by the
+// end of their parent.
+var optionalEndTagElements = map[string]bool{
+	"li": true, "dt": true, "dd": true, "p": true, "colgroup": true,
+	"rb": true, "rt": true, "rp": true, "rtc": true,
+	"optgroup": true, "option": true,
+	"tr": true, "td": true, "th": true,
+	"thead": true, "tbody": true, "tfoot": true,
+	"caption": true,
+	"html":    true, "head": true, "body": true,
+}
+
+// UnclosedTags reports non-void elements that are never closed and whose end
+// tag is not optional. Elements the parser could not close at all are left
+// to SyntaxErrors.
+func UnclosedTags() Rule {
+	const name = "unclosedTags"
+	return ruleFunc{name, func(root *tree_sitter.Node, src []byte) []Diagnostic {
+		var diagnostics []Diagnostic
+		walk.Visit(root, func(node *tree_sitter.Node) bool {
+			if node.Kind() != "html_element" ||
+				walk.ChildOfKind(node, "html_end_tag") != nil || walk.ChildOfKind(node, "html_forced_end_tag") != nil ||
+				node.HasError() && !childHasError(node) {
+				return true
+			}
+			start := walk.ChildOfKind(node, "html_start_tag")
+			if start == nil {
+				return true
+			}
+			tag := walk.ChildOfKind(start, "html_tag_name")
+			if tag == nil {
+				return true
+			}
+			if lower := strings.ToLower(tag.Utf8Text(src)); !voidElements[lower] && !optionalEndTagElements[lower] {
+				diagnostics = append(diagnostics, At(start, name, Error, fmt.Sprintf("Unclosed HTML tag: <%s>", lower)))
+			}
+			return true
+		})
+		return diagnostics
+	}}
+}
+
+// condition is a section an attribute is nested in.
+type condition struct {
+	name     string
+	inverted bool
+}
+
+type attributeName struct {
+	node       *tree_sitter.Node
+	conditions []condition
+}
+
+// DuplicateAttributes reports attributes that appear more than once on the
+// same element. Attributes in mutually exclusive sections, such as
+// {{#a}}class="x"{{/a}}{{^a}}class="y"{{/a}}, are not duplicates.
+func DuplicateAttributes() Rule {
+	const name = "duplicateAttributes"
+	return ruleFunc{name, func(root *tree_sitter.Node, src []byte) []Diagnostic {
+		var diagnostics []Diagnostic
+		walk.Visit(root, func(node *tree_sitter.Node) bool {
+			switch node.Kind() {
+			case "html_start_tag", "html_self_closing_tag":
+			default:
+				return true
+			}
+			seen := map[string][]attributeName{}
+			for _, attribute := range attributeNames(node, nil, src, nil) {
+				key := strings.ToLower(attribute.node.Utf8Text(src))
+				for _, previous := range seen[key] {
+					if !exclusive(previous.conditions, attribute.conditions) {
+						message := fmt.Sprintf("Duplicate attribute %q", attribute.node.Utf8Text(src))
+						diagnostics = append(diagnostics, At(attribute.node, name, Error, message))
+						break
+					}
+				}
+				seen[key] = append(seen[key], attribute)
+			}
+			return false
+		})
+		return diagnostics
+	}}
+}
+
+// attributeNames appends the static attribute names of a tag to names,
+// descending into attribute sections.
+func attributeNames(node *tree_sitter.Node, conditions []condition, src []byte, names []attributeName) []attributeName {
+	for i := uint(0); i < node.ChildCount(); i++ {
+		child := node.Child(i)
+		switch child.Kind() {
+		case "html_attribute":
+			if nameNode := walk.ChildOfKind(child, "html_attribute_name"); nameNode != nil {
+				names = append(names, attributeName{node: nameNode, conditions: conditions})
+			}
+		case "mustache_attribute":
+			names = attributeNames(child, conditions, src, names)
+		case "mustache_section", "mustache_inverted_section":
+			if section := sectionName(child, src); section != "" {
+				nested := append(append([]condition{}, conditions...), condition{section, child.Kind() == "mustache_inverted_section"})
+				names = attributeNames(child, nested, src, names)
+			}
+		}
+	}
+	return names
+}
+
+// exclusive reports whether two sets of conditions can never hold together:
+// some section is truthy in one and falsy in the other.
+func exclusive(a, b []condition) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x.name == y.name && x.inverted != y.inverted {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// UndefinedPartials reports partials that resolver cannot load. Dynamic
+// partials ({{>*name}}) are skipped as their name is only known at render
+// time.
+func UndefinedPartials(resolver analysis.Resolver) Rule {
+	const name = "undefinedPartials"
+	return ruleFunc{name, func(root *tree_sitter.Node, src []byte) []Diagnostic {
+		var diagnostics []Diagnostic
+		defined := map[string]bool{}
+		walk.Visit(root, func(node *tree_sitter.Node) bool {
+			if node.Kind() != "mustache_partial" {
+				return true
+			}
+			content := walk.ChildOfKind(node, "mustache_partial_content")
+			if content == nil {
+				return false
+			}
+			partial := strings.TrimSpace(content.Utf8Text(src))
+			if partial == "" || strings.HasPrefix(partial, "*") {
+				return false
+			}
+			ok, checked := defined[partial]
+			if !checked {
+				_, err := resolver(partial)
+				ok = err == nil
+				defined[partial] = ok
+			}
+			if !ok {
+				diagnostics = append(diagnostics, At(node, name, Error, fmt.Sprintf("Undefined partial %q", partial)))
+			}
+			return false
+		})
+		return diagnostics
+	}}
+}
+
+// UnescapedAttributeInterpolation warns about {{{name}}} inside attribute
+// values, where unescaped data can break out of the attribute and inject
+// markup or script.
+func UnescapedAttributeInterpolation() Rule {
+	const name = "unescapedAttributeInterpolation"
+	return ruleFunc{name, func(root *tree_sitter.Node, src []byte) []Diagnostic {
+		var diagnostics []Diagnostic
+		walk.Visit(root, func(node *tree_sitter.Node) bool {
+			if node.Kind() != "html_attribute" {
+				return true
+			}
+			walk.Visit(node, func(value *tree_sitter.Node) bool {
+				if value.Kind() == "mustache_triple" {
+					message := fmt.Sprintf("Unescaped interpolation %s in attribute value", value.Utf8Text(src))
+					diagnostics = append(diagnostics, At(value, name, Warning, message))
+				}
+				return true
+			})
+			return false
+		})
+		return diagnostics
+	}}
+}
+
+// booleanAttributes are the attributes that are on when present, whatever
+// their value.
+var booleanAttributes = map[string]bool{
+	"allowfullscreen": true, "async": true, "autofocus": true, "autoplay": true,
+	"checked": true, "controls": true, "default": true, "defer": true,
+	"disabled": true, "formnovalidate": true, "hidden": true, "inert": true,
+	"ismap": true, "itemscope": true, "loop": true, "multiple": true,
+	"muted": true, "nomodule": true, "novalidate": true, "open": true,
+	"playsinline": true, "readonly": true, "required": true, "reversed": true,
+	"selected": true,
+}
+
+// BooleanAttributes warns about values on boolean attributes, which turn the
+// attribute on whatever they are: disabled="false" disables the control,
+// and so does disabled="{{disabled}}" when disabled renders as false.
+// disabled, disabled="" and disabled="disabled" are fine.
+func BooleanAttributes() Rule {
+	const name = "booleanAttributes"
+	return ruleFunc{name, func(root *tree_sitter.Node, src []byte) []Diagnostic {
+		var diagnostics []Diagnostic
+		walk.Visit(root, func(node *tree_sitter.Node) bool {
+			switch node.Kind() {
+			case "html_start_tag", "html_self_closing_tag":
+			default:
+				return true
+			}
+			for _, attr := range tagAttributes(node, src).list {
+				if !booleanAttributes[attr.name] || attr.node.ChildCount() < 3 {
+					continue
+				}
+				value, dynamic := attributeValue(attr.node, src)
+				var message string
+				switch {
+				case dynamic:
+					message = fmt.Sprintf("Boolean attribute %s is set whatever %s renders; put it in a section instead", attr.name, value)
+					if key := interpolatedName(attr.node.Child(2), src); key != "" {
+						message = fmt.Sprintf("Boolean attribute %s is set whatever %s renders; write {{#%s}}%s{{/%s}} instead", attr.name, value, key, attr.name, key)
+					}
+				case value == "" || strings.EqualFold(value, attr.name):
+					continue
+				case strings.EqualFold(value, "false"):
+					message = fmt.Sprintf("Boolean attribute %s=%q is still set; remove it to turn it off", attr.name, value)
+				default:
+					message = fmt.Sprintf("Boolean attribute %s ignores its value %q", attr.name, value)
+				}
+				diagnostics = append(diagnostics, At(attr.node, name, Warning, message))
+			}
+			return false
+		})
+		return diagnostics
+	}}
+}
+
+// interpolatedName returns the name in an attribute value that is a single
+// interpolation, as {{name}} or "{{name}}", or "".
+func interpolatedName(value *tree_sitter.Node, src []byte) string {
+	if value.Kind() == "html_quoted_attribute_value" && value.NamedChildCount() == 1 {
+		value = value.NamedChild(0)
+	}
+	if value.Kind() != "mustache_interpolation" || value.NamedChildCount() != 1 {
+		return ""
+	}
+	return value.NamedChild(0).Utf8Text(src)
+}
+
+// sectionName returns the name a section was opened with.
+func sectionName(section *tree_sitter.Node, src []byte) string {
+	if section == nil {
+		return ""
+	}
+	for _, kind := range []string{"mustache_section_begin", "mustache_inverted_section_begin"} {
+		if begin := walk.ChildOfKind(section, kind); begin != nil {
+			if tag := walk.ChildOfKind(begin, "mustache_tag_name"); tag != nil {
+				return tag.Utf8Text(src)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// StrictRules returns rules reporting the HTML irregularities the parser
+// recovers from without an error: end tags left out, unquoted attribute
+// values, end tags that close nothing and delimiters outside any tag.
+// Browsers accept all of them, so the rules are not among DefaultRules; run
+// them where templates should be written out in full, such as in CI.
+// Together with UnclosedTags they report every element the parser closes
+// implicitly.
+func StrictRules() []Rule {
+	return []Rule{
+		ImplicitEndTags(),
+		UnquotedAttributes(),
+		StrayEndTags(),
+		StrayDelimiters(),
+	}
+}
+
+// ImplicitEndTags reports elements whose end tag is optional and left out,
+// such as <li> items closed by the next <li>. Other unclosed elements are
+// left to UnclosedTags.
+func ImplicitEndTags() Rule {
+	const name = "implicitEndTags"
+	return ruleFunc{name, func(root *tree_sitter.Node, src []byte) []Diagnostic {
+		var diagnostics []Diagnostic
+		walk.Visit(root, func(node *tree_sitter.Node) bool {
+			if node.Kind() != "html_element" ||
+				walk.ChildOfKind(node, "html_end_tag") != nil || walk.ChildOfKind(node, "html_forced_end_tag") != nil {
+				return true
+			}
+			start := walk.ChildOfKind(node, "html_start_tag")
+			if start == nil {
+				return true
+			}
+			if tag := walk.ChildOfKind(start, "html_tag_name"); tag != nil {
+				if lower := strings.ToLower(tag.Utf8Text(src)); optionalEndTagElements[lower] {
+					diagnostics = append(diagnostics, At(start, name, Warning,
+						fmt.Sprintf("<%s> is closed implicitly; add </%s>", lower, lower)))
+				}
+			}
+			return true
+		})
+		return diagnostics
+	}}
+}
+
+// UnquotedAttributes reports attribute values written without quotes,
+// including interpolations, which end the value at the first space they
+// render.
+func UnquotedAttributes() Rule {
+	const name = "unquotedAttributes"
+	return ruleFunc{name, func(root *tree_sitter.Node, src []byte) []Diagnostic {
+		var diagnostics []Diagnostic
+		walk.Visit(root, func(node *tree_sitter.Node) bool {
+			if node.Kind() != "html_attribute" {
+				return true
+			}
+			if node.NamedChildCount() < 2 {
+				return false
+			}
+			value := node.NamedChild(node.NamedChildCount() - 1)
+			if value.Kind() == "html_quoted_attribute_value" {
+				return false
+			}
+			attribute := ""
+			if n := walk.ChildOfKind(node, "html_attribute_name"); n != nil {
+				attribute = n.Utf8Text(src)
+			}
+			diagnostics = append(diagnostics, At(value, name, Warning,
+				fmt.Sprintf("Value of attribute %s is not quoted", attribute)))
+			return false
+		})
+		return diagnostics
+	}}
+}
+
+// StrayEndTags reports end tags that close no open element, such as </br>
+// or a </span> without a <span>.
+func StrayEndTags() Rule {
+	const name = "strayEndTags"
+	return ruleFunc{name, func(root *tree_sitter.Node, src []byte) []Diagnostic {
+		var diagnostics []Diagnostic
+		walk.Visit(root, func(node *tree_sitter.Node) bool {
+			if node.Kind() != "html_erroneous_end_tag" {
+				return true
+			}
+			tag := "?"
+			if n := walk.ChildOfKind(node, "html_erroneous_end_tag_name"); n != nil {
+				tag = strings.ToLower(n.Utf8Text(src))
+			}
+			diagnostics = append(diagnostics, At(node, name, Warning,
+				fmt.Sprintf("End tag </%s> closes no open element", tag)))
+			return false
+		})
+		return diagnostics
+	}}
+}
+
+// StrayDelimiters reports mustache delimiters in text and attribute values,
+// such as a }} left over from an edit, which render as they are. The
+// delimiters looked for are those active where the text is.
+func StrayDelimiters() Rule {
+	const name = "strayDelimiters"
+	return ruleFunc{name, func(root *tree_sitter.Node, src []byte) []Diagnostic {
+		regions := analysis.DelimiterRegions(root, src)
+		var diagnostics []Diagnostic
+		walk.Visit(root, func(node *tree_sitter.Node) bool {
+			// The parser splits text at braces, so look at each run of
+			// adjacent text children as a whole.
+			for i := uint(0); i < node.ChildCount(); {
+				first := node.Child(i)
+				if !isText(first) {
+					i++
+					continue
+				}
+				last := first
+				for i++; i < node.ChildCount() && isText(node.Child(i)); i++ {
+					last = node.Child(i)
+				}
+				text := string(src[first.StartByte():last.EndByte()])
+				delimiters := analysis.DelimitersAt(regions, first.StartByte())
+				for _, delimiter := range []string{delimiters.Open, delimiters.Close} {
+					if at := strings.Index(text, delimiter); at >= 0 {
+						start := advance(first.StartPosition(), text[:at])
+						diagnostics = append(diagnostics, Diagnostic{
+							Rule:       name,
+							Severity:   Warning,
+							Message:    fmt.Sprintf("Stray %s outside a mustache tag", delimiter),
+							StartByte:  first.StartByte() + uint(at),
+							EndByte:    first.StartByte() + uint(at+len(delimiter)),
+							StartPoint: start,
+							EndPoint:   advance(start, delimiter),
+						})
+						break
+					}
+				}
+			}
+			return true
+		})
+		return diagnostics
+	}}
+}
+
+func isText(node *tree_sitter.Node) bool {
+	return node.Kind() == "text" || node.Kind() == "html_attribute_value"
+}
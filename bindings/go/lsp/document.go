@@ -0,0 +1,63 @@
+package lsp
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// document is an open text document.
+type document struct {
+	uri  string
+	text []byte
+	// lines holds the byte offset of the start of each line.
+	lines []uint
+}
+
+func newDocument(uri string, text []byte) *document {
+	d := &document{uri: uri, text: text, lines: []uint{0}}
+	for i, c := range text {
+		if c == '\n' {
+			d.lines = append(d.lines, uint(i+1))
+		}
+	}
+	return d
+}
+
+// position converts a byte offset to an LSP position, whose character is
+// counted in UTF-16 code units.
+func (d *document) position(offset uint) position {
+	if offset > uint(len(d.text)) {
+		offset = uint(len(d.text))
+	}
+	line := 0
+	for line+1 < len(d.lines) && d.lines[line+1] <= offset {
+		line++
+	}
+	character := uint(0)
+	for _, r := range string(d.text[d.lines[line]:offset]) {
+		character += uint(utf16.RuneLen(r))
+	}
+	return position{Line: uint(line), Character: character}
+}
+
+// offset converts an LSP position to a byte offset, clamping positions past
+// the end of their line or of the document.
+func (d *document) offset(p position) uint {
+	if int(p.Line) >= len(d.lines) {
+		return uint(len(d.text))
+	}
+	offset := d.lines[p.Line]
+	for units := uint(0); units < p.Character && offset < uint(len(d.text)); {
+		r, size := utf8.DecodeRune(d.text[offset:])
+		if r == '\n' {
+			break
+		}
+		units += uint(utf16.RuneLen(r))
+		offset += uint(size)
+	}
+	return offset
+}
+
+func (d *document) span(start, end uint) lspRange {
+	return lspRange{Start: d.position(start), End: d.position(end)}
+}
@@ -0,0 +1,324 @@
+package lsp
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/lint"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// templateExtensions are tried, in order, when resolving a partial name to a
+// file, and identify the templates offered as partial completions.
+var templateExtensions = []string{".mustache", ".hbs", ".handlebars", ".html"}
+
+// maxWorkspaceTemplates bounds the workspace scan for partial completions.
+const maxWorkspaceTemplates = 2000
+
+// parse parses d. The caller must close the tree.
+func (d *document) parse() (*tree_sitter.Tree, error) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		return nil, err
+	}
+	tree := parser.Parse(d.text, nil)
+	if tree == nil {
+		return nil, errors.New("lsp: parse failed")
+	}
+	return tree, nil
+}
+
+// diagnostics runs the default lint rules over d, and reports undefined
+// partials when there is a workspace to resolve them in.
+func (s *Server) diagnostics(d *document) []diagnostic {
+	rules := lint.DefaultRules()
+	if s.root != "" {
+		rules = append(rules, lint.UndefinedPartials(s.resolvePartial))
+	}
+	found, err := lint.Lint(d.text, rules)
+	if err != nil {
+		return []diagnostic{}
+	}
+	diagnostics := make([]diagnostic, 0, len(found))
+	for _, f := range found {
+		severity := severityError
+		if f.Severity == lint.Warning {
+			severity = severityWarning
+		}
+		diagnostics = append(diagnostics, diagnostic{
+			Range:    d.span(f.StartByte, f.EndByte),
+			Severity: severity,
+			Code:     f.Rule,
+			Source:   "htmlmustache",
+			Message:  f.Message,
+		})
+	}
+	return diagnostics
+}
+
+// documentSymbols returns the outline of d: elements and sections, nested
+// as in the document.
+func documentSymbols(d *document) []documentSymbol {
+	tree, err := d.parse()
+	if err != nil {
+		return []documentSymbol{}
+	}
+	defer tree.Close()
+	symbols := symbolsIn(d, tree.RootNode())
+	if symbols == nil {
+		symbols = []documentSymbol{}
+	}
+	return symbols
+}
+
+func symbolsIn(d *document, n *tree_sitter.Node) []documentSymbol {
+	var symbols []documentSymbol
+	for i := uint(0); i < n.ChildCount(); i++ {
+		child := n.Child(i)
+		symbol, ok := nodeSymbol(d, child)
+		if !ok {
+			symbols = append(symbols, symbolsIn(d, child)...)
+			continue
+		}
+		symbol.Children = symbolsIn(d, child)
+		symbols = append(symbols, symbol)
+	}
+	return symbols
+}
+
+func nodeSymbol(d *document, n *tree_sitter.Node) (documentSymbol, bool) {
+	first := n.Child(0)
+	if first == nil {
+		return documentSymbol{}, false
+	}
+	symbol := documentSymbol{
+		Range:          d.span(n.StartByte(), n.EndByte()),
+		SelectionRange: d.span(first.StartByte(), first.EndByte()),
+	}
+	switch n.Kind() {
+	case "html_element":
+		name := childText(first, "html_tag_name", d.text)
+		if name == "" {
+			return documentSymbol{}, false
+		}
+		symbol.Name, symbol.Kind = "<"+name+">", symbolKindClass
+	case "html_script_element", "html_style_element", "html_raw_element":
+		symbol.Name, symbol.Kind = "<"+childText(first, "html_tag_name", d.text)+">", symbolKindModule
+	case "mustache_section", "mustache_inverted_section":
+		name := childText(first, "mustache_tag_name", d.text)
+		if name == "" {
+			return documentSymbol{}, false
+		}
+		prefix := "{{#"
+		if n.Kind() == "mustache_inverted_section" {
+			prefix = "{{^"
+		}
+		symbol.Name, symbol.Kind = prefix+name+"}}", symbolKindNamespace
+	default:
+		return documentSymbol{}, false
+	}
+	return symbol, true
+}
+
+// foldingRanges returns the multi-line elements, sections and comments of
+// d.
+func foldingRanges(d *document) []foldingRange {
+	ranges := []foldingRange{}
+	tree, err := d.parse()
+	if err != nil {
+		return ranges
+	}
+	defer tree.Close()
+	for n := range walk.Named(tree) {
+		start, end := n.StartPosition().Row, n.EndPosition().Row
+		if start != end {
+			switch n.Kind() {
+			case "html_element", "html_script_element", "html_style_element", "html_raw_element",
+				"mustache_section", "mustache_inverted_section":
+				ranges = append(ranges, foldingRange{StartLine: start, EndLine: end, Kind: "region"})
+			case "html_comment", "mustache_comment":
+				ranges = append(ranges, foldingRange{StartLine: start, EndLine: end, Kind: "comment"})
+			}
+		}
+	}
+	return ranges
+}
+
+// completion returns completions for what is being typed at offset.
+func (s *Server) completion(d *document, offset uint) []completionItem {
+	ctx := analysis.ContextAt(d.text, offset)
+	var labels []string
+	var kind int
+	var detail string
+	switch ctx.Kind {
+	case analysis.InTagName:
+		labels, kind = htmlTags, completionKindProperty
+		if strings.HasSuffix(string(d.text[:ctx.StartByte]), "</") {
+			labels = openElements(ctx.Scopes)
+		}
+	case analysis.InAttributeName:
+		labels, kind = append(append([]string{}, tagAttributes[strings.ToLower(ctx.TagName)]...), globalAttributes...), completionKindProperty
+	case analysis.InInterpolation:
+		labels, kind, detail = variableNames(d, ctx), completionKindVariable, "variable"
+	case analysis.InSectionName:
+		labels, kind, detail = variableNames(d, ctx), completionKindVariable, "section"
+		if strings.HasSuffix(string(d.text[:ctx.StartByte]), "/") {
+			labels = append(openSections(ctx.Scopes), labels...)
+		}
+	case analysis.InPartialName:
+		labels, kind, detail = s.partialNames(d), completionKindFile, "partial"
+	default:
+		return []completionItem{}
+	}
+
+	items := []completionItem{}
+	seen := map[string]bool{}
+	replace := d.span(ctx.StartByte, ctx.EndByte)
+	for _, label := range labels {
+		if seen[label] || !strings.HasPrefix(label, ctx.Prefix) {
+			continue
+		}
+		seen[label] = true
+		items = append(items, completionItem{
+			Label:    label,
+			Kind:     kind,
+			Detail:   detail,
+			TextEdit: &textEdit{Range: replace, NewText: label},
+		})
+	}
+	return items
+}
+
+// openElements returns the names of the enclosing elements, innermost
+// first, as candidates for an end tag.
+func openElements(scopes []analysis.Scope) []string {
+	var names []string
+	for i := len(scopes) - 1; i >= 0; i-- {
+		if scopes[i].Kind == analysis.ElementScope {
+			names = append(names, scopes[i].Name)
+		}
+	}
+	return names
+}
+
+// openSections returns the names of the enclosing sections, innermost
+// first, as candidates for {{/name}}.
+func openSections(scopes []analysis.Scope) []string {
+	var names []string
+	for i := len(scopes) - 1; i >= 0; i-- {
+		if scopes[i].Kind != analysis.ElementScope {
+			names = append(names, scopes[i].Name)
+		}
+	}
+	return names
+}
+
+// variableNames returns the names used elsewhere in d, sorted.
+func variableNames(d *document, ctx analysis.Context) []string {
+	variables, err := analysis.ExtractVariables(d.text)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, v := range variables {
+		if v.Path != "." && v.StartByte != ctx.StartByte {
+			names = append(names, v.Path)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// partialNames returns the partials included by d and the templates in the
+// workspace, named relative to the root without their extension.
+func (s *Server) partialNames(d *document) []string {
+	names, _ := analysis.PartialNames(d.text)
+	if s.root == "" {
+		return names
+	}
+	var found []string
+	filepath.WalkDir(s.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if len(found) >= maxWorkspaceTemplates {
+			return filepath.SkipAll
+		}
+		if entry.IsDir() {
+			if name := entry.Name(); path != s.root && (strings.HasPrefix(name, ".") || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := filepath.Ext(path)
+		for _, templateExt := range templateExtensions {
+			if ext == templateExt {
+				if rel, err := filepath.Rel(s.root, path); err == nil {
+					found = append(found, filepath.ToSlash(strings.TrimSuffix(rel, ext)))
+				}
+				break
+			}
+		}
+		return nil
+	})
+	sort.Strings(found)
+	return append(names, found...)
+}
+
+// definition returns the file of the partial named at offset.
+func (s *Server) definition(d *document, offset uint) []location {
+	ctx := analysis.ContextAt(d.text, offset)
+	if ctx.Kind != analysis.InPartialName || s.root == "" {
+		return []location{}
+	}
+	path, ok := s.partialPath(string(d.text[ctx.StartByte:ctx.EndByte]))
+	if !ok {
+		return []location{}
+	}
+	return []location{{URI: pathToURI(path)}}
+}
+
+// partialPath returns the file a partial name refers to: the name itself
+// relative to the workspace root, or the name with a template extension.
+func (s *Server) partialPath(name string) (string, bool) {
+	if name == "" || s.root == "" {
+		return "", false
+	}
+	base := filepath.Join(s.root, filepath.FromSlash(name))
+	if rel, err := filepath.Rel(s.root, base); err != nil || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	for _, ext := range append([]string{""}, templateExtensions...) {
+		if info, err := os.Stat(base + ext); err == nil && !info.IsDir() {
+			return base + ext, true
+		}
+	}
+	return "", false
+}
+
+// resolvePartial is the analysis.Resolver for partials in the workspace.
+func (s *Server) resolvePartial(name string) ([]byte, error) {
+	path, ok := s.partialPath(name)
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return os.ReadFile(path)
+}
+
+func childText(n *tree_sitter.Node, kind string, src []byte) string {
+	for i := uint(0); i < n.ChildCount(); i++ {
+		if child := n.Child(i); child.Kind() == kind {
+			return child.Utf8Text(src)
+		}
+	}
+	return ""
+}
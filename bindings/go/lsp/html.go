@@ -0,0 +1,47 @@
+package lsp
+
+// htmlTags are the element names offered as tag completions.
+var htmlTags = []string{
+	"a", "abbr", "address", "area", "article", "aside", "audio", "b", "base", "blockquote",
+	"body", "br", "button", "canvas", "caption", "code", "col", "colgroup", "datalist", "dd",
+	"del", "details", "dialog", "div", "dl", "dt", "em", "embed", "fieldset", "figcaption",
+	"figure", "footer", "form", "h1", "h2", "h3", "h4", "h5", "h6", "head", "header", "hr",
+	"html", "i", "iframe", "img", "input", "ins", "kbd", "label", "legend", "li", "link",
+	"main", "mark", "meta", "nav", "noscript", "object", "ol", "optgroup", "option", "output",
+	"p", "picture", "pre", "progress", "q", "s", "samp", "script", "section", "select", "small",
+	"source", "span", "strong", "style", "sub", "summary", "sup", "table", "tbody", "td",
+	"template", "textarea", "tfoot", "th", "thead", "time", "title", "tr", "track", "u", "ul",
+	"var", "video", "wbr",
+}
+
+// globalAttributes are offered in every start tag.
+var globalAttributes = []string{
+	"accesskey", "autofocus", "class", "contenteditable", "dir", "draggable", "hidden", "id",
+	"inert", "lang", "role", "slot", "spellcheck", "style", "tabindex", "title", "translate",
+	"onblur", "onchange", "onclick", "onfocus", "oninput", "onkeydown", "onkeyup", "onsubmit",
+}
+
+// tagAttributes are offered, before the global ones, in start tags of the
+// element they belong to.
+var tagAttributes = map[string][]string{
+	"a":        {"href", "target", "rel", "download", "hreflang", "type"},
+	"area":     {"alt", "coords", "href", "shape", "target"},
+	"audio":    {"src", "autoplay", "controls", "loop", "muted", "preload"},
+	"button":   {"type", "name", "value", "disabled", "form"},
+	"form":     {"action", "method", "enctype", "name", "novalidate", "target"},
+	"iframe":   {"src", "srcdoc", "name", "width", "height", "allow", "loading", "sandbox"},
+	"img":      {"src", "alt", "width", "height", "srcset", "sizes", "loading"},
+	"input":    {"type", "name", "value", "placeholder", "checked", "disabled", "required", "readonly", "min", "max", "step", "pattern"},
+	"label":    {"for", "form"},
+	"link":     {"rel", "href", "type", "media", "as", "crossorigin"},
+	"meta":     {"name", "content", "charset", "http-equiv"},
+	"ol":       {"reversed", "start", "type"},
+	"option":   {"value", "selected", "disabled", "label"},
+	"script":   {"src", "type", "async", "defer", "crossorigin", "integrity"},
+	"select":   {"name", "multiple", "disabled", "required", "size"},
+	"source":   {"src", "srcset", "type", "media", "sizes"},
+	"td":       {"colspan", "rowspan", "headers"},
+	"textarea": {"name", "rows", "cols", "placeholder", "disabled", "required", "readonly"},
+	"th":       {"colspan", "rowspan", "headers", "scope"},
+	"video":    {"src", "poster", "width", "height", "autoplay", "controls", "loop", "muted"},
+}
@@ -0,0 +1,214 @@
+// Package lsp implements a Language Server Protocol server for htmlmustache
+// templates over the Go bindings. It publishes diagnostics from parse errors
+// and lint rules, and answers document symbol, folding range, completion and
+// go-to-definition requests. Partials are resolved to files under the
+// workspace root.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// Server is a language server speaking JSON-RPC over a reader and writer,
+// usually stdin and stdout.
+type Server struct {
+	in  *bufio.Reader
+	out io.Writer
+	// root is the workspace directory partials are resolved in, or "" if the
+	// client opened no workspace.
+	root        string
+	documents   map[string]*document
+	initialized bool
+	shutdown    bool
+}
+
+// NewServer returns a server reading requests from in and writing responses
+// to out.
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{in: bufio.NewReader(in), out: out, documents: map[string]*document{}}
+}
+
+// ErrNoShutdown is returned by Run when the client sent exit without a
+// shutdown request first, or closed the connection.
+var ErrNoShutdown = errors.New("lsp: exit without shutdown")
+
+// Run serves requests until the client sends exit. It returns nil after an
+// orderly shutdown.
+func (s *Server) Run() error {
+	for {
+		msg, err := readMessage(s.in)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return ErrNoShutdown
+			}
+			return err
+		}
+		if msg.Method == "exit" {
+			if s.shutdown {
+				return nil
+			}
+			return ErrNoShutdown
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches a request or notification. It returns an error only
+// when writing to the client fails.
+func (s *Server) handle(msg *message) error {
+	if msg.ID == nil {
+		s.notify(msg)
+		return nil
+	}
+	if !s.initialized && msg.Method != "initialize" {
+		return s.replyError(msg, codeServerNotInitialized, "server not initialized")
+	}
+
+	var result any
+	var err error
+	switch msg.Method {
+	case "initialize":
+		result, err = s.initialize(msg.Params)
+	case "shutdown":
+		s.shutdown = true
+	case "textDocument/documentSymbol":
+		result, err = withDocument(s, msg.Params, documentSymbols)
+	case "textDocument/foldingRange":
+		result, err = withDocument(s, msg.Params, foldingRanges)
+	case "textDocument/completion":
+		result, err = withPosition(s, msg.Params, s.completion)
+	case "textDocument/definition":
+		result, err = withPosition(s, msg.Params, s.definition)
+	default:
+		return s.replyError(msg, codeMethodNotFound, "method not supported: "+msg.Method)
+	}
+	if err != nil {
+		return s.replyError(msg, codeInvalidParams, err.Error())
+	}
+	return writeMessage(s.out, response{JSONRPC: "2.0", ID: msg.ID, Result: result})
+}
+
+func (s *Server) replyError(msg *message, code int, text string) error {
+	return writeMessage(s.out, errorResponse{JSONRPC: "2.0", ID: msg.ID, Error: responseError{Code: code, Message: text}})
+}
+
+// notify handles a notification. Malformed notifications are dropped, as
+// there is no way to report them.
+func (s *Server) notify(msg *message) {
+	if !s.initialized {
+		return
+	}
+	switch msg.Method {
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if json.Unmarshal(msg.Params, &params) == nil {
+			s.update(params.TextDocument.URI, params.TextDocument.Text)
+		}
+	case "textDocument/didChange":
+		var params didChangeParams
+		if json.Unmarshal(msg.Params, &params) == nil && len(params.ContentChanges) > 0 {
+			// The server asks for full document sync, so the last change
+			// holds the whole text.
+			s.update(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+		}
+	case "textDocument/didClose":
+		var params documentParams
+		if json.Unmarshal(msg.Params, &params) == nil {
+			delete(s.documents, params.TextDocument.URI)
+			s.publish(params.TextDocument.URI, []diagnostic{})
+		}
+	}
+}
+
+func (s *Server) update(uri, text string) {
+	doc := newDocument(uri, []byte(text))
+	s.documents[uri] = doc
+	s.publish(uri, s.diagnostics(doc))
+}
+
+func (s *Server) publish(uri string, diagnostics []diagnostic) {
+	writeMessage(s.out, notification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics},
+	})
+}
+
+func (s *Server) initialize(raw json.RawMessage) (any, error) {
+	var params initializeParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, err
+	}
+	switch {
+	case params.RootURI != "":
+		s.root = uriToPath(params.RootURI)
+	case params.RootPath != "":
+		s.root = params.RootPath
+	}
+	s.initialized = true
+	return map[string]any{
+		"capabilities": map[string]any{
+			// Full document sync.
+			"textDocumentSync":       1,
+			"documentSymbolProvider": true,
+			"foldingRangeProvider":   true,
+			"definitionProvider":     true,
+			"completionProvider": map[string]any{
+				"triggerCharacters": []string{"<", " ", "{", "#", "^", "/", ">", "."},
+			},
+		},
+		"serverInfo": map[string]any{"name": "htmlmustache-ls"},
+	}, nil
+}
+
+// withDocument decodes the document of a request and calls handler with it.
+func withDocument[T any](s *Server, raw json.RawMessage, handler func(*document) T) (any, error) {
+	var params documentParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, err
+	}
+	doc, ok := s.documents[params.TextDocument.URI]
+	if !ok {
+		return nil, fmt.Errorf("document not open: %s", params.TextDocument.URI)
+	}
+	return handler(doc), nil
+}
+
+// withPosition decodes the document and position of a request and calls
+// handler with them.
+func withPosition[T any](s *Server, raw json.RawMessage, handler func(*document, uint) T) (any, error) {
+	var params textDocumentPositionParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, err
+	}
+	doc, ok := s.documents[params.TextDocument.URI]
+	if !ok {
+		return nil, fmt.Errorf("document not open: %s", params.TextDocument.URI)
+	}
+	return handler(doc, doc.offset(params.Position)), nil
+}
+
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+func pathToURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
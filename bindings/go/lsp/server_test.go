@@ -0,0 +1,263 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// client drives a Server over pipes.
+type client struct {
+	t      *testing.T
+	in     io.WriteCloser
+	out    *bufio.Reader
+	done   chan error
+	nextID int
+}
+
+func newClient(t *testing.T) *client {
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	c := &client{t: t, in: inWriter, out: bufio.NewReader(outReader), done: make(chan error, 1)}
+	go func() {
+		c.done <- NewServer(inReader, outWriter).Run()
+		outWriter.Close()
+	}()
+	return c
+}
+
+func (c *client) send(method string, params any) {
+	c.t.Helper()
+	msg := map[string]any{"jsonrpc": "2.0", "method": method, "params": params}
+	if err := writeMessage(c.in, msg); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// request sends a request and decodes its result into result, skipping
+// notifications sent in the meantime.
+func (c *client) request(method string, params, result any) {
+	c.t.Helper()
+	c.nextID++
+	msg := map[string]any{"jsonrpc": "2.0", "id": c.nextID, "method": method, "params": params}
+	if err := writeMessage(c.in, msg); err != nil {
+		c.t.Fatal(err)
+	}
+	for {
+		raw := c.read()
+		var reply struct {
+			ID     *int
+			Result json.RawMessage
+			Error  *responseError
+		}
+		if err := json.Unmarshal(raw, &reply); err != nil {
+			c.t.Fatal(err)
+		}
+		if reply.ID == nil {
+			continue
+		}
+		if reply.Error != nil {
+			c.t.Fatalf("%s: %s", method, reply.Error.Message)
+		}
+		if result != nil {
+			if err := json.Unmarshal(reply.Result, result); err != nil {
+				c.t.Fatal(err)
+			}
+		}
+		return
+	}
+}
+
+// diagnostics reads the next publishDiagnostics notification.
+func (c *client) diagnostics() publishDiagnosticsParams {
+	c.t.Helper()
+	var msg struct {
+		Method string
+		Params publishDiagnosticsParams
+	}
+	if err := json.Unmarshal(c.read(), &msg); err != nil {
+		c.t.Fatal(err)
+	}
+	if msg.Method != "textDocument/publishDiagnostics" {
+		c.t.Fatalf("got %s, want publishDiagnostics", msg.Method)
+	}
+	return msg.Params
+}
+
+func (c *client) read() []byte {
+	c.t.Helper()
+	msg, err := readRaw(c.out)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return msg
+}
+
+// readRaw reads one framed message body.
+func readRaw(r *bufio.Reader) ([]byte, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, err
+	}
+	body := make([]byte, length)
+	_, err = io.ReadFull(r, body)
+	return body, err
+}
+
+func (c *client) close() {
+	c.t.Helper()
+	c.request("shutdown", nil, nil)
+	c.send("exit", nil)
+	if err := <-c.done; err != nil {
+		c.t.Errorf("Run() = %v", err)
+	}
+}
+
+func open(c *client, uri, text string) {
+	c.t.Helper()
+	c.send("textDocument/didOpen", map[string]any{"textDocument": map[string]any{"uri": uri, "languageId": "htmlmustache", "version": 1, "text": text}})
+}
+
+func at(uri string, line, character int) map[string]any {
+	return map[string]any{"textDocument": map[string]any{"uri": uri}, "position": map[string]any{"line": line, "character": character}}
+}
+
+func TestServer(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "partials"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "partials", "item.mustache"), []byte("<li>{{name}}</li>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newClient(t)
+	var init struct {
+		Capabilities map[string]any
+	}
+	c.request("initialize", map[string]any{"rootUri": pathToURI(root)}, &init)
+	if init.Capabilities["definitionProvider"] != true {
+		t.Errorf("capabilities = %v", init.Capabilities)
+	}
+	c.send("initialized", map[string]any{})
+
+	uri := pathToURI(filepath.Join(root, "page.mustache"))
+	src := "<ul>\n  {{#items}}\n    {{> partials/item}}\n  {{/items}}\n  {{> missing}}\n</ul>\n<p>{{title}} {{ti}}</p>\n"
+	open(c, uri, src)
+	published := c.diagnostics()
+	if len(published.Diagnostics) != 1 || published.Diagnostics[0].Code != "undefinedPartials" {
+		t.Errorf("diagnostics = %+v", published.Diagnostics)
+	} else if got := published.Diagnostics[0].Range.Start; got != (position{Line: 4, Character: 2}) {
+		t.Errorf("diagnostic starts at %+v", got)
+	}
+
+	var symbols []documentSymbol
+	c.request("textDocument/documentSymbol", map[string]any{"textDocument": map[string]any{"uri": uri}}, &symbols)
+	var names []string
+	var walk func([]documentSymbol)
+	walk = func(symbols []documentSymbol) {
+		for _, s := range symbols {
+			names = append(names, s.Name)
+			walk(s.Children)
+		}
+	}
+	walk(symbols)
+	if want := []string{"<ul>", "{{#items}}", "<p>"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("symbols = %q, want %q", names, want)
+	}
+
+	var folds []foldingRange
+	c.request("textDocument/foldingRange", map[string]any{"textDocument": map[string]any{"uri": uri}}, &folds)
+	if want := []foldingRange{{StartLine: 0, EndLine: 5, Kind: "region"}, {StartLine: 1, EndLine: 3, Kind: "region"}}; !reflect.DeepEqual(folds, want) {
+		t.Errorf("folding ranges = %+v, want %+v", folds, want)
+	}
+
+	var items []completionItem
+	c.request("textDocument/completion", at(uri, 6, 17), &items)
+	if len(items) != 1 || items[0].Label != "title" || items[0].TextEdit.Range != (lspRange{Start: position{6, 15}, End: position{6, 17}}) {
+		t.Errorf("variable completions = %+v", items)
+	}
+	c.request("textDocument/completion", at(uri, 2, 14), &items)
+	if len(items) != 1 || items[0].Label != "partials/item" {
+		t.Errorf("partial completions = %+v", items)
+	}
+
+	var locations []location
+	c.request("textDocument/definition", at(uri, 2, 12), &locations)
+	if want := []location{{URI: pathToURI(filepath.Join(root, "partials", "item.mustache"))}}; !reflect.DeepEqual(locations, want) {
+		t.Errorf("definition = %+v, want %+v", locations, want)
+	}
+
+	c.send("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": uri, "version": 2},
+		"contentChanges": []map[string]any{{"text": "<di"}},
+	})
+	c.diagnostics()
+	c.request("textDocument/completion", at(uri, 0, 3), &items)
+	if len(items) != 2 || items[0].Label != "dialog" || items[1].Label != "div" {
+		t.Errorf("tag completions = %+v", items)
+	}
+
+	c.send("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": uri, "version": 3},
+		"contentChanges": []map[string]any{{"text": "<ul><li>x</"}},
+	})
+	c.diagnostics()
+	c.request("textDocument/completion", at(uri, 0, 11), &items)
+	if len(items) != 2 || items[0].Label != "li" || items[1].Label != "ul" {
+		t.Errorf("end tag completions = %+v", items)
+	}
+	c.close()
+}
+
+func TestServerRequiresInitialize(t *testing.T) {
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	go NewServer(inReader, outWriter).Run()
+	go writeMessage(inWriter, map[string]any{"jsonrpc": "2.0", "id": 1, "method": "textDocument/completion", "params": map[string]any{}})
+	raw, err := readRaw(bufio.NewReader(outReader))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reply struct{ Error *responseError }
+	if err := json.Unmarshal(raw, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Error == nil || reply.Error.Code != codeServerNotInitialized {
+		t.Errorf("reply = %s", raw)
+	}
+	inWriter.Close()
+}
+
+func TestDocumentPositions(t *testing.T) {
+	d := newDocument("file:///x", []byte("ab\né\U0001F600x\n"))
+	tests := []struct {
+		offset uint
+		pos    position
+	}{
+		{0, position{0, 0}},
+		{2, position{0, 2}},
+		{3, position{1, 0}},
+		{5, position{1, 1}},
+		{9, position{1, 3}},
+		{11, position{2, 0}},
+	}
+	for _, test := range tests {
+		if got := d.position(test.offset); got != test.pos {
+			t.Errorf("position(%d) = %+v, want %+v", test.offset, got, test.pos)
+		}
+		if got := d.offset(test.pos); got != test.offset {
+			t.Errorf("offset(%+v) = %d, want %d", test.pos, got, test.offset)
+		}
+	}
+}
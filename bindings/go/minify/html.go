@@ -0,0 +1,120 @@
+package minify
+
+import (
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// blockElements are the elements whose default CSS display is not inline,
+// so whitespace around them does not render.
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"body": true, "caption": true, "center": true, "col": true,
+	"colgroup": true, "dd": true, "details": true, "dialog": true,
+	"dir": true, "div": true, "dl": true, "dt": true, "fieldset": true,
+	"figcaption": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"head": true, "header": true, "hgroup": true, "hr": true, "html": true,
+	"legend": true, "li": true, "link": true, "listing": true, "main": true,
+	"menu": true, "meta": true, "nav": true, "ol": true, "p": true,
+	"plaintext": true, "pre": true, "search": true, "section": true, "summary": true,
+	"table": true, "tbody": true, "td": true, "template": true,
+	"tfoot": true, "th": true, "thead": true, "title": true, "tr": true,
+	"ul": true, "xmp": true,
+}
+
+// booleanAttributes are the attributes whose value is ignored, so
+// disabled="disabled" and disabled="" can be written as disabled.
+var booleanAttributes = map[string]bool{
+	"allowfullscreen": true, "async": true, "autofocus": true, "autoplay": true,
+	"checked": true, "controls": true, "default": true, "defer": true,
+	"disabled": true, "formnovalidate": true, "hidden": true, "inert": true,
+	"ismap": true, "itemscope": true, "loop": true, "multiple": true,
+	"muted": true, "nomodule": true, "novalidate": true, "open": true,
+	"playsinline": true, "readonly": true, "required": true, "reversed": true,
+	"selected": true,
+}
+
+// preservesContent reports whether the element's content is whitespace
+// sensitive and must be copied as written.
+func preservesContent(tag string) bool {
+	return tag == "pre" || tag == "textarea"
+}
+
+// attribute writes an attribute as name, name=value or name="value",
+// choosing the shortest form that means the same.
+func (m *minifier) attribute(n *tree_sitter.Node) {
+	name := walk.ChildOfKind(n, "html_attribute_name")
+	if name == nil {
+		m.verbatim(n)
+		return
+	}
+	m.out.WriteString(m.text(name))
+	value := n.Child(n.ChildCount() - 1)
+	switch value.Kind() {
+	case "html_attribute_name":
+		return
+	case "html_quoted_attribute_value":
+		text, static := m.quotedValue(value)
+		switch {
+		case !static:
+			m.out.WriteByte('=')
+			m.verbatim(value)
+			return
+		case booleanAttributes[strings.ToLower(m.text(name))] &&
+			(text == "" || strings.EqualFold(text, m.text(name))):
+			return
+		case unquotable(text):
+			m.out.WriteString("=" + text)
+			m.unquoted = true
+			return
+		}
+		m.out.WriteByte('=')
+		m.verbatim(value)
+	case "html_attribute_value":
+		text := m.text(value)
+		if booleanAttributes[strings.ToLower(m.text(name))] && strings.EqualFold(text, m.text(name)) {
+			return
+		}
+		m.out.WriteString("=" + text)
+		m.unquoted = true
+	default:
+		m.out.WriteByte('=')
+		m.verbatim(value)
+	}
+}
+
+// quotedValue returns the text inside quotes and whether it is plain text,
+// without mustache tags.
+func (m *minifier) quotedValue(n *tree_sitter.Node) (string, bool) {
+	var b strings.Builder
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		child := n.NamedChild(i)
+		if child.Kind() != "html_attribute_value" {
+			return "", false
+		}
+		b.WriteString(m.text(child))
+	}
+	return b.String(), true
+}
+
+// unquotable reports whether value can be written without quotes: it is
+// not empty and has no whitespace, quotes, "=", "<", ">", backticks or a
+// trailing slash.
+func unquotable(value string) bool {
+	return value != "" && !strings.ContainsAny(value, " \t\n\r\f\"'=<>`") &&
+		!strings.HasSuffix(value, "/") && !strings.Contains(value, "{{")
+}
+
+// tagName returns the lowercased tag name of an element.
+func tagName(n *tree_sitter.Node, src []byte) string {
+	if start := n.Child(0); start != nil {
+		if name := walk.ChildOfKind(start, "html_tag_name"); name != nil {
+			return strings.ToLower(string(src[name.StartByte():name.EndByte()]))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,200 @@
+// Package minify shrinks htmlmustache templates without changing what they
+// render.
+//
+// Whitespace between nodes collapses to a single space, or disappears next
+// to block-level elements. HTML comments are dropped, except conditional
+// comments. Attribute values lose their quotes where HTML allows it, and
+// boolean attributes lose redundant values. Raw text, <pre> and <textarea>
+// are copied as written, and mustache tags that were standalone (alone on
+// their line) are kept on lines of their own so the renderer still strips
+// those lines.
+package minify
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+)
+
+// ErrSyntax is returned for templates whose parse tree contains errors.
+var ErrSyntax = errors.New("minify: template has syntax errors")
+
+// Minify returns the minified src.
+func Minify(src []byte) ([]byte, error) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		return nil, err
+	}
+	tree := parser.Parse(src, nil)
+	if tree == nil {
+		return nil, errors.New("minify: parse failed")
+	}
+	defer tree.Close()
+
+	root := tree.RootNode()
+	if root.HasError() {
+		return nil, ErrSyntax
+	}
+	m := &minifier{src: src, tagRows: map[uint]bool{}}
+	m.markTagRows(root)
+	m.node(root)
+	return m.out.Bytes(), nil
+}
+
+type minifier struct {
+	src []byte
+	out bytes.Buffer
+	// tagRows holds the source rows of mustache tags that may be standalone.
+	// Comments on those rows are kept, as removing one could make a tag
+	// standalone.
+	tagRows map[uint]bool
+	// unquoted is set when the last attribute written ended in an unquoted
+	// value, which must not be followed directly by "/>".
+	unquoted bool
+}
+
+func (m *minifier) node(n *tree_sitter.Node) {
+	m.unquoted = false
+	switch n.Kind() {
+	case "document", "mustache_section", "mustache_inverted_section", "mustache_attribute",
+		"html_start_tag", "html_self_closing_tag", "html_end_tag":
+		m.children(n)
+	case "html_element":
+		if preservesContent(tagName(n, m.src)) {
+			m.verbatim(n)
+			return
+		}
+		m.children(n)
+	case "html_attribute":
+		m.attribute(n)
+	case "text":
+		m.out.WriteString(strings.Join(strings.Fields(m.text(n)), " "))
+	default:
+		m.verbatim(n)
+	}
+}
+
+func (m *minifier) verbatim(n *tree_sitter.Node) {
+	m.out.WriteString(m.text(n))
+}
+
+// children writes the children of n, minifying the whitespace between them.
+func (m *minifier) children(n *tree_sitter.Node) {
+	var prev *tree_sitter.Node
+	var space []byte
+	last := n.StartByte()
+	for i := uint(0); i < n.ChildCount(); i++ {
+		child := n.Child(i)
+		space = append(space, m.src[last:child.StartByte()]...)
+		last = child.EndByte()
+		// A dropped comment merges the whitespace on both of its sides.
+		if m.removable(child) {
+			continue
+		}
+		if prev != nil {
+			m.out.WriteString(m.gap(n, prev, child, string(space)))
+		}
+		m.node(child)
+		prev, space = child, space[:0]
+	}
+}
+
+// gap returns the minified form of the whitespace between prev and next,
+// two children of parent.
+func (m *minifier) gap(parent, prev, next *tree_sitter.Node, space string) string {
+	if space == "" {
+		return ""
+	}
+	if strings.Contains(space, "\n") && (m.standalone(edgeTag(prev, false)) || m.standalone(edgeTag(next, true))) {
+		return "\n"
+	}
+	switch parent.Kind() {
+	case "html_start_tag", "html_self_closing_tag", "html_end_tag":
+		switch next.Kind() {
+		case ">":
+			return ""
+		case "/>":
+			if m.unquoted {
+				return " "
+			}
+			return ""
+		}
+		return " "
+	}
+	if m.blockBoundary(prev) || m.blockBoundary(next) {
+		return ""
+	}
+	return " "
+}
+
+// blockBoundary reports whether whitespace next to n does not render: n is
+// a block-level element or one of its tags.
+func (m *minifier) blockBoundary(n *tree_sitter.Node) bool {
+	switch n.Kind() {
+	case "html_element":
+		return blockElements[tagName(n, m.src)]
+	case "html_start_tag", "html_end_tag":
+		if parent := n.Parent(); parent != nil && parent.Kind() == "html_element" {
+			return blockElements[tagName(parent, m.src)]
+		}
+	case "html_doctype":
+		return true
+	}
+	return false
+}
+
+// removable reports whether n is an HTML comment that can be dropped.
+func (m *minifier) removable(n *tree_sitter.Node) bool {
+	if n.Kind() != "html_comment" {
+		return false
+	}
+	text := m.text(n)
+	if strings.HasPrefix(text, "<!--[if") || strings.HasPrefix(text, "<!--<![endif]") ||
+		strings.HasSuffix(text, "<![endif]-->") {
+		return false
+	}
+	return !m.tagRows[n.StartPosition().Row] && !m.tagRows[n.EndPosition().Row]
+}
+
+// markTagRows records the rows of mustache tags that Mustache may treat as
+// standalone.
+func (m *minifier) markTagRows(n *tree_sitter.Node) {
+	if analysis.CanBeStandalone(n.Kind()) {
+		m.tagRows[n.StartPosition().Row] = true
+		m.tagRows[n.EndPosition().Row] = true
+		return
+	}
+	for i := uint(0); i < n.ChildCount(); i++ {
+		m.markTagRows(n.Child(i))
+	}
+}
+
+// edgeTag returns the first or last tag of a section, which is what lies
+// next to the whitespace around it, or n itself for other nodes.
+func edgeTag(n *tree_sitter.Node, first bool) *tree_sitter.Node {
+	for n.ChildCount() > 0 && (n.Kind() == "mustache_section" || n.Kind() == "mustache_inverted_section") {
+		if first {
+			n = n.Child(0)
+		} else {
+			n = n.Child(n.ChildCount() - 1)
+		}
+	}
+	return n
+}
+
+// standalone reports whether n is a mustache tag that is the only thing on
+// its source line.
+func (m *minifier) standalone(n *tree_sitter.Node) bool {
+	_, ok := analysis.Standalone(n, m.src)
+	return ok
+}
+
+func (m *minifier) text(n *tree_sitter.Node) string {
+	return string(m.src[n.StartByte():n.EndByte()])
+}
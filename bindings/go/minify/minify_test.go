@@ -0,0 +1,79 @@
+package minify_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/minify"
+)
+
+func TestMinify(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "whitespace around block elements",
+			src:  "<div>\n  <p>\n    Hello,   world\n  </p>\n</div>\n",
+			want: "<div><p>Hello, world</p></div>",
+		},
+		{
+			name: "whitespace between inline elements",
+			src:  "<p>a <b>b</b>\n  <i>c</i></p>",
+			want: "<p>a <b>b</b> <i>c</i></p>",
+		},
+		{
+			name: "comments",
+			src:  "<!-- note -->\n<p>a</p>\n<!--[if IE]><p>ie</p><![endif]-->",
+			want: "<p>a</p><!--[if IE]><p>ie</p><![endif]-->",
+		},
+		{
+			name: "raw text and pre",
+			src:  "<div>\n  <pre>  a\n   b </pre>\n  <script>\n  let a = 1;\n  </script>\n</div>",
+			want: "<div><pre>  a\n   b </pre><script>\n  let a = 1;\n  </script></div>",
+		},
+		{
+			name: "attributes",
+			src:  `<input type="text" disabled="disabled" checked="" value="a b" class = "x" title="{{t}}">`,
+			want: `<input type=text disabled checked value="a b" class=x title="{{t}}">`,
+		},
+		{
+			name: "unquoted value before self-closing slash",
+			src:  `<img src="a.png" />`,
+			want: `<img src=a.png />`,
+		},
+		{
+			name: "standalone tags keep their lines",
+			src:  "<ul>\n  {{#items}}\n    <li>{{name}}</li>\n  {{/items}}\n</ul>",
+			want: "<ul>\n{{#items}}\n<li>{{name}}</li>\n{{/items}}\n</ul>",
+		},
+		{
+			name: "inline sections stay inline",
+			src:  "<p>{{#a}} yes {{/a}}\n  {{^a}}no{{/a}}</p>",
+			want: "<p>{{#a}} yes {{/a}} {{^a}}no{{/a}}</p>",
+		},
+		{
+			name: "comments next to mustache tags are kept",
+			src:  "<div>\n  <!-- c --> {{#a}}\n  x\n  {{/a}}\n</div>",
+			want: "<div><!-- c --> {{#a}} x\n{{/a}}\n</div>",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := minify.Minify([]byte(test.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("Minify() =\n%q\nwant\n%q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMinifySyntaxError(t *testing.T) {
+	if _, err := minify.Minify([]byte("<div>{{#a}}</div>")); !errors.Is(err, minify.ErrSyntax) {
+		t.Errorf("Minify() error = %v, want ErrSyntax", err)
+	}
+}
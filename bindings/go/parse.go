@@ -0,0 +1,48 @@
+package tree_sitter_htmlmustache
+
+import (
+	"context"
+	"errors"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// ErrParseFailed is returned by ParseContext when the parser returns no
+// tree for a reason other than cancellation, such as having no language.
+var ErrParseFailed = errors.New("htmlmustache: parse failed")
+
+// ParseContext parses src with parser, which must be set to this language,
+// stopping early if ctx is done. It returns ctx.Err() if ctx is done before
+// parsing completes, and the tree otherwise; either way parser is ready for
+// another parse. The caller must close the tree.
+func ParseContext(ctx context.Context, parser *tree_sitter.Parser, src []byte) (*tree_sitter.Tree, error) {
+	return ReparseContext(ctx, parser, src, nil)
+}
+
+// ReparseContext is like ParseContext, but parses incrementally from old, a
+// tree of the previous source edited with Tree.Edit to match src. old is
+// not modified or closed.
+func ReparseContext(ctx context.Context, parser *tree_sitter.Parser, src []byte, old *tree_sitter.Tree) (*tree_sitter.Tree, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// Parser.ParseCtx cancels through the parser's cancellation flag, which
+	// is nil unless set, so it crashes if ctx is cancelled mid-parse. Poll
+	// ctx from the progress callback instead.
+	tree := parser.ParseWithOptions(func(i int, _ tree_sitter.Point) []byte {
+		if i < len(src) {
+			return src[i:]
+		}
+		return nil
+	}, old, &tree_sitter.ParseOptions{ProgressCallback: func(tree_sitter.ParseState) bool { return ctx.Err() != nil }})
+	if tree == nil {
+		// A halted parse resumes on the parser's next parse unless it is
+		// reset, which would mix the sources of the two.
+		parser.Reset()
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, ErrParseFailed
+	}
+	return tree, nil
+}
@@ -0,0 +1,67 @@
+package tree_sitter_htmlmustache_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+)
+
+// cancelAfter is a context that is cancelled once Err has been called n
+// times, so that cancellation lands in the middle of a parse.
+type cancelAfter struct {
+	context.Context
+	n     int64
+	calls atomic.Int64
+}
+
+func (c *cancelAfter) Err() error {
+	if c.calls.Add(1) > c.n {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestParseContext(t *testing.T) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		t.Fatal(err)
+	}
+	src := []byte(strings.Repeat("<ul>{{#items}}<li>{{name}}</li>{{/items}}</ul>\n", 2000))
+
+	tree, err := tree_sitter_htmlmustache.ParseContext(context.Background(), parser, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.RootNode().HasError() {
+		t.Errorf("unexpected syntax errors: %s", tree.RootNode().ToSexp())
+	}
+	tree.Close()
+
+	ctx := &cancelAfter{Context: context.Background(), n: 2}
+	if tree, err := tree_sitter_htmlmustache.ParseContext(ctx, parser, src); !errors.Is(err, context.Canceled) {
+		if tree != nil {
+			tree.Close()
+		}
+		t.Errorf("ParseContext() error = %v, want context.Canceled", err)
+	}
+	if ctx.calls.Load() <= ctx.n {
+		t.Errorf("ctx checked %d times, want the parse to be cancelled partway", ctx.calls.Load())
+	}
+
+	// The cancelled parse is not resumed by the next one.
+	tree, err = tree_sitter_htmlmustache.ParseContext(context.Background(), parser, []byte("<p>x</p>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tree.Close()
+	if got, expected := tree.RootNode().EndByte(), uint(len("<p>x</p>")); got != expected {
+		t.Errorf("next parse ends at %d, want %d", got, expected)
+	}
+}
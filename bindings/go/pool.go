@@ -0,0 +1,113 @@
+package tree_sitter_htmlmustache
+
+import (
+	"runtime"
+	"sync"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// ParserPool holds parsers set to this language for reuse. A parser must
+// only be used by one goroutine at a time; the pool lets goroutines share
+// parsers without creating one per parse. The zero value is an empty pool
+// ready for use, and a ParserPool must not be copied after first use.
+//
+// Parsers hold C memory that the garbage collector does not free, so call
+// Close when the pool is no longer needed.
+type ParserPool struct {
+	mu      sync.Mutex
+	parsers []*tree_sitter.Parser
+}
+
+// Get returns a parser from the pool, creating one if the pool is empty.
+// Return it with Put when done.
+func (p *ParserPool) Get() (*tree_sitter.Parser, error) {
+	p.mu.Lock()
+	if n := len(p.parsers); n > 0 {
+		parser := p.parsers[n-1]
+		p.parsers = p.parsers[:n-1]
+		p.mu.Unlock()
+		return parser, nil
+	}
+	p.mu.Unlock()
+
+	parser := tree_sitter.NewParser()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(Language())); err != nil {
+		parser.Close()
+		return nil, err
+	}
+	return parser, nil
+}
+
+// Put returns a parser obtained from Get to the pool.
+func (p *ParserPool) Put(parser *tree_sitter.Parser) {
+	parser.Reset()
+	p.mu.Lock()
+	p.parsers = append(p.parsers, parser)
+	p.mu.Unlock()
+}
+
+// Close closes the parsers in the pool. Parsers still checked out are not
+// affected and may be returned with Put afterwards.
+func (p *ParserPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, parser := range p.parsers {
+		parser.Close()
+	}
+	p.parsers = nil
+}
+
+// parsers is the pool ParseConcurrently draws from. It is never closed, so
+// it holds at most one parser per worker that has ever run at once.
+var parsers ParserPool
+
+// ParseConcurrently parses each file on a pool of up to GOMAXPROCS workers
+// and returns the trees by file name. The caller must close every tree. A
+// file that cannot be parsed is missing from the result.
+func ParseConcurrently(files map[string][]byte) map[string]*tree_sitter.Tree {
+	type result struct {
+		name string
+		tree *tree_sitter.Tree
+	}
+	names := make(chan string)
+	results := make(chan result)
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(files) {
+		workers = len(files)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			parser, err := parsers.Get()
+			if err != nil {
+				for range names {
+				}
+				return
+			}
+			defer parsers.Put(parser)
+			for name := range names {
+				if tree := parser.Parse(files[name], nil); tree != nil {
+					results <- result{name, tree}
+				}
+			}
+		}()
+	}
+	go func() {
+		for name := range files {
+			names <- name
+		}
+		close(names)
+		wg.Wait()
+		close(results)
+	}()
+
+	trees := make(map[string]*tree_sitter.Tree, len(files))
+	for r := range results {
+		trees[r.name] = r.tree
+	}
+	return trees
+}
@@ -0,0 +1,56 @@
+package tree_sitter_htmlmustache_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+)
+
+func TestParseConcurrently(t *testing.T) {
+	files := map[string][]byte{}
+	for i := 0; i < 50; i++ {
+		files[fmt.Sprintf("t%d.mustache", i)] = []byte(fmt.Sprintf("<p>{{#s%d}}{{name}}{{/s%d}}</p>", i, i))
+	}
+	trees := tree_sitter_htmlmustache.ParseConcurrently(files)
+	if len(trees) != len(files) {
+		t.Fatalf("got %d trees, want %d", len(trees), len(files))
+	}
+	for name, tree := range trees {
+		root := tree.RootNode()
+		if root.HasError() || root.Utf8Text(files[name]) != string(files[name]) {
+			t.Errorf("%s: tree %s does not match its source", name, root.ToSexp())
+		}
+		tree.Close()
+	}
+
+	if trees := tree_sitter_htmlmustache.ParseConcurrently(nil); len(trees) != 0 {
+		t.Errorf("ParseConcurrently(nil) = %v", trees)
+	}
+}
+
+func TestParserPool(t *testing.T) {
+	var pool tree_sitter_htmlmustache.ParserPool
+	defer pool.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			parser, err := pool.Get()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer pool.Put(parser)
+			tree := parser.Parse([]byte("{{> item}}"), nil)
+			defer tree.Close()
+			if kind := tree.RootNode().Child(0).Kind(); kind != "mustache_partial" {
+				t.Errorf("parsed a %s, want mustache_partial", kind)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,232 @@
+// Package querytest checks tree-sitter queries against assertion comments,
+// in the format tree-sitter test reads from test/highlight:
+//
+//	<p class="a">{{name}}</p>
+//	<!-- <- tag -->
+//	{{!  ^ attribute }}
+//	{{!         ^^^^ !string }}
+//
+// An assertion is a comment on a later line than the code it is about. A
+// caret (^) asserts on its own column, and several carets on as many
+// columns; an arrow (<-) asserts on the column the comment starts in. The
+// capture name may be negated with "!". Assertions refer to the nearest
+// line above them that is not itself an assertion and reaches their
+// column.
+//
+// A capture in a pattern that sets injection.language also satisfies the
+// capture name followed by the language, as in injection.content.javascript.
+package querytest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// Assertion is a capture expected, or with Negative not expected, at a
+// position of a test file.
+type Assertion struct {
+	// Row and Column are the zero-based position asserted on, with the
+	// column in bytes. Length is the number of columns from it that are
+	// asserted on, one per caret.
+	Row, Column, Length uint
+	Negative            bool
+	Capture             string
+}
+
+var captureRE = regexp.MustCompile(`[\w\-.]+`)
+
+// Assertions returns the assertions of the comments in the tree rooted at
+// root, whose source is src. Comments are the nodes whose kind contains
+// "comment"; the descendants of one with an assertion are not searched.
+func Assertions(root *tree_sitter.Node, src []byte) ([]Assertion, error) {
+	var found []Assertion
+	commentRows := map[uint]bool{}
+	walk.Visit(root, func(n *tree_sitter.Node) bool {
+		if strings.Contains(strings.ToLower(n.Kind()), "comment") && n.StartPosition().Row > 0 {
+			if a, ok := parseAssertion(n, src); ok {
+				found = append(found, a)
+				commentRows[n.StartPosition().Row] = true
+				return false
+			}
+		}
+		return true
+	})
+
+	lines := strings.Split(string(src), "\n")
+	for i := range found {
+		a := &found[i]
+		row := a.Row
+		for commentRows[a.Row] || uint(len(strings.TrimRight(lines[a.Row], "\r"))) <= a.Column {
+			if a.Row == 0 {
+				return nil, fmt.Errorf("querytest: the assertion on line %d refers to no line above it", row+1)
+			}
+			a.Row--
+		}
+	}
+	return found, nil
+}
+
+// parseAssertion returns the assertion in the comment n, if it has one.
+func parseAssertion(n *tree_sitter.Node, src []byte) (Assertion, bool) {
+	text := n.Utf8Text(src)
+	start := n.StartPosition()
+	a := Assertion{Row: start.Row, Column: start.Column, Length: 1}
+	end := -1
+	for i := 0; i < len(text); i++ {
+		if text[i] == '<' && i+1 < len(text) && text[i+1] == '-' {
+			end = i + 2
+			break
+		}
+		if text[i] == '^' {
+			a.Column += uint(i)
+			end = i + 1
+			for end < len(text) && text[end] == '^' {
+				a.Length++
+				end++
+			}
+			break
+		}
+	}
+	if end < 0 {
+		return a, false
+	}
+	rest := strings.TrimLeft(text[end:], " \t")
+	if strings.HasPrefix(rest, "!") {
+		a.Negative = true
+		rest = rest[1:]
+	}
+	capture := captureRE.FindString(rest)
+	if capture == "" {
+		return a, false
+	}
+	a.Capture = capture
+	return a, true
+}
+
+// Failure is an assertion the captures of a query do not satisfy.
+type Failure struct {
+	Assertion
+	// Actual are the captures at the first column that fails.
+	Actual []string
+}
+
+func (f Failure) Error() string {
+	want := f.Capture
+	if f.Negative {
+		want = "no " + want
+	}
+	return fmt.Sprintf("%d:%d: expected %s, got %v", f.Row+1, f.Column+1, want, f.Actual)
+}
+
+// Check parses src with language and returns the assertions in it that the
+// captures of queries do not satisfy.
+func Check(language *tree_sitter.Language, src []byte, queries ...*tree_sitter.Query) ([]Failure, error) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(language); err != nil {
+		return nil, err
+	}
+	tree := parser.Parse(src, nil)
+	if tree == nil {
+		return nil, errors.New("querytest: parse failed")
+	}
+	defer tree.Close()
+	root := tree.RootNode()
+
+	assertions, err := Assertions(root, src)
+	if err != nil {
+		return nil, err
+	}
+
+	type capture struct {
+		start, end tree_sitter.Point
+		names      []string
+	}
+	var captures []capture
+	for _, query := range queries {
+		names := query.CaptureNames()
+		cursor := tree_sitter.NewQueryCursor()
+		matches := cursor.Matches(query, root, src)
+		for match := matches.Next(); match != nil; match = matches.Next() {
+			language := ""
+			for _, property := range query.PropertySettings(match.PatternIndex) {
+				if property.Key == "injection.language" && property.Value != nil {
+					language = *property.Value
+				}
+			}
+			for _, c := range match.Captures {
+				name := names[c.Index]
+				found := capture{start: c.Node.StartPosition(), end: c.Node.EndPosition(), names: []string{name}}
+				if language != "" {
+					found.names = append(found.names, name+"."+language)
+				}
+				captures = append(captures, found)
+			}
+		}
+		cursor.Close()
+	}
+
+	var failures []Failure
+	for _, a := range assertions {
+		for column := a.Column; column < a.Column+a.Length; column++ {
+			at := tree_sitter.Point{Row: a.Row, Column: column}
+			var actual []string
+			matched := false
+			for _, c := range captures {
+				if !before(at, c.start) && before(at, c.end) {
+					actual = append(actual, c.names[0])
+					for _, name := range c.names {
+						matched = matched || name == a.Capture
+					}
+				}
+			}
+			if matched == a.Negative {
+				failures = append(failures, Failure{Assertion: a, Actual: actual})
+				break
+			}
+		}
+	}
+	return failures, nil
+}
+
+// before reports whether a comes before b.
+func before(a, b tree_sitter.Point) bool {
+	return a.Row < b.Row || a.Row == b.Row && a.Column < b.Column
+}
+
+// Run checks the files matching the glob pattern against queries, as
+// subtests of t named after the files.
+func Run(t *testing.T, language *tree_sitter.Language, pattern string, queries ...*tree_sitter.Query) {
+	t.Helper()
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatalf("no query tests match %s", pattern)
+	}
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			src, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			failures, err := Check(language, src, queries...)
+			if err != nil {
+				t.Fatalf("%s: %v", file, err)
+			}
+			for _, f := range failures {
+				t.Errorf("%s:%v", file, f)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package querytest_test
+
+import (
+	"reflect"
+	"testing"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/querytest"
+)
+
+const src = `<p class="x">{{name}}</p>
+<!-- <- tag -->
+<!--        ^^^^^^^ !tag -->
+
+  <!--         ^ @variable -->
+<!-- no assertion -->
+`
+
+func TestAssertions(t *testing.T) {
+	language := tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(language); err != nil {
+		t.Fatal(err)
+	}
+	tree := parser.Parse([]byte(src), nil)
+	defer tree.Close()
+
+	got, err := querytest.Assertions(tree.RootNode(), []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []querytest.Assertion{
+		{Row: 0, Column: 0, Length: 1, Capture: "tag"},
+		{Row: 0, Column: 12, Length: 7, Negative: true, Capture: "tag"},
+		{Row: 0, Column: 15, Length: 1, Capture: "variable"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Assertions() =\n%+v\nwant\n%+v", got, expected)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	language := tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())
+	query, qerr := tree_sitter.NewQuery(language, `
+		(html_start_tag) @tag
+		(mustache_identifier) @variable
+		((html_script_element (html_raw_text) @injection.content)
+		 (#set! injection.language "javascript"))`)
+	if qerr != nil {
+		t.Fatal(qerr)
+	}
+	defer query.Close()
+
+	failures, err := querytest.Check(language, []byte(src), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != 1 || failures[0].Column != 12 || !reflect.DeepEqual(failures[0].Actual, []string{"tag"}) {
+		t.Errorf("Check() = %+v", failures)
+	}
+	if msg := failures[0].Error(); msg != "1:13: expected no tag, got [tag]" {
+		t.Errorf("Error() = %q", msg)
+	}
+
+	script := []byte("<script>\nlet x;\n<!-- ^ injection.content.javascript -->\n</script>\n<p>some text</p>\n<!-- ^ injection.content -->")
+	failures, err = querytest.Check(language, script, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != 1 || failures[0].Row != 4 {
+		t.Errorf("Check() of injections = %+v", failures)
+	}
+
+	if _, err := querytest.Check(language, []byte("\n<!-- ^ tag -->"), query); err == nil {
+		t.Error("Check() accepted an assertion with no line above it")
+	}
+}
@@ -0,0 +1,115 @@
+package render
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// lookup resolves name against the context stack. The first key of a dotted
+// name is looked up from the innermost context outwards; the remaining keys
+// must each be found in the previous value.
+func lookup(stack []any, name string) any {
+	if name == "." {
+		return stack[len(stack)-1]
+	}
+	keys := strings.Split(name, ".")
+	for i := len(stack) - 1; i >= 0; i-- {
+		value, ok := field(stack[i], keys[0])
+		if !ok {
+			continue
+		}
+		for _, key := range keys[1:] {
+			if value, ok = field(value, key); !ok {
+				return nil
+			}
+		}
+		return value
+	}
+	return nil
+}
+
+// field returns the value of key in ctx: a map entry, an exported struct
+// field matched by name or json tag, or the result of a method without
+// arguments.
+func field(ctx any, key string) (any, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	v := reflect.ValueOf(ctx)
+	if method := v.MethodByName(key); method.IsValid() {
+		if method.Type().NumIn() == 0 && method.Type().NumOut() == 1 {
+			return method.Call(nil)[0].Interface(), true
+		}
+	}
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		entry := v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+		if !entry.IsValid() {
+			return nil, false
+		}
+		return entry.Interface(), true
+	case reflect.Struct:
+		typ := v.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if f.Name == key || tag == key {
+				return v.Field(i).Interface(), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// truthy reports whether a section over value renders. Nil, false and empty
+// lists are falsey; everything else, lambdas included, is truthy.
+func truthy(value any) bool {
+	if value == nil {
+		return false
+	}
+	if b, ok := value.(bool); ok {
+		return b
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		return v.Len() > 0
+	case reflect.Pointer, reflect.Map, reflect.Interface:
+		return !v.IsNil()
+	}
+	return true
+}
+
+// items returns the contexts a section over value renders with: the elements
+// of a list, or value itself.
+func items(value any) []any {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return []any{value}
+	}
+	list := make([]any, v.Len())
+	for i := range list {
+		list[i] = v.Index(i).Interface()
+	}
+	return list
+}
+
+func stringify(value any) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
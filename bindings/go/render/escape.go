@@ -0,0 +1,139 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/ast"
+)
+
+// Values that contextual escaping cannot make safe are replaced with these,
+// the markers html/template uses, so that the failure is easy to search for.
+const (
+	unsafeURL = "#ZgotmplZ"
+	unsafeCSS = "ZgotmplZ"
+)
+
+// WithContextualEscaping escapes {{name}} in attribute values for the
+// language the attribute's value is in, as classified by ast.AttributeSink,
+// before the HTML escaping:
+//
+//   - in URL attributes, characters that are not allowed in URLs are
+//     percent-encoded, and a value that starts the attribute with a scheme
+//     other than http, https or mailto, such as javascript:, is replaced;
+//   - in event handler attributes, the value is escaped for use inside a
+//     JavaScript string literal, which the template must quote;
+//   - in style attributes, a value with anything but letters, digits,
+//     spaces and #.,%+-_ is replaced.
+//
+// {{{name}}} is never escaped.
+func WithContextualEscaping() Option {
+	return func(o *options) { o.contextual = true }
+}
+
+// escapeForSink escapes text, which n renders to, for the attribute value it
+// is in, if any.
+func escapeForSink(n *tree_sitter.Node, src []byte, text string) string {
+	attribute, value := enclosingAttribute(n)
+	if attribute == nil {
+		return text
+	}
+	switch attribute.SinkKind(src) {
+	case ast.SinkURL:
+		// Only characters before n in the value's source are known, so a
+		// value starts the attribute if only blanks come before it.
+		from := value.StartByte()
+		if value.Kind() == "html_quoted_attribute_value" {
+			from++
+		}
+		start := from >= n.StartByte() || strings.TrimSpace(string(src[from:n.StartByte()])) == ""
+		return escapeURL(text, start)
+	case ast.SinkJS:
+		return escapeJSString(text)
+	case ast.SinkCSS:
+		return filterCSS(text)
+	}
+	return text
+}
+
+// enclosingAttribute returns the attribute whose value n is in, and that
+// value, or nil if n is not in an attribute value.
+func enclosingAttribute(n *tree_sitter.Node) (*ast.Attribute, *tree_sitter.Node) {
+	for p := n.Parent(); p != nil; p = p.Parent() {
+		switch p.Kind() {
+		case "html_attribute":
+			attribute, _ := ast.AsAttribute(p)
+			value, ok := attribute.Value()
+			if !ok || n.StartByte() < value.StartByte() || n.EndByte() > value.EndByte() {
+				return nil, nil
+			}
+			return &attribute, value
+		case "html_start_tag", "html_self_closing_tag", "html_element", "document":
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+// escapeURL percent-encodes the bytes of s that may not appear in a URL.
+// If start is set, s begins the URL, and is replaced if it has an unsafe
+// scheme.
+func escapeURL(s string, start bool) string {
+	if start {
+		if i := strings.IndexAny(s, ":/?#"); i >= 0 && s[i] == ':' {
+			switch strings.ToLower(strings.TrimSpace(s[:i])) {
+			case "http", "https", "mailto":
+			default:
+				return unsafeURL
+			}
+		}
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x80 && (c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+			strings.IndexByte("-._~:/?#[]@!$&'()*+,;=%", c) >= 0) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// escapeJSString escapes s for use inside a single- or double-quoted
+// JavaScript string. Characters that could end the string, the attribute or
+// the script are written as \u escapes.
+func escapeJSString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x2028 || r == 0x2029 || strings.ContainsRune("'\"`<>&=/", r):
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// filterCSS returns s if it is safe as a CSS value, and unsafeCSS if not.
+func filterCSS(s string) string {
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != ' ' && !strings.ContainsRune("#.,%+-_", r) {
+			return unsafeCSS
+		}
+	}
+	return s
+}
@@ -0,0 +1,120 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// blockHelper renders b for the Handlebars block helper name, called by the
+// tag that opens b, and reports whether it rendered. The built-in helpers
+// are supported:
+//
+//   - {{#if cond}} renders if cond is truthy, and {{#unless cond}} if not;
+//   - {{#with value}} renders with value pushed onto the context stack;
+//   - {{#each list}} renders once for each item of list.
+//
+// with and each bind block params, as in {{#each list as |item i|}}, to the
+// value, or to the item and its index. An inverted tag, {{^if cond}},
+// renders when the helper would not.
+func (r *renderer) blockHelper(out *bytes.Buffer, t *template, name string, b branch, inverted bool, stack []any) (bool, error) {
+	params := fieldChildren(b.tag, "param")
+	if len(params) != 1 || len(fieldChildren(b.tag, "hash")) > 0 {
+		return true, fmt.Errorf("render: line %d: {{%s}} takes one param", b.tag.StartPosition().Row+1, name)
+	}
+	value, err := r.argument(t, params[0], stack)
+	if err != nil {
+		return true, err
+	}
+	var holds bool
+	switch name {
+	case "if", "with", "each":
+		holds = truthy(value)
+	case "unless":
+		holds = !truthy(value)
+	default:
+		return true, fmt.Errorf("render: line %d: unknown helper %q", b.tag.StartPosition().Row+1, name)
+	}
+	if holds == inverted {
+		return false, nil
+	}
+	if inverted {
+		return true, r.span(out, t, b.nodes, b.from, b.to, stack)
+	}
+
+	names := blockParams(b.tag, t.src)
+	switch name {
+	case "with":
+		contexts := []any{value}
+		if len(names) > 0 {
+			contexts[0] = map[string]any{names[0]: value}
+		}
+		return true, r.each(out, t, b, contexts, stack)
+	case "each":
+		list := items(value)
+		if len(names) == 0 {
+			return true, r.each(out, t, b, list, stack)
+		}
+		// Block params name the item and its index instead of pushing the
+		// item itself.
+		contexts := make([]any, len(list))
+		for i, item := range list {
+			params := map[string]any{names[0]: item}
+			if len(names) > 1 {
+				params[names[1]] = i
+			}
+			contexts[i] = params
+		}
+		return true, r.each(out, t, b, contexts, stack)
+	}
+	return true, r.span(out, t, b.nodes, b.from, b.to, stack)
+}
+
+// argument returns the value of a helper param: a name looked up in stack,
+// or a string literal. Subexpressions call helpers, which are not
+// supported.
+func (r *renderer) argument(t *template, n *tree_sitter.Node, stack []any) (any, error) {
+	switch n.Kind() {
+	case "mustache_path_expression", "mustache_identifier", "mustache_implicit_iterator":
+		return lookup(stack, n.Utf8Text(t.src)), nil
+	case "mustache_string":
+		text := n.Utf8Text(t.src)
+		return text[1 : len(text)-1], nil
+	case "mustache_subexpression":
+		helper := n.ChildByFieldName("helper")
+		return nil, fmt.Errorf("render: line %d: unknown helper %q", n.StartPosition().Row+1, helper.Utf8Text(t.src))
+	}
+	return nil, fmt.Errorf("render: line %d: unsupported param %s", n.StartPosition().Row+1, n.Utf8Text(t.src))
+}
+
+// hasArguments reports whether the tag n has params or hash pairs, which
+// make it a helper call.
+func hasArguments(n *tree_sitter.Node) bool {
+	return len(fieldChildren(n, "param")) > 0 || len(fieldChildren(n, "hash")) > 0
+}
+
+// fieldChildren returns the children of n in field.
+func fieldChildren(n *tree_sitter.Node, field string) []*tree_sitter.Node {
+	var nodes []*tree_sitter.Node
+	for i := uint(0); i < n.ChildCount(); i++ {
+		if n.FieldNameForChild(uint32(i)) == field {
+			nodes = append(nodes, n.Child(i))
+		}
+	}
+	return nodes
+}
+
+// blockParams returns the names in the block params of the tag n, as in
+// {{#each list as |item i|}}.
+func blockParams(n *tree_sitter.Node, src []byte) []string {
+	params := n.ChildByFieldName("block_params")
+	if params == nil {
+		return nil
+	}
+	var names []string
+	for i := uint(0); i < params.NamedChildCount(); i++ {
+		names = append(names, params.NamedChild(i).Utf8Text(src))
+	}
+	return names
+}
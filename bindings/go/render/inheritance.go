@@ -0,0 +1,93 @@
+package render
+
+import (
+	"bytes"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// override is a block that a parent tag replaces: the content of a
+// {{$name}}...{{/name}} inside {{<parent}}...{{/parent}}, in the template
+// it was written in.
+type override struct {
+	t *template
+	b branch
+}
+
+// parent renders the parent tag n as the partial it names, with the blocks
+// inside n replacing the partial's blocks of the same name. A block that an
+// enclosing parent tag replaces stays replaced, so the template furthest
+// down the inheritance chain wins. Other content inside n is not rendered.
+func (r *renderer) parent(out *bytes.Buffer, t *template, n *tree_sitter.Node, stack []any) error {
+	nodes := walk.Children(n)
+	if len(nodes) < 2 {
+		return nil
+	}
+	name := nodes[0].ChildByFieldName("name")
+	if name == nil {
+		return nil
+	}
+	blocks := map[string]override{}
+	for _, child := range nodes[1 : len(nodes)-1] {
+		walk.Visit(child, func(block *tree_sitter.Node) bool {
+			switch block.Kind() {
+			case "mustache_block":
+				if b, ok := blockBranch(block, t.src); ok {
+					if _, seen := blocks[b.name]; !seen {
+						blocks[b.name] = override{t: t, b: b.branch}
+					}
+				}
+				return false
+			case "mustache_parent":
+				// A nested parent tag keeps its blocks to itself.
+				return false
+			}
+			return true
+		})
+	}
+	for name, o := range r.blocks {
+		blocks[name] = o
+	}
+	outer := r.blocks
+	r.blocks = blocks
+	defer func() { r.blocks = outer }()
+	return r.include(out, name.Utf8Text(t.src), t.indents[nodes[0].Id()], stack)
+}
+
+// block renders the block n: what a parent tag replaces it with, or its own
+// content by default. Either way it renders against the context stack at n.
+func (r *renderer) block(out *bytes.Buffer, t *template, n *tree_sitter.Node, stack []any) error {
+	b, ok := blockBranch(n, t.src)
+	if !ok {
+		return nil
+	}
+	if o, ok := r.blocks[b.name]; ok {
+		return r.span(out, o.t, o.b.nodes, o.b.from, o.b.to, stack)
+	}
+	return r.span(out, t, b.nodes, b.from, b.to, stack)
+}
+
+// namedBranch is the content of a block along with its name.
+type namedBranch struct {
+	name string
+	branch
+}
+
+// blockBranch returns the content of the block n, between its tags.
+func blockBranch(n *tree_sitter.Node, src []byte) (namedBranch, bool) {
+	nodes := walk.Children(n)
+	if len(nodes) < 2 {
+		return namedBranch{}, false
+	}
+	begin, end := nodes[0], nodes[len(nodes)-1]
+	name := begin.ChildByFieldName("name")
+	if name == nil {
+		return namedBranch{}, false
+	}
+	return namedBranch{
+		name:   name.Utf8Text(src),
+		branch: branch{tag: begin, nodes: nodes[1 : len(nodes)-1], from: begin.EndByte(), to: end.StartByte()},
+	}, true
+}
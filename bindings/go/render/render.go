@@ -0,0 +1,400 @@
+// Package render renders htmlmustache templates from their parse tree,
+// following the Mustache spec: interpolation with HTML escaping, sections,
+// inverted sections, partials, lambdas, standalone lines, the dynamic
+// partials of the optional dynamic names module, and the parent and block
+// tags of the optional inheritance module. Handlebars {{else}}
+// chains and the built-in block helpers if, unless, with and each are
+// supported; other helper calls are errors.
+package render
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/lint"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+// maxPartialDepth bounds partial nesting, so that a recursive partial whose
+// data never ends the recursion fails instead of overflowing the stack.
+const maxPartialDepth = 100
+
+// htmlEscaper escapes the characters the Mustache spec requires, spelling
+// quotes as &quot; as the spec's expected output does.
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&#39;")
+
+func escapeHTML(s string) string {
+	return htmlEscaper.Replace(s)
+}
+
+// Option configures Render.
+type Option func(*options)
+
+type options struct {
+	partials   analysis.Resolver
+	escape     func(string) string
+	contextual bool
+	sourceMap  *SourceMap
+}
+
+// WithPartials loads partials through resolver. Partials it reports as
+// missing, with an error wrapping fs.ErrNotExist, render as the empty
+// string, as the spec requires; any other error stops rendering. Without a
+// resolver every partial is empty.
+func WithPartials(resolver analysis.Resolver) Option {
+	return func(o *options) { o.partials = resolver }
+}
+
+// WithEscape replaces the HTML escaping applied to {{name}}.
+func WithEscape(escape func(string) string) Option {
+	return func(o *options) { o.escape = escape }
+}
+
+// Render renders src with data as the root context.
+//
+// Names are looked up in map keys, struct fields (by name or json tag) and
+// methods without arguments. Lambdas are func() string or func() any for
+// interpolations, and func(string) string for sections, which receive the
+// unrendered section text. A lambda's result is rendered as a template.
+func Render(src []byte, data any, opts ...Option) ([]byte, error) {
+	return RenderContext(context.Background(), src, data, opts...)
+}
+
+// RenderContext is like Render, but stops and returns ctx.Err() once ctx is
+// done. Rendering checks ctx as it parses each template and partial and
+// before each iteration of a section.
+func RenderContext(ctx context.Context, src []byte, data any, opts ...Option) ([]byte, error) {
+	o := options{escape: escapeHTML}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		return nil, err
+	}
+
+	if o.sourceMap != nil {
+		o.sourceMap.Mappings = nil
+	}
+	r := &renderer{ctx: ctx, parser: parser, opts: o}
+	var out bytes.Buffer
+	if err := r.template(&out, src, "", "", []any{data}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+type renderer struct {
+	ctx    context.Context
+	parser *tree_sitter.Parser
+	opts   options
+	depth  int
+	// lambdas counts the lambda results being rendered. Their output is
+	// mapped to the tag that called the lambda as a whole.
+	lambdas int
+	// blocks holds the blocks the parent tags being rendered replace, by
+	// name.
+	blocks map[string]override
+}
+
+// template parses and renders src against stack. name is the partial src
+// was loaded as, and indent the indentation to add to each of its lines.
+func (r *renderer) template(out *bytes.Buffer, src []byte, name, indent string, stack []any) error {
+	src = indentLines(src, indent)
+	tree, err := tree_sitter_htmlmustache.ParseContext(r.ctx, r.parser, src)
+	if err != nil {
+		return err
+	}
+	defer tree.Close()
+
+	root := tree.RootNode()
+	rules := []lint.Rule{lint.SyntaxErrors(), lint.MismatchedSections()}
+	if diagnostics := lint.LintTree(root, src, rules); len(diagnostics) > 0 {
+		d := diagnostics[0]
+		return fmt.Errorf("render: line %d: %s", d.StartPoint.Row+1, d.Message)
+	}
+	sourceMap := r.opts.sourceMap
+	if r.lambdas > 0 {
+		sourceMap = nil
+	}
+	t := newTemplate(root, src, name, uint(len(indent)), sourceMap)
+	return r.span(out, t, walk.Children(root), 0, uint(len(src)), stack)
+}
+
+// span renders nodes along with the source text between them, from the
+// offset from up to to.
+func (r *renderer) span(out *bytes.Buffer, t *template, nodes []*tree_sitter.Node, from, to uint, stack []any) error {
+	pos := from
+	for _, n := range nodes {
+		t.write(out, pos, n.StartByte())
+		if err := r.node(out, t, n, stack); err != nil {
+			return err
+		}
+		pos = n.EndByte()
+	}
+	t.write(out, pos, to)
+	return nil
+}
+
+func (r *renderer) node(out *bytes.Buffer, t *template, n *tree_sitter.Node, stack []any) error {
+	switch n.Kind() {
+	case "mustache_interpolation", "mustache_triple":
+		return r.interpolation(out, t, n, stack)
+	case "mustache_section", "mustache_inverted_section":
+		return r.section(out, t, n, stack)
+	case "mustache_partial":
+		return r.partial(out, t, n, stack)
+	case "mustache_dynamic_partial":
+		return r.dynamicPartial(out, t, n, stack)
+	case "mustache_parent":
+		return r.parent(out, t, n, stack)
+	case "mustache_block":
+		return r.block(out, t, n, stack)
+	case "mustache_comment", "mustache_set_delimiter":
+		return nil
+	}
+	if n.ChildCount() == 0 {
+		t.write(out, n.StartByte(), n.EndByte())
+		return nil
+	}
+	return r.span(out, t, walk.Children(n), n.StartByte(), n.EndByte(), stack)
+}
+
+func (r *renderer) interpolation(out *bytes.Buffer, t *template, n *tree_sitter.Node, stack []any) error {
+	if call := walk.ChildOfKind(n, "mustache_helper_call"); call != nil {
+		helper := call.ChildByFieldName("helper")
+		return fmt.Errorf("render: line %d: unknown helper %q", n.StartPosition().Row+1, helper.Utf8Text(t.src))
+	}
+	name := expressionName(n, t.src)
+	if name == "" {
+		return nil
+	}
+	value := lookup(stack, name)
+
+	var text string
+	switch fn := value.(type) {
+	case func() string:
+		rendered, err := r.lambdaResult(fn(), analysis.DefaultDelimiters, stack)
+		if err != nil {
+			return err
+		}
+		text = rendered
+	case func() any:
+		rendered, err := r.lambdaResult(stringify(fn()), analysis.DefaultDelimiters, stack)
+		if err != nil {
+			return err
+		}
+		text = rendered
+	default:
+		text = stringify(value)
+	}
+	if n.Kind() == "mustache_interpolation" {
+		if r.opts.contextual {
+			text = escapeForSink(n, t.src, text)
+		}
+		text = r.opts.escape(text)
+	}
+	r.writeValue(out, t, n, text)
+	return nil
+}
+
+// writeValue writes text as what n rendered to.
+func (r *renderer) writeValue(out *bytes.Buffer, t *template, n *tree_sitter.Node, text string) {
+	if t.sourceMap != nil {
+		start := uint(out.Len())
+		t.sourceMap.add(t, n.Kind(), start, start+uint(len(text)), n.StartByte(), n.EndByte())
+	}
+	out.WriteString(text)
+}
+
+func (r *renderer) section(out *bytes.Buffer, t *template, n *tree_sitter.Node, stack []any) error {
+	nodes := walk.Children(n)
+	if len(nodes) < 2 {
+		return nil
+	}
+	begin, end := nodes[0], nodes[len(nodes)-1]
+
+	// Handlebars {{else}} and {{else if cond}} tags split the content into
+	// branches, each opened by the tag that decides whether it renders. The
+	// first branch whose tag holds is rendered.
+	branches := []branch{{tag: begin, from: begin.EndByte()}}
+	for _, child := range nodes[1 : len(nodes)-1] {
+		last := &branches[len(branches)-1]
+		if child.Kind() == "mustache_else" {
+			last.to = child.StartByte()
+			branches = append(branches, branch{tag: child, from: child.EndByte()})
+			continue
+		}
+		last.nodes = append(last.nodes, child)
+	}
+	branches[len(branches)-1].to = end.StartByte()
+
+	inverted := n.Kind() == "mustache_inverted_section"
+	for i, b := range branches {
+		rendered, err := r.branch(out, t, n, b, inverted && i == 0, stack)
+		if rendered || err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// branch is the content of a section between two of its tags.
+type branch struct {
+	// tag is the section's begin tag or the {{else}} that opens the branch.
+	tag      *tree_sitter.Node
+	nodes    []*tree_sitter.Node
+	from, to uint
+}
+
+// branch renders b of section n if its tag holds, negated if inverted, and
+// reports whether it did. A plain {{else}} always holds; a tag with
+// arguments calls a block helper; any other tag is a Mustache section over
+// the value of its name.
+func (r *renderer) branch(out *bytes.Buffer, t *template, n *tree_sitter.Node, b branch, inverted bool, stack []any) (bool, error) {
+	tag := walk.ChildOfKind(b.tag, "mustache_tag_name")
+	if tag == nil {
+		return true, r.span(out, t, b.nodes, b.from, b.to, stack)
+	}
+	name := tag.Utf8Text(t.src)
+	if hasArguments(b.tag) {
+		return r.blockHelper(out, t, name, b, inverted, stack)
+	}
+	value := lookup(stack, name)
+	if inverted {
+		if truthy(value) {
+			return false, nil
+		}
+		return true, r.span(out, t, b.nodes, b.from, b.to, stack)
+	}
+
+	if fn, ok := value.(func(string) string); ok {
+		rendered, err := r.lambdaResult(fn(string(t.src[b.from:b.to])), analysis.DelimitersAt(t.delimiters, b.from), stack)
+		if err != nil {
+			return true, err
+		}
+		r.writeValue(out, t, n, rendered)
+		return true, nil
+	}
+	if !truthy(value) {
+		return false, nil
+	}
+	return true, r.each(out, t, b, items(value), stack)
+}
+
+// each renders b once for each of contexts, pushing it onto stack.
+func (r *renderer) each(out *bytes.Buffer, t *template, b branch, contexts []any, stack []any) error {
+	for _, item := range contexts {
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
+		if err := r.span(out, t, b.nodes, b.from, b.to, append(stack[:len(stack):len(stack)], item)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *renderer) partial(out *bytes.Buffer, t *template, n *tree_sitter.Node, stack []any) error {
+	content := walk.ChildOfKind(n, "mustache_partial_content")
+	if content == nil {
+		return nil
+	}
+	return r.include(out, strings.TrimSpace(content.Utf8Text(t.src)), t.indents[n.Id()], stack)
+}
+
+// dynamicPartial renders {{>*name}}: the partial named by the value of name,
+// which must be a string. Any other value renders as nothing.
+func (r *renderer) dynamicPartial(out *bytes.Buffer, t *template, n *tree_sitter.Node, stack []any) error {
+	name := n.ChildByFieldName("name")
+	if name == nil {
+		return nil
+	}
+	partial, ok := lookup(stack, name.Utf8Text(t.src)).(string)
+	if !ok {
+		return nil
+	}
+	return r.include(out, partial, t.indents[n.Id()], stack)
+}
+
+// include renders the partial called name, with indent added to each of its
+// lines. A partial the resolver does not have renders as nothing.
+func (r *renderer) include(out *bytes.Buffer, name, indent string, stack []any) error {
+	if r.opts.partials == nil {
+		return nil
+	}
+	src, err := r.opts.partials(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("render: partial %q: %w", name, err)
+	}
+	if r.depth >= maxPartialDepth {
+		return fmt.Errorf("render: partials nested more than %d deep", maxPartialDepth)
+	}
+	r.depth++
+	defer func() { r.depth-- }()
+	return r.template(out, src, name, indent, stack)
+}
+
+// lambdaResult renders the value returned by a lambda as a template written
+// with delimiters: the default ones for an interpolation, and those active
+// at the tag for a section, as the spec has it.
+func (r *renderer) lambdaResult(result string, delimiters analysis.Delimiters, stack []any) (string, error) {
+	if !strings.Contains(result, delimiters.Open) {
+		return result, nil
+	}
+	r.lambdas++
+	defer func() { r.lambdas-- }()
+	src := result
+	if delimiters != analysis.DefaultDelimiters {
+		// A set delimiter tag on a line of its own is standalone, so it
+		// takes its line with it and leaves the result as it was.
+		src = "{{=" + delimiters.Open + " " + delimiters.Close + "=}}\n" + result
+	}
+	var out bytes.Buffer
+	if err := r.template(&out, []byte(src), "", "", stack); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// indentLines prefixes every line of src with indent, as a standalone
+// partial is indented by the whitespace before it.
+func indentLines(src []byte, indent string) []byte {
+	if indent == "" || len(src) == 0 {
+		return src
+	}
+	var b bytes.Buffer
+	b.WriteString(indent)
+	for i, c := range src {
+		b.WriteByte(c)
+		if c == '\n' && i < len(src)-1 {
+			b.WriteString(indent)
+		}
+	}
+	return b.Bytes()
+}
+
+// expressionName returns the name of an interpolation, or "." for the
+// implicit iterator.
+func expressionName(n *tree_sitter.Node, src []byte) string {
+	for i := uint(0); i < n.ChildCount(); i++ {
+		child := n.Child(i)
+		switch child.Kind() {
+		case "mustache_path_expression", "mustache_identifier", "mustache_implicit_iterator", ".":
+			return child.Utf8Text(src)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,368 @@
+package render_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/render"
+)
+
+type person struct {
+	Name  string
+	Email string `json:"email"`
+}
+
+func (p person) Greeting() string { return "Hi " + p.Name }
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		data any
+		want string
+	}{
+		{
+			name: "escaped and unescaped interpolation",
+			src:  "<p>{{a}} {{{a}}}</p>",
+			data: map[string]any{"a": "<b>&\"</b>"},
+			want: "<p>&lt;b&gt;&amp;&quot;&lt;/b&gt; <b>&\"</b></p>",
+		},
+		{
+			name: "missing names render empty",
+			src:  "<p>[{{missing}}]</p>",
+			data: map[string]any{},
+			want: "<p>[]</p>",
+		},
+		{
+			name: "dotted names",
+			src:  "{{a.b.c}} {{#a}}{{b.c}}{{/a}} {{a.x.c}}",
+			data: map[string]any{"a": map[string]any{"b": map[string]any{"c": "deep"}}, "x": map[string]any{"c": "no"}},
+			want: "deep deep ",
+		},
+		{
+			name: "struct fields, json tags and methods",
+			src:  "{{Name}} ({{email}}) {{Greeting}}",
+			data: &person{Name: "Ada", Email: "ada@example.com"},
+			want: "Ada (ada@example.com) Hi Ada",
+		},
+		{
+			name: "list sections",
+			src:  "<ul>\n  {{#items}}\n  <li>{{.}}</li>\n  {{/items}}\n</ul>",
+			data: map[string]any{"items": []string{"a", "b"}},
+			want: "<ul>\n  <li>a</li>\n  <li>b</li>\n</ul>",
+		},
+		{
+			name: "Handlebars else if chains",
+			src:  "{{#if a}}A{{else if b}}B{{else}}C{{/if}} {{#if a}}A{{else if a}}B{{else}}C{{/if}} {{#unless a}}not a{{/unless}}",
+			data: map[string]any{"if": true, "a": false, "b": true},
+			want: "B C not a",
+		},
+		{
+			name: "Handlebars each and with with block params",
+			src:  "{{#each items as |item i|}}{{i}}={{item}} {{else}}none{{/each}}{{#with user as |u|}}{{u.name}}{{/with}}{{#each missing}}x{{else}}none{{/each}}",
+			data: map[string]any{"items": []string{"a", "b"}, "user": map[string]any{"name": "Ada"}},
+			want: "0=a 1=b Adanone",
+		},
+		{
+			name: "context sections and lookup up the stack",
+			src:  "{{#user}}{{name}} of {{site}}{{/user}}",
+			data: map[string]any{"site": "home", "user": map[string]any{"name": "Ada"}},
+			want: "Ada of home",
+		},
+		{
+			name: "falsey sections",
+			src:  "[{{#a}}x{{/a}}{{#b}}x{{/b}}{{#c}}x{{/c}}]",
+			data: map[string]any{"a": false, "b": []int{}, "c": nil},
+			want: "[]",
+		},
+		{
+			name: "inverted sections",
+			src:  "{{^a}}none{{/a}}{{^b}}hidden{{/b}}",
+			data: map[string]any{"a": []int{}, "b": true},
+			want: "none",
+		},
+		{
+			name: "standalone lines",
+			src:  "<div>\n  {{! comment }}\n  {{#a}}\n  yes\n  {{/a}}\n</div>\n",
+			data: map[string]any{"a": true},
+			want: "<div>\n  yes\n</div>\n",
+		},
+		{
+			name: "standalone lines with CRLF endings and a byte order mark",
+			src:  "\xef\xbb\xbf{{#a}}\r\n  yes\r\n{{/a}}\r\n",
+			data: map[string]any{"a": true},
+			want: "\xef\xbb\xbf  yes\r\n",
+		},
+		{
+			name: "inline tags keep their whitespace",
+			src:  " {{#a}} yes {{/a}} \n",
+			data: map[string]any{"a": true},
+			want: "  yes  \n",
+		},
+		{
+			name: "dynamic tag names",
+			src:  "<{{ tag }} id=\"x\"><h{{level}}>t</h{{level}}></{{tag}}>",
+			data: map[string]any{"tag": "section", "level": 2},
+			want: "<section id=\"x\"><h2>t</h2></section>",
+		},
+		{
+			name: "attribute sections",
+			src:  "<li {{#active}}class=\"on\"{{/active}}>a</li><li {{#b}}class=\"on\"{{/b}}>b</li>",
+			data: map[string]any{"active": true},
+			want: "<li class=\"on\">a</li><li >b</li>",
+		},
+		{
+			name: "interpolation lambdas",
+			src:  "{{lambda}}",
+			data: map[string]any{"x": "y", "lambda": func() string { return "{{x}} &" }},
+			want: "y &amp;",
+		},
+		{
+			name: "section lambdas",
+			src:  "{{#wrap}}{{x}}{{/wrap}}",
+			data: map[string]any{"x": "v", "wrap": func(text string) string { return "<b>" + text + "</b>" }},
+			want: "<b>v</b>",
+		},
+		{
+			name: "section lambdas under custom delimiters",
+			src:  "{{= | | =}}[|#lambda|-|/lambda|]",
+			data: map[string]any{"planet": "Earth", "lambda": func(text string) string { return text + "{{planet}} => |planet|" + text }},
+			want: "[-{{planet}} => Earth-]",
+		},
+		{
+			name: "interpolation lambdas under custom delimiters",
+			src:  "{{= | | =}}(|&lambda|)",
+			data: map[string]any{"planet": "world", "lambda": func() string { return "|planet| => {{planet}}" }},
+			want: "(|planet| => world)",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := render.Render([]byte(test.src), test.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("Render() =\n%q\nwant\n%q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRenderPartials(t *testing.T) {
+	partials := map[string]string{
+		"item":   "<li>{{.}}</li>\n",
+		"block":  "a\nb\n",
+		"self":   "{{#child}}({{>self}}){{/child}}",
+		"layout": "<title>{{$title}}Site{{/title}}</title>\n<main>{{$main}}{{/main}}</main>\n",
+		"page":   "{{<layout}}{{$title}}Page{{/title}}{{/layout}}",
+	}
+	resolver := func(name string) ([]byte, error) {
+		src, ok := partials[name]
+		if !ok {
+			return nil, fs.ErrNotExist
+		}
+		return []byte(src), nil
+	}
+	tests := []struct {
+		name string
+		src  string
+		data any
+		want string
+	}{
+		{
+			name: "partials use the current context",
+			src:  "<ul>{{#items}}{{> item}}{{/items}}</ul>",
+			data: map[string]any{"items": []string{"a"}},
+			want: "<ul><li>a</li>\n</ul>",
+		},
+		{
+			name: "standalone partials are indented",
+			src:  "<pre>\n  {{>block}}\n</pre>",
+			want: "<pre>\n  a\n  b\n</pre>",
+		},
+		{
+			name: "recursive partials",
+			src:  "{{>self}}",
+			data: map[string]any{"child": map[string]any{"child": map[string]any{"child": false}}},
+			want: "(())",
+		},
+		{
+			name: "parents replace their blocks",
+			src:  "{{<layout}}\n{{$main}}<p>{{body}}</p>{{/main}}\n{{/layout}}\n",
+			data: map[string]any{"body": "hi"},
+			want: "<title>Site</title>\n<main><p>hi</p></main>\n",
+		},
+		{
+			name: "the outermost block wins",
+			src:  "{{<page}}{{$title}}Post{{/title}}{{/page}}|{{<page}}{{/page}}|{{$title}}default{{/title}}",
+			want: "<title>Post</title>\n<main></main>\n|<title>Page</title>\n<main></main>\n|default",
+		},
+		{
+			name: "dynamic partials",
+			src:  "{{#items}}{{>*kind}}{{/items}}[{{>*missing}}]",
+			data: map[string]any{"kind": "item", "items": []string{"a", "b"}},
+			want: "<li>a</li>\n<li>b</li>\n[]",
+		},
+		{
+			name: "missing partials render empty",
+			src:  "[{{>missing}}]",
+			want: "[]",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := render.Render([]byte(test.src), test.data, render.WithPartials(resolver))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("Render() =\n%q\nwant\n%q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRenderPartialErrors(t *testing.T) {
+	errDenied := errors.New("permission denied")
+	resolver := func(string) ([]byte, error) { return nil, errDenied }
+	_, err := render.Render([]byte("[{{>secret}}]"), nil, render.WithPartials(resolver))
+	if !errors.Is(err, errDenied) {
+		t.Errorf("Render() error = %v, want %v", err, errDenied)
+	}
+}
+
+func TestRenderWithEscape(t *testing.T) {
+	got, err := render.Render([]byte("{{a}}"), map[string]any{"a": "x"}, render.WithEscape(strings.ToUpper))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "X" {
+		t.Errorf("Render() = %q, want %q", got, "X")
+	}
+}
+
+func TestRenderWithContextualEscaping(t *testing.T) {
+	data := map[string]any{
+		"url": "javascript:alert(1)", "path": "a b/ü?q=1", "site": "https://example.com/x y",
+		"js": `'); alert("x`, "color": "#fff", "rule": "red; background: url(x)", "text": "<b>",
+	}
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`<a href="{{url}}">x</a>`, `<a href="#ZgotmplZ">x</a>`},
+		{`<a href={{site}}>x</a>`, `<a href=https://example.com/x%20y>x</a>`},
+		{`<img src={{url}}>`, `<img src=#ZgotmplZ>`},
+		{`<a href="/u/{{path}}">x</a>`, `<a href="/u/a%20b/%C3%BC?q=1">x</a>`},
+		{`<a href="/?next={{url}}">x</a>`, `<a href="/?next=javascript:alert(1)">x</a>`},
+		{`<button onclick="go('{{js}}')">x</button>`, `<button onclick="go('\u0027); alert(\u0022x')">x</button>`},
+		{`<p style="color: {{color}}">x</p><p style="color: {{rule}}">y</p>`, `<p style="color: #fff">x</p><p style="color: ZgotmplZ">y</p>`},
+		{`<p title="{{text}}">{{text}}</p>`, `<p title="&lt;b&gt;">&lt;b&gt;</p>`},
+		{`<a href="{{{url}}}">x</a>`, `<a href="javascript:alert(1)">x</a>`},
+	}
+	for _, test := range tests {
+		got, err := render.Render([]byte(test.src), data, render.WithContextualEscaping())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != test.want {
+			t.Errorf("Render(%q) =\n%s\nwant\n%s", test.src, got, test.want)
+		}
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	for _, src := range []string{"{{#a}}x{{/b}}", "{{#a}}x", "{{format date}}", "{{#if (eq a b)}}x{{/if}}", "{{#repeat a}}x{{/repeat}}"} {
+		if _, err := render.Render([]byte(src), nil); err == nil {
+			t.Errorf("Render(%q) succeeded, want an error", src)
+		}
+	}
+}
+
+func TestRenderContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rendered := 0
+	data := map[string]any{
+		"items": make([]int, 1000),
+		"item": func() string {
+			if rendered++; rendered == 3 {
+				cancel()
+			}
+			return "x"
+		},
+	}
+	_, err := render.RenderContext(ctx, []byte("{{#items}}{{item}}{{/items}}"), data)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("RenderContext() error = %v, want context.Canceled", err)
+	}
+	if rendered != 3 {
+		t.Errorf("rendered %d items after cancelling at 3", rendered)
+	}
+}
+
+func TestRenderWithSourceMap(t *testing.T) {
+	partial := "<li>\n{{name}}\n</li>\n"
+	resolver := func(string) ([]byte, error) { return []byte(partial), nil }
+	src := "<ul>\n  {{> item}}\n</ul>\n{{#xs}}<b>{{.}}</b>{{/xs}}{{#wrap}}x{{/wrap}}"
+	wrap := func(text string) string { return "[" + text + "]" }
+	data := map[string]any{"name": "A&B", "xs": []int{1, 2}, "wrap": wrap}
+
+	var m render.SourceMap
+	got, err := render.Render([]byte(src), data, render.WithPartials(resolver), render.WithSourceMap(&m))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "<ul>\n  <li>\n  A&amp;B\n  </li>\n</ul>\n<b>1</b><b>2</b>[x]"; string(got) != want {
+		t.Fatalf("Render() = %q, want %q", got, want)
+	}
+
+	type mapping struct {
+		output, partial, source string
+		line, column            uint
+		kind                    string
+	}
+	var mappings []mapping
+	for _, mp := range m.Mappings {
+		from := src
+		if mp.Partial != "" {
+			from = partial
+		}
+		mappings = append(mappings, mapping{
+			string(got[mp.OutputStart:mp.OutputEnd]), mp.Partial, from[mp.SourceStart:mp.SourceEnd],
+			mp.SourcePoint.Row, mp.SourcePoint.Column, mp.Kind,
+		})
+	}
+	expected := []mapping{
+		{"<ul>\n", "", "<ul>\n", 0, 0, "text"},
+		// The indentation of the standalone partial is not mapped.
+		{"<li>\n", "item", "<li>\n", 0, 0, "text"},
+		{"A&amp;B", "item", "{{name}}", 1, 0, "mustache_interpolation"},
+		{"\n", "item", "\n", 1, 8, "text"},
+		{"</li>\n", "item", "</li>\n", 2, 0, "text"},
+		{"</ul>\n", "", "</ul>\n", 2, 0, "text"},
+		{"<b>", "", "<b>", 3, 7, "text"},
+		{"1", "", "{{.}}", 3, 10, "mustache_interpolation"},
+		{"</b>", "", "</b>", 3, 15, "text"},
+		{"<b>", "", "<b>", 3, 7, "text"},
+		{"2", "", "{{.}}", 3, 10, "mustache_interpolation"},
+		{"</b>", "", "</b>", 3, 15, "text"},
+		// Lambda output maps to the section as a whole.
+		{"[x]", "", "{{#wrap}}x{{/wrap}}", 3, 26, "mustache_section"},
+	}
+	if !reflect.DeepEqual(mappings, expected) {
+		t.Errorf("mappings =\n%+v\nwant\n%+v", mappings, expected)
+	}
+
+	if mp, ok := m.At(15); !ok || mp.Kind != "mustache_interpolation" || mp.Partial != "item" {
+		t.Errorf("At(15) = %+v, %v", mp, ok)
+	}
+	if _, ok := m.At(12); ok {
+		t.Error("At(12) found a mapping for partial indentation")
+	}
+}
@@ -0,0 +1,73 @@
+package render
+
+import (
+	"sort"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// SourceMap relates the output of Render to the templates it came from.
+type SourceMap struct {
+	// Mappings cover the non-empty ranges of the output in order. Output
+	// bytes with no template source, such as the indentation added to a
+	// standalone partial, are not covered.
+	Mappings []Mapping
+}
+
+// Mapping is a range of the output and the source it was rendered from.
+type Mapping struct {
+	// OutputStart and OutputEnd delimit the output range.
+	OutputStart, OutputEnd uint
+	// Partial is the name of the partial the range came from, or "" for the
+	// rendered template.
+	Partial string
+	// SourceStart and SourceEnd delimit the source the range came from, and
+	// SourcePoint is where it starts.
+	SourceStart, SourceEnd uint
+	SourcePoint            tree_sitter.Point
+	// Kind is "text" for source copied to the output, in which case each
+	// output byte is the source byte at the same offset into the mapping.
+	// Otherwise it is the kind of the node the range is the value of: an
+	// interpolation, or a section whose lambda rendered it.
+	Kind string
+}
+
+// WithSourceMap records in m where each range of the output came from.
+func WithSourceMap(m *SourceMap) Option {
+	return func(o *options) { o.sourceMap = m }
+}
+
+// At returns the mapping covering the output byte at offset.
+func (m *SourceMap) At(offset uint) (Mapping, bool) {
+	i := sort.Search(len(m.Mappings), func(i int) bool { return m.Mappings[i].OutputEnd > offset })
+	if i < len(m.Mappings) && m.Mappings[i].OutputStart <= offset {
+		return m.Mappings[i], true
+	}
+	return Mapping{}, false
+}
+
+// add records that output[outStart:outEnd] came from src[srcStart:srcEnd]
+// of t. Text continuing the previous mapping extends it.
+func (m *SourceMap) add(t *template, kind string, outStart, outEnd, srcStart, srcEnd uint) {
+	if outStart == outEnd {
+		return
+	}
+	point := t.point(srcStart)
+	srcStart, srcEnd = t.origin(srcStart), t.origin(srcEnd)
+	if n := len(m.Mappings); n > 0 && kind == "text" {
+		last := &m.Mappings[n-1]
+		if last.Kind == "text" && last.Partial == t.name && last.OutputEnd == outStart && last.SourceEnd == srcStart {
+			last.OutputEnd, last.SourceEnd = outEnd, srcEnd
+			return
+		}
+	}
+	m.Mappings = append(m.Mappings, Mapping{
+		OutputStart: outStart,
+		OutputEnd:   outEnd,
+		Partial:     t.name,
+		SourceStart: srcStart,
+		SourceEnd:   srcEnd,
+		SourcePoint: point,
+		Kind:        kind,
+	})
+}
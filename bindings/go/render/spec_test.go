@@ -0,0 +1,261 @@
+package render_test
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/render"
+)
+
+// specDir points at the suites to run. testdata/spec holds the official
+// mustache/spec suites in their JSON form, with the optional ~dynamic-names,
+// ~inheritance and ~lambdas ones; pass the specs directory of a
+// mustache/spec checkout to run another version of them:
+//
+//	go test ./bindings/go/render -run TestSpec -v -spec=/path/to/spec/specs
+var specDir = flag.String("spec", "testdata/spec", "directory of mustache/spec JSON suites")
+
+// compliancePath holds the compliance matrix of the suites in testdata/spec,
+// which TestSpec checks so that any change to it shows up in review. Run
+//
+//	go test ./bindings/go/render -run TestSpec -update
+//
+// to rewrite it.
+const compliancePath = "testdata/compliance.txt"
+
+var update = flag.Bool("update", false, "rewrite "+compliancePath)
+
+type specSuite struct {
+	Overview string     `json:"overview"`
+	Tests    []specCase `json:"tests"`
+}
+
+type specCase struct {
+	Name     string            `json:"name"`
+	Desc     string            `json:"desc"`
+	Data     any               `json:"data"`
+	Template string            `json:"template"`
+	Partials map[string]string `json:"partials"`
+	Expected string            `json:"expected"`
+}
+
+// knownFailures lists the spec cases that fail, by "suite/name", with the
+// reason. The harness fails if one of them starts passing, so the list stays
+// accurate as the grammar and renderer improve.
+var knownFailures = map[string]string{
+	"partials/Recursion":                                    "a mustache tag right after < is an HTML syntax error",
+	"~dynamic-names/Recursion":                              "a mustache tag right after < is an HTML syntax error",
+	"~dynamic-names/Dynamic Names - Double Dereferencing":   "a second * is a syntax error, where the spec renders nothing",
+	"~dynamic-names/Dynamic Names - Composed Dereferencing": "a second * is a syntax error, where the spec renders nothing",
+	"~inheritance/Standalone block":                         "blocks are not reindented",
+	"~inheritance/Block reindentation":                      "blocks are not reindented",
+	"~inheritance/Intrinsic indentation":                    "blocks are not reindented",
+	"~inheritance/Nested block reindentation":               "blocks are not reindented",
+	"comments/Variable Name Collision":                      "a < that opens no tag is an HTML syntax error",
+	"~lambdas/Escaping":                                     "a mustache tag right after < is an HTML syntax error",
+	"~lambdas/Section":                                      "a mustache tag right after < is an HTML syntax error",
+	"~lambdas/Section - Expansion":                          "a mustache tag right after < is an HTML syntax error",
+	"~lambdas/Section - Alternate Delimiters":               "a mustache tag right after < is an HTML syntax error",
+	"~lambdas/Inverted Section":                             "a mustache tag right after < is an HTML syntax error",
+}
+
+// specLambdas are the Go versions of the lambdas in the ~lambdas suite,
+// which the spec gives as source code in several languages. Each call
+// returns fresh lambdas so that counters start over.
+func specLambdas(name string) any {
+	switch name {
+	case "Interpolation":
+		return func() string { return "world" }
+	case "Interpolation - Expansion":
+		return func() string { return "{{planet}}" }
+	case "Interpolation - Alternate Delimiters":
+		return func() string { return "|planet| => {{planet}}" }
+	case "Interpolation - Multiple Calls":
+		calls := 0
+		return func() any { calls++; return calls }
+	case "Escaping":
+		return func() string { return ">" }
+	case "Section":
+		return func(text string) string {
+			if text == "{{x}}" {
+				return "yes"
+			}
+			return "no"
+		}
+	case "Section - Expansion":
+		return func(text string) string { return text + "{{planet}}" + text }
+	case "Section - Alternate Delimiters":
+		return func(text string) string { return text + "{{planet}} => |planet|" + text }
+	case "Section - Multiple Calls":
+		return func(text string) string { return "__" + text + "__" }
+	case "Inverted Section":
+		return func(string) string { return "" }
+	}
+	return nil
+}
+
+func TestSpec(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join(*specDir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("no spec suites in %s", *specDir)
+	}
+
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct{ passed, known, total int }
+	matrix := map[string]*result{}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var suite specSuite
+		if err := json.Unmarshal(content, &suite); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		suiteName := strings.TrimSuffix(filepath.Base(path), ".json")
+		res := &result{}
+		matrix[suiteName] = res
+
+		for _, test := range suite.Tests {
+			key := suiteName + "/" + test.Name
+			res.total++
+			failure := runSpecCase(parser, suiteName, test)
+			reason, known := knownFailures[key]
+			switch {
+			case failure == "":
+				res.passed++
+				if known {
+					t.Errorf("%s: passes now; remove it from knownFailures", key)
+				}
+			case known:
+				res.known++
+				t.Logf("%s: known failure (%s): %s", key, reason, failure)
+			default:
+				t.Errorf("%s: %s\n%s", key, test.Desc, failure)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(matrix))
+	for name := range matrix {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var report strings.Builder
+	fmt.Fprintf(&report, "%-16s %6s %6s %6s\n", "suite", "pass", "known", "total")
+	for _, name := range names {
+		res := matrix[name]
+		fmt.Fprintf(&report, "%-16s %6d %6d %6d\n", name, res.passed, res.known, res.total)
+	}
+	t.Logf("mustache spec compliance:\n%s", report.String())
+
+	if *specDir != "testdata/spec" {
+		return
+	}
+	if *update {
+		if err := os.WriteFile(compliancePath, []byte(report.String()), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(compliancePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.String() != string(want) {
+		t.Errorf("compliance matrix changed; run with -update to rewrite %s:\n%s", compliancePath, report.String())
+	}
+}
+
+// runSpecCase checks that test's template parses into the expected mustache
+// tags and renders to the expected output. It returns a description of the
+// first problem, or "" if there is none.
+func runSpecCase(parser *tree_sitter.Parser, suite string, test specCase) string {
+	tree := parser.Parse([]byte(test.Template), nil)
+	defer tree.Close()
+	root := tree.RootNode()
+	if root.HasError() {
+		return fmt.Sprintf("parse error: %s", root.ToSexp())
+	}
+	if got, want := countTags(root), countOpenDelimiters(root, []byte(test.Template)); got != want {
+		return fmt.Sprintf("parsed %d mustache tags, want %d: %s", got, want, root.ToSexp())
+	}
+
+	data := test.Data
+	if suite == "~lambdas" {
+		if m, ok := data.(map[string]any); ok {
+			m["lambda"] = specLambdas(test.Name)
+		}
+	}
+	resolver := func(name string) ([]byte, error) {
+		src, ok := test.Partials[name]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return []byte(src), nil
+	}
+	got, err := render.Render([]byte(test.Template), data, render.WithPartials(resolver))
+	if err != nil {
+		return fmt.Sprintf("render error: %v", err)
+	}
+	if string(got) != test.Expected {
+		return fmt.Sprintf("rendered %q, want %q", got, test.Expected)
+	}
+	return ""
+}
+
+// countOpenDelimiters counts the tags written in src by their delimiters,
+// following its set delimiter tags. Each tag runs from an opening delimiter
+// to the next closing one, which may be the same string.
+func countOpenDelimiters(root *tree_sitter.Node, src []byte) int {
+	count := 0
+	for _, region := range analysis.DelimiterRegions(root, src) {
+		text := string(src[region.StartByte:region.EndByte])
+		for {
+			i := strings.Index(text, region.Open)
+			if i < 0 {
+				break
+			}
+			text = text[i+len(region.Open):]
+			count++
+			if j := strings.Index(text, region.Close); j >= 0 {
+				text = text[j+len(region.Close):]
+			}
+		}
+	}
+	return count
+}
+
+// countTags counts the mustache tag openers in the tree, such as "{{",
+// "{{#" and "{{{".
+func countTags(n *tree_sitter.Node) int {
+	if n.ChildCount() == 0 {
+		if strings.HasPrefix(n.Kind(), "{{") {
+			return 1
+		}
+		return 0
+	}
+	count := 0
+	for i := uint(0); i < n.ChildCount(); i++ {
+		count += countTags(n.Child(i))
+	}
+	return count
+}
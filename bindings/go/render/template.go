@@ -0,0 +1,126 @@
+package render
+
+import (
+	"bytes"
+	"sort"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/analysis"
+)
+
+// template is a parsed source along with the whitespace that standalone
+// tags remove from it.
+type template struct {
+	src []byte
+	// skip marks the bytes of src that are not rendered: the indentation and
+	// line ending around standalone tags.
+	skip []bool
+	// indents holds the indentation of standalone partials and parent tags,
+	// by node id.
+	indents map[uintptr]string
+	// delimiters are the regions of src set delimiter tags split it into.
+	delimiters []analysis.DelimiterRegion
+
+	// sourceMap, if not nil, records what write copies. name is the partial
+	// the template was loaded as, and indent the length of the indentation
+	// added to each of its lines, which is not in the partial's source.
+	sourceMap  *SourceMap
+	name       string
+	indent     uint
+	lineStarts []uint
+}
+
+func newTemplate(root *tree_sitter.Node, src []byte, name string, indent uint, sourceMap *SourceMap) *template {
+	t := &template{src: src, skip: make([]bool, len(src)), indents: map[uintptr]string{}, delimiters: analysis.DelimiterRegions(root, src)}
+	if sourceMap != nil {
+		t.sourceMap, t.name, t.indent = sourceMap, name, indent
+		t.lineStarts = []uint{0}
+		for i, c := range src {
+			if c == '\n' {
+				t.lineStarts = append(t.lineStarts, uint(i+1))
+			}
+		}
+	}
+	for _, tag := range analysis.StandaloneTags(root, src) {
+		for i := tag.LineStart; i < tag.Node.StartByte(); i++ {
+			t.skip[i] = true
+		}
+		for i := tag.Node.EndByte(); i < tag.LineEnd; i++ {
+			t.skip[i] = true
+		}
+		switch tag.Node.Kind() {
+		case "mustache_partial", "mustache_dynamic_partial", "mustache_parent_begin":
+			t.indents[tag.Node.Id()] = tag.Indent
+		}
+	}
+	return t
+}
+
+// write writes src[from:to] to out, leaving out skipped bytes.
+func (t *template) write(out *bytes.Buffer, from, to uint) {
+	for i := from; i < to; {
+		if t.skip[i] {
+			i++
+			continue
+		}
+		j := i
+		for j < to && !t.skip[j] {
+			j++
+		}
+		if t.sourceMap != nil {
+			t.recordText(uint(out.Len()), i, j)
+		}
+		out.Write(t.src[i:j])
+		i = j
+	}
+}
+
+// recordText maps the output from offset outStart to the copy of
+// src[from:to], leaving out the indentation added to lines.
+func (t *template) recordText(outStart, from, to uint) {
+	for i := from; i < to; {
+		line := t.line(i)
+		lineEnd := to
+		if line+1 < len(t.lineStarts) && t.lineStarts[line+1] < to {
+			lineEnd = t.lineStarts[line+1]
+		}
+		start := i
+		if indentEnd := t.lineStarts[line] + t.indent; start < indentEnd {
+			start = indentEnd
+		}
+		if start < lineEnd {
+			t.sourceMap.add(t, "text", outStart+start-from, outStart+lineEnd-from, start, lineEnd)
+		}
+		i = lineEnd
+	}
+}
+
+// line returns the zero-based line of src holding offset.
+func (t *template) line(offset uint) int {
+	return sort.Search(len(t.lineStarts), func(i int) bool { return t.lineStarts[i] > offset }) - 1
+}
+
+// origin returns the offset in the template as written of offset in src,
+// before the partial indentation was added.
+func (t *template) origin(offset uint) uint {
+	if t.indent == 0 {
+		return offset
+	}
+	line := t.line(offset)
+	own := offset - t.lineStarts[line]
+	if own > t.indent {
+		own = t.indent
+	}
+	if t.lineStarts[line] == uint(len(t.src)) {
+		// indentLines does not indent the empty line after a final newline.
+		own = 0
+	}
+	return offset - uint(line)*t.indent - own
+}
+
+// point returns the position in the template as written of offset in src.
+func (t *template) point(offset uint) tree_sitter.Point {
+	line := t.line(offset)
+	return tree_sitter.Point{Row: uint(line), Column: t.origin(offset) - t.origin(t.lineStarts[line])}
+}
@@ -0,0 +1,342 @@
+// Package rewrite makes codemod-style changes to htmlmustache templates:
+// renaming variables, tags and attributes, adding attributes and wrapping
+// elements. Changes are computed from the parse tree and returned as the
+// few byte-range edits that make them, so the rest of the template,
+// including its formatting, is left exactly as it was.
+//
+// Elements are chosen with the selectors of package selector, such as
+// "nav a" or "{{#admin}} button"; operations on elements skip the Mustache
+// constructs a selector matches.
+//
+//	edits, err := rewrite.Rewrite(src,
+//		rewrite.RenameVariable("user", "account"),
+//		rewrite.AddAttribute("main img", "loading", "lazy"),
+//	)
+//
+// A template must parse without errors, and so must the result of
+// rewriting it; a rewrite that would break the template is an error rather
+// than a silently broken file.
+package rewrite
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/selector"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+var (
+	// ErrSyntax is returned for templates whose parse tree contains errors.
+	ErrSyntax = errors.New("rewrite: template has syntax errors")
+	// ErrInvalid is returned when the rewritten template would not parse
+	// without errors.
+	ErrInvalid = errors.New("rewrite: result has syntax errors")
+	// ErrConflict is returned when two operations edit overlapping ranges.
+	ErrConflict = errors.New("rewrite: conflicting edits")
+)
+
+// Edit replaces the bytes from StartByte to EndByte of a template with
+// Text. An insertion has equal StartByte and EndByte.
+type Edit struct {
+	StartByte uint   `json:"startByte"`
+	EndByte   uint   `json:"endByte"`
+	Text      string `json:"text"`
+}
+
+// Op is a rewrite operation, made by RenameVariable and the other
+// functions of this package.
+type Op struct {
+	edits func(t *template) ([]Edit, error)
+}
+
+// template is the parsed template operations compute their edits from.
+type template struct {
+	root *tree_sitter.Node
+	src  []byte
+}
+
+// Rewrite returns the edits that apply ops to src, sorted by position and
+// without overlaps. The ops all see the original template; edits they make
+// at the same position are applied in the order of ops. Edits that would
+// not change anything are left out.
+func Rewrite(src []byte, ops ...Op) ([]Edit, error) {
+	tree, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+	if tree.RootNode().HasError() {
+		return nil, ErrSyntax
+	}
+
+	t := &template{root: tree.RootNode(), src: src}
+	var edits []Edit
+	for _, op := range ops {
+		opEdits, err := op.edits(t)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range opEdits {
+			if string(src[e.StartByte:e.EndByte]) != e.Text {
+				edits = append(edits, e)
+			}
+		}
+	}
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].StartByte < edits[j].StartByte })
+
+	out, err := Apply(src, edits)
+	if err != nil {
+		return nil, err
+	}
+	result, err := parse(out)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+	if result.RootNode().HasError() {
+		return nil, fmt.Errorf("%w:\n%s", ErrInvalid, out)
+	}
+	return edits, nil
+}
+
+// Apply returns src with edits, which must be sorted by position and not
+// overlap, applied.
+func Apply(src []byte, edits []Edit) ([]byte, error) {
+	var b bytes.Buffer
+	last := uint(0)
+	for _, e := range edits {
+		if e.StartByte < last || e.EndByte < e.StartByte || e.EndByte > uint(len(src)) {
+			return nil, fmt.Errorf("%w: %d-%d", ErrConflict, e.StartByte, e.EndByte)
+		}
+		b.Write(src[last:e.StartByte])
+		b.WriteString(e.Text)
+		last = e.EndByte
+	}
+	b.Write(src[last:])
+	return b.Bytes(), nil
+}
+
+// RenameVariable renames the variable old to new in interpolations and
+// section tags, along with the paths below it: renaming user to account
+// changes {{user.name}} to {{account.name}}. Names are matched as written,
+// without resolving them against enclosing sections.
+func RenameVariable(old, new string) Op {
+	return Op{func(t *template) ([]Edit, error) {
+		if old == "" || old == "." {
+			return nil, fmt.Errorf("rewrite: cannot rename %q", old)
+		}
+		var edits []Edit
+		walk.Visit(t.root, func(n *tree_sitter.Node) bool {
+			var name *tree_sitter.Node
+			switch n.Kind() {
+			case "mustache_tag_name":
+				name = n
+			case "mustache_path_expression", "mustache_identifier":
+				if parent := n.Parent(); parent != nil && (parent.Kind() == "mustache_interpolation" || parent.Kind() == "mustache_triple") {
+					name = n
+				}
+			}
+			if name == nil {
+				return true
+			}
+			if text := name.Utf8Text(t.src); text == old || strings.HasPrefix(text, old+".") {
+				edits = append(edits, Edit{name.StartByte(), name.StartByte() + uint(len(old)), new})
+			}
+			return false
+		})
+		return edits, nil
+	}}
+}
+
+// RenameTag renames the elements matching selector, in their start and end
+// tags.
+func RenameTag(selector, name string) Op {
+	return Op{func(t *template) ([]Edit, error) {
+		elements, err := t.elements(selector)
+		if err != nil {
+			return nil, err
+		}
+		var edits []Edit
+		for _, e := range elements {
+			for _, tag := range []*tree_sitter.Node{startTag(e), walk.ChildOfKind(e, "html_end_tag")} {
+				if tag == nil {
+					continue
+				}
+				if tagName := walk.ChildOfKind(tag, "html_tag_name"); tagName != nil {
+					edits = append(edits, Edit{tagName.StartByte(), tagName.EndByte(), name})
+				}
+			}
+		}
+		return edits, nil
+	}}
+}
+
+// RenameAttribute renames the attribute old of the elements matching
+// selector to new. Attribute names are matched case-insensitively.
+func RenameAttribute(selector, old, new string) Op {
+	return Op{func(t *template) ([]Edit, error) {
+		elements, err := t.elements(selector)
+		if err != nil {
+			return nil, err
+		}
+		var edits []Edit
+		for _, e := range elements {
+			if attribute := findAttribute(startTag(e), old, t.src); attribute != nil {
+				name := walk.ChildOfKind(attribute, "html_attribute_name")
+				edits = append(edits, Edit{name.StartByte(), name.EndByte(), new})
+			}
+		}
+		return edits, nil
+	}}
+}
+
+// AddAttribute sets the attribute name of the elements matching selector to
+// value, adding it after their last attribute or replacing the value they
+// have. An empty value adds the attribute without one, as in disabled.
+// Double quotes in value are written as &quot;.
+func AddAttribute(selector, name, value string) Op {
+	return Op{func(t *template) ([]Edit, error) {
+		elements, err := t.elements(selector)
+		if err != nil {
+			return nil, err
+		}
+		quoted := ""
+		if value != "" {
+			quoted = `="` + strings.ReplaceAll(value, `"`, "&quot;") + `"`
+		}
+		var edits []Edit
+		for _, e := range elements {
+			tag := startTag(e)
+			if attribute := findAttribute(tag, name, t.src); attribute != nil {
+				// Replace everything after the name, which is also where a
+				// value goes if the attribute has none.
+				start := walk.ChildOfKind(attribute, "html_attribute_name").EndByte()
+				edits = append(edits, Edit{start, attribute.EndByte(), quoted})
+				continue
+			}
+			end := tag.Child(tag.ChildCount() - 1)
+			if end.Kind() != ">" && end.Kind() != "/>" {
+				continue
+			}
+			at := end.PrevSibling().EndByte()
+			edits = append(edits, Edit{at, at, " " + name + quoted})
+		}
+		return edits, nil
+	}}
+}
+
+// WrapWith wraps the elements matching selector in a new element. element
+// is its start tag, as in <div class="card">, or just its tag name.
+func WrapWith(selector, element string) Op {
+	return Op{func(t *template) ([]Edit, error) {
+		start := strings.TrimSpace(element)
+		if !strings.HasPrefix(start, "<") {
+			start = "<" + start + ">"
+		}
+		name, err := wrapperName(start)
+		if err != nil {
+			return nil, err
+		}
+		elements, err := t.elements(selector)
+		if err != nil {
+			return nil, err
+		}
+		var edits []Edit
+		for _, e := range elements {
+			edits = append(edits,
+				Edit{e.StartByte(), e.StartByte(), start},
+				Edit{e.EndByte(), e.EndByte(), "</" + name + ">"})
+		}
+		return edits, nil
+	}}
+}
+
+// wrapperName returns the tag name of start, which must be a single start
+// tag without errors. The element it opens is left unclosed.
+func wrapperName(start string) (string, error) {
+	tree, err := parse([]byte(start))
+	if err != nil {
+		return "", err
+	}
+	defer tree.Close()
+	root := tree.RootNode()
+	var name string
+	if root.NamedChildCount() == 1 {
+		if tag := walk.ChildOfKind(root.NamedChild(0), "html_start_tag"); tag != nil && !tag.HasError() && tag.StartByte() == 0 && tag.EndByte() == uint(len(start)) {
+			name = childText(tag, "html_tag_name", []byte(start))
+		}
+	}
+	if name == "" {
+		return "", fmt.Errorf("rewrite: %q is not a start tag", start)
+	}
+	return name, nil
+}
+
+// elements returns the elements of t matching selector, in document order.
+// Mustache constructs the selector matches are left out.
+func (t *template) elements(source string) ([]*tree_sitter.Node, error) {
+	sel, err := selector.Compile(source)
+	if err != nil {
+		return nil, err
+	}
+	defer sel.Close()
+	var elements []*tree_sitter.Node
+	for _, n := range sel.Find(t.root, t.src) {
+		if startTag(n) != nil {
+			elements = append(elements, n)
+		}
+	}
+	return elements, nil
+}
+
+// startTag returns the start or self-closing tag of an element, or nil if
+// n is not an element.
+func startTag(n *tree_sitter.Node) *tree_sitter.Node {
+	switch n.Kind() {
+	case "html_element", "html_script_element", "html_style_element", "html_raw_element":
+		if tag := walk.ChildOfKind(n, "html_start_tag"); tag != nil {
+			return tag
+		}
+		return walk.ChildOfKind(n, "html_self_closing_tag")
+	}
+	return nil
+}
+
+// findAttribute returns the attribute of tag called name, compared
+// case-insensitively.
+func findAttribute(tag *tree_sitter.Node, name string, src []byte) *tree_sitter.Node {
+	for i := uint(0); i < tag.NamedChildCount(); i++ {
+		attribute := tag.NamedChild(i)
+		if attribute.Kind() == "html_attribute" && strings.EqualFold(childText(attribute, "html_attribute_name", src), name) {
+			return attribute
+		}
+	}
+	return nil
+}
+
+func parse(src []byte) (*tree_sitter.Tree, error) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		return nil, err
+	}
+	tree := parser.Parse(src, nil)
+	if tree == nil {
+		return nil, errors.New("rewrite: parse failed")
+	}
+	return tree, nil
+}
+
+func childText(n *tree_sitter.Node, kind string, src []byte) string {
+	if child := walk.ChildOfKind(n, kind); child != nil {
+		return child.Utf8Text(src)
+	}
+	return ""
+}
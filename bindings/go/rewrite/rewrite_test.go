@@ -0,0 +1,146 @@
+package rewrite_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/rewrite"
+)
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		ops      []rewrite.Op
+		expected string
+	}{
+		{
+			"rename variable",
+			`{{#user}}<p title="{{user.name}}">{{{ user.bio }}} {{username}}</p>{{/user}}{{^ user }}{{/ user }}`,
+			[]rewrite.Op{rewrite.RenameVariable("user", "account")},
+			`{{#account}}<p title="{{account.name}}">{{{ account.bio }}} {{username}}</p>{{/account}}{{^ account }}{{/ account }}`,
+		},
+		{
+			"rename nested path",
+			`{{user.name}} {{user.email}} {{user}}`,
+			[]rewrite.Op{rewrite.RenameVariable("user.name", "user.fullName")},
+			`{{user.fullName}} {{user.email}} {{user}}`,
+		},
+		{
+			"rename tag",
+			`<ul class="nav"><li>a</li></ul><ul><li>b</li></ul><br class="nav"/>`,
+			[]rewrite.Op{rewrite.RenameTag(".nav", "ol")},
+			`<ol class="nav"><li>a</li></ol><ul><li>b</li></ul><ol class="nav"/>`,
+		},
+		{
+			"rename attribute",
+			`<img SRC="a.png" alt=""><a src="x"></a>`,
+			[]rewrite.Op{rewrite.RenameAttribute("img", "src", "data-src")},
+			`<img data-src="a.png" alt=""><a src="x"></a>`,
+		},
+		{
+			"add attribute",
+			`<main><img src="a.png"><img src="b.png" loading='eager' /><img/></main><img>`,
+			[]rewrite.Op{rewrite.AddAttribute("main img", "loading", "lazy")},
+			`<main><img src="a.png" loading="lazy"><img src="b.png" loading="lazy" /><img loading="lazy"/></main><img>`,
+		},
+		{
+			"add boolean attribute and quotes",
+			`<input type="text"><button></button>`,
+			[]rewrite.Op{rewrite.AddAttribute("input[type=text]", "required", ""), rewrite.AddAttribute("button", "data-x", `say "hi"`)},
+			`<input type="text" required><button data-x="say &quot;hi&quot;"></button>`,
+		},
+		{
+			"wrap with",
+			`<div id="a"><p>x</p></div><p>y</p>`,
+			[]rewrite.Op{rewrite.WrapWith("#a > p", `<section class="card">`), rewrite.WrapWith("div", "main")},
+			`<main><div id="a"><section class="card"><p>x</p></section></div></main><p>y</p>`,
+		},
+		{
+			"combined",
+			`<p class="a b">{{x}}</p>`,
+			[]rewrite.Op{rewrite.RenameTag("p.b", "span"), rewrite.AddAttribute("p", "id", "{{id}}"), rewrite.RenameVariable("x", "y")},
+			`<span class="a b" id="{{id}}">{{y}}</span>`,
+		},
+		{
+			"mustache context",
+			`{{#admin}}<button>x</button>{{/admin}}<button>y</button>`,
+			[]rewrite.Op{rewrite.AddAttribute("{{#admin}} button", "class", "danger")},
+			`{{#admin}}<button class="danger">x</button>{{/admin}}<button>y</button>`,
+		},
+		{
+			"selector list",
+			`<h1>a</h1><h2>b</h2><h3>c</h3>`,
+			[]rewrite.Op{rewrite.RenameTag("h1, h3", "h2")},
+			`<h2>a</h2><h2>b</h2><h2>c</h2>`,
+		},
+	}
+	for _, test := range tests {
+		edits, err := rewrite.Rewrite([]byte(test.src), test.ops...)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		out, err := rewrite.Apply([]byte(test.src), edits)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if string(out) != test.expected {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.name, out, test.expected)
+		}
+	}
+}
+
+func TestRewriteEdits(t *testing.T) {
+	src := []byte(`<p>{{a}}</p>{{#a}}{{/a}}`)
+	edits, err := rewrite.Rewrite(src, rewrite.RenameVariable("a", "bc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []rewrite.Edit{{5, 6, "bc"}, {15, 16, "bc"}, {21, 22, "bc"}}
+	if len(edits) != len(expected) {
+		t.Fatalf("Rewrite() = %+v, want %+v", edits, expected)
+	}
+	for i := range edits {
+		if edits[i] != expected[i] {
+			t.Errorf("edit %d = %+v, want %+v", i, edits[i], expected[i])
+		}
+	}
+
+	if edits, err := rewrite.Rewrite(src, rewrite.RenameVariable("a", "a")); err != nil || len(edits) != 0 {
+		t.Errorf("renaming to the same name = %+v, %v; want no edits", edits, err)
+	}
+}
+
+func TestRewriteErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		op   rewrite.Op
+		err  error
+	}{
+		{"syntax", `{{#a}}`, rewrite.RenameVariable("a", "b"), rewrite.ErrSyntax},
+		{"invalid result", `{{a}}`, rewrite.RenameVariable("a", "b{{"), rewrite.ErrInvalid},
+		{"valid", `<p>x</p>`, rewrite.RenameTag("p", "div"), nil},
+	}
+	for _, test := range tests {
+		_, err := rewrite.Rewrite([]byte(test.src), test.op)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+		}
+	}
+
+	src := []byte(`<p>x</p>`)
+	if _, err := rewrite.Rewrite(src, rewrite.RenameTag("p", "div"), rewrite.RenameTag("p", "span")); !errors.Is(err, rewrite.ErrConflict) {
+		t.Errorf("overlapping renames: got %v, want %v", err, rewrite.ErrConflict)
+	}
+	for _, selector := range []string{"", "p >", "[", "#", "p, ", "a[x='y]"} {
+		if _, err := rewrite.Rewrite(src, rewrite.RenameTag(selector, "div")); err == nil {
+			t.Errorf("selector %q: no error", selector)
+		}
+	}
+	if _, err := rewrite.Rewrite(src, rewrite.WrapWith("p", "<div></div>")); err == nil {
+		t.Error("wrapping with a whole element: no error")
+	}
+}
@@ -0,0 +1,285 @@
+package tree_sitter_htmlmustache_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// scannerInputs exercise each external scanner token: raw text, comments,
+// implicit end tags, set delimiters and custom delimiters.
+var scannerInputs = []string{
+	"<div><p>a<br>b</div>",
+	"<script>if (a < b) {{x}}</script>",
+	"<textarea>{{#a}}{{b}}</textarea>",
+	"<!-- {{x}} -- -->",
+	"{{#items}}<li>{{name}}{{/items}}",
+	"{{!-- a }} b --}}",
+	"{{=<% %>=}}<p><%#a%><%b%> {{c}}<%/a%></p><%={{ }}=%>{{d}}",
+	"{{=[ ]=}}<x>[> p]</x>[! c]",
+	"café {{naïve}} <b title=\"€\">ü</b>",
+	"<svg><image></image><foreignObject><img></foreignObject></svg>",
+	"<div>{{#a}}</div>{{/a}}{{#b}}<p>{{/b}}{{#b}}</p>{{/b}}</div>",
+	"<script src=\"a/>\" /><style media=a/>x</style><my-widget/>",
+	"<{{ tag }} id=\"x\"><h{{n}}>a</h{{n}}></{{tag}}><{{#a}}>",
+}
+
+func newParser(t *testing.T) *tree_sitter.Parser {
+	t.Helper()
+	parser := tree_sitter.NewParser()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		t.Fatal(err)
+	}
+	return parser
+}
+
+// TestTruncatedInput parses every prefix of the inputs, each cut where a
+// scanner token is half read, and checks that the parse terminates.
+func TestTruncatedInput(t *testing.T) {
+	parser := newParser(t)
+	defer parser.Close()
+	for _, input := range scannerInputs {
+		for end := 0; end <= len(input); end++ {
+			src := []byte(input[:end])
+			deadline := time.Now().Add(5 * time.Second)
+			tree := parse(parser, src, func(tree_sitter.ParseState) bool { return time.Now().After(deadline) })
+			if tree == nil {
+				t.Fatalf("parse of %q did not terminate", src)
+			}
+			if root := tree.RootNode(); root.EndByte() > uint(len(src)) {
+				t.Errorf("tree of %q ends at %d", src, root.EndByte())
+			}
+			tree.Close()
+			parser.Reset()
+		}
+	}
+}
+
+// TestNulInInput checks that a NUL byte inside scanner tokens is content
+// rather than the end of input.
+func TestNulInInput(t *testing.T) {
+	parser := newParser(t)
+	defer parser.Close()
+	tests := []struct {
+		src, expected string
+	}{
+		{"<!-- a\x00b -->", "(document (html_comment))"},
+		{"<script>a\x00b</script>", "(document (html_script_element open: (html_start_tag name: (html_tag_name)) content: (html_raw_text) close: (html_end_tag name: (html_tag_name))))"},
+	}
+	for _, test := range tests {
+		tree := parser.Parse([]byte(test.src), nil)
+		if got := tree.RootNode().ToSexp(); got != test.expected {
+			t.Errorf("%q parsed as %s, want %s", test.src, got, test.expected)
+		}
+		tree.Close()
+	}
+}
+
+// TestForeignContent checks that HTML void element names in <svg> and
+// <math> have end tags, except in their HTML integration points.
+func TestForeignContent(t *testing.T) {
+	parser := newParser(t)
+	defer parser.Close()
+	tests := []struct {
+		src, expected string
+	}{
+		{"<svg><image></image></svg>", "(document (html_element open: (html_start_tag name: (html_tag_name)) content: (html_element open: (html_start_tag name: (html_tag_name)) close: (html_end_tag name: (html_tag_name))) close: (html_end_tag name: (html_tag_name))))"},
+		{"<math><mi><br>x</mi></math>", "(document (html_element open: (html_start_tag name: (html_tag_name)) content: (html_element open: (html_start_tag name: (html_tag_name)) content: (html_element open: (html_start_tag name: (html_tag_name))) content: (text) close: (html_end_tag name: (html_tag_name))) close: (html_end_tag name: (html_tag_name))))"},
+		{"<svg></svg><image>x", "(document (html_element open: (html_start_tag name: (html_tag_name)) close: (html_end_tag name: (html_tag_name))) (html_element open: (html_start_tag name: (html_tag_name))) (text))"},
+	}
+	for _, test := range tests {
+		tree := parser.Parse([]byte(test.src), nil)
+		if got := tree.RootNode().ToSexp(); got != test.expected {
+			t.Errorf("%q parsed as %s, want %s", test.src, got, test.expected)
+		}
+		tree.Close()
+	}
+}
+
+// TestSectionBoundaries checks that an end tag in a section for an element
+// opened before it ends the element after the section, unless it pairs with
+// an element an earlier section left open.
+func TestSectionBoundaries(t *testing.T) {
+	parser := newParser(t)
+	defer parser.Close()
+	tests := []struct {
+		src, expected string
+	}{
+		{"<div>{{#a}}</div>{{/a}}<p>z</p>", "(document (html_element open: (html_start_tag name: (html_tag_name)) content: (mustache_section open: (mustache_section_begin name: (mustache_tag_name)) content: (html_erroneous_end_tag name: (html_erroneous_end_tag_name)) close: (mustache_section_end name: (mustache_tag_name)))) (html_element open: (html_start_tag name: (html_tag_name)) content: (text) close: (html_end_tag name: (html_tag_name))))"},
+		{"<div>{{#a}}{{#b}}</div>{{/b}}{{/a}}<p>z</p>", "(document (html_element open: (html_start_tag name: (html_tag_name)) content: (mustache_section open: (mustache_section_begin name: (mustache_tag_name)) content: (mustache_section open: (mustache_section_begin name: (mustache_tag_name)) content: (html_erroneous_end_tag name: (html_erroneous_end_tag_name)) close: (mustache_section_end name: (mustache_tag_name))) close: (mustache_section_end name: (mustache_tag_name)))) (html_element open: (html_start_tag name: (html_tag_name)) content: (text) close: (html_end_tag name: (html_tag_name))))"},
+		{"<div>{{#a}}<div>{{/a}}{{#a}}</div>{{/a}}</div>", "(document (html_element open: (html_start_tag name: (html_tag_name)) content: (mustache_section open: (mustache_section_begin name: (mustache_tag_name)) content: (html_element open: (html_start_tag name: (html_tag_name)) close: (html_forced_end_tag)) close: (mustache_section_end name: (mustache_tag_name))) content: (mustache_section open: (mustache_section_begin name: (mustache_tag_name)) content: (html_erroneous_end_tag name: (html_erroneous_end_tag_name)) close: (mustache_section_end name: (mustache_tag_name))) close: (html_end_tag name: (html_tag_name))))"},
+	}
+	for _, test := range tests {
+		tree := parser.Parse([]byte(test.src), nil)
+		if got := tree.RootNode().ToSexp(); got != test.expected {
+			t.Errorf("%q parsed as %s, want %s", test.src, got, test.expected)
+		}
+		tree.Close()
+	}
+}
+
+// TestDynamicTagNames checks that interpolations in tag names are nodes in
+// the names, and that end tags match start tags whatever their spacing.
+func TestDynamicTagNames(t *testing.T) {
+	parser := newParser(t)
+	defer parser.Close()
+	tests := []struct {
+		src, expected string
+		names         []string
+	}{
+		{
+			"<{{tag}} id=\"x\">z</{{ tag }}>",
+			"(document (html_element open: (html_start_tag name: (html_tag_name (mustache_interpolation expression: (mustache_identifier))) attribute: (html_attribute name: (html_attribute_name) value: (html_quoted_attribute_value (html_attribute_value)))) content: (text) close: (html_end_tag name: (html_tag_name (mustache_interpolation expression: (mustache_identifier))))))",
+			[]string{"{{tag}}", "{{ tag }}"},
+		},
+		{
+			"<ul><h{{n}}><li>x</h{{n}}></ul>",
+			"(document (html_element open: (html_start_tag name: (html_tag_name)) content: (html_element open: (html_start_tag name: (html_tag_name (mustache_interpolation expression: (mustache_identifier)))) content: (html_element open: (html_start_tag name: (html_tag_name)) content: (text)) close: (html_end_tag name: (html_tag_name (mustache_interpolation expression: (mustache_identifier))))) close: (html_end_tag name: (html_tag_name))))",
+			[]string{"ul", "h{{n}}", "li", "h{{n}}", "ul"},
+		},
+		{
+			"<{{a}}>x</{{b}}>",
+			"(document (html_element open: (html_start_tag name: (html_tag_name (mustache_interpolation expression: (mustache_identifier)))) content: (text)) (html_erroneous_end_tag name: (html_erroneous_end_tag_name (mustache_interpolation expression: (mustache_identifier)))))",
+			[]string{"{{a}}"},
+		},
+	}
+	for _, test := range tests {
+		tree := parser.Parse([]byte(test.src), nil)
+		if got := tree.RootNode().ToSexp(); got != test.expected {
+			t.Errorf("%q parsed as %s, want %s", test.src, got, test.expected)
+		}
+		var names []string
+		var visit func(node *tree_sitter.Node)
+		visit = func(node *tree_sitter.Node) {
+			if node.Kind() == "html_tag_name" {
+				names = append(names, node.Utf8Text([]byte(test.src)))
+			}
+			for i := uint(0); i < node.ChildCount(); i++ {
+				visit(node.Child(i))
+			}
+		}
+		visit(tree.RootNode())
+		if !reflect.DeepEqual(names, test.names) {
+			t.Errorf("%q has tag names %q, want %q", test.src, names, test.names)
+		}
+		tree.Close()
+	}
+}
+
+// TestUnicodeNames checks that tag and section names outside ASCII are
+// compared whole, so that names sharing a low byte do not match.
+func TestUnicodeNames(t *testing.T) {
+	parser := newParser(t)
+	defer parser.Close()
+	tests := []struct {
+		src, expected string
+	}{
+		{"<x-ü-a data-ñ>y</x-ü-a>", "(document (html_element open: (html_start_tag name: (html_tag_name) attribute: (html_attribute name: (html_attribute_name))) content: (text) close: (html_end_tag name: (html_tag_name))))"},
+		{"<x-ü>y</x-Ǽ>", "(document (html_element open: (html_start_tag name: (html_tag_name)) content: (text)) (html_erroneous_end_tag name: (html_erroneous_end_tag_name)))"},
+		{"{{#ü}}y{{/Ǽ}}", "(document (mustache_section open: (mustache_section_begin name: (mustache_tag_name)) content: (text) close: (mustache_erroneous_section_end name: (mustache_erroneous_tag_name))))"},
+	}
+	for _, test := range tests {
+		tree := parser.Parse([]byte(test.src), nil)
+		if got := tree.RootNode().ToSexp(); got != test.expected {
+			t.Errorf("%q parsed as %s, want %s", test.src, got, test.expected)
+		}
+		tree.Close()
+	}
+}
+
+// TestLineEndings checks that the inputs parse the same with CRLF line
+// endings and a byte order mark as with LF.
+func TestLineEndings(t *testing.T) {
+	parser := newParser(t)
+	defer parser.Close()
+	for _, input := range append(scannerInputs, "<ul>\n  {{#a}}\n  <li>x\n  {{/a}}\n</ul>\n<pre>\n{{b}}\n</pre>\n") {
+		lf := parser.Parse([]byte(input), nil)
+		crlf := parser.Parse([]byte("\xef\xbb\xbf"+strings.ReplaceAll(input, "\n", "\r\n")), nil)
+		if got, want := crlf.RootNode().ToSexp(), lf.RootNode().ToSexp(); got != want {
+			t.Errorf("%q with CRLF parsed as %s, want %s", input, got, want)
+		}
+		lf.Close()
+		crlf.Close()
+	}
+}
+
+// TestCombinedInjection parses a template split across two string literals
+// of a host language, as an injection with injection.combined does, and
+// checks that the tree is one document with the offsets of the host source.
+func TestCombinedInjection(t *testing.T) {
+	parser := newParser(t)
+	defer parser.Close()
+	src := []byte(`t := "<p>Hi {{name}}" + "!</p>"`)
+	literal := func(start, end uint) tree_sitter.Range {
+		return tree_sitter.Range{
+			StartByte:  start,
+			EndByte:    end,
+			StartPoint: tree_sitter.Point{Column: start},
+			EndPoint:   tree_sitter.Point{Column: end},
+		}
+	}
+	if err := parser.SetIncludedRanges([]tree_sitter.Range{literal(6, 20), literal(25, 30)}); err != nil {
+		t.Fatal(err)
+	}
+	tree := parser.Parse(src, nil)
+	defer tree.Close()
+	root := tree.RootNode()
+	expected := "(document (html_element open: (html_start_tag name: (html_tag_name)) content: (text) content: (mustache_interpolation expression: (mustache_identifier)) content: (text) close: (html_end_tag name: (html_tag_name))))"
+	if got := root.ToSexp(); got != expected {
+		t.Fatalf("parsed as %s, want %s", got, expected)
+	}
+	element := root.Child(0)
+	for i, want := range []string{"<p>", "Hi", "{{name}}", "!", "</p>"} {
+		node := element.Child(uint(i))
+		if got := string(src[node.StartByte():node.EndByte()]); got != want {
+			t.Errorf("child %d is %q, want %q", i, got, want)
+		}
+	}
+}
+
+// TestUTF16 checks that UTF-16 input gives the same trees as UTF-8, so the
+// scanner reads code points rather than bytes.
+func TestUTF16(t *testing.T) {
+	parser := newParser(t)
+	defer parser.Close()
+	for _, input := range append(scannerInputs, "{{x}}😀<p>😀</p><style>😀{}</style>") {
+		tree := parser.Parse([]byte(input), nil)
+		expected := tree.RootNode().ToSexp()
+		tree.Close()
+
+		units := utf16.Encode([]rune(input))
+		tree = parser.ParseUTF16LE(units, nil)
+		if got := tree.RootNode().ToSexp(); got != expected {
+			t.Errorf("UTF-16 %q parsed as %s, want %s", input, got, expected)
+		}
+		if end := tree.RootNode().EndByte(); end > uint(2*len(units)) {
+			t.Errorf("UTF-16 tree of %q ends at byte %d of %d", input, end, 2*len(units))
+		}
+		tree.Close()
+
+		// Cut the input inside the surrogate pair of each emoji.
+		for i, unit := range units {
+			if !utf16.IsSurrogate(rune(unit)) || i+1 == len(units) {
+				continue
+			}
+			cut := units[:i+1]
+			deadline := time.Now().Add(5 * time.Second)
+			tree := parser.ParseUTF16LEWithOptions(func(offset int, _ tree_sitter.Point) []uint16 {
+				if offset/2 < len(cut) {
+					return cut[offset/2:]
+				}
+				return nil
+			}, nil, &tree_sitter.ParseOptions{ProgressCallback: func(tree_sitter.ParseState) bool { return time.Now().After(deadline) }})
+			if tree == nil {
+				t.Fatalf("parse of %q cut at unit %d did not terminate", input, len(cut))
+			}
+			tree.Close()
+			parser.Reset()
+		}
+	}
+}
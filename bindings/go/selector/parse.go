@@ -0,0 +1,233 @@
+package selector
+
+import (
+	"fmt"
+	"strings"
+)
+
+// step is a compound selector along with how it relates to the step before
+// it in an alternative.
+type step struct {
+	// child requires the construct of the previous step to be the parent,
+	// rather than any ancestor.
+	child bool
+	// element is set for element selectors, and tag to their lowercased tag
+	// name, or empty for any element.
+	element bool
+	tag     string
+	ids     []string
+	classes []string
+	attrs   []attrTest
+	// mustache is set for Mustache selectors such as {{#items}}.
+	mustache *mustacheTest
+}
+
+type attrTest struct {
+	// name is lowercased.
+	name string
+	// value is compared if hasValue is set.
+	value    string
+	hasValue bool
+}
+
+type mustacheTest struct {
+	// sigil is the character after the opening braces: '#', '^', '>' or
+	// '!', '{' for triple mustaches, or 0 for interpolations.
+	sigil byte
+	// name is the name as written, or empty for any name.
+	name string
+}
+
+// parse parses a selector list into its alternatives.
+func parse(s string) ([][]step, error) {
+	p := &parser{s: s}
+	var alts [][]step
+	for {
+		alt, err := p.alternative()
+		if err != nil {
+			return nil, fmt.Errorf("selector: %q: %w", s, err)
+		}
+		alts = append(alts, alt)
+		p.space()
+		if p.done() {
+			return alts, nil
+		}
+		if !p.eat(',') {
+			return nil, fmt.Errorf("selector: %q: unexpected %q", s, p.s[p.i:])
+		}
+	}
+}
+
+type parser struct {
+	s string
+	i int
+}
+
+func (p *parser) done() bool { return p.i >= len(p.s) }
+
+func (p *parser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.s[p.i]
+}
+
+func (p *parser) eat(c byte) bool {
+	if p.peek() == c && !p.done() {
+		p.i++
+		return true
+	}
+	return false
+}
+
+// space skips whitespace and reports whether there was any.
+func (p *parser) space() bool {
+	start := p.i
+	for !p.done() && strings.IndexByte(" \t\r\n", p.s[p.i]) >= 0 {
+		p.i++
+	}
+	return p.i > start
+}
+
+func (p *parser) alternative() ([]step, error) {
+	var steps []step
+	child := false
+	for {
+		p.space()
+		s, err := p.compound()
+		if err != nil {
+			return nil, err
+		}
+		s.child = child
+		steps = append(steps, s)
+		spaced := p.space()
+		switch {
+		case p.eat('>'):
+			child = true
+		case spaced && !p.done() && p.peek() != ',':
+			child = false
+		default:
+			return steps, nil
+		}
+	}
+}
+
+func (p *parser) compound() (step, error) {
+	var s step
+	if strings.HasPrefix(p.s[p.i:], "{{") {
+		m, err := p.mustache()
+		s.mustache = m
+		return s, err
+	}
+	start := p.i
+	if !p.eat('*') {
+		s.tag = strings.ToLower(p.name())
+	}
+	for {
+		switch {
+		case p.eat('#'):
+			name := p.name()
+			if name == "" {
+				return s, fmt.Errorf("expected an id after #")
+			}
+			s.ids = append(s.ids, name)
+		case p.eat('.'):
+			name := p.name()
+			if name == "" {
+				return s, fmt.Errorf("expected a class after .")
+			}
+			s.classes = append(s.classes, name)
+		case p.eat('['):
+			p.space()
+			test := attrTest{name: strings.ToLower(p.name())}
+			if test.name == "" {
+				return s, fmt.Errorf("expected an attribute name after [")
+			}
+			p.space()
+			if p.eat('=') {
+				p.space()
+				value, err := p.value()
+				if err != nil {
+					return s, err
+				}
+				test.value, test.hasValue = value, true
+				p.space()
+			}
+			if !p.eat(']') {
+				return s, fmt.Errorf("expected ] after attribute %s", test.name)
+			}
+			s.attrs = append(s.attrs, test)
+		default:
+			if p.i == start {
+				if p.done() {
+					return s, fmt.Errorf("expected a selector")
+				}
+				return s, fmt.Errorf("unexpected %q", p.s[p.i:])
+			}
+			s.element = true
+			return s, nil
+		}
+	}
+}
+
+// mustache reads a Mustache selector: {{name}}, {{{name}}}, {{#name}},
+// {{^name}}, {{>name}} or {{!}}, where an empty name or * matches any.
+func (p *parser) mustache() (*mustacheTest, error) {
+	p.i += len("{{")
+	m := &mustacheTest{}
+	closing := "}}"
+	switch c := p.peek(); c {
+	case '{':
+		closing = "}}}"
+		fallthrough
+	case '#', '^', '>', '!':
+		m.sigil = c
+		p.i++
+	}
+	end := strings.Index(p.s[p.i:], closing)
+	if end < 0 {
+		return nil, fmt.Errorf("expected %s", closing)
+	}
+	m.name = strings.TrimSpace(p.s[p.i : p.i+end])
+	p.i += end + len(closing)
+	switch {
+	case m.name == "*":
+		m.name = ""
+	case m.sigil == '!' && m.name != "":
+		return nil, fmt.Errorf("comments have no name: {{!%s}}", m.name)
+	case strings.ContainsAny(m.name, " \t\r\n{}"):
+		return nil, fmt.Errorf("invalid name %q", m.name)
+	}
+	return m, nil
+}
+
+// name reads an identifier: letters, digits, "-", "_" and ":".
+func (p *parser) name() string {
+	start := p.i
+	for !p.done() {
+		c := p.s[p.i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == ':' || c >= 0x80) {
+			break
+		}
+		p.i++
+	}
+	return p.s[start:p.i]
+}
+
+// value reads an attribute value: a name or a quoted string.
+func (p *parser) value() (string, error) {
+	quote := p.peek()
+	if quote != '"' && quote != '\'' {
+		if name := p.name(); name != "" {
+			return name, nil
+		}
+		return "", fmt.Errorf("expected an attribute value")
+	}
+	end := strings.IndexByte(p.s[p.i+1:], quote)
+	if end < 0 {
+		return "", fmt.Errorf("unterminated string")
+	}
+	value := p.s[p.i+1 : p.i+1+end]
+	p.i += end + 2
+	return value, nil
+}
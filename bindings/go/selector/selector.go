@@ -0,0 +1,296 @@
+// Package selector finds nodes of htmlmustache parse trees with CSS-style
+// selectors that know about Mustache as well as HTML:
+//
+//	div.card > {{#items}} img[alt]
+//
+// finds the img elements with an alt attribute anywhere inside an items
+// section that is directly inside a div of class card. Elements are
+// selected by type (div), universal (*), id (#main), class (.card) and
+// attribute ([href], [type=text]) selectors, compared as HTML does: tag
+// and attribute names case-insensitively, values exactly. Mustache
+// constructs are selected by writing them:
+//
+//	{{name}}    interpolations of name
+//	{{{name}}}  triple mustaches of name
+//	{{#name}}   sections over name
+//	{{^name}}   inverted sections over name
+//	{{>name}}   includes of the partial name
+//	{{!}}       comments
+//
+// An empty name, or *, matches any name, as in {{#}}. Selectors are
+// combined with the descendant (a b) and child (a > b) combinators, where
+// the parent of a node is the element or section directly around it, and
+// listed with commas. Only attributes written directly in a start tag are
+// matched; those inside Mustache sections are not.
+//
+// Each element and Mustache selector is compiled to a tree-sitter query
+// pattern, and the combinators are checked on the nodes the patterns
+// capture.
+package selector
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+)
+
+// Selector is a compiled selector. It may be used from several goroutines
+// at once.
+type Selector struct {
+	source string
+	alts   [][]compiledStep
+	query  *tree_sitter.Query
+	// tests maps the query's patterns to the tests they belong to, of
+	// which there are testCount.
+	tests     []int
+	testCount int
+}
+
+// compiledStep is a step whose tests are numbered across the selector; a
+// node matches the step if it matches all of them.
+type compiledStep struct {
+	child bool
+	tests []int
+}
+
+// elementKinds are the node kinds of elements, and the tags that name
+// them.
+var elementKinds = []struct{ kind, tag string }{
+	{"html_element", "html_start_tag"},
+	{"html_element", "html_self_closing_tag"},
+	{"html_script_element", "html_start_tag"},
+	{"html_style_element", "html_start_tag"},
+	{"html_raw_element", "html_start_tag"},
+}
+
+// Compile parses selector and compiles it to a query.
+func Compile(selector string) (*Selector, error) {
+	alts, err := parse(selector)
+	if err != nil {
+		return nil, err
+	}
+	s := &Selector{source: selector}
+	var patterns []string
+	test := 0
+	add := func(pattern ...string) int {
+		for _, p := range pattern {
+			patterns = append(patterns, p)
+			s.tests = append(s.tests, test)
+		}
+		test++
+		return test - 1
+	}
+	for _, alt := range alts {
+		var steps []compiledStep
+		for _, st := range alt {
+			c := compiledStep{child: st.child}
+			if st.mustache != nil {
+				c.tests = append(c.tests, add(mustachePattern(st.mustache)))
+			} else {
+				c.tests = append(c.tests, add(elementPatterns("", "")...))
+				if st.tag != "" {
+					c.tests = append(c.tests, add(elementPatterns(`(html_tag_name) @name`, matchName("name", st.tag))...))
+				}
+				for _, id := range st.ids {
+					c.tests = append(c.tests, add(elementPatterns(attributePattern("id", true), matchValue(id))...))
+				}
+				for _, class := range st.classes {
+					c.tests = append(c.tests, add(elementPatterns(attributePattern("class", true),
+						fmt.Sprintf("(#match? @value %s)", quote(`(?:^|[\s"'])`+regexp.QuoteMeta(class)+`(?:[\s"']|$)`)))...))
+				}
+				for _, attr := range st.attrs {
+					predicates := ""
+					if attr.hasValue {
+						predicates = matchValue(attr.value)
+					}
+					c.tests = append(c.tests, add(elementPatterns(attributePattern(attr.name, attr.hasValue), predicates)...))
+				}
+			}
+			steps = append(steps, c)
+		}
+		s.alts = append(s.alts, steps)
+	}
+
+	query, qerr := tree_sitter.NewQuery(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language()), strings.Join(patterns, "\n"))
+	if qerr != nil {
+		return nil, fmt.Errorf("selector: %q: %v", selector, qerr)
+	}
+	s.query = query
+	s.testCount = test
+	return s, nil
+}
+
+// elementPatterns returns a pattern per element kind capturing, as @node,
+// elements whose start tag has the children inner, with predicates.
+func elementPatterns(inner, predicates string) []string {
+	var patterns []string
+	for _, k := range elementKinds {
+		patterns = append(patterns, fmt.Sprintf("((%s (%s %s)) @node %s)", k.kind, k.tag, inner, predicates))
+	}
+	return patterns
+}
+
+// attributePattern matches an attribute called name, capturing its value,
+// with any quotes, as @value. The value is optional unless required is set.
+func attributePattern(name string, required bool) string {
+	optional := "?"
+	if required {
+		optional = ""
+	}
+	return fmt.Sprintf(`(html_attribute (html_attribute_name) @attribute (#match? @attribute %s) [(html_attribute_value) (html_quoted_attribute_value)]%s @value)`,
+		quote(`(?i)^`+regexp.QuoteMeta(name)+`$`), optional)
+}
+
+// matchName requires the capture to be name, compared case-insensitively.
+func matchName(capture, name string) string {
+	return fmt.Sprintf("(#match? @%s %s)", capture, quote(`(?i)^`+regexp.QuoteMeta(name)+`$`))
+}
+
+// matchValue requires @value to be value, quoted or not.
+func matchValue(value string) string {
+	v := regexp.QuoteMeta(value)
+	return fmt.Sprintf("(#match? @value %s)", quote(`^(?:"`+v+`"|'`+v+`'|`+v+`)$`))
+}
+
+func mustachePattern(m *mustacheTest) string {
+	var kind, name string
+	switch m.sigil {
+	case '#':
+		kind, name = "mustache_section", "(mustache_section_begin (mustache_tag_name) @name)"
+	case '^':
+		kind, name = "mustache_inverted_section", "(mustache_inverted_section_begin (mustache_tag_name) @name)"
+	case '>':
+		kind, name = "mustache_partial", "(mustache_partial_content) @name"
+	case '!':
+		kind = "mustache_comment"
+	case '{':
+		kind, name = "mustache_triple", "[(mustache_path_expression) (mustache_identifier)] @name"
+	default:
+		kind, name = "mustache_interpolation", "[(mustache_path_expression) (mustache_identifier)] @name"
+	}
+	switch {
+	case m.name == "":
+		return fmt.Sprintf("(%s) @node", kind)
+	case m.name == "." && (m.sigil == 0 || m.sigil == '{'):
+		// The implicit iterator is an anonymous node, so match the text of
+		// the whole tag.
+		return fmt.Sprintf(`((%s) @node (#match? @node %s))`, kind, quote(`^\{\{\{?\s*\.\s*\}?\}\}$`))
+	}
+	return fmt.Sprintf(`((%s %s) @node (#match? @name %s))`, kind, name, quote(`^\s*`+regexp.QuoteMeta(m.name)+`\s*$`))
+}
+
+// quote returns s as a query string literal.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// String returns the selector as written.
+func (s *Selector) String() string {
+	return s.source
+}
+
+// Close releases the compiled query.
+func (s *Selector) Close() {
+	s.query.Close()
+}
+
+// Find returns the nodes under root, whose source is src, that match s, in
+// document order, outer nodes before the nodes inside them.
+func (s *Selector) Find(root *tree_sitter.Node, src []byte) []*tree_sitter.Node {
+	cursor := tree_sitter.NewQueryCursor()
+	defer cursor.Close()
+
+	// matched maps each test to the ids of the nodes that pass it.
+	matched := make([]map[uintptr]*tree_sitter.Node, s.testCount)
+	names := s.query.CaptureNames()
+	matches := cursor.Matches(s.query, root, src)
+	for match := matches.Next(); match != nil; match = matches.Next() {
+		for _, c := range match.Captures {
+			if names[c.Index] != "node" {
+				continue
+			}
+			test := s.tests[match.PatternIndex]
+			if matched[test] == nil {
+				matched[test] = map[uintptr]*tree_sitter.Node{}
+			}
+			node := c.Node
+			matched[test][node.Id()] = &node
+		}
+	}
+
+	passes := func(st compiledStep, n *tree_sitter.Node) bool {
+		for _, test := range st.tests {
+			if matched[test][n.Id()] == nil {
+				return false
+			}
+		}
+		return true
+	}
+	var matchSteps func(steps []compiledStep, n *tree_sitter.Node) bool
+	matchSteps = func(steps []compiledStep, n *tree_sitter.Node) bool {
+		last := steps[len(steps)-1]
+		if !passes(last, n) {
+			return false
+		}
+		if len(steps) == 1 {
+			return true
+		}
+		for ancestor := parent(n); ancestor != nil; ancestor = parent(ancestor) {
+			if matchSteps(steps[:len(steps)-1], ancestor) {
+				return true
+			}
+			if last.child {
+				break
+			}
+		}
+		return false
+	}
+
+	found := map[uintptr]*tree_sitter.Node{}
+	for _, alt := range s.alts {
+		last := alt[len(alt)-1]
+		for _, n := range matched[last.tests[0]] {
+			if found[n.Id()] == nil && matchSteps(alt, n) {
+				found[n.Id()] = n
+			}
+		}
+	}
+	nodes := make([]*tree_sitter.Node, 0, len(found))
+	for _, n := range found {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].StartByte() != nodes[j].StartByte() {
+			return nodes[i].StartByte() < nodes[j].StartByte()
+		}
+		return nodes[i].EndByte() > nodes[j].EndByte()
+	})
+	return nodes
+}
+
+// parent returns the element or section directly around n, or nil.
+func parent(n *tree_sitter.Node) *tree_sitter.Node {
+	for p := n.Parent(); p != nil; p = p.Parent() {
+		switch p.Kind() {
+		case "html_element", "html_script_element", "html_style_element", "html_raw_element",
+			"mustache_section", "mustache_inverted_section":
+			return p
+		}
+	}
+	return nil
+}
+
+// Find returns the nodes of tree, whose source is src, that match selector.
+func Find(tree *tree_sitter.Tree, src []byte, selector string) ([]*tree_sitter.Node, error) {
+	s, err := Compile(selector)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+	return s.Find(tree.RootNode(), src), nil
+}
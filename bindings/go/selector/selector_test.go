@@ -0,0 +1,75 @@
+package selector_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/selector"
+)
+
+const page = `<div class="card wide" id=main>` +
+	`{{#items}}<img alt="{{name}}" src="a.png"><img src="b.png">{{/items}}` +
+	`<p>{{#items}}<img alt="">{{/items}}</p>` +
+	`</div>` +
+	`{{#items}}<IMG ALT=x>{{{ html }}}{{> row }}{{! note }}{{.}}{{/items}}` +
+	`{{^items}}<p class='empty'>{{user.name}}</p>{{/items}}` +
+	`<script type="module">go()</script><input disabled/>`
+
+func TestFind(t *testing.T) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		t.Fatal(err)
+	}
+	src := []byte(page)
+	tree := parser.Parse(src, nil)
+	defer tree.Close()
+
+	tests := []struct {
+		selector string
+		expected []string
+	}{
+		{`div.card > {{#items}} img[alt]`, []string{`<img alt="{{name}}" src="a.png">`}},
+		{`div.card {{#items}} img[alt]`, []string{`<img alt="{{name}}" src="a.png">`, `<img alt="">`}},
+		{`img[alt]`, []string{`<img alt="{{name}}" src="a.png">`, `<img alt="">`, `<IMG ALT=x>`}},
+		{`img[alt=x], img[src='b.png']`, []string{`<img src="b.png">`, `<IMG ALT=x>`}},
+		{`#main`, []string{page[:strings.Index(page, "</div>")+len("</div>")]}},
+		{`.empty`, []string{`<p class='empty'>{{user.name}}</p>`}},
+		{`.card.wide > p`, []string{`<p>{{#items}}<img alt="">{{/items}}</p>`}},
+		{`.card.narrow`, nil},
+		{`{{^}} {{user.name}}`, []string{`{{user.name}}`}},
+		{`{{{html}}}, {{>row}}, {{!}}, {{.}}`, []string{`{{{ html }}}`, `{{> row }}`, `{{! note }}`, `{{.}}`}},
+		{`{{name}}`, []string{`{{name}}`}},
+		// Interpolations in attributes are inside their element.
+		{`img > {{name}}`, []string{`{{name}}`}},
+		{`script[type=module], input[disabled]`, []string{`<script type="module">go()</script>`, `<input disabled/>`}},
+		{`p {{#items}}`, []string{`{{#items}}<img alt="">{{/items}}`}},
+		{`{{#*}} > *`, []string{`<img alt="{{name}}" src="a.png">`, `<img src="b.png">`, `<img alt="">`, `<IMG ALT=x>`}},
+	}
+	for _, test := range tests {
+		nodes, err := selector.Find(tree, src, test.selector)
+		if err != nil {
+			t.Errorf("Find(%q): %v", test.selector, err)
+			continue
+		}
+		var got []string
+		for _, n := range nodes {
+			got = append(got, n.Utf8Text(src))
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("Find(%q) =\n%q\nwant\n%q", test.selector, got, test.expected)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, s := range []string{"", "p >", "[", "#", "p, ", "a[x='y]", "{{#items", "{{!x}}", "{{a b}}", "p)"} {
+		if _, err := selector.Compile(s); err == nil {
+			t.Errorf("Compile(%q): no error", s)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Package walk iterates over the nodes of htmlmustache parse trees without
+// recursion. The iterators are built on a single go-tree-sitter cursor,
+// so walking a deep tree does not grow the stack, and they add no
+// allocations to the one go-tree-sitter makes for each node it returns:
+//
+//	for n := range walk.Kind(tree, "mustache_interpolation") {
+//		fmt.Println(n.Utf8Text(src))
+//	}
+//
+// Nodes are yielded in document order, each before its descendants, and
+// stay valid for as long as the tree they belong to.
+package walk
+
+import (
+	"iter"
+	"slices"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// Preorder returns the nodes of tree, named and anonymous, in document
+// order.
+func Preorder(tree *tree_sitter.Tree) iter.Seq[tree_sitter.Node] {
+	return PreorderNode(tree.RootNode())
+}
+
+// PreorderNode returns root and its descendants in document order.
+func PreorderNode(root *tree_sitter.Node) iter.Seq[tree_sitter.Node] {
+	return func(yield func(tree_sitter.Node) bool) {
+		preorder(root, false, nil, yield)
+	}
+}
+
+// Named returns the named nodes of tree in document order.
+func Named(tree *tree_sitter.Tree) iter.Seq[tree_sitter.Node] {
+	root := tree.RootNode()
+	return func(yield func(tree_sitter.Node) bool) {
+		preorder(root, true, nil, yield)
+	}
+}
+
+// Kind returns the nodes of tree of any of kinds, in document order.
+func Kind(tree *tree_sitter.Tree, kinds ...string) iter.Seq[tree_sitter.Node] {
+	return KindNode(tree.RootNode(), kinds...)
+}
+
+// KindNode returns root and its descendants of any of kinds, in document
+// order.
+func KindNode(root *tree_sitter.Node, kinds ...string) iter.Seq[tree_sitter.Node] {
+	// Nodes are compared by their numeric kind, since Node.Kind copies the
+	// kind's name for every call. A name may be both a named and an
+	// anonymous kind, and names the grammar does not have match nothing.
+	language := root.Language()
+	var ids []uint16
+	for _, kind := range kinds {
+		for _, named := range []bool{true, false} {
+			if id := language.IdForNodeKind(kind, named); id != 0 && !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return func(yield func(tree_sitter.Node) bool) {
+		if len(ids) > 0 {
+			preorder(root, false, ids, yield)
+		}
+	}
+}
+
+// Visit calls visit for root and its descendants in document order,
+// skipping the descendants of nodes for which visit returns false.
+func Visit(root *tree_sitter.Node, visit func(n *tree_sitter.Node) bool) {
+	cursor := root.Walk()
+	defer cursor.Close()
+	for next(cursor, visit(cursor.Node())) {
+	}
+}
+
+// ChildOfKind returns the first child of n of kind, or nil if it has none.
+func ChildOfKind(n *tree_sitter.Node, kind string) *tree_sitter.Node {
+	for i := uint(0); i < n.ChildCount(); i++ {
+		if child := n.Child(i); child.Kind() == kind {
+			return child
+		}
+	}
+	return nil
+}
+
+// Children returns the children of n, named and anonymous, in order.
+func Children(n *tree_sitter.Node) []*tree_sitter.Node {
+	nodes := make([]*tree_sitter.Node, 0, n.ChildCount())
+	for i := uint(0); i < n.ChildCount(); i++ {
+		nodes = append(nodes, n.Child(i))
+	}
+	return nodes
+}
+
+// preorder yields root and its descendants in document order, until yield
+// returns false. It skips anonymous nodes if named is set and, unless ids
+// is empty, nodes whose kind is not one of ids.
+func preorder(root *tree_sitter.Node, named bool, ids []uint16, yield func(tree_sitter.Node) bool) {
+	cursor := root.Walk()
+	defer cursor.Close()
+	for {
+		node := *cursor.Node()
+		if (!named || node.IsNamed()) && (len(ids) == 0 || slices.Contains(ids, node.KindId())) && !yield(node) {
+			return
+		}
+		if !next(cursor, true) {
+			return
+		}
+	}
+}
+
+// next moves cursor to the node after its current one in document order,
+// entering the current node's children only if descend is set, and reports
+// whether there was one. The cursor does not leave the node it was created
+// for.
+func next(cursor *tree_sitter.TreeCursor, descend bool) bool {
+	if descend && cursor.GotoFirstChild() {
+		return true
+	}
+	for !cursor.GotoNextSibling() {
+		if !cursor.GotoParent() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,205 @@
+package walk_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/walk"
+)
+
+const page = `<ul>{{#items}}<li class="{{kind}}">{{name}}</li>{{/items}}</ul>{{{footer}}}`
+
+func parse(t testing.TB, src []byte) *tree_sitter.Tree {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())); err != nil {
+		t.Fatal(err)
+	}
+	return parser.Parse(src, nil)
+}
+
+// recursive returns the kinds of n and its descendants, walked with
+// Node.Child.
+func recursive(n *tree_sitter.Node, named bool) []string {
+	var kinds []string
+	if !named || n.IsNamed() {
+		kinds = append(kinds, n.Kind())
+	}
+	for i := uint(0); i < n.ChildCount(); i++ {
+		kinds = append(kinds, recursive(n.Child(i), named)...)
+	}
+	return kinds
+}
+
+func kinds(seq func(func(tree_sitter.Node) bool)) []string {
+	var kinds []string
+	for n := range seq {
+		kinds = append(kinds, n.Kind())
+	}
+	return kinds
+}
+
+func TestPreorder(t *testing.T) {
+	tree := parse(t, []byte(page))
+	defer tree.Close()
+	root := tree.RootNode()
+
+	if got, expected := kinds(walk.Preorder(tree)), recursive(root, false); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Preorder:\n got: %v\nwant: %v", got, expected)
+	}
+	if got, expected := kinds(walk.Named(tree)), recursive(root, true); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Named:\n got: %v\nwant: %v", got, expected)
+	}
+
+	li := root.NamedChild(0).NamedChild(1).NamedChild(1)
+	if got, expected := kinds(walk.PreorderNode(li)), recursive(li, false); !reflect.DeepEqual(got, expected) {
+		t.Errorf("PreorderNode:\n got: %v\nwant: %v", got, expected)
+	}
+}
+
+func TestKind(t *testing.T) {
+	src := []byte(page)
+	tree := parse(t, src)
+	defer tree.Close()
+
+	tests := []struct {
+		kinds    []string
+		expected []string
+	}{
+		{[]string{"mustache_interpolation"}, []string{"{{kind}}", "{{name}}"}},
+		{[]string{"mustache_triple", "html_element"}, []string{
+			`<ul>{{#items}}<li class="{{kind}}">{{name}}</li>{{/items}}</ul>`,
+			`<li class="{{kind}}">{{name}}</li>`,
+			"{{{footer}}}",
+		}},
+		{[]string{"{{{"}, []string{"{{{"}},
+		{[]string{"no_such_kind"}, nil},
+		{nil, nil},
+	}
+	for _, test := range tests {
+		var got []string
+		for n := range walk.Kind(tree, test.kinds...) {
+			got = append(got, n.Utf8Text(src))
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("Kind(%q) = %q, want %q", test.kinds, got, test.expected)
+		}
+	}
+}
+
+func TestBreak(t *testing.T) {
+	tree := parse(t, []byte(page))
+	defer tree.Close()
+
+	var got []string
+	for n := range walk.Named(tree) {
+		if n.Kind() == "mustache_section" {
+			break
+		}
+		got = append(got, n.Kind())
+	}
+	expected := []string{"document", "html_element", "html_start_tag", "html_tag_name"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, want %v", got, expected)
+	}
+}
+
+func TestVisit(t *testing.T) {
+	tree := parse(t, []byte(page))
+	defer tree.Close()
+
+	// Skipping the children of elements leaves the ul and the triple's
+	// parts.
+	var got []string
+	walk.Visit(tree.RootNode(), func(n *tree_sitter.Node) bool {
+		got = append(got, n.Kind())
+		return n.Kind() != "html_element"
+	})
+	expected := []string{"document", "html_element", "mustache_triple", "{{{", "mustache_identifier", "}}}"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, want %v", got, expected)
+	}
+}
+
+func TestChildOfKind(t *testing.T) {
+	src := []byte(page)
+	tree := parse(t, src)
+	defer tree.Close()
+	ul := tree.RootNode().NamedChild(0)
+
+	if tag := walk.ChildOfKind(ul, "html_start_tag"); tag == nil || tag.Utf8Text(src) != "<ul>" {
+		t.Errorf("ChildOfKind(html_start_tag) = %v", tag)
+	}
+	if li := walk.ChildOfKind(ul, "html_element"); li != nil {
+		t.Errorf("ChildOfKind found a grandchild: %s", li.Utf8Text(src))
+	}
+	if got := len(walk.Children(ul)); got != int(ul.ChildCount()) {
+		t.Errorf("Children returned %d nodes, want %d", got, ul.ChildCount())
+	}
+}
+
+// go-tree-sitter allocates every node it returns, so the iterators can make
+// no fewer than one allocation per node visited. They must not make more.
+func TestAllocations(t *testing.T) {
+	small := parse(t, []byte(page))
+	defer small.Close()
+	large := parse(t, []byte(strings.Repeat(page, 100)))
+	defer large.Close()
+
+	for _, test := range []struct {
+		name string
+		seq  func(*tree_sitter.Tree) func(func(tree_sitter.Node) bool)
+	}{
+		{"Preorder", func(tree *tree_sitter.Tree) func(func(tree_sitter.Node) bool) { return walk.Preorder(tree) }},
+		{"Named", func(tree *tree_sitter.Tree) func(func(tree_sitter.Node) bool) { return walk.Named(tree) }},
+		{"Kind", func(tree *tree_sitter.Tree) func(func(tree_sitter.Node) bool) {
+			return walk.Kind(tree, "mustache_interpolation", "html_element")
+		}},
+	} {
+		allocs := func(tree *tree_sitter.Tree) float64 {
+			return testing.AllocsPerRun(100, func() {
+				for range test.seq(tree) {
+				}
+			})
+		}
+		nodes := float64(len(kinds(walk.Preorder(large))) - len(kinds(walk.Preorder(small))))
+		if extra := allocs(large) - allocs(small); extra > nodes {
+			t.Errorf("%s: %v allocations for %v more nodes", test.name, extra, nodes)
+		}
+	}
+}
+
+func BenchmarkPreorder(b *testing.B) {
+	src, err := os.ReadFile(filepath.Join("..", "testdata", "page.mustache"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	tree := parse(b, src)
+	defer tree.Close()
+
+	b.Run("Walk", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for range walk.Preorder(tree) {
+			}
+		}
+	})
+	b.Run("Child", func(b *testing.B) {
+		var visit func(n *tree_sitter.Node)
+		visit = func(n *tree_sitter.Node) {
+			for i := uint(0); i < n.ChildCount(); i++ {
+				visit(n.Child(i))
+			}
+		}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			visit(tree.RootNode())
+		}
+	})
+}
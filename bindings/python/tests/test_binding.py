@@ -10,3 +10,16 @@ class TestLanguage(TestCase):
             tree_sitter.Language(tree_sitter_htmlmustache.language())
         except Exception:
             self.fail("Error loading Htmlmustache grammar")
+
+    def test_parse(self):
+        parser = tree_sitter.Parser(tree_sitter.Language(tree_sitter_htmlmustache.language()))
+        tree = parser.parse(b"<p>{{#items}}{{name}}{{/items}}</p>")
+        self.assertFalse(tree.root_node.has_error)
+        self.assertEqual(tree.root_node.child(0).type, "html_element")
+
+    def test_queries(self):
+        language = tree_sitter.Language(tree_sitter_htmlmustache.language())
+        for name in tree_sitter_htmlmustache.__all__:
+            if name.endswith("_QUERY"):
+                with self.subTest(name):
+                    tree_sitter.Query(language, getattr(tree_sitter_htmlmustache, name))
@@ -17,5 +17,12 @@ fn main() {
         println!("cargo:rerun-if-changed={}", scanner_path.to_str().unwrap());
     }
 
+    for header in ["tag.h", "mustache_tag.h"] {
+        println!(
+            "cargo:rerun-if-changed={}",
+            src_dir.join(header).to_str().unwrap()
+        );
+    }
+
     c_config.compile("tree-sitter-htmlmustache");
 }
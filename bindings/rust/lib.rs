@@ -5,6 +5,9 @@
 //!
 //! ```
 //! let code = r#"
+//! <ul>
+//!   {{#items}}<li>{{name}}</li>{{/items}}
+//! </ul>
 //! "#;
 //! let mut parser = tree_sitter::Parser::new();
 //! let language = tree_sitter_htmlmustache::LANGUAGE;
@@ -32,12 +35,27 @@ pub const LANGUAGE: LanguageFn = unsafe { LanguageFn::from_raw(tree_sitter_htmlm
 /// [`node-types.json`]: https://tree-sitter.github.io/tree-sitter/using-parsers/6-static-node-types
 pub const NODE_TYPES: &str = include_str!("../../src/node-types.json");
 
-// NOTE: uncomment these to include any queries that this grammar contains:
+/// The syntax highlighting query for this grammar.
+pub const HIGHLIGHTS_QUERY: &str = include_str!("../../queries/highlights.scm");
 
-// pub const HIGHLIGHTS_QUERY: &str = include_str!("../../queries/highlights.scm");
-// pub const INJECTIONS_QUERY: &str = include_str!("../../queries/injections.scm");
-// pub const LOCALS_QUERY: &str = include_str!("../../queries/locals.scm");
-// pub const TAGS_QUERY: &str = include_str!("../../queries/tags.scm");
+/// The language injection query for this grammar: JavaScript in `<script>`,
+/// CSS in `<style>` and the like.
+pub const INJECTIONS_QUERY: &str = include_str!("../../queries/injections.scm");
+
+/// The local-variable query for this grammar, with sections as scopes.
+pub const LOCALS_QUERY: &str = include_str!("../../queries/locals.scm");
+
+/// The symbol tagging query for this grammar.
+pub const TAGS_QUERY: &str = include_str!("../../queries/tags.scm");
+
+/// The code folding query for this grammar.
+pub const FOLDS_QUERY: &str = include_str!("../../queries/folds.scm");
+
+/// The indentation query for this grammar, with nvim-treesitter capture names.
+pub const INDENTS_QUERY: &str = include_str!("../../queries/indents.scm");
+
+/// The text object query for this grammar, with nvim-treesitter capture names.
+pub const TEXTOBJECTS_QUERY: &str = include_str!("../../queries/textobjects.scm");
 
 #[cfg(test)]
 mod tests {
@@ -48,4 +66,22 @@ mod tests {
             .set_language(&super::LANGUAGE.into())
             .expect("Error loading Htmlmustache parser");
     }
+
+    #[test]
+    fn test_queries_compile() {
+        let language: tree_sitter::Language = super::LANGUAGE.into();
+        for (name, source) in [
+            ("highlights", super::HIGHLIGHTS_QUERY),
+            ("injections", super::INJECTIONS_QUERY),
+            ("locals", super::LOCALS_QUERY),
+            ("tags", super::TAGS_QUERY),
+            ("folds", super::FOLDS_QUERY),
+            ("indents", super::INDENTS_QUERY),
+            ("textobjects", super::TEXTOBJECTS_QUERY),
+        ] {
+            if let Err(err) = tree_sitter::Query::new(&language, source) {
+                panic!("{name} query: {err}");
+            }
+        }
+    }
 }
@@ -1,12 +1,21 @@
 import XCTest
 import SwiftTreeSitter
-import TreeSitterHtmlmustache
+import TreeSitterHTMLMustache
 
-final class TreeSitterHtmlmustacheTests: XCTestCase {
+final class TreeSitterHTMLMustacheTests: XCTestCase {
     func testCanLoadGrammar() throws {
         let parser = Parser()
         let language = Language(language: tree_sitter_htmlmustache())
         XCTAssertNoThrow(try parser.setLanguage(language),
-                         "Error loading Htmlmustache grammar")
+                         "Error loading HTMLMustache grammar")
+    }
+
+    func testParse() throws {
+        let parser = Parser()
+        try parser.setLanguage(Language(language: tree_sitter_htmlmustache()))
+        let tree = try XCTUnwrap(parser.parse("<p>{{#items}}{{name}}{{/items}}</p>"))
+        let root = try XCTUnwrap(tree.rootNode)
+        XCTAssertEqual(root.nodeType, "document")
+        XCTAssertEqual(root.child(at: 0)?.nodeType, "html_element")
     }
 }
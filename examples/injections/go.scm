@@ -0,0 +1,28 @@
+; Go: a string literal marked with a /* htmlmustache */ comment, or passed to
+; a Render or Compile function of a mustache/handlebars package.
+;
+;   page := /* htmlmustache */ `<h1>{{title}}</h1>`
+;   mustache.Render(`<p>{{name}}</p>`, data)
+
+((comment) @_marker
+  .
+  [
+    (raw_string_literal (raw_string_literal_content) @injection.content)
+    (interpreted_string_literal (interpreted_string_literal_content) @injection.content)
+  ]
+  (#match? @_marker "^/\\*\\s*(htmlmustache|mustache|handlebars)\\s*\\*/$")
+  (#set! injection.language "htmlmustache"))
+
+(call_expression
+  function: (selector_expression
+    operand: (identifier) @_package
+    field: (field_identifier) @_function)
+  arguments: (argument_list
+    .
+    [
+      (raw_string_literal (raw_string_literal_content) @injection.content)
+      (interpreted_string_literal (interpreted_string_literal_content) @injection.content)
+    ])
+  (#any-of? @_package "mustache" "raymond" "handlebars")
+  (#match? @_function "^(Render|Parse|Compile|MustParse)")
+  (#set! injection.language "htmlmustache"))
@@ -0,0 +1,12 @@
+; JavaScript and TypeScript: a template literal tagged mustache, hbs or
+; handlebars. The literal text around ${...} substitutions is one template.
+;
+;   const page = hbs`<h1>{{title}}</h1>`;
+
+(call_expression
+  function: (identifier) @_tag
+  arguments: (template_string
+    (string_fragment) @injection.content)
+  (#any-of? @_tag "mustache" "hbs" "handlebars")
+  (#set! injection.language "htmlmustache")
+  (#set! injection.combined))
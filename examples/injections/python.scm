@@ -0,0 +1,31 @@
+; Python: a string assigned to a name ending in _TEMPLATE, or passed to
+; chevron.render or pystache.render. Implicitly concatenated literals are
+; one template.
+;
+;   PAGE_TEMPLATE = "<h1>{{title}}</h1>"
+;   chevron.render("<p>{{name}}</p>" "<p>{{email}}</p>", data)
+
+(assignment
+  left: (identifier) @_name
+  right: [
+    (string (string_content) @injection.content)
+    (concatenated_string (string (string_content) @injection.content))
+  ]
+  (#match? @_name "_TEMPLATE$")
+  (#set! injection.language "htmlmustache")
+  (#set! injection.combined))
+
+(call
+  function: (attribute
+    object: (identifier) @_module
+    attribute: (identifier) @_function)
+  arguments: (argument_list
+    .
+    [
+      (string (string_content) @injection.content)
+      (concatenated_string (string (string_content) @injection.content))
+    ])
+  (#any-of? @_module "chevron" "pystache")
+  (#eq? @_function "render")
+  (#set! injection.language "htmlmustache")
+  (#set! injection.combined))
@@ -0,0 +1,12 @@
+; Ruby: a heredoc whose delimiter names the template language.
+;
+;   template = <<~MUSTACHE
+;     <h1>{{title}}</h1>
+;   MUSTACHE
+
+(heredoc_body
+  (heredoc_content) @injection.content
+  (heredoc_end) @_end
+  (#match? @_end "^(MUSTACHE|HANDLEBARS|HBS)$")
+  (#set! injection.language "htmlmustache")
+  (#set! injection.combined))
@@ -1,7 +1,14 @@
-module github.com/tree-sitter/tree-sitter-html
+module github.com/reteps/tree-sitter-htmlmustache
 
-go 1.22
+go 1.23
 
-require github.com/tree-sitter/go-tree-sitter v0.24.0
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/fsnotify/fsnotify v1.10.1
+	github.com/tree-sitter/go-tree-sitter v0.25.0
+)
 
-require github.com/mattn/go-pointer v0.0.1 // indirect
+require (
+	github.com/mattn/go-pointer v0.0.1 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+)
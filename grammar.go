@@ -0,0 +1,10 @@
+// Package grammar embeds the generated grammar files so the Go bindings can
+// expose them; go:embed cannot reach src/ from bindings/go.
+package grammar
+
+import _ "embed"
+
+// NodeTypesSource is the content of src/node-types.json.
+//
+//go:embed src/node-types.json
+var NodeTypesSource []byte
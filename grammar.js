@@ -8,51 +8,198 @@
 /// <reference types="tree-sitter-cli/dsl" />
 // @ts-check
 
+/**
+ * Matches a default-delimiter mustache token or its custom-delimiter
+ * counterpart from the external scanner. The custom token is aliased to the
+ * default spelling so queries work regardless of the active delimiters.
+ *
+ * @param {string} token
+ * @param {RuleOrLiteral} custom
+ * @returns {ChoiceRule}
+ */
+const delimited = (token, custom) => choice(trimmable(token), alias(custom, token));
+
+/**
+ * Matches a default-delimiter mustache token, or the same token with a
+ * Handlebars `~` on its inner side: `{{~` trims the whitespace before the
+ * tag and `~}}` the whitespace after it. The tilde spelling is aliased to
+ * the plain one and is the trim_before or trim_after field of the tag.
+ *
+ * @param {string} token
+ * @returns {ChoiceRule}
+ */
+function trimmable(token) {
+  if (token.startsWith('{{')) {
+    return choice(token, field('trim_before', alias('{{~' + token.slice(2), token)));
+  }
+  // The closing tilde of a triple goes between the braces: {{~{html}~}}.
+  const trimmed = token === '}}}' ? '}~}}' : '~' + token;
+  return choice(token, field('trim_after', alias(trimmed, token)));
+}
+
 module.exports = grammar({
   name: 'htmlmustache',
 
   extras: ($) => [$.html_comment, /\s+/],
 
+  // Queries can match any node of these kinds as, say, (_mustache_node), and
+  // node-types.json lists the kinds in each.
+  supertypes: ($) => [
+    $._node,
+    $._html_node,
+    $._mustache_node,
+    $._mustache_expression,
+    $._attribute,
+  ],
+
+  // The names of tags are inlined into them, so that a name without
+  // interpolations is a token of the tag itself.
+  inline: ($) => [
+    $._html_start_tag_name_field,
+    $._html_end_tag_name_field,
+    $._html_erroneous_end_tag_name_field,
+  ],
+
   externals: ($) => [
     $._html_start_tag_name,
     $._html_script_start_tag_name,
     $._html_style_start_tag_name,
     $._html_raw_start_tag_name,
     $._html_end_tag_name,
-    $.html_erroneous_end_tag_name,
+    $._html_erroneous_end_tag_name,
+    // The tag names with interpolations in them, up to the first one, as
+    // the h of <h{{level}}-x>; after an interpolation, more of the name or
+    // the opening delimiter of the next one
+    $._html_start_tag_name_prefix,
+    $._html_end_tag_name_prefix,
+    $._html_erroneous_end_tag_name_prefix,
+    $._html_tag_name_part,
+    $._html_tag_name_open,
     '/>',
     $._html_implicit_end_tag,
-    $.html_raw_text,
+    $._html_raw_text,
     $.html_comment,
     // Mustache externals
     $._mustache_start_tag_name,
     $._mustache_end_tag_name,
     $._mustache_erroneous_end_tag_name,
     $._mustache_end_tag_html_implicit_end_tag,
+    // Set Delimiter tag ({{=<% %>=}}) and the tokens used once custom
+    // delimiters are active
+    $._mustache_set_delimiter_start,
+    $._mustache_delimiter,
+    $._mustache_set_delimiter_end,
+    $._mustache_custom_open,
+    $._mustache_custom_triple_open,
+    $._mustache_custom_section_open,
+    $._mustache_custom_inverted_section_open,
+    $._mustache_custom_end_open,
+    $._mustache_custom_comment_open,
+    $._mustache_custom_partial_open,
+    $._mustache_custom_close,
+    $._mustache_custom_triple_close,
+    $._mustache_custom_content,
+    $._mustache_custom_text,
+    $._mustache_custom_ampersand_open,
+    $._mustache_long_comment_open,
+    // The opening line of frontmatter, only valid at the document start
+    $._frontmatter_yaml_start,
+    $._frontmatter_toml_start,
+    // The delimiters of a conditional comment, which the scanner tells apart
+    // from an html_comment
+    $._html_conditional_comment_start,
+    $._html_conditional_comment_reveal,
+    $._html_conditional_comment_end,
+    // The quote opening a quoted attribute value, and the text in it and in
+    // mustache comments and partials there, which ends at the same quote
+    $._html_attribute_single_quote,
+    $._html_attribute_double_quote,
+    $._html_attribute_text,
+    $._html_attribute_mustache_content,
   ],
 
   rules: {
-    document: ($) => repeat($._node),
+    document: ($) => seq(optional($.frontmatter), repeat($._node)),
+
+    // YAML (---) or TOML (+++) frontmatter at the top of the template, as
+    // static site generators read it. The scanner returns the opening line,
+    // since text would otherwise be the longer match there. The content is
+    // every line up to the closing delimiter line.
+    frontmatter: ($) =>
+      choice(
+        seq(
+          alias($._frontmatter_yaml_start, '---'),
+          optional(alias(/(([^-\r\n][^\r\n]*|-([^-\r\n][^\r\n]*)?|--([^-\r\n][^\r\n]*)?)?\r?\n)+/, $.frontmatter_content)),
+          '---',
+        ),
+        seq(
+          alias($._frontmatter_toml_start, '+++'),
+          optional(alias(/(([^+\r\n][^\r\n]*|\+([^+\r\n][^\r\n]*)?|\+\+([^+\r\n][^\r\n]*)?)?\r?\n)+/, $.frontmatter_content)),
+          '+++',
+        ),
+      ),
 
     html_doctype: ($) =>
       seq('<!', alias($._html_doctype, 'doctype'), /[^>]+/, '>'),
 
     _html_doctype: (_) => /[Dd][Oo][Cc][Tt][Yy][Pp][Ee]/,
 
+    // <![CDATA[ ... ]]>, used by XML templates such as RSS feeds and in
+    // inline <svg> and <math>
+    html_cdata: (_) =>
+      token(seq('<![CDATA[', /([^\]]|\][^\]]|\]\]+[^\]>])*\]*/, ']]>')),
+
+    // <?xml ... ?> and other processing instructions. Like HTML parsers,
+    // this ends at the first '>'.
+    html_processing_instruction: (_) => token(seq('<?', /[^>]*/, '>')),
+
+    // An IE conditional comment, as email templates use to target Outlook.
+    // <!--[if mso]>...<![endif]--> hides its content from other clients and
+    // <!--[if !mso]><!-->...<!--<![endif]--> reveals it to them. The content
+    // is markup like any other, and elements opened in it end with it, as
+    // they do in a section.
+    html_conditional_comment: ($) =>
+      seq(
+        alias($._html_conditional_comment_start, '<!--[if'),
+        field('condition', $.html_conditional_comment_condition),
+        ']>',
+        optional(
+          field('reveal', alias($._html_conditional_comment_reveal, '<!-->')),
+        ),
+        field(
+          'content',
+          repeat(
+            choice(
+              $._node,
+              $._html_implicit_end_tag,
+              $._mustache_end_tag_html_implicit_end_tag,
+            ),
+          ),
+        ),
+        alias($._html_conditional_comment_end, '<![endif]-->'),
+      ),
+
+    html_conditional_comment_condition: (_) => /[^\s\]]+(\s+[^\s\]]+)*/,
+
     _node: ($) => choice($._html_node, $._mustache_node),
 
     _html_node: ($) =>
       choice(
         $.html_doctype,
+        $.html_conditional_comment,
+        $.html_cdata,
+        $.html_processing_instruction,
         $.html_entity,
         $.html_element,
         $.html_script_element,
         $.html_style_element,
         $.html_raw_element,
+        alias($.html_rcdata_element, $.html_element),
         $.html_erroneous_end_tag,
         $.text,
         alias($._text_brace, $.text),
         alias($._text_ampersand, $.text),
+        alias($._mustache_custom_text, $.text),
       ),
 
     _mustache_node: ($) =>
@@ -60,163 +207,522 @@ module.exports = grammar({
         $.mustache_triple,
         $.mustache_comment,
         $.mustache_partial,
+        $.mustache_dynamic_partial,
         $.mustache_section,
         $.mustache_inverted_section,
+        $.mustache_parent,
+        $.mustache_block,
         $.mustache_interpolation,
+        $.mustache_set_delimiter,
       ),
     // Mustache rules - order matters for parsing precedence
-    mustache_triple: ($) => seq('{{{', $._mustache_expression, '}}}'),
+    // Tag delimiters in each of their spellings. They are rules of their own
+    // so that the spellings share the states of the tag that follows; the
+    // trim_before and trim_after fields are inherited by the tag.
+    _mustache_open: ($) => delimited('{{', $._mustache_custom_open),
+    _mustache_triple_open: ($) => delimited('{{{', $._mustache_custom_triple_open),
+    _mustache_ampersand_open: ($) => delimited('{{&', $._mustache_custom_ampersand_open),
+    _mustache_section_open: ($) => delimited('{{#', $._mustache_custom_section_open),
+    _mustache_inverted_section_open: ($) => delimited('{{^', $._mustache_custom_inverted_section_open),
+    _mustache_close: ($) => delimited('}}', $._mustache_custom_close),
+    _mustache_triple_close: ($) => delimited('}}}', $._mustache_custom_triple_close),
+    _mustache_end_open: ($) => delimited('{{/', $._mustache_custom_end_open),
+    _mustache_default_close: (_) => trimmable('}}'),
+    _mustache_comment_open: (_) => trimmable('{{!'),
+    _mustache_partial_open: (_) => trimmable('{{>'),
+    _mustache_parent_open: (_) => trimmable('{{<'),
+    _mustache_block_open: (_) => trimmable('{{$'),
+
+    // Unescaped output: {{{name}}} and its {{&name}} spelling. Both are
+    // mustache_triple rather than separate node kinds, so queries and tools
+    // treat them the same.
+    mustache_triple: ($) =>
+      choice(
+        seq(
+          $._mustache_triple_open,
+          field('expression', $._mustache_call),
+          $._mustache_triple_close,
+        ),
+        seq(
+          $._mustache_ampersand_open,
+          field('expression', $._mustache_call),
+          $._mustache_close,
+        ),
+      ),
 
     mustache_comment: ($) =>
-      seq('{{!', alias($._mustache_content, $.mustache_comment_content), '}}'),
+      choice(
+        seq(
+          $._mustache_comment_open,
+          field('content', alias($._mustache_content, $.mustache_comment_content)),
+          $._mustache_default_close,
+        ),
+        seq(
+          alias($._mustache_custom_comment_open, '{{!'),
+          field('content', alias($._mustache_custom_content, $.mustache_comment_content)),
+          alias($._mustache_custom_close, '}}'),
+        ),
+        // {{!-- ... --}} runs to the closing --}}, so it may contain }}. The
+        // scanner only opens it when a closing --}} follows.
+        seq(
+          alias($._mustache_long_comment_open, '{{!--'),
+          optional(
+            field(
+              'content',
+              alias($._mustache_long_comment_content, $.mustache_comment_content),
+            ),
+          ),
+          alias(/--+\}\}/, '--}}'),
+        ),
+      ),
 
-    _mustache_content: ($) => /[^}]+/,
+    // A `~` before the closing braces is a trim marker rather than content.
+    _mustache_content: ($) => /([^}~]|~+[^}~])+/,
+    // Partial names end at a newline or `<`, so an unclosed {{> tag only
+    // takes the rest of its line
+    _mustache_partial_content: ($) => /([^}~<\r\n]|~+[^}~<\r\n])+/,
+
+    // Anything without --}}, ending before the dashes of the closing tag
+    _mustache_long_comment_content: (_) =>
+      /([^-]|-(--*\}-)*([^-]|--*([^-}]|\}[^-}])))+/,
 
     mustache_partial: ($) =>
-      seq('{{>', alias($._mustache_content, $.mustache_partial_content), '}}'),
+      choice(
+        seq(
+          $._mustache_partial_open,
+          field('name', alias($._mustache_partial_content, $.mustache_partial_content)),
+          $._mustache_default_close,
+        ),
+        seq(
+          alias($._mustache_custom_partial_open, '{{>'),
+          field('name', alias($._mustache_custom_content, $.mustache_partial_content)),
+          alias($._mustache_custom_close, '}}'),
+        ),
+      ),
+
+    // {{>*name}} loads the partial named by the value of `name`, from the
+    // optional dynamic names module
+    mustache_dynamic_partial: ($) =>
+      seq(
+        $._mustache_dynamic_partial_open,
+        field('name', $._mustache_expression),
+        $._mustache_default_close,
+      ),
+
+    _mustache_dynamic_partial_open: (_) =>
+      choice(
+        alias(token(seq('{{>', /\s*/, '*')), '{{>*'),
+        field('trim_before', alias(token(seq('{{~>', /\s*/, '*')), '{{>*')),
+      ),
+
+    mustache_interpolation: ($) =>
+      seq(
+        $._mustache_open,
+        field('expression', $._mustache_call),
+        $._mustache_close,
+      ),
 
-    mustache_interpolation: ($) => seq('{{', $._mustache_expression, '}}'),
+    // {{=<% %>=}} - the scanner tracks the new delimiters for the rest of
+    // the document (or until the next set delimiter tag)
+    mustache_set_delimiter: ($) =>
+      seq(
+        alias($._mustache_set_delimiter_start, '{{='),
+        field('open_delimiter', alias($._mustache_delimiter, $.mustache_delimiter)),
+        field('close_delimiter', alias($._mustache_delimiter, $.mustache_delimiter)),
+        alias($._mustache_set_delimiter_end, '=}}'),
+      ),
 
     mustache_section: ($) =>
       seq(
-        $.mustache_section_begin,
-        repeat($._node),
-        choice($.mustache_section_end, $.mustache_erroneous_section_end),
+        field('open', $.mustache_section_begin),
+        field('content', repeat(choice($._node, $.mustache_else))),
+        field(
+          'close',
+          choice($.mustache_section_end, $.mustache_erroneous_section_end),
+        ),
       ),
 
     mustache_section_begin: ($) =>
-      seq('{{#', alias($._mustache_start_tag_name, $.mustache_tag_name), '}}'),
+      seq(
+        $._mustache_section_open,
+        field('name', alias($._mustache_start_tag_name, $.mustache_tag_name)),
+        $._mustache_block_tail,
+      ),
 
     mustache_section_end: ($) =>
-      seq('{{/', alias($._mustache_end_tag_name, $.mustache_tag_name), '}}'),
+      seq(
+        $._mustache_end_open,
+        field('name', alias($._mustache_end_tag_name, $.mustache_tag_name)),
+        $._mustache_close,
+      ),
 
     mustache_erroneous_section_end: ($) =>
       seq(
-        '{{/',
-        alias(
-          $._mustache_erroneous_end_tag_name,
-          $.mustache_erroneous_tag_name,
+        $._mustache_end_open,
+        field(
+          'name',
+          alias(
+            $._mustache_erroneous_end_tag_name,
+            $.mustache_erroneous_tag_name,
+          ),
         ),
-        '}}',
+        $._mustache_close,
       ),
 
     mustache_inverted_section: ($) =>
       seq(
-        $.mustache_inverted_section_begin,
-        repeat($._node),
-        choice(
-          $.mustache_inverted_section_end,
-          $.mustache_erroneous_inverted_section_end,
+        field('open', $.mustache_inverted_section_begin),
+        field('content', repeat(choice($._node, $.mustache_else))),
+        field(
+          'close',
+          choice(
+            alias($.mustache_section_end, $.mustache_inverted_section_end),
+            alias(
+              $.mustache_erroneous_section_end,
+              $.mustache_erroneous_inverted_section_end,
+            ),
+          ),
         ),
       ),
 
     mustache_inverted_section_begin: ($) =>
-      seq('{{^', alias($._mustache_start_tag_name, $.mustache_tag_name), '}}'),
+      seq(
+        $._mustache_inverted_section_open,
+        field('name', alias($._mustache_start_tag_name, $.mustache_tag_name)),
+        $._mustache_block_tail,
+      ),
+
+    // Template inheritance, from the optional Mustache inheritance module.
+    // {{<parent}}...{{/parent}} renders the partial `parent` with the blocks
+    // inside it, {{$name}}...{{/name}}, replacing the parent's blocks of the
+    // same name. A block outside a parent is a default that can be
+    // replaced. The begin tags take only the default delimiters; the end
+    // tags are section ends under another name, and content may hold an
+    // {{else}} as section content does, so that the three kinds of block
+    // share their states.
+    mustache_parent: ($) =>
+      seq(
+        field('open', $.mustache_parent_begin),
+        field('content', repeat($._node)),
+        field(
+          'close',
+          choice(
+            alias($.mustache_section_end, $.mustache_parent_end),
+            alias(
+              $.mustache_erroneous_section_end,
+              $.mustache_erroneous_parent_end,
+            ),
+          ),
+        ),
+      ),
 
-    mustache_inverted_section_end: ($) =>
-      seq('{{/', alias($._mustache_end_tag_name, $.mustache_tag_name), '}}'),
+    mustache_parent_begin: ($) =>
+      seq(
+        $._mustache_parent_open,
+        field('name', alias($._mustache_start_tag_name, $.mustache_tag_name)),
+        $._mustache_default_close,
+      ),
 
-    mustache_erroneous_inverted_section_end: ($) =>
+    mustache_block: ($) =>
       seq(
-        '{{/',
-        alias(
-          $._mustache_erroneous_end_tag_name,
-          $.mustache_erroneous_tag_name,
+        field('open', $.mustache_block_begin),
+        field('content', repeat($._node)),
+        field(
+          'close',
+          choice(
+            alias($.mustache_section_end, $.mustache_block_end),
+            alias(
+              $.mustache_erroneous_section_end,
+              $.mustache_erroneous_block_end,
+            ),
+          ),
         ),
-        '}}',
+      ),
+
+    mustache_block_begin: ($) =>
+      seq(
+        $._mustache_block_open,
+        field('name', alias($._mustache_start_tag_name, $.mustache_tag_name)),
+        $._mustache_default_close,
       ),
 
     _mustache_expression: ($) =>
-      choice($.mustache_path_expression, $.mustache_identifier, '.'),
+      choice(
+        $.mustache_path_expression,
+        $.mustache_identifier,
+        $.mustache_implicit_iterator,
+      ),
+
+    // {{.}}, the current item of the enclosing section
+    mustache_implicit_iterator: (_) => '.',
+
+    // Handlebars extensions. Plain Mustache templates never use them; they
+    // parse Handlebars files such as {{#if cond}}...{{else}}...{{/if}}.
+    _mustache_call: ($) =>
+      choice($._mustache_expression, $.mustache_helper_call),
+
+    // {{format date "short" locale=lang}}
+    mustache_helper_call: ($) =>
+      seq(field('helper', $._mustache_expression), $._mustache_arguments),
+
+    _mustache_arguments: ($) =>
+      repeat1(
+        choice(
+          field('param', $._mustache_param),
+          field('hash', $.mustache_hash_pair),
+        ),
+      ),
 
-    mustache_identifier: ($) => /[a-zA-Z0-9_-]+/,
+    _mustache_param: ($) =>
+      choice($._mustache_expression, $.mustache_string, $.mustache_subexpression),
 
+    // (concat a "-" b)
+    mustache_subexpression: ($) =>
+      seq(
+        '(',
+        field('helper', $._mustache_expression),
+        optional($._mustache_arguments),
+        ')',
+      ),
+
+    mustache_hash_pair: ($) =>
+      seq(
+        field('key', $.mustache_identifier),
+        '=',
+        field('value', $._mustache_param),
+      ),
+
+    mustache_string: (_) => /"[^"]*"|'[^']*'/,
+
+    // The rest of a section begin tag or an {{else if}} after its name, one
+    // rule so that the three of them share its states.
+    _mustache_block_tail: ($) =>
+      seq(
+        optional($._mustache_arguments),
+        optional(field('block_params', $.mustache_block_params)),
+        $._mustache_close,
+      ),
+
+    // {{#each items as |item index|}}
+    mustache_block_params: ($) =>
+      seq(
+        alias(token(seq('as', /\s+/, '|')), 'as |'),
+        repeat1($.mustache_identifier),
+        '|',
+      ),
+
+    // {{else}} and chained {{else if cond}} or {{else each b as |x|}} inside
+    // a block. The scanner ends HTML elements opened in the branch, as it
+    // does for {{/name}}. A plain {{else}} is one token, so {{~else}} and
+    // {{else~}} mark it as trimmed and {{~else~}} as trim_before only; its
+    // text has both tildes.
+    mustache_else: ($) =>
+      choice(
+        alias(token(seq('{{', /\s*/, 'else', /\s*/, '}}')), '{{else}}'),
+        field(
+          'trim_before',
+          alias(token(seq('{{~', /\s*/, 'else', /\s*/, optional('~'), '}}')), '{{else}}'),
+        ),
+        field(
+          'trim_after',
+          alias(token(seq('{{', /\s*/, 'else', /\s*/, '~}}')), '{{else}}'),
+        ),
+        seq(
+          $._mustache_else_open,
+          field('name', alias($.mustache_identifier, $.mustache_tag_name)),
+          $._mustache_block_tail,
+        ),
+      ),
+
+    _mustache_else_open: (_) =>
+      choice(
+        alias(token(seq('{{', /\s*/, 'else', /\s+/)), '{{else'),
+        field(
+          'trim_before',
+          alias(token(seq('{{~', /\s*/, 'else', /\s+/)), '{{else'),
+        ),
+      ),
+
+    // Names may have letters and digits of any script, and start with the @
+    // of a Handlebars data variable such as @index or @root.
+    mustache_identifier: ($) => /@?[\p{L}\p{M}\p{N}_$-]+/,
+
+    // The dots of a path are immediate, so that {{helper a .}} passes the
+    // current item rather than continuing the path.
     mustache_path_expression: ($) =>
-      seq($.mustache_identifier, repeat1(seq('.', $.mustache_identifier))),
+      seq(
+        field('key', $.mustache_identifier),
+        repeat1(seq(token.immediate('.'), field('key', $.mustache_identifier))),
+      ),
 
     html_element: ($) =>
       choice(
         seq(
-          $.html_start_tag,
-          repeat($._node), // 0 or more times
-          choice(
-            $.html_end_tag,
-            $._html_implicit_end_tag,
-            alias(
-              $._mustache_end_tag_html_implicit_end_tag,
-              $.html_forced_end_tag,
+          field('open', $.html_start_tag),
+          // Element and conditional comment content take the tokens that end
+          // each other so that they share their parse states. The scanner
+          // only returns the ones that end the innermost of the two.
+          field('content', repeat(choice($._node, $._html_conditional_comment_end))),
+          field(
+            'close',
+            choice(
+              $.html_end_tag,
+              $._html_implicit_end_tag,
+              alias(
+                $._mustache_end_tag_html_implicit_end_tag,
+                $.html_forced_end_tag,
+              ),
             ),
           ),
         ),
-        $.html_self_closing_tag,
+        field('open', $.html_self_closing_tag),
       ),
 
     html_script_element: ($) =>
       seq(
-        alias($.html_script_start_tag, $.html_start_tag),
-        optional($.html_raw_text),
-        $.html_end_tag,
+        field('open', alias($.html_script_start_tag, $.html_start_tag)),
+        optional(field('content', $.html_raw_text)),
+        field('close', choice($.html_end_tag, $._html_implicit_end_tag)),
       ),
 
     html_style_element: ($) =>
       seq(
-        alias($.html_style_start_tag, $.html_start_tag),
-        optional($.html_raw_text),
-        $.html_end_tag,
+        field('open', alias($.html_style_start_tag, $.html_start_tag)),
+        optional(field('content', $.html_raw_text)),
+        field('close', choice($.html_end_tag, $._html_implicit_end_tag)),
       ),
 
     html_raw_element: ($) =>
       seq(
-        alias($.html_raw_start_tag, $.html_start_tag),
-        optional($.html_raw_text),
-        $.html_end_tag,
+        field('open', alias($.html_raw_start_tag, $.html_start_tag)),
+        optional(field('content', $.html_raw_text)),
+        field('close', choice($.html_end_tag, $._html_implicit_end_tag)),
+      ),
+
+    // <textarea> holds text rather than elements, as in HTML, but mustache
+    // tags inside it are still parsed. The scanner returns raw text after a
+    // start tag only for <textarea>. <pre> is deliberately not one: HTML
+    // parses elements in it, as in <pre><code>, and its text nodes keep
+    // their whitespace, which the formatter copies as written.
+    html_rcdata_element: ($) =>
+      seq(
+        field('open', $.html_start_tag),
+        field('content', $.html_raw_text),
+        field('close', choice($.html_end_tag, $._html_implicit_end_tag)),
+      ),
+
+    // Text with the mustache tags in it as children, e.g. `var data =
+    // {{json}};` in a <script>. The text between the tags keeps its
+    // whitespace, and is what gets injected. Section tags stay part of the
+    // text.
+    html_raw_text: ($) =>
+      seq(
+        $._html_raw_text,
+        repeat(
+          seq(
+            choice(
+              $.mustache_interpolation,
+              $.mustache_triple,
+              $.mustache_comment,
+              $.mustache_partial,
+            ),
+            optional($._html_raw_text),
+          ),
+        ),
       ),
 
     html_start_tag: ($) =>
       seq(
         '<',
-        alias($._html_start_tag_name, $.html_tag_name),
-        repeat($._attribute),
+        $._html_start_tag_name_field,
+        field('attribute', repeat($._attribute)),
         '>',
       ),
 
     html_script_start_tag: ($) =>
       seq(
         '<',
-        alias($._html_script_start_tag_name, $.html_tag_name),
-        repeat($._attribute),
+        field('name', alias($._html_script_start_tag_name, $.html_tag_name)),
+        field('attribute', repeat($._attribute)),
         '>',
       ),
 
     html_style_start_tag: ($) =>
       seq(
         '<',
-        alias($._html_style_start_tag_name, $.html_tag_name),
-        repeat($._attribute),
+        field('name', alias($._html_style_start_tag_name, $.html_tag_name)),
+        field('attribute', repeat($._attribute)),
         '>',
       ),
 
     html_raw_start_tag: ($) =>
       seq(
         '<',
-        alias($._html_raw_start_tag_name, $.html_tag_name),
-        repeat($._attribute),
+        field('name', alias($._html_raw_start_tag_name, $.html_tag_name)),
+        field('attribute', repeat($._attribute)),
         '>',
       ),
 
     html_self_closing_tag: ($) =>
       seq(
         '<',
-        alias($._html_start_tag_name, $.html_tag_name),
-        repeat($._attribute),
+        $._html_start_tag_name_field,
+        field('attribute', repeat($._attribute)),
         '/>',
       ),
 
     html_end_tag: ($) =>
-      seq('</', alias($._html_end_tag_name, $.html_tag_name), '>'),
+      seq('</', $._html_end_tag_name_field, '>'),
 
     html_erroneous_end_tag: ($) =>
-      seq('</', $.html_erroneous_end_tag_name, '>'),
+      seq('</', $._html_erroneous_end_tag_name_field, '>'),
+
+    // A tag name may have interpolations in it, as <{{tag}}> and
+    // <h{{level}}> do. The scanner reads the whole name to track open
+    // elements, but its prefix token ends before the first interpolation,
+    // so the interpolations are nodes of their own.
+    _html_start_tag_name_field: ($) =>
+      choice(
+        field('name', alias($._html_start_tag_name, $.html_tag_name)),
+        field('name', alias($._html_interpolated_start_tag_name, $.html_tag_name)),
+      ),
+
+    _html_end_tag_name_field: ($) =>
+      choice(
+        field('name', alias($._html_end_tag_name, $.html_tag_name)),
+        field('name', alias($._html_interpolated_end_tag_name, $.html_tag_name)),
+      ),
+
+    _html_erroneous_end_tag_name_field: ($) =>
+      choice(
+        field('name', alias($._html_erroneous_end_tag_name, $.html_erroneous_end_tag_name)),
+        field(
+          'name',
+          alias($._html_interpolated_erroneous_end_tag_name, $.html_erroneous_end_tag_name),
+        ),
+      ),
+
+    _html_interpolated_start_tag_name: ($) =>
+      seq($._html_start_tag_name_prefix, repeat1($._html_tag_name_rest)),
+
+    _html_interpolated_end_tag_name: ($) =>
+      seq($._html_end_tag_name_prefix, repeat1($._html_tag_name_rest)),
+
+    _html_interpolated_erroneous_end_tag_name: ($) =>
+      seq($._html_erroneous_end_tag_name_prefix, repeat1($._html_tag_name_rest)),
+
+    _html_tag_name_rest: ($) =>
+      choice(
+        $._html_tag_name_part,
+        alias($._html_tag_name_interpolation, $.mustache_interpolation),
+      ),
+
+    _html_tag_name_interpolation: ($) =>
+      seq(
+        alias($._html_tag_name_open, '{{'),
+        field('expression', $._mustache_call),
+        $._mustache_close,
+      ),
 
     _attribute: ($) =>
       choice(
@@ -225,13 +731,17 @@ module.exports = grammar({
         $.mustache_interpolation,
         $.mustache_triple,
       ),
+    // An unquoted value is an html_attribute_value and a quoted one an
+    // html_quoted_attribute_value with the quotes as its first and last
+    // children, so tools can keep the quoting style when they rewrite it.
     html_attribute: ($) =>
       seq(
-        seq(
-          $.html_attribute_name,
-          optional(
-            seq(
-              '=',
+        field('name', $.html_attribute_name),
+        optional(
+          seq(
+            '=',
+            field(
+              'value',
               choice(
                 $.html_attribute_value,
                 $.html_quoted_attribute_value,
@@ -253,15 +763,15 @@ module.exports = grammar({
 
     mustache_inverted_section_attribute: ($) =>
       seq(
-        $.mustache_inverted_section_begin,
-        repeat1($._attribute),
-        $.mustache_inverted_section_end,
+        field('open', $.mustache_inverted_section_begin),
+        field('content', repeat1(choice($._attribute, $.mustache_else))),
+        field('close', alias($.mustache_section_end, $.mustache_inverted_section_end)),
       ),
     mustache_section_attribute: ($) =>
       seq(
-        $.mustache_section_begin,
-        repeat1($._attribute),
-        $.mustache_section_end,
+        field('open', $.mustache_section_begin),
+        field('content', repeat1(choice($._attribute, $.mustache_else))),
+        field('close', $.mustache_section_end),
       ),
 
     html_attribute_name: (_) => /[^<>{}"'/=\s]+/,
@@ -274,154 +784,93 @@ module.exports = grammar({
     html_entity: (_) =>
       /&(#([xX][0-9a-fA-F]{1,6}|[0-9]{1,5})|[A-Za-z]{1,30});?/,
 
-    _html_attribute_value_no_single_quote: ($) => /[^'{}]+/,
-    _html_attribute_value_no_double_quote: ($) => /[^"{}]+/,
     // Single braces that aren't part of {{ or }}
     _single_curly_brace: ($) => /[{}]/,
-    _attribute_value_no_double_quote: ($) =>
-      choice(
-        $._mustache_node,
-        alias($._html_attribute_value_no_double_quote, $.text),
-      ),
-    _attribute_value_no_single_quote: ($) =>
+    _attribute_value: ($) =>
       choice(
-        $._mustache_node,
-        alias($._html_attribute_value_no_single_quote, $.text),
-      ),
-    _mustache_section_no_single_quote: ($) =>
-      seq(
-        $.mustache_section_begin,
-        repeat(
-          alias(
-            $._attribute_value_no_single_quote,
-            $._mustache_section_content,
-          ),
-        ),
-        $.mustache_section_end,
-      ),
-    _mustache_section_no_double_quote: ($) =>
-      seq(
-        $.mustache_section_begin,
-        repeat(
-          alias(
-            $._attribute_value_no_double_quote,
-            '_mustache_section_content',
-          ),
-        ),
-        $.mustache_section_end,
+        $._mustache_attribute_value_node,
+        $.html_entity,
+        alias($._html_attribute_text, $.text),
+        alias($._text_ampersand, $.text),
       ),
-    _mustache_inverted_section_no_single_quote: ($) =>
+    _mustache_attribute_value_section: ($) =>
       seq(
-        $.mustache_inverted_section_begin,
-        repeat(
-          alias(
-            $._attribute_value_no_single_quote,
-            $._mustache_inverted_section_content,
+        field('open', $.mustache_section_begin),
+        field(
+          'content',
+          repeat(
+            choice(
+              alias($._attribute_value, '_mustache_section_content'),
+              $.mustache_else,
+            ),
           ),
         ),
-        $.mustache_inverted_section_end,
+        field('close', $.mustache_section_end),
       ),
-    _mustache_inverted_section_no_double_quote: ($) =>
+    _mustache_attribute_value_inverted_section: ($) =>
       seq(
-        $.mustache_inverted_section_begin,
-        repeat(
-          alias(
-            $._attribute_value_no_double_quote,
-            $._mustache_inverted_section_content,
+        field('open', $.mustache_inverted_section_begin),
+        field(
+          'content',
+          repeat(
+            choice(
+              alias($._attribute_value, $._mustache_inverted_section_content),
+              $.mustache_else,
+            ),
           ),
         ),
-        $.mustache_inverted_section_end,
+        field('close', alias($.mustache_section_end, $.mustache_inverted_section_end)),
       ),
-    _mustache_comment_no_single_quote: ($) =>
+    _mustache_attribute_value_comment: ($) =>
       seq(
         '{{!',
-        alias(
-          $._html_attribute_value_no_single_quote,
-          $._mustache_comment_content,
-        ),
+        alias($._html_attribute_mustache_content, $.mustache_comment_content),
         '}}',
       ),
-    _mustache_comment_no_double_quote: ($) =>
-      seq(
-        '{{!',
-        alias(
-          $._html_attribute_value_no_double_quote,
-          $._mustache_comment_content,
-        ),
-        '}}',
-      ),
-    _mustache_partial_no_single_quote: ($) =>
+    _mustache_attribute_value_partial: ($) =>
       seq(
         '{{>',
-        alias(
-          $._html_attribute_value_no_single_quote,
-          $._mustache_partial_content,
-        ),
+        alias($._html_attribute_mustache_content, $.mustache_partial_content),
         '}}',
       ),
-    _mustache_partial_no_double_quote: ($) =>
-      seq(
-        '{{>',
-        alias(
-          $._html_attribute_value_no_double_quote,
-          $._mustache_partial_content,
-        ),
-        '}}',
-      ),
-    _mustache_node_no_single_quote: ($) =>
+    _mustache_attribute_value_node: ($) =>
       choice(
         $.mustache_triple,
         $.mustache_interpolation,
-        alias($._mustache_comment_no_single_quote, $.mustache_comment),
-        alias($._mustache_partial_no_single_quote, $.mustache_partial),
-        alias($._mustache_section_no_single_quote, $.mustache_section),
+        alias($._mustache_attribute_value_comment, $.mustache_comment),
+        alias($._mustache_attribute_value_partial, $.mustache_partial),
+        alias($._mustache_attribute_value_section, $.mustache_section),
         alias(
-          $._mustache_inverted_section_no_single_quote,
-          $.mustache_inverted_section,
-        ),
-      ),
-    _mustache_node_no_double_quote: ($) =>
-      choice(
-        $.mustache_triple,
-        $.mustache_interpolation,
-        alias($._mustache_comment_no_double_quote, $.mustache_comment),
-        alias($._mustache_partial_no_double_quote, $.mustache_partial),
-        alias($._mustache_section_no_double_quote, $.mustache_section),
-        alias(
-          $._mustache_inverted_section_no_double_quote,
+          $._mustache_attribute_value_inverted_section,
           $.mustache_inverted_section,
         ),
       ),
 
+    // Both quotes share the content rules: the scanner returns the opening
+    // quote and ends the text in the value at the matching one, and stops
+    // text at & so that entities are nodes of their own.
     html_quoted_attribute_value: ($) =>
       choice(
         seq(
-          "'",
-          repeat(
-            choice(
-              alias(
-                $._html_attribute_value_no_single_quote,
-                $.html_attribute_value,
-              ),
-              $._mustache_node_no_single_quote,
-              alias($._single_curly_brace, $.html_attribute_value),
-            ),
-          ),
+          alias($._html_attribute_single_quote, "'"),
+          optional($._html_quoted_attribute_content),
           "'",
         ),
         seq(
+          alias($._html_attribute_double_quote, '"'),
+          optional($._html_quoted_attribute_content),
           '"',
-          repeat(
-            choice(
-              alias(
-                $._html_attribute_value_no_double_quote,
-                $.html_attribute_value,
-              ),
-              $._mustache_node_no_double_quote,
-              alias($._single_curly_brace, $.html_attribute_value),
-            ),
-          ),
-          '"',
+        ),
+      ),
+
+    _html_quoted_attribute_content: ($) =>
+      repeat1(
+        choice(
+          alias($._html_attribute_text, $.html_attribute_value),
+          $.html_entity,
+          alias($._text_ampersand, $.html_attribute_value),
+          $._mustache_attribute_value_node,
+          alias($._single_curly_brace, $.html_attribute_value),
         ),
       ),
 
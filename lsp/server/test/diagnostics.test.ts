@@ -572,6 +572,13 @@ describe('Diagnostics', () => {
       expect(diagnostics.some(d => d.message.includes('Self-closing non-void'))).toBe(false);
     });
 
+    it('long mustache comment disables a specific rule', () => {
+      const tree = parseText('{{!-- htmlmustache-disable selfClosingNonVoidTags --}}\n<div/>');
+      const diagnostics = getDiagnostics(tree);
+
+      expect(diagnostics.some(d => d.message.includes('Self-closing non-void'))).toBe(false);
+    });
+
     it('multiple rules disabled with multiple comments', () => {
       const tree = parseText('<!-- htmlmustache-disable selfClosingNonVoidTags -->\n{{! htmlmustache-disable unescapedEntities }}\n<div/><p>a > b</p>');
       const diagnostics = getDiagnostics(tree);
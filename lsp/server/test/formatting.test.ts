@@ -709,6 +709,11 @@ describe('Mustache Spaces', () => {
       expect(result).toBe('<div>{{! comment }}</div>\n');
     });
 
+    it('keeps the dashes of long comments', () => {
+      const result = formatWithSpaces('<div>{{!--comment--}}</div>', false);
+      expect(result).toBe('<div>{{!-- comment --}}</div>\n');
+    });
+
     it('removes spaces from force-inlined sections', () => {
       const result = formatWithSpaces('<p>figure{{# plural }}s{{/ plural }}.</p>', false);
       expect(result).toBe('<p>figure{{#plural}}s{{/plural}}.</p>\n');
@@ -0,0 +1,3 @@
+-- Neovim detects *.mustache and *.hogan as mustache, and *.hbs and
+-- *.handlebars as handlebars. Parse both with this grammar.
+vim.treesitter.language.register('htmlmustache', { 'mustache', 'handlebars' })
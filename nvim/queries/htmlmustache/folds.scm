@@ -0,0 +1,11 @@
+[
+  (html_element)
+  (html_script_element)
+  (html_style_element)
+  (html_raw_element)
+  (html_comment)
+  (html_conditional_comment)
+  (mustache_section)
+  (mustache_inverted_section)
+  (mustache_comment)
+] @fold
@@ -0,0 +1,128 @@
+; queries/highlights.scm with nvim-treesitter capture names
+
+; HTML
+(html_tag_name) @tag
+(html_erroneous_end_tag_name) @tag
+(html_doctype) @keyword.directive
+(html_attribute_name) @tag.attribute
+(html_attribute_value) @string
+(html_quoted_attribute_value) @string
+(html_entity) @character.special
+(html_comment) @comment @spell
+(html_conditional_comment_condition) @keyword.directive
+
+[
+  "<!--[if"
+  "]>"
+  "<!-->"
+  "<![endif]-->"
+] @comment
+
+[
+  "<"
+  ">"
+  "</"
+  "/>"
+  "<!"
+] @tag.delimiter
+
+"=" @operator
+
+; Mustache
+(mustache_partial_content) @module
+(mustache_implicit_iterator) @variable.builtin
+(mustache_string) @string
+(mustache_comment) @comment @spell
+
+; Names are variables, except for the helper a tag with arguments calls, such
+; as if in {{#if cond}}...{{/if}} or format in {{format date}}
+(mustache_path_expression
+  key: (mustache_identifier) @variable)
+(_ expression: (mustache_identifier) @variable)
+(_ param: (mustache_identifier) @variable)
+(_ helper: (mustache_identifier) @function.call)
+(mustache_dynamic_partial
+  name: (mustache_identifier) @variable)
+(mustache_hash_pair
+  value: (mustache_identifier) @variable)
+(mustache_hash_pair
+  key: (mustache_identifier) @property)
+(mustache_block_params
+  (mustache_identifier) @variable.parameter)
+
+[
+  (mustache_section_begin name: (_) @variable !param !hash)
+  (mustache_inverted_section_begin name: (_) @variable !param !hash)
+  (mustache_section
+    open: (mustache_section_begin !param !hash)
+    close: (mustache_section_end name: (_) @variable))
+  (mustache_inverted_section
+    open: (mustache_inverted_section_begin !param !hash)
+    close: (mustache_inverted_section_end name: (_) @variable))
+  (mustache_parent_begin name: (_) @variable)
+  (mustache_parent_end name: (_) @variable)
+  (mustache_block_begin name: (_) @variable)
+  (mustache_block_end name: (_) @variable)
+]
+
+[
+  (mustache_section_begin name: (_) @function.call param: (_))
+  (mustache_section_begin name: (_) @function.call hash: (_))
+  (mustache_inverted_section_begin name: (_) @function.call param: (_))
+  (mustache_inverted_section_begin name: (_) @function.call hash: (_))
+  (mustache_section
+    open: (mustache_section_begin param: (_))
+    close: (mustache_section_end name: (_) @function.call))
+  (mustache_section
+    open: (mustache_section_begin hash: (_))
+    close: (mustache_section_end name: (_) @function.call))
+  (mustache_inverted_section
+    open: (mustache_inverted_section_begin param: (_))
+    close: (mustache_inverted_section_end name: (_) @function.call))
+  (mustache_inverted_section
+    open: (mustache_inverted_section_begin hash: (_))
+    close: (mustache_inverted_section_end name: (_) @function.call))
+  (mustache_else name: (_) @function.call)
+]
+
+(mustache_path_expression
+  "." @punctuation.delimiter)
+
+[
+  "{{"
+  "}}"
+  "{{{"
+  "}}}"
+  "{{&"
+  "{{>"
+  "{{>*"
+  "{{<"
+  "{{$"
+  "{{#"
+  "{{/"
+  "{{^"
+  "{{!"
+  "{{!--"
+  "--}}"
+  "{{="
+  "=}}"
+  "&"
+] @punctuation.special
+
+[
+  "{{else}}"
+  "{{else"
+] @keyword.conditional
+
+"as |" @keyword
+
+(mustache_delimiter) @punctuation.special
+
+(mustache_block_params
+  "|" @punctuation.bracket)
+
+(mustache_subexpression
+  [
+    "("
+    ")"
+  ] @punctuation.bracket)
@@ -0,0 +1,46 @@
+; Elements and sections indent their content
+((html_element
+  (html_start_tag
+    (html_tag_name) @_tag)) @indent.begin
+  (#not-any-of? @_tag
+    "area" "base" "basefont" "bgsound" "br" "col" "command" "embed" "frame"
+    "hr" "image" "img" "input" "isindex" "keygen" "link" "menuitem" "meta"
+    "nextid" "param" "source" "track" "wbr"))
+
+[
+  (html_conditional_comment)
+  (mustache_section)
+  (mustache_inverted_section)
+] @indent.begin
+
+(html_conditional_comment
+  "<![endif]-->" @indent.branch)
+
+; Closing tags line up with their opening tag
+(html_end_tag) @indent.branch
+
+[
+  (mustache_section_end)
+  (mustache_inverted_section_end)
+] @indent.branch
+
+; The last child has already been ended when a new line is opened after it
+(html_end_tag
+  ">" @indent.end)
+
+(html_self_closing_tag
+  "/>" @indent.end)
+
+(mustache_section_end
+  "}}" @indent.end)
+
+(mustache_inverted_section_end
+  "}}" @indent.end)
+
+; Keep the author's indentation in comments and raw text
+[
+  (html_comment)
+  (mustache_comment)
+] @indent.ignore
+
+(html_raw_text) @indent.auto
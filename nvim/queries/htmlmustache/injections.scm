@@ -0,0 +1,19 @@
+; queries/injections.scm, and comment tags in comments
+
+((html_script_element
+  (html_raw_text) @injection.content)
+ (#set! injection.language "javascript"))
+
+((html_style_element
+  (html_raw_text) @injection.content)
+ (#set! injection.language "css"))
+
+((html_raw_element
+  (html_raw_text) @injection.content)
+ (#set! injection.language "markdown"))
+
+([
+  (html_comment)
+  (mustache_comment)
+] @injection.content
+ (#set! injection.language "comment"))
@@ -0,0 +1,29 @@
+package queries_test
+
+import (
+	"testing"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/bindings/go/querytest"
+	"github.com/reteps/tree-sitter-htmlmustache/queries"
+)
+
+func TestHighlightAssertions(t *testing.T) {
+	language := tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())
+	query, err := queries.Highlights(language)
+	if err != nil {
+		t.Fatal(err)
+	}
+	querytest.Run(t, language, "../test/highlight/*", query)
+}
+
+func TestInjectionAssertions(t *testing.T) {
+	language := tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())
+	query, err := queries.Injections(language)
+	if err != nil {
+		t.Fatal(err)
+	}
+	querytest.Run(t, language, "../test/injections/*", query)
+}
@@ -0,0 +1,92 @@
+package queries
+
+import (
+	"sync"
+	"unsafe"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// Compiling a query walks the language's parse table for every pattern, so
+// the accessors below compile each query once per language and hand out
+// the same *tree_sitter.Query afterwards. A query may be run from several
+// goroutines at once, each with its own QueryCursor. The cached queries are
+// shared: callers must not Close them, nor disable their patterns or
+// captures.
+
+// Highlights returns highlights.scm compiled for language.
+func Highlights(language *tree_sitter.Language) (*tree_sitter.Query, error) {
+	return compiled(language, "highlights", HighlightsSource)
+}
+
+// Injections returns injections.scm compiled for language.
+func Injections(language *tree_sitter.Language) (*tree_sitter.Query, error) {
+	return compiled(language, "injections", InjectionsSource)
+}
+
+// Locals returns locals.scm compiled for language.
+func Locals(language *tree_sitter.Language) (*tree_sitter.Query, error) {
+	return compiled(language, "locals", LocalsSource)
+}
+
+// Tags returns tags.scm compiled for language.
+func Tags(language *tree_sitter.Language) (*tree_sitter.Query, error) {
+	return compiled(language, "tags", TagsSource)
+}
+
+// Folds returns folds.scm compiled for language.
+func Folds(language *tree_sitter.Language) (*tree_sitter.Query, error) {
+	return compiled(language, "folds", FoldsSource)
+}
+
+// Indents returns indents.scm compiled for language.
+func Indents(language *tree_sitter.Language) (*tree_sitter.Query, error) {
+	return compiled(language, "indents", IndentsSource)
+}
+
+// Textobjects returns textobjects.scm compiled for language.
+func Textobjects(language *tree_sitter.Language) (*tree_sitter.Query, error) {
+	return compiled(language, "textobjects", TextobjectsSource)
+}
+
+// cacheKey identifies a query by the C language it is compiled for, as
+// every tree_sitter.NewLanguage call wraps the same language in a new
+// *tree_sitter.Language.
+type cacheKey struct {
+	language unsafe.Pointer
+	name     string
+}
+
+type cacheEntry struct {
+	once  sync.Once
+	query *tree_sitter.Query
+	err   error
+}
+
+var (
+	cacheMu sync.Mutex
+	cache   = map[cacheKey]*cacheEntry{}
+)
+
+// compiled returns source compiled for language, compiling it on first use.
+// A query that fails to compile fails the same way on every call.
+func compiled(language *tree_sitter.Language, name string, source []byte) (*tree_sitter.Query, error) {
+	key := cacheKey{unsafe.Pointer(language.Inner), name}
+	cacheMu.Lock()
+	entry := cache[key]
+	if entry == nil {
+		entry = &cacheEntry{}
+		cache[key] = entry
+	}
+	cacheMu.Unlock()
+
+	entry.once.Do(func() {
+		query, err := tree_sitter.NewQuery(language, string(source))
+		if err != nil {
+			entry.err = err
+			return
+		}
+		entry.query = query
+	})
+	return entry.query, entry.err
+}
@@ -0,0 +1,79 @@
+package queries_test
+
+import (
+	"sync"
+	"testing"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+	tree_sitter_htmlmustache "github.com/reteps/tree-sitter-htmlmustache/bindings/go"
+	"github.com/reteps/tree-sitter-htmlmustache/queries"
+)
+
+var accessors = map[string]func(*tree_sitter.Language) (*tree_sitter.Query, error){
+	"highlights":  queries.Highlights,
+	"injections":  queries.Injections,
+	"locals":      queries.Locals,
+	"tags":        queries.Tags,
+	"folds":       queries.Folds,
+	"indents":     queries.Indents,
+	"textobjects": queries.Textobjects,
+}
+
+func TestCompiledQueriesAreCached(t *testing.T) {
+	for name, compile := range accessors {
+		first, err := compile(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language()))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		second, err := compile(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language()))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if first != second {
+			t.Errorf("%s: compiled twice for the same language", name)
+		}
+	}
+	highlights, _ := queries.Highlights(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language()))
+	folds, _ := queries.Folds(tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language()))
+	if highlights == folds {
+		t.Error("highlights and folds share a query")
+	}
+}
+
+func TestCompiledQueriesConcurrently(t *testing.T) {
+	language := tree_sitter.NewLanguage(tree_sitter_htmlmustache.Language())
+	src := []byte("<div>{{#a}}<p>{{b}}</p>{{/a}}</div>")
+
+	var wg sync.WaitGroup
+	counts := make([]int, 8)
+	for i := range counts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			query, err := queries.Highlights(language)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			parser := tree_sitter.NewParser()
+			defer parser.Close()
+			parser.SetLanguage(language)
+			tree := parser.Parse(src, nil)
+			defer tree.Close()
+
+			cursor := tree_sitter.NewQueryCursor()
+			defer cursor.Close()
+			matches := cursor.Matches(query, tree.RootNode(), src)
+			for match := matches.Next(); match != nil; match = matches.Next() {
+				counts[i]++
+			}
+		}()
+	}
+	wg.Wait()
+	for i, n := range counts {
+		if n == 0 || n != counts[0] {
+			t.Fatalf("goroutine %d found %d matches, want %d", i, n, counts[0])
+		}
+	}
+}
@@ -4,7 +4,16 @@
 (html_doctype) @constant
 (html_attribute_name) @attribute
 (html_attribute_value) @string
+(html_entity) @string.special
 (html_comment) @comment
+(html_conditional_comment_condition) @keyword
+
+[
+  "<!--[if"
+  "]>"
+  "<!-->"
+  "<![endif]-->"
+] @comment
 
 [
   "<"
@@ -14,17 +23,92 @@
 ] @punctuation.bracket
 
 ; Mustache
-(mustache_tag_name) @variable
-(mustache_identifier) @variable
+(mustache_partial_content) @variable
+(mustache_implicit_iterator) @variable.builtin
+(mustache_string) @string
 (mustache_comment) @comment
 
+; Names are variables, except for the helper a tag with arguments calls, such
+; as if in {{#if cond}}...{{/if}} or format in {{format date}}
+(mustache_path_expression
+  key: (mustache_identifier) @variable)
+(_ expression: (mustache_identifier) @variable)
+(_ param: (mustache_identifier) @variable)
+(_ helper: (mustache_identifier) @function)
+(mustache_dynamic_partial
+  name: (mustache_identifier) @variable)
+(mustache_hash_pair
+  value: (mustache_identifier) @variable)
+(mustache_hash_pair
+  key: (mustache_identifier) @property)
+(mustache_block_params
+  (mustache_identifier) @variable.parameter)
+
+[
+  (mustache_section_begin name: (_) @variable !param !hash)
+  (mustache_inverted_section_begin name: (_) @variable !param !hash)
+  (mustache_section
+    open: (mustache_section_begin !param !hash)
+    close: (mustache_section_end name: (_) @variable))
+  (mustache_inverted_section
+    open: (mustache_inverted_section_begin !param !hash)
+    close: (mustache_inverted_section_end name: (_) @variable))
+  (mustache_parent_begin name: (_) @variable)
+  (mustache_parent_end name: (_) @variable)
+  (mustache_block_begin name: (_) @variable)
+  (mustache_block_end name: (_) @variable)
+]
+
+[
+  (mustache_section_begin name: (_) @function param: (_))
+  (mustache_section_begin name: (_) @function hash: (_))
+  (mustache_inverted_section_begin name: (_) @function param: (_))
+  (mustache_inverted_section_begin name: (_) @function hash: (_))
+  (mustache_section
+    open: (mustache_section_begin param: (_))
+    close: (mustache_section_end name: (_) @function))
+  (mustache_section
+    open: (mustache_section_begin hash: (_))
+    close: (mustache_section_end name: (_) @function))
+  (mustache_inverted_section
+    open: (mustache_inverted_section_begin param: (_))
+    close: (mustache_inverted_section_end name: (_) @function))
+  (mustache_inverted_section
+    open: (mustache_inverted_section_begin hash: (_))
+    close: (mustache_inverted_section_end name: (_) @function))
+  (mustache_else name: (_) @function)
+]
+
 [
   "{{"
   "}}"
   "{{{"
   "}}}"
+  "{{&"
   "{{>"
+  "{{>*"
+  "{{<"
+  "{{$"
   "{{#"
   "{{/"
   "{{^"
+  "{{="
+  "=}}"
+  "{{else}}"
+  "{{else"
+  "as |"
 ] @keyword
+
+(mustache_delimiter) @punctuation.special
+
+(mustache_block_params
+  "|" @punctuation.bracket)
+
+(mustache_subexpression
+  [
+    "("
+    ")"
+  ] @punctuation.bracket)
+
+(mustache_hash_pair
+  "=" @operator)
@@ -1,3 +1,6 @@
+; Mustache tags in the text are children of html_raw_text, so they are left
+; out of the injected ranges.
+
 ((html_script_element
   (html_raw_text) @injection.content)
  (#set! injection.language "javascript"))
@@ -5,3 +8,22 @@
 ((html_style_element
   (html_raw_text) @injection.content)
  (#set! injection.language "css"))
+
+; Custom raw tags are configurable in src/custom_raw_tags.h, so only inject
+; markdown into the markdown element.
+((html_raw_element
+  (html_start_tag
+    (html_tag_name) @_tag)
+  (html_raw_text) @injection.content)
+ (#match? @_tag "^[Mm][Aa][Rr][Kk][Dd][Oo][Ww][Nn]$")
+ (#set! injection.language "markdown"))
+
+((frontmatter
+  "---"
+  (frontmatter_content) @injection.content)
+ (#set! injection.language "yaml"))
+
+((frontmatter
+  "+++"
+  (frontmatter_content) @injection.content)
+ (#set! injection.language "toml"))
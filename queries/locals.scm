@@ -0,0 +1,21 @@
+; A section pushes its value onto the context stack, so names inside it
+; resolve against the section first. Inverted sections render with the
+; enclosing context and do not open a scope.
+(mustache_section) @local.scope
+
+(mustache_section_begin
+  (mustache_tag_name) @local.definition)
+
+(mustache_inverted_section_begin
+  (mustache_tag_name) @local.reference)
+
+; Only the first key of a dotted name is looked up on the context stack
+(mustache_interpolation
+  (mustache_identifier) @local.reference)
+
+(mustache_triple
+  (mustache_identifier) @local.reference)
+
+(mustache_path_expression
+  .
+  (mustache_identifier) @local.reference)
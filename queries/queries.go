@@ -0,0 +1,41 @@
+// Package queries embeds the tree-sitter query files shipped with the
+// htmlmustache grammar so Go embedders don't have to vendor them, and
+// compiles them on demand with a cache shared by the whole process.
+package queries
+
+import _ "embed"
+
+// HighlightsSource is the content of highlights.scm.
+//
+//go:embed highlights.scm
+var HighlightsSource []byte
+
+// InjectionsSource is the content of injections.scm.
+//
+//go:embed injections.scm
+var InjectionsSource []byte
+
+// LocalsSource is the content of locals.scm.
+//
+//go:embed locals.scm
+var LocalsSource []byte
+
+// TagsSource is the content of tags.scm.
+//
+//go:embed tags.scm
+var TagsSource []byte
+
+// FoldsSource is the content of folds.scm.
+//
+//go:embed folds.scm
+var FoldsSource []byte
+
+// IndentsSource is the content of indents.scm.
+//
+//go:embed indents.scm
+var IndentsSource []byte
+
+// TextobjectsSource is the content of textobjects.scm.
+//
+//go:embed textobjects.scm
+var TextobjectsSource []byte
@@ -0,0 +1,40 @@
+; Sections name the data they render
+(mustache_section
+  (mustache_section_begin
+    (mustache_tag_name) @name)) @definition.section
+
+(mustache_inverted_section
+  (mustache_inverted_section_begin
+    (mustache_tag_name) @name)) @definition.section
+
+; Partials are defined by the template file they are loaded from, so
+; templates only reference them
+(mustache_partial
+  (mustache_partial_content) @name) @reference.partial
+
+; Element ids are link and script targets
+(html_element
+  (html_start_tag
+    (html_attribute
+      (html_attribute_name) @_attribute
+      [
+        (html_attribute_value) @name
+        (html_quoted_attribute_value
+          .
+          (html_attribute_value) @name
+          .)
+      ]))
+  (#eq? @_attribute "id")) @definition.id
+
+(html_element
+  (html_self_closing_tag
+    (html_attribute
+      (html_attribute_name) @_attribute
+      [
+        (html_attribute_value) @name
+        (html_quoted_attribute_value
+          .
+          (html_attribute_value) @name
+          .)
+      ]))
+  (#eq? @_attribute "id")) @definition.id
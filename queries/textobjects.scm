@@ -0,0 +1,91 @@
+; Sections
+[
+  (mustache_section)
+  (mustache_inverted_section)
+] @block.outer
+
+(mustache_section
+  (mustache_section_begin)
+  .
+  (_) @block.inner
+  .
+  (mustache_section_end))
+
+(mustache_section
+  (mustache_section_begin)
+  .
+  (_) @_start
+  (_) @_end
+  .
+  (mustache_section_end)
+  (#make-range! "block.inner" @_start @_end))
+
+(mustache_inverted_section
+  (mustache_inverted_section_begin)
+  .
+  (_) @block.inner
+  .
+  (mustache_inverted_section_end))
+
+(mustache_inverted_section
+  (mustache_inverted_section_begin)
+  .
+  (_) @_start
+  (_) @_end
+  .
+  (mustache_inverted_section_end)
+  (#make-range! "block.inner" @_start @_end))
+
+; Elements
+[
+  (html_element)
+  (html_script_element)
+  (html_style_element)
+  (html_raw_element)
+] @tag.outer
+
+(html_element
+  (html_start_tag)
+  .
+  (_) @tag.inner
+  .
+  (html_end_tag))
+
+(html_element
+  (html_start_tag)
+  .
+  (_) @_start
+  (_) @_end
+  .
+  (html_end_tag)
+  (#make-range! "tag.inner" @_start @_end))
+
+[
+  (html_script_element
+    (html_raw_text) @tag.inner)
+  (html_style_element
+    (html_raw_text) @tag.inner)
+  (html_raw_element
+    (html_raw_text) @tag.inner)
+]
+
+; Attributes, including attributes wrapped in a section
+[
+  (html_attribute)
+  (mustache_attribute)
+] @attribute.outer
+
+(html_attribute
+  (html_attribute_value) @attribute.inner)
+
+(html_quoted_attribute_value
+  .
+  (_) @attribute.inner
+  .)
+
+(html_quoted_attribute_value
+  .
+  (_) @_start
+  (_) @_end
+  .
+  (#make-range! "attribute.inner" @_start @_end))
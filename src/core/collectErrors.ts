@@ -101,8 +101,8 @@ function parseDisableDirective(node: BalanceNode, customRuleIds?: Set<string>):
     const match = node.text.match(/^<!--([\s\S]*)-->$/);
     if (match) inner = match[1].trim();
   } else {
-    const match = node.text.match(/^\{\{!([\s\S]*)\}\}$/);
-    if (match) inner = match[1].trim();
+    const match = node.text.match(/^\{\{!(?:--([\s\S]*)--|([\s\S]*))\}\}$/);
+    if (match) inner = (match[1] ?? match[2]).trim();
   }
   if (!inner) return null;
   const prefix = 'htmlmustache-disable ';
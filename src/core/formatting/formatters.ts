@@ -171,6 +171,9 @@ export function formatNode(
 
     case 'html_comment':
     case 'html_doctype':
+    case 'html_cdata':
+    case 'html_processing_instruction':
+    case 'frontmatter':
     case 'html_entity':
     case 'html_erroneous_end_tag':
       return text(node.text);
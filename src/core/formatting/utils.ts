@@ -68,7 +68,7 @@ export function calculateIndentLevel(
 
 /**
  * Normalize whitespace inside a single mustache expression.
- * Handles triple ({{{...}}}), prefixed ({{#, {{/, {{^, {{!, {{>), and plain ({{...}}).
+ * Handles triple ({{{...}}}), prefixed ({{#, {{/, {{^, {{!, {{!--, {{>), and plain ({{...}}).
  * For multiline comments, preserves internal newlines, only normalizes space adjacent to delimiters.
  */
 export function normalizeMustacheWhitespace(raw: string, addSpaces: boolean): string {
@@ -84,8 +84,16 @@ export function normalizeMustacheWhitespace(raw: string, addSpaces: boolean): st
   // Prefixed: {{#, {{/, {{^, {{!, {{>
   const prefixedMatch = raw.match(/^\{\{([#/^!>])([\s\S]*)\}\}$/);
   if (prefixedMatch) {
-    const prefix = prefixedMatch[1];
-    const inner = prefixedMatch[2];
+    let prefix = prefixedMatch[1];
+    let inner = prefixedMatch[2];
+    let suffix = '';
+
+    // Long comments keep their dashes: {{!-- ... --}}
+    if (prefix === '!' && inner.length >= 4 && inner.startsWith('--') && inner.endsWith('--')) {
+      prefix = '!--';
+      suffix = '--';
+      inner = inner.slice(2, -2);
+    }
 
     // Multiline comments: preserve internal newlines, always use spaces
     if (prefix === '!' && inner.includes('\n')) {
@@ -93,16 +101,16 @@ export function normalizeMustacheWhitespace(raw: string, addSpaces: boolean): st
       const first = lines[0].trimStart();
       const last = lines[lines.length - 1].trimEnd();
       if (lines.length === 1) {
-        return `{{${prefix} ${first} }}`;
+        return `{{${prefix} ${first} ${suffix}}}`;
       }
       const middle = lines.slice(1, -1);
-      return `{{${prefix} ${first}\n${middle.join('\n')}\n${last} }}`;
+      return `{{${prefix} ${first}\n${middle.join('\n')}\n${last} ${suffix}}}`;
     }
 
     const trimmed = inner.trim();
     // Comments always get spaces for readability, regardless of mustacheSpaces setting
     const s = prefix === '!' ? ' ' : space;
-    return `{{${prefix}${s}${trimmed}${s}}}`;
+    return `{{${prefix}${s}${trimmed}${s}${suffix}}}`;
   }
 
   // Plain: {{...}}
@@ -147,10 +155,10 @@ export function getIgnoreDirective(
       inner = match[1].trim();
     }
   } else {
-    // {{! ... }}
-    const match = node.text.match(/^\{\{!([\s\S]*)\}\}$/);
+    // {{! ... }} or {{!-- ... --}}
+    const match = node.text.match(/^\{\{!(?:--([\s\S]*)--|([\s\S]*))\}\}$/);
     if (match) {
-      inner = match[1].trim();
+      inner = (match[1] ?? match[2]).trim();
     }
   }
 
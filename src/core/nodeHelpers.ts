@@ -22,7 +22,7 @@ export const MUSTACHE_SECTION_TYPES = new Set([
 
 export const INTERPOLATION_TYPES = new Set([
   'mustache_interpolation', // {{foo}}
-  'mustache_triple',        // {{{foo}}} — unescaped
+  'mustache_triple',        // {{{foo}}} / {{&foo}} — unescaped
 ]);
 
 export const RAW_CONTENT_ELEMENT_TYPES = new Set([
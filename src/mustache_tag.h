@@ -6,6 +6,10 @@
 typedef struct {
   String tag_name;
   unsigned html_tag_stack_size;
+  // The number of HTML tags left open once the section is rendered: end tags
+  // in the section that close elements opened before it lower it from
+  // html_tag_stack_size.
+  unsigned closed_html_tag_stack_size;
 } MustacheTag;
 
 static inline void mustache_tag_free(MustacheTag *tag) { array_delete(&tag->tag_name); }
@@ -14,6 +18,7 @@ static inline MustacheTag mustache_tag_new() {
   MustacheTag tag;
   tag.tag_name = (String) array_new();
   tag.html_tag_stack_size = 0;
+  tag.closed_html_tag_stack_size = 0;
   return tag;
 }
 
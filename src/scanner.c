@@ -30,6 +30,11 @@ enum TokenType {
     HTML_RAW_START_TAG_NAME,
     HTML_END_TAG_NAME,
     HTML_ERRONEOUS_END_TAG_NAME,
+    HTML_START_TAG_NAME_PREFIX,
+    HTML_END_TAG_NAME_PREFIX,
+    HTML_ERRONEOUS_END_TAG_NAME_PREFIX,
+    HTML_TAG_NAME_PART,
+    HTML_TAG_NAME_OPEN,
     HTML_SELF_CLOSING_TAG_DELIMITER,
     HTML_IMPLICIT_END_TAG,
     HTML_RAW_TEXT,
@@ -39,11 +44,65 @@ enum TokenType {
     MUSTACHE_END_TAG_NAME,
     MUSTACHE_ERRONEOUS_END_TAG_NAME,
     MUSTACHE_END_TAG_HTML_IMPLICIT_END_TAG,
+    // Set delimiter tokens
+    MUSTACHE_SET_DELIMITER_START,
+    MUSTACHE_DELIMITER,
+    MUSTACHE_SET_DELIMITER_END,
+    MUSTACHE_CUSTOM_OPEN,
+    MUSTACHE_CUSTOM_TRIPLE_OPEN,
+    MUSTACHE_CUSTOM_SECTION_OPEN,
+    MUSTACHE_CUSTOM_INVERTED_SECTION_OPEN,
+    MUSTACHE_CUSTOM_END_OPEN,
+    MUSTACHE_CUSTOM_COMMENT_OPEN,
+    MUSTACHE_CUSTOM_PARTIAL_OPEN,
+    MUSTACHE_CUSTOM_CLOSE,
+    MUSTACHE_CUSTOM_TRIPLE_CLOSE,
+    MUSTACHE_CUSTOM_CONTENT,
+    MUSTACHE_CUSTOM_TEXT,
+    MUSTACHE_CUSTOM_AMPERSAND_OPEN,
+    MUSTACHE_LONG_COMMENT_OPEN,
+    FRONTMATTER_YAML_START,
+    FRONTMATTER_TOML_START,
+    // Conditional comment tokens
+    HTML_CONDITIONAL_COMMENT_START,
+    HTML_CONDITIONAL_COMMENT_REVEAL,
+    HTML_CONDITIONAL_COMMENT_END,
+    // Quoted attribute value tokens
+    HTML_ATTRIBUTE_SINGLE_QUOTE,
+    HTML_ATTRIBUTE_DOUBLE_QUOTE,
+    HTML_ATTRIBUTE_TEXT,
+    HTML_ATTRIBUTE_MUSTACHE_CONTENT,
 };
 
+typedef enum {
+    SET_DELIMITER_NONE,
+    SET_DELIMITER_EXPECT_OPEN,
+    SET_DELIMITER_EXPECT_CLOSE,
+    SET_DELIMITER_EXPECT_END,
+} SetDelimiterState;
+
 typedef struct {
     Array(Tag) tags;
     Array(MustacheTag) mustache_tags;
+    // Progress through a {{=<% %>=}} tag.
+    uint8_t set_delimiter_state;
+    // Active delimiters set by {{=...=}}; empty means the default {{ }}.
+    String open_delimiter;
+    String close_delimiter;
+    // Delimiters read from a set delimiter tag that hasn't been closed yet.
+    String pending_open_delimiter;
+    String pending_close_delimiter;
+    // Elements closed inside a section that has just ended, and which end
+    // implicitly after it.
+    uint8_t pending_implicit_end_tags;
+    // Elements sections ended at the end of the section, as <div> is in
+    // {{#a}}<div>{{/a}}, leaving forced_end_tag_depth elements open. End tags
+    // in later sections at that depth pair with them, as in
+    // {{#a}}</div>{{/a}}, rather than closing an element around both.
+    uint8_t forced_end_tags;
+    uint16_t forced_end_tag_depth;
+    // The quote that opened the attribute value being scanned.
+    uint8_t attribute_quote;
 } Scanner;
 
 #define MAX(a, b) ((a) > (b) ? (a) : (b))
@@ -100,8 +159,8 @@ static unsigned serialize(Scanner *scanner, char *buffer) {
         if (name_length > UINT8_MAX) {
             name_length = UINT8_MAX;
         }
-        // position + size(uint8_t) + size(name) + size(unsigned)
-        if (size + 1 + name_length + sizeof(unsigned) >= TREE_SITTER_SERIALIZATION_BUFFER_SIZE) {
+        // position + size(uint8_t) + size(name) + 2 * size(unsigned)
+        if (size + 1 + name_length + 2 * sizeof(unsigned) >= TREE_SITTER_SERIALIZATION_BUFFER_SIZE) {
             break;
         }
         buffer[size++] = (char)name_length;
@@ -109,9 +168,36 @@ static unsigned serialize(Scanner *scanner, char *buffer) {
         size += name_length;
         memcpy(&buffer[size], &tag.html_tag_stack_size, sizeof(unsigned));
         size += sizeof(unsigned);
+        memcpy(&buffer[size], &tag.closed_html_tag_stack_size, sizeof(unsigned));
+        size += sizeof(unsigned);
     }
 
     memcpy(&buffer[mustache_start_offset], &m_serialized_tag_count, sizeof(m_serialized_tag_count));
+
+    // Delimiters
+    const String *delimiters[] = {
+        &scanner->open_delimiter,
+        &scanner->close_delimiter,
+        &scanner->pending_open_delimiter,
+        &scanner->pending_close_delimiter,
+    };
+    unsigned delimiters_size = 6;
+    for (unsigned i = 0; i < 4; i++) {
+        delimiters_size += 1 + delimiters[i]->size;
+    }
+    if (size + delimiters_size <= TREE_SITTER_SERIALIZATION_BUFFER_SIZE) {
+        buffer[size++] = (char)scanner->set_delimiter_state;
+        for (unsigned i = 0; i < 4; i++) {
+            buffer[size++] = (char)delimiters[i]->size;
+            memcpy(&buffer[size], delimiters[i]->contents, delimiters[i]->size);
+            size += delimiters[i]->size;
+        }
+        buffer[size++] = (char)scanner->pending_implicit_end_tags;
+        buffer[size++] = (char)scanner->forced_end_tags;
+        memcpy(&buffer[size], &scanner->forced_end_tag_depth, sizeof(scanner->forced_end_tag_depth));
+        size += sizeof(scanner->forced_end_tag_depth);
+        buffer[size++] = (char)scanner->attribute_quote;
+    }
     return size;
 }
 
@@ -124,6 +210,15 @@ static void deserialize(Scanner *scanner, const char *buffer, unsigned length) {
     }
     array_clear(&scanner->tags);
     array_clear(&scanner->mustache_tags);
+    array_clear(&scanner->open_delimiter);
+    array_clear(&scanner->close_delimiter);
+    array_clear(&scanner->pending_open_delimiter);
+    array_clear(&scanner->pending_close_delimiter);
+    scanner->set_delimiter_state = SET_DELIMITER_NONE;
+    scanner->pending_implicit_end_tags = 0;
+    scanner->forced_end_tags = 0;
+    scanner->forced_end_tag_depth = 0;
+    scanner->attribute_quote = 0;
 
     if (length > 0) {
         unsigned size = 0;
@@ -181,6 +276,8 @@ static void deserialize(Scanner *scanner, const char *buffer, unsigned length) {
                 size += name_length;
                 memcpy(&tag.html_tag_stack_size, &buffer[size], sizeof(unsigned));
                 size += sizeof(unsigned);
+                memcpy(&tag.closed_html_tag_stack_size, &buffer[size], sizeof(unsigned));
+                size += sizeof(unsigned);
                 array_push(&scanner->mustache_tags, tag);
             }
             // add zero tags if we didn't read enough, this is because the
@@ -189,30 +286,221 @@ static void deserialize(Scanner *scanner, const char *buffer, unsigned length) {
                 array_push(&scanner->mustache_tags, mustache_tag_new());
             }
         }
+
+        // Delimiters
+        String *delimiters[] = {
+            &scanner->open_delimiter,
+            &scanner->close_delimiter,
+            &scanner->pending_open_delimiter,
+            &scanner->pending_close_delimiter,
+        };
+        if (size < length) {
+            scanner->set_delimiter_state = (uint8_t)buffer[size++];
+        }
+        for (unsigned i = 0; i < 4 && size < length; i++) {
+            uint8_t delimiter_length = (uint8_t)buffer[size++];
+            array_reserve(delimiters[i], delimiter_length);
+            delimiters[i]->size = delimiter_length;
+            memcpy(delimiters[i]->contents, &buffer[size], delimiter_length);
+            size += delimiter_length;
+        }
+        if (size < length) {
+            scanner->pending_implicit_end_tags = (uint8_t)buffer[size++];
+        }
+        if (size + 1 + sizeof(scanner->forced_end_tag_depth) <= length) {
+            scanner->forced_end_tags = (uint8_t)buffer[size++];
+            memcpy(&scanner->forced_end_tag_depth, &buffer[size], sizeof(scanner->forced_end_tag_depth));
+            size += sizeof(scanner->forced_end_tag_depth);
+        }
+        if (size < length) {
+            scanner->attribute_quote = (uint8_t)buffer[size++];
+        }
     }
 }
 
-static String scan_html_tag_name(TSLexer *lexer) {
-    String tag_name = array_new();
-    while (iswalnum(lexer->lookahead) || lexer->lookahead == '-' || lexer->lookahead == ':') {
-        array_push(&tag_name, towupper(lexer->lookahead));
+static inline bool has_custom_delimiters(const Scanner *scanner) {
+    return scanner->open_delimiter.size > 0;
+}
+
+static inline int32_t close_delimiter_start(const Scanner *scanner) {
+    return has_custom_delimiters(scanner) ? (unsigned char)scanner->close_delimiter.contents[0] : '}';
+}
+
+// Consumes `delimiter` from `index` onwards, stopping at the first mismatch.
+static bool scan_delimiter(TSLexer *lexer, const char *delimiter, unsigned size, unsigned index) {
+    for (; index < size; index++) {
+        if (lexer->lookahead != (unsigned char)delimiter[index]) {
+            return false;
+        }
         advance(lexer);
     }
-    return tag_name;
+    return true;
+}
+
+static inline bool scan_open_delimiter(Scanner *scanner, TSLexer *lexer, unsigned index) {
+    if (has_custom_delimiters(scanner)) {
+        return scan_delimiter(lexer, scanner->open_delimiter.contents, scanner->open_delimiter.size, index);
+    }
+    return scan_delimiter(lexer, "{{", 2, index);
+}
+
+static inline bool scan_close_delimiter(Scanner *scanner, TSLexer *lexer) {
+    if (has_custom_delimiters(scanner)) {
+        return scan_delimiter(lexer, scanner->close_delimiter.contents, scanner->close_delimiter.size, 0);
+    }
+    return scan_delimiter(lexer, "}}", 2, 0);
+}
+
+// Appends c to s in UTF-8, so names that differ outside ASCII stay apart.
+static void push_codepoint(String *s, int32_t c) {
+    if (c < 0x80) {
+        array_push(s, (char)c);
+    } else if (c < 0x800) {
+        array_push(s, (char)(0xC0 | (c >> 6)));
+        array_push(s, (char)(0x80 | (c & 0x3F)));
+    } else if (c < 0x10000) {
+        array_push(s, (char)(0xE0 | (c >> 12)));
+        array_push(s, (char)(0x80 | ((c >> 6) & 0x3F)));
+        array_push(s, (char)(0x80 | (c & 0x3F)));
+    } else {
+        array_push(s, (char)(0xF0 | (c >> 18)));
+        array_push(s, (char)(0x80 | ((c >> 12) & 0x3F)));
+        array_push(s, (char)(0x80 | ((c >> 6) & 0x3F)));
+        array_push(s, (char)(0x80 | (c & 0x3F)));
+    }
+}
+
+// Whether c may be in a tag name. Custom element names may have any
+// character outside ASCII, as <x-ü-component> does, and . and _.
+static inline bool is_tag_name_char(int32_t c) {
+    return iswalnum(c) || c == '-' || c == ':' || c == '.' || c == '_' || c >= 0x80;
 }
 
-static bool scan_html_comment(TSLexer *lexer) {
-    if (lexer->lookahead != '-') {
+// Consumes an interpolation in a tag name, as in <{{tag}}> or <h{{level}}>,
+// and appends it to the name without its spaces, so that </{{ tag }}> ends
+// <{{tag}}>. Sections, partials and other mustache tags are not names. With
+// mark set, the token ends after the opening delimiter.
+static bool scan_tag_name_interpolation(Scanner *scanner, TSLexer *lexer, String *tag_name, bool mark) {
+    if (!scan_open_delimiter(scanner, lexer, 0)) {
         return false;
     }
-    advance(lexer);
-    if (lexer->lookahead != '-') {
+    if (mark) {
+        lexer->mark_end(lexer);
+    }
+    unsigned size = tag_name->size;
+    array_push(tag_name, '{');
+    array_push(tag_name, '{');
+    while (iswspace(lexer->lookahead)) {
+        advance(lexer);
+    }
+    int32_t close = close_delimiter_start(scanner);
+    while (lexer->lookahead != close && !lexer->eof(lexer)) {
+        int32_t c = lexer->lookahead;
+        if (c == '<' || c == '>' || c == '/' || c == '"' || c == '\'' || c == '=' || c == '{' ||
+            (tag_name->size == size + 2 && (c == '#' || c == '^' || c == '!' || c == '&'))) {
+            return false;
+        }
+        if (!iswspace(c)) {
+            push_codepoint(tag_name, towupper(c));
+        }
+        advance(lexer);
+    }
+    if (tag_name->size == size + 2 || !scan_close_delimiter(scanner, lexer)) {
         return false;
     }
-    advance(lexer);
+    array_push(tag_name, '}');
+    array_push(tag_name, '}');
+    return true;
+}
 
-    unsigned dashes = 0;
-    while (lexer->lookahead) {
+// Scans a tag name, with its interpolations. With interpolated non-NULL, the
+// token ends before the first interpolation, so that the parser reads the
+// rest of the name with scan_tag_name_rest, and is empty for a name such as
+// {{tag}}; *interpolated tells whether the name has one.
+static String scan_html_tag_name(Scanner *scanner, TSLexer *lexer, bool *interpolated) {
+    String tag_name = array_new();
+    int32_t open_start = has_custom_delimiters(scanner) ? (unsigned char)scanner->open_delimiter.contents[0] : '{';
+    bool mark = interpolated != NULL, found = false;
+    if (mark) {
+        lexer->mark_end(lexer);
+    }
+    for (;;) {
+        if (is_tag_name_char(lexer->lookahead)) {
+            push_codepoint(&tag_name, towupper(lexer->lookahead));
+            advance(lexer);
+        } else if (lexer->lookahead == open_start && open_start != '<') {
+            unsigned size = tag_name.size;
+            if (!scan_tag_name_interpolation(scanner, lexer, &tag_name, false)) {
+                tag_name.size = size;
+                break;
+            }
+            found = true;
+        } else {
+            break;
+        }
+        if (mark && !found) {
+            lexer->mark_end(lexer);
+        }
+    }
+    if (mark) {
+        *interpolated = found;
+    }
+    return tag_name;
+}
+
+// Scans the rest of a tag name after an interpolation in it, which
+// scan_html_tag_name has read as part of the name: more of the name, or the
+// opening delimiter of the next interpolation.
+static bool scan_tag_name_rest(Scanner *scanner, TSLexer *lexer, const bool *valid_symbols) {
+    int32_t open_start = has_custom_delimiters(scanner) ? (unsigned char)scanner->open_delimiter.contents[0] : '{';
+    if (valid_symbols[HTML_TAG_NAME_PART] && is_tag_name_char(lexer->lookahead)) {
+        while (is_tag_name_char(lexer->lookahead)) {
+            advance(lexer);
+        }
+        lexer->result_symbol = HTML_TAG_NAME_PART;
+        return true;
+    }
+    if (valid_symbols[HTML_TAG_NAME_OPEN] && lexer->lookahead == open_start && open_start != '<') {
+        String tag_name = array_new();
+        bool found = scan_tag_name_interpolation(scanner, lexer, &tag_name, true);
+        array_delete(&tag_name);
+        lexer->result_symbol = HTML_TAG_NAME_OPEN;
+        return found;
+    }
+    return false;
+}
+
+// Whether the innermost open element is in an <svg> or <math> element, and
+// not in one of their HTML integration points such as <foreignObject>.
+static bool in_foreign_content(const Scanner *scanner) {
+    for (unsigned i = scanner->tags.size; i > 0; i--) {
+        const Tag *tag = &scanner->tags.contents[i - 1];
+        if (tag->type == SVG || tag->type == MATH) {
+            return true;
+        }
+        if (tag_is_html_integration_point(tag)) {
+            return false;
+        }
+    }
+    return false;
+}
+
+// Like tag_for_name, but in foreign content the names of HTML void elements
+// are ordinary elements, as <image></image> is in SVG.
+static Tag scan_tag_for_name(const Scanner *scanner, String tag_name) {
+    if (tag_type_for_name(&tag_name) < END_OF_VOID_TAGS && in_foreign_content(scanner)) {
+        Tag tag = tag_new();
+        tag.type = CUSTOM;
+        tag.custom_tag_name = tag_name;
+        return tag;
+    }
+    return tag_for_name(tag_name);
+}
+
+// Lexes the rest of an html_comment up to its -->, once its <!-- has been
+// consumed and the dashes after it counted.
+static bool scan_html_comment_rest(TSLexer *lexer, unsigned dashes) {
+    while (!lexer->eof(lexer)) {
         switch (lexer->lookahead) {
             case '-':
                 ++dashes;
@@ -232,61 +520,12 @@ static bool scan_html_comment(TSLexer *lexer) {
     return false;
 }
 
-static bool scan_raw_text(Scanner *scanner, TSLexer *lexer) {
-    if (scanner->tags.size == 0) {
-        return false;
-    }
-
-    lexer->mark_end(lexer);
-
-    Tag *tag = array_back(&scanner->tags);
-
-#ifdef CUSTOM_RAW_TAGS
-    char end_delimiter_buf[256];
-    const char *end_delimiter;
-    if (tag->type == SCRIPT) {
-        end_delimiter = "</SCRIPT";
-    } else if (tag->type == STYLE) {
-        end_delimiter = "</STYLE";
-    } else if (tag->type == CUSTOM && is_custom_raw_tag(&tag->custom_tag_name)) {
-        end_delimiter_buf[0] = '<';
-        end_delimiter_buf[1] = '/';
-        unsigned len = tag->custom_tag_name.size;
-        if (len > sizeof(end_delimiter_buf) - 3) {
-            len = sizeof(end_delimiter_buf) - 3;
-        }
-        memcpy(end_delimiter_buf + 2, tag->custom_tag_name.contents, len);
-        end_delimiter_buf[2 + len] = '\0';
-        end_delimiter = end_delimiter_buf;
-    } else {
-        return false;
-    }
-#else
-    const char *end_delimiter = tag->type == SCRIPT ? "</SCRIPT" : "</STYLE";
-#endif
-
-    unsigned delimiter_index = 0;
-    while (lexer->lookahead) {
-        if (towupper(lexer->lookahead) == end_delimiter[delimiter_index]) {
-            delimiter_index++;
-            if (delimiter_index == strlen(end_delimiter)) {
-                break;
-            }
-            advance(lexer);
-        } else {
-            delimiter_index = 0;
-            advance(lexer);
-            lexer->mark_end(lexer);
-        }
-    }
-
-    lexer->result_symbol = HTML_RAW_TEXT;
-    return true;
-}
-
 static void pop_html_tag(Scanner *scanner) {
     Tag popped_tag = array_pop(&scanner->tags);
     tag_free(&popped_tag);
+    if (scanner->tags.size < scanner->forced_end_tag_depth) {
+        scanner->forced_end_tags = 0;
+    }
 }
 
 static bool scan_implicit_end_tag(Scanner *scanner, TSLexer *lexer) {
@@ -304,13 +543,13 @@ static bool scan_implicit_end_tag(Scanner *scanner, TSLexer *lexer) {
         }
     }
 
-    String tag_name = scan_html_tag_name(lexer);
+    String tag_name = scan_html_tag_name(scanner, lexer, NULL);
     if (tag_name.size == 0 && !lexer->eof(lexer)) {
         array_delete(&tag_name);
         return false;
     }
 
-    Tag next_tag = tag_for_name(tag_name);
+    Tag next_tag = scan_tag_for_name(scanner, tag_name);
 
     if (is_closing_tag) {
         // The tag correctly closes the topmost element on the stack
@@ -346,15 +585,60 @@ static bool scan_implicit_end_tag(Scanner *scanner, TSLexer *lexer) {
     return false;
 }
 
+// Whether the start tag being scanned ends with />, reading ahead of the
+// token through its attributes. A / in an unquoted value, as in src=a/>, is
+// part of the value.
+static bool start_tag_self_closes(TSLexer *lexer) {
+    int32_t quote = 0;
+    bool value = false, unquoted = false, slash = false;
+    while (!lexer->eof(lexer)) {
+        int32_t c = lexer->lookahead;
+        if (quote != 0) {
+            if (c == quote) {
+                quote = 0;
+            }
+        } else if (c == '>') {
+            return slash;
+        } else if (c == '<') {
+            return false;
+        } else if (iswspace(c)) {
+            unquoted = false;
+        } else if (value && (c == '"' || c == '\'')) {
+            quote = c;
+        } else if (value) {
+            unquoted = true;
+        }
+        slash = quote == 0 && !unquoted && c == '/';
+        value = quote == 0 && !unquoted && (c == '=' || (value && iswspace(c)));
+        advance(lexer);
+    }
+    return false;
+}
+
 static bool scan_start_tag_name(Scanner *scanner, TSLexer *lexer) {
-    String tag_name = scan_html_tag_name(lexer);
+    bool interpolated;
+    String tag_name = scan_html_tag_name(scanner, lexer, &interpolated);
     if (tag_name.size == 0) {
         array_delete(&tag_name);
         return false;
     }
 
-    Tag tag = tag_for_name(tag_name);
+    Tag tag = scan_tag_for_name(scanner, tag_name);
     array_push(&scanner->tags, tag);
+    if (interpolated) {
+        lexer->result_symbol = HTML_START_TAG_NAME_PREFIX;
+        return true;
+    }
+    // A self-closing <script/> or <style/> has no raw text to scan, and is
+    // an element like <div/>.
+    bool raw = tag.type == SCRIPT || tag.type == STYLE;
+#ifdef CUSTOM_RAW_TAGS
+    raw = raw || (tag.type == CUSTOM && is_custom_raw_tag(&tag.custom_tag_name));
+#endif
+    if (raw && start_tag_self_closes(lexer)) {
+        lexer->result_symbol = HTML_START_TAG_NAME;
+        return true;
+    }
     switch (tag.type) {
         case SCRIPT:
             lexer->result_symbol = HTML_SCRIPT_START_TAG_NAME;
@@ -375,8 +659,29 @@ static bool scan_start_tag_name(Scanner *scanner, TSLexer *lexer) {
     return true;
 }
 
+// Records an end tag in the current section that closes the innermost
+// element opened before it and not closed by the section yet, as </div> does
+// in <div>{{#a}}</div>{{/a}}. The end tag is erroneous, as the section must
+// hold whole elements, but the element then ends right after the section
+// rather than swallowing the rest of the document.
+static void close_outside_section(Scanner *scanner, const Tag *tag) {
+    if (scanner->mustache_tags.size == 0) {
+        return;
+    }
+    MustacheTag *section = array_back(&scanner->mustache_tags);
+    if (scanner->forced_end_tags > 0 && scanner->forced_end_tag_depth == section->html_tag_stack_size) {
+        scanner->forced_end_tags--;
+        return;
+    }
+    unsigned open = section->closed_html_tag_stack_size;
+    if (open > 0 && open <= scanner->tags.size && tag_eq(&scanner->tags.contents[open - 1], tag)) {
+        section->closed_html_tag_stack_size--;
+    }
+}
+
 static bool scan_end_tag_name(Scanner *scanner, TSLexer *lexer) {
-    String tag_name = scan_html_tag_name(lexer);
+    bool interpolated;
+    String tag_name = scan_html_tag_name(scanner, lexer, &interpolated);
 
     if (tag_name.size == 0) {
         array_delete(&tag_name);
@@ -384,7 +689,7 @@ static bool scan_end_tag_name(Scanner *scanner, TSLexer *lexer) {
     }
 
 
-    Tag tag = tag_for_name(tag_name);
+    Tag tag = scan_tag_for_name(scanner, tag_name);
     if (scanner->tags.size > 0 && tag_eq(array_back(&scanner->tags), &tag)) {
         // Don't close HTML tags that were opened before the current mustache section.
         // This prevents e.g. </div> inside {{#section}}...{{/section}} from closing
@@ -392,15 +697,17 @@ static bool scan_end_tag_name(Scanner *scanner, TSLexer *lexer) {
         if (scanner->mustache_tags.size > 0) {
             MustacheTag *current_mustache_tag = array_back(&scanner->mustache_tags);
             if (scanner->tags.size <= current_mustache_tag->html_tag_stack_size) {
-                lexer->result_symbol = HTML_ERRONEOUS_END_TAG_NAME;
+                close_outside_section(scanner, &tag);
+                lexer->result_symbol = interpolated ? HTML_ERRONEOUS_END_TAG_NAME_PREFIX : HTML_ERRONEOUS_END_TAG_NAME;
                 tag_free(&tag);
                 return true;
             }
         }
         pop_html_tag(scanner);
-        lexer->result_symbol = HTML_END_TAG_NAME;
+        lexer->result_symbol = interpolated ? HTML_END_TAG_NAME_PREFIX : HTML_END_TAG_NAME;
     } else {
-        lexer->result_symbol = HTML_ERRONEOUS_END_TAG_NAME;
+        close_outside_section(scanner, &tag);
+        lexer->result_symbol = interpolated ? HTML_ERRONEOUS_END_TAG_NAME_PREFIX : HTML_ERRONEOUS_END_TAG_NAME;
     }
 
     tag_free(&tag);
@@ -422,12 +729,28 @@ static bool scan_self_closing_tag_delimiter(Scanner *scanner, TSLexer *lexer) {
 
 static String scan_mustache_tag_name(Scanner *scanner, TSLexer *lexer) {
   String tag_name = array_new();
-  while (lexer->lookahead != '}' && !lexer->eof(lexer)) {
-    if (iswspace(lexer->lookahead))
+  int32_t close = close_delimiter_start(scanner);
+  lexer->mark_end(lexer);
+  while (lexer->lookahead != close && !lexer->eof(lexer)) {
+    // A `<` ends the name, so an unclosed {{#name before an HTML tag
+    // leaves the tag alone.
+    if (iswspace(lexer->lookahead) || lexer->lookahead == '<')
       break;
 
-    array_push(&tag_name, lexer->lookahead);
+    // So does the `~` of a Handlebars ~}}, which the grammar lexes.
+    if (lexer->lookahead == '~' && !has_custom_delimiters(scanner)) {
+      advance(lexer);
+      if (lexer->lookahead == close) {
+        break;
+      }
+      array_push(&tag_name, '~');
+      lexer->mark_end(lexer);
+      continue;
+    }
+
+    push_codepoint(&tag_name, lexer->lookahead);
     advance(lexer);
+    lexer->mark_end(lexer);
   }
   return tag_name;
 }
@@ -441,11 +764,20 @@ static bool scan_mustache_start_tag_name(Scanner *scanner, TSLexer *lexer) {
     MustacheTag tag = mustache_tag_new();
     tag.tag_name = tag_name;
     tag.html_tag_stack_size = scanner->tags.size;
+    tag.closed_html_tag_stack_size = scanner->tags.size;
     array_push(&scanner->mustache_tags, tag);
     lexer->result_symbol = MUSTACHE_START_TAG_NAME;
     return true;
 }
 
+// Frees the tag in place rather than copying it out with array_pop: gcc -O2
+// treats the copy's fields as uninitialized when they are read back through
+// the Array cast in array_delete, and frees a garbage pointer.
+static void pop_mustache_tag(Scanner *scanner) {
+    mustache_tag_free(array_back(&scanner->mustache_tags));
+    scanner->mustache_tags.size--;
+}
+
 static bool scan_mustache_end_tag_name(Scanner *scanner, TSLexer *lexer) {
   String tag_name = scan_mustache_tag_name(scanner, lexer);
 
@@ -458,13 +790,26 @@ static bool scan_mustache_end_tag_name(Scanner *scanner, TSLexer *lexer) {
   MustacheTag tag = mustache_tag_new();
   tag.tag_name = tag_name;
   if (scanner->mustache_tags.size > 0 && mustache_tag_eq(array_back(&scanner->mustache_tags), &tag)) {
-    MustacheTag popped_tag = array_pop(&scanner->mustache_tags);
-    mustache_tag_free(&popped_tag);
+    unsigned closed = array_back(&scanner->mustache_tags)->closed_html_tag_stack_size;
+    pop_mustache_tag(scanner);
+    // Elements the section closed that were opened in the enclosing section
+    // end after this one; those opened before it are closed by it in turn.
+    unsigned floor = 0;
+    if (scanner->mustache_tags.size > 0) {
+      MustacheTag *parent = array_back(&scanner->mustache_tags);
+      floor = parent->html_tag_stack_size;
+      if (closed < parent->closed_html_tag_stack_size) {
+        parent->closed_html_tag_stack_size = closed;
+      }
+    }
+    unsigned open = MAX(closed, floor);
+    if (scanner->tags.size > open) {
+      scanner->pending_implicit_end_tags = scanner->tags.size - open;
+    }
     lexer->result_symbol = MUSTACHE_END_TAG_NAME;
   } else {
     if (scanner->mustache_tags.size > 0) {
-      MustacheTag popped_tag = array_pop(&scanner->mustache_tags);
-      mustache_tag_free(&popped_tag);
+      pop_mustache_tag(scanner);
     }
     lexer->result_symbol = MUSTACHE_ERRONEOUS_END_TAG_NAME;
   }
@@ -473,19 +818,29 @@ static bool scan_mustache_end_tag_name(Scanner *scanner, TSLexer *lexer) {
   return true;
 }
 
-static bool scan_mustache_end_tag_html_implicit_end_tag(Scanner *scanner, TSLexer *lexer) {
-    lexer->mark_end(lexer);
-    if (lexer->lookahead != '{') {
-        return false;
-    }
-    advance(lexer);
-    if (lexer->lookahead != '{') {
-        return false;
+// Consumes a Handlebars `else` keyword, as in {{else}} or {{else if cond}}.
+static bool scan_else_keyword(TSLexer *lexer) {
+    while (iswspace(lexer->lookahead)) {
+        advance(lexer);
     }
-    advance(lexer);
-    if (lexer->lookahead != '/') {
-        return false;
+    const char *keyword = "else";
+    for (unsigned i = 0; keyword[i]; i++) {
+        if (lexer->lookahead != keyword[i]) {
+            return false;
+        }
+        advance(lexer);
     }
+    return iswspace(lexer->lookahead) || lexer->lookahead == '}' || lexer->lookahead == '~';
+}
+
+// Whether the innermost open element was opened in the innermost section or
+// conditional comment, or outside all of them, so that it may end.
+static bool element_is_innermost(const Scanner *scanner) {
+    unsigned floor = scanner->mustache_tags.size == 0 ? 0 : array_back(&scanner->mustache_tags)->html_tag_stack_size;
+    return scanner->tags.size > floor;
+}
+
+static bool scan_mustache_end_tag_html_implicit_end_tag(Scanner *scanner, TSLexer *lexer) {
     if (scanner->mustache_tags.size > 0) {
         MustacheTag *current_mustache_tag = array_back(&scanner->mustache_tags);
         if (scanner->tags.size > current_mustache_tag->html_tag_stack_size) {
@@ -495,7 +850,13 @@ static bool scan_mustache_end_tag_html_implicit_end_tag(Scanner *scanner, TSLexe
             if (tag_is_void(top_tag)) {
                 return false;
             }
+            uint8_t forced = scanner->forced_end_tags;
             pop_html_tag(scanner);
+            if (forced == 0 || scanner->tags.size > scanner->forced_end_tag_depth || forced == UINT8_MAX) {
+                forced = 0;
+            }
+            scanner->forced_end_tags = forced + 1;
+            scanner->forced_end_tag_depth = scanner->tags.size;
             lexer->result_symbol = MUSTACHE_END_TAG_HTML_IMPLICIT_END_TAG;
             return true;
         }
@@ -503,6 +864,469 @@ static bool scan_mustache_end_tag_html_implicit_end_tag(Scanner *scanner, TSLexe
     return false;
 }
 
+// The entry a conditional comment pushes onto mustache_tags, so that the
+// elements opened in it end with it, as they do with a section. Mustache tag
+// names cannot hold a `<`.
+static const char CONDITIONAL_COMMENT_TAG[] = "<![if]>";
+
+static bool in_conditional_comment(Scanner *scanner) {
+    if (scanner->mustache_tags.size == 0) {
+        return false;
+    }
+    const String *name = &array_back(&scanner->mustache_tags)->tag_name;
+    return name->size == sizeof(CONDITIONAL_COMMENT_TAG) - 1 &&
+           memcmp(name->contents, CONDITIONAL_COMMENT_TAG, name->size) == 0;
+}
+
+// Consumes the characters of literal for as long as the input matches them.
+static bool scan_literal(TSLexer *lexer, const char *literal) {
+    for (unsigned i = 0; literal[i]; i++) {
+        if (lexer->lookahead != literal[i]) {
+            return false;
+        }
+        advance(lexer);
+    }
+    return true;
+}
+
+// Lexes the <!--[if of a conditional comment once its <!-- has been
+// consumed. The grammar lexes the condition after it.
+static bool scan_conditional_comment_start(Scanner *scanner, TSLexer *lexer) {
+    if (!scan_literal(lexer, "[if") || !iswspace(lexer->lookahead)) {
+        return false;
+    }
+    lexer->mark_end(lexer);
+    MustacheTag tag = mustache_tag_new();
+    array_extend(&tag.tag_name, sizeof(CONDITIONAL_COMMENT_TAG) - 1, CONDITIONAL_COMMENT_TAG);
+    tag.html_tag_stack_size = scanner->tags.size;
+    tag.closed_html_tag_stack_size = scanner->tags.size;
+    array_push(&scanner->mustache_tags, tag);
+    lexer->result_symbol = HTML_CONDITIONAL_COMMENT_START;
+    return true;
+}
+
+// Lexes the [endif]--> that ends a conditional comment, after its <! or
+// <!--<!. Elements opened in the comment and still open end in front of it
+// first, like those left open in a section.
+static bool scan_conditional_comment_end(Scanner *scanner, TSLexer *lexer, const bool *valid_symbols) {
+    if (!scan_literal(lexer, "[endif]-->")) {
+        return false;
+    }
+    if (valid_symbols[MUSTACHE_END_TAG_HTML_IMPLICIT_END_TAG] && scan_mustache_end_tag_html_implicit_end_tag(scanner, lexer)) {
+        return true;
+    }
+    if (!valid_symbols[HTML_CONDITIONAL_COMMENT_END]) {
+        return false;
+    }
+    lexer->mark_end(lexer);
+    pop_mustache_tag(scanner);
+    lexer->result_symbol = HTML_CONDITIONAL_COMMENT_END;
+    return true;
+}
+
+// Lexes what follows a <!: an html_comment or, in a conditional comment,
+// the <!--> that reveals its content to other clients and the <![endif]-->
+// or <!--<![endif]--> that ends it.
+static bool scan_comment(Scanner *scanner, TSLexer *lexer, const bool *valid_symbols) {
+    bool conditional_end = in_conditional_comment(scanner) && (valid_symbols[HTML_CONDITIONAL_COMMENT_END] ||
+                                                               valid_symbols[MUSTACHE_END_TAG_HTML_IMPLICIT_END_TAG]);
+    if (lexer->lookahead == '[') {
+        return conditional_end && scan_conditional_comment_end(scanner, lexer, valid_symbols);
+    }
+    if (!scan_literal(lexer, "--")) {
+        return false;
+    }
+
+    unsigned dashes = 0;
+    switch (lexer->lookahead) {
+        case '[':
+            if (valid_symbols[HTML_CONDITIONAL_COMMENT_START] && scan_conditional_comment_start(scanner, lexer)) {
+                return true;
+            }
+            break;
+        case '<':
+            if (conditional_end) {
+                advance(lexer);
+                if (lexer->lookahead == '!') {
+                    advance(lexer);
+                    if (scan_conditional_comment_end(scanner, lexer, valid_symbols)) {
+                        return true;
+                    }
+                }
+            }
+            break;
+        case '>':
+        case '-':
+            // <!--> or, as some email templates spell it, <!---->.
+            if (valid_symbols[HTML_CONDITIONAL_COMMENT_REVEAL]) {
+                while (lexer->lookahead == '-' && dashes < 2) {
+                    ++dashes;
+                    advance(lexer);
+                }
+                if (lexer->lookahead == '>' && dashes != 1) {
+                    advance(lexer);
+                    lexer->mark_end(lexer);
+                    lexer->result_symbol = HTML_CONDITIONAL_COMMENT_REVEAL;
+                    return true;
+                }
+            }
+            break;
+    }
+    return scan_html_comment_rest(lexer, dashes);
+}
+
+static bool scan_raw_text(Scanner *scanner, TSLexer *lexer, const bool *valid_symbols) {
+    if (scanner->tags.size == 0) {
+        return false;
+    }
+
+    lexer->mark_end(lexer);
+
+    Tag *tag = array_back(&scanner->tags);
+
+#ifdef CUSTOM_RAW_TAGS
+    char end_delimiter_buf[256];
+    const char *end_delimiter;
+    if (tag->type == TEXTAREA) {
+        end_delimiter = "</TEXTAREA";
+    } else if (tag->type == SCRIPT) {
+        end_delimiter = "</SCRIPT";
+    } else if (tag->type == STYLE) {
+        end_delimiter = "</STYLE";
+    } else if (tag->type == CUSTOM && is_custom_raw_tag(&tag->custom_tag_name)) {
+        end_delimiter_buf[0] = '<';
+        end_delimiter_buf[1] = '/';
+        unsigned len = tag->custom_tag_name.size;
+        if (len > sizeof(end_delimiter_buf) - 3) {
+            len = sizeof(end_delimiter_buf) - 3;
+        }
+        memcpy(end_delimiter_buf + 2, tag->custom_tag_name.contents, len);
+        end_delimiter_buf[2 + len] = '\0';
+        end_delimiter = end_delimiter_buf;
+    } else {
+        return false;
+    }
+#else
+    const char *end_delimiter;
+    if (tag->type == TEXTAREA) {
+        end_delimiter = "</TEXTAREA";
+    } else if (tag->type == SCRIPT) {
+        end_delimiter = "</SCRIPT";
+    } else if (tag->type == STYLE) {
+        end_delimiter = "</STYLE";
+    } else {
+        return false;
+    }
+#endif
+
+    // The text ends in front of each mustache tag, which follows as its own
+    // node. Section and set delimiter tags are text. Script and style text
+    // is split where their element may also end implicitly, so a parser
+    // generated from a grammar that kept it as one token still gets one.
+    bool split = tag->type == TEXTAREA || valid_symbols[HTML_IMPLICIT_END_TAG];
+    int32_t open_start = has_custom_delimiters(scanner) ? (unsigned char)scanner->open_delimiter.contents[0] : '{';
+    unsigned delimiter_index = 0;
+    while (!lexer->eof(lexer)) {
+        if (split && delimiter_index == 0 && lexer->lookahead == open_start && open_start != '<') {
+            if (scan_open_delimiter(scanner, lexer, 0) && lexer->lookahead != '#' && lexer->lookahead != '^' &&
+                lexer->lookahead != '/' && lexer->lookahead != '=') {
+                break;
+            }
+            lexer->mark_end(lexer);
+            continue;
+        }
+        if (towupper(lexer->lookahead) == end_delimiter[delimiter_index]) {
+            delimiter_index++;
+            if (delimiter_index == strlen(end_delimiter)) {
+                break;
+            }
+            advance(lexer);
+        } else {
+            delimiter_index = 0;
+            advance(lexer);
+            lexer->mark_end(lexer);
+        }
+    }
+
+    lexer->result_symbol = HTML_RAW_TEXT;
+    return true;
+}
+
+// Called with the lexer right after an open delimiter (the default `{{` or a
+// custom one). `mark_end` was called before the delimiter, so zero-width
+// tokens returned here end in front of it.
+// {{!-- opens a comment that runs to the next --}}, even across }}. Without a
+// closing --}} it is an ordinary {{! comment, lexed by the grammar.
+static bool scan_long_comment_open(TSLexer *lexer, const bool *valid_symbols) {
+    for (unsigned i = 0; i < 3; i++) {
+        if (lexer->lookahead != "!--"[i]) {
+            return false;
+        }
+        advance(lexer);
+    }
+    if (!valid_symbols[MUSTACHE_LONG_COMMENT_OPEN]) {
+        return false;
+    }
+    lexer->mark_end(lexer);
+
+    unsigned dashes = 0;
+    while (!lexer->eof(lexer)) {
+        if (lexer->lookahead == '}' && dashes >= 2) {
+            advance(lexer);
+            if (lexer->lookahead == '}') {
+                lexer->result_symbol = MUSTACHE_LONG_COMMENT_OPEN;
+                return true;
+            }
+            dashes = 0;
+            continue;
+        }
+        dashes = lexer->lookahead == '-' ? dashes + 1 : 0;
+        advance(lexer);
+    }
+    return false;
+}
+
+static bool scan_mustache_open(Scanner *scanner, TSLexer *lexer, const bool *valid_symbols) {
+    // The `~` of a Handlebars {{~ only trims whitespace, so look past it to
+    // find the kind of tag. Tags other than {{~/name}} and {{~else}} are
+    // then lexed by the grammar.
+    bool trim = !has_custom_delimiters(scanner) && lexer->lookahead == '~';
+    if (trim) {
+        advance(lexer);
+    }
+
+    if (valid_symbols[MUSTACHE_END_TAG_HTML_IMPLICIT_END_TAG] && lexer->lookahead == '/' &&
+        scan_mustache_end_tag_html_implicit_end_tag(scanner, lexer)) {
+        return true;
+    }
+
+    // A void element ends in front of the tag.
+    if (valid_symbols[HTML_IMPLICIT_END_TAG] && scanner->tags.size > 0 && tag_is_void(array_back(&scanner->tags))) {
+        pop_html_tag(scanner);
+        lexer->result_symbol = HTML_IMPLICIT_END_TAG;
+        return true;
+    }
+
+    // {{else}} ends the HTML elements opened in its branch like {{/name}}
+    // does. The tag itself is lexed by the grammar.
+    if (!has_custom_delimiters(scanner) && (lexer->lookahead == 'e' || iswspace(lexer->lookahead))) {
+        return valid_symbols[MUSTACHE_END_TAG_HTML_IMPLICIT_END_TAG] && scan_else_keyword(lexer) &&
+               scan_mustache_end_tag_html_implicit_end_tag(scanner, lexer);
+    }
+
+    if (trim) {
+        return false;
+    }
+
+    if (lexer->lookahead == '!' && !has_custom_delimiters(scanner)) {
+        return scan_long_comment_open(lexer, valid_symbols);
+    }
+
+    if (lexer->lookahead == '=' && valid_symbols[MUSTACHE_SET_DELIMITER_START]) {
+        advance(lexer);
+        lexer->mark_end(lexer);
+        scanner->set_delimiter_state = SET_DELIMITER_EXPECT_OPEN;
+        lexer->result_symbol = MUSTACHE_SET_DELIMITER_START;
+        return true;
+    }
+
+    // With the default delimiters the remaining tags are lexed by the grammar.
+    if (!has_custom_delimiters(scanner)) {
+        return false;
+    }
+
+    enum TokenType symbol;
+    switch (lexer->lookahead) {
+        case '{': symbol = MUSTACHE_CUSTOM_TRIPLE_OPEN; break;
+        case '&': symbol = MUSTACHE_CUSTOM_AMPERSAND_OPEN; break;
+        case '#': symbol = MUSTACHE_CUSTOM_SECTION_OPEN; break;
+        case '^': symbol = MUSTACHE_CUSTOM_INVERTED_SECTION_OPEN; break;
+        case '/': symbol = MUSTACHE_CUSTOM_END_OPEN; break;
+        case '!': symbol = MUSTACHE_CUSTOM_COMMENT_OPEN; break;
+        case '>': symbol = MUSTACHE_CUSTOM_PARTIAL_OPEN; break;
+        default:
+            if (!valid_symbols[MUSTACHE_CUSTOM_OPEN]) {
+                return false;
+            }
+            lexer->mark_end(lexer);
+            lexer->result_symbol = MUSTACHE_CUSTOM_OPEN;
+            return true;
+    }
+    if (!valid_symbols[symbol]) {
+        return false;
+    }
+    advance(lexer);
+    lexer->mark_end(lexer);
+    lexer->result_symbol = symbol;
+    return true;
+}
+
+// Scans the delimiters and the closing `=}}` of a set delimiter tag. The new
+// delimiters take effect once the tag is closed with the old ones.
+static bool scan_set_delimiter(Scanner *scanner, TSLexer *lexer, const bool *valid_symbols) {
+    if (scanner->set_delimiter_state == SET_DELIMITER_EXPECT_END) {
+        if (!valid_symbols[MUSTACHE_SET_DELIMITER_END] || lexer->lookahead != '=') {
+            return false;
+        }
+        advance(lexer);
+        if (!scan_close_delimiter(scanner, lexer)) {
+            return false;
+        }
+        lexer->mark_end(lexer);
+
+        array_clear(&scanner->open_delimiter);
+        array_clear(&scanner->close_delimiter);
+        bool is_default =
+            scanner->pending_open_delimiter.size == 2 &&
+            memcmp(scanner->pending_open_delimiter.contents, "{{", 2) == 0 &&
+            scanner->pending_close_delimiter.size == 2 &&
+            memcmp(scanner->pending_close_delimiter.contents, "}}", 2) == 0;
+        if (!is_default) {
+            array_push_all(&scanner->open_delimiter, &scanner->pending_open_delimiter);
+            array_push_all(&scanner->close_delimiter, &scanner->pending_close_delimiter);
+        }
+        array_clear(&scanner->pending_open_delimiter);
+        array_clear(&scanner->pending_close_delimiter);
+        scanner->set_delimiter_state = SET_DELIMITER_NONE;
+        lexer->result_symbol = MUSTACHE_SET_DELIMITER_END;
+        return true;
+    }
+
+    if (!valid_symbols[MUSTACHE_DELIMITER]) {
+        return false;
+    }
+    String *delimiter = scanner->set_delimiter_state == SET_DELIMITER_EXPECT_OPEN
+        ? &scanner->pending_open_delimiter
+        : &scanner->pending_close_delimiter;
+    array_clear(delimiter);
+    // Delimiters may not contain whitespace or the equals sign. They are
+    // matched a byte at a time, so they must also be ASCII.
+    while (!lexer->eof(lexer) && !iswspace(lexer->lookahead) && lexer->lookahead != '=' &&
+           delimiter->size < UINT8_MAX) {
+        if (lexer->lookahead < 0 || lexer->lookahead > 0x7f) {
+            return false;
+        }
+        array_push(delimiter, (char)lexer->lookahead);
+        advance(lexer);
+    }
+    if (delimiter->size == 0) {
+        return false;
+    }
+    lexer->mark_end(lexer);
+    scanner->set_delimiter_state++;
+    lexer->result_symbol = MUSTACHE_DELIMITER;
+    return true;
+}
+
+// Comment and partial content up to the custom close delimiter.
+static bool scan_custom_content(Scanner *scanner, TSLexer *lexer) {
+    const String *close = &scanner->close_delimiter;
+    bool has_content = false;
+    while (!lexer->eof(lexer)) {
+        if (lexer->lookahead == (unsigned char)close->contents[0]) {
+            lexer->mark_end(lexer);
+            if (scan_delimiter(lexer, close->contents, close->size, 0)) {
+                break;
+            }
+            has_content = true;
+            continue;
+        }
+        advance(lexer);
+        has_content = true;
+        lexer->mark_end(lexer);
+    }
+    if (!has_content) {
+        return false;
+    }
+    lexer->result_symbol = MUSTACHE_CUSTOM_CONTENT;
+    return true;
+}
+
+// Text while custom delimiters are active. The grammar's text token stops at
+// braces (and anything could be a delimiter), so the scanner takes over text
+// up to the next open delimiter. `{{` and `}}` are plain text in this mode.
+static bool scan_custom_text(Scanner *scanner, TSLexer *lexer, bool has_text) {
+    const String *open = &scanner->open_delimiter;
+    while (!lexer->eof(lexer) && lexer->lookahead != '<' && lexer->lookahead != '&') {
+        if (lexer->lookahead == (unsigned char)open->contents[0]) {
+            if (scan_delimiter(lexer, open->contents, open->size, 0)) {
+                break;
+            }
+            has_text = true;
+            lexer->mark_end(lexer);
+            continue;
+        }
+        bool is_space = iswspace(lexer->lookahead);
+        advance(lexer);
+        if (!is_space) {
+            has_text = true;
+            lexer->mark_end(lexer);
+        }
+    }
+    if (!has_text) {
+        return false;
+    }
+    lexer->result_symbol = MUSTACHE_CUSTOM_TEXT;
+    return true;
+}
+
+// Text in a quoted attribute value, up to the quote that opened it or a
+// brace, and for value text up to an entity too. Comment and partial content
+// keeps its `&`. An open delimiter ends the text like a brace does, and one
+// at the start of the token is scanned as one.
+static bool scan_attribute_text(Scanner *scanner, TSLexer *lexer, const bool *valid_symbols) {
+    bool text = valid_symbols[HTML_ATTRIBUTE_TEXT];
+    const String *open = &scanner->open_delimiter;
+    bool has_text = false;
+    while (!lexer->eof(lexer)) {
+        int32_t c = lexer->lookahead;
+        if (c == scanner->attribute_quote || c == '{' || c == '}' || (text && c == '&')) {
+            break;
+        }
+        if (has_custom_delimiters(scanner) && c == (unsigned char)open->contents[0]) {
+            lexer->mark_end(lexer);
+            if (scan_delimiter(lexer, open->contents, open->size, 0)) {
+                if (!has_text) {
+                    return scan_mustache_open(scanner, lexer, valid_symbols);
+                }
+                lexer->result_symbol = text ? HTML_ATTRIBUTE_TEXT : HTML_ATTRIBUTE_MUSTACHE_CONTENT;
+                return true;
+            }
+            has_text = true;
+            continue;
+        }
+        advance(lexer);
+        has_text = true;
+    }
+    lexer->mark_end(lexer);
+    if (!has_text) {
+        return false;
+    }
+    lexer->result_symbol = text ? HTML_ATTRIBUTE_TEXT : HTML_ATTRIBUTE_MUSTACHE_CONTENT;
+    return true;
+}
+
+// The opening line of frontmatter, three dashes (YAML) or pluses (TOML).
+// The grammar only allows it at the start of the document.
+static bool scan_frontmatter_start(TSLexer *lexer) {
+    int32_t c = lexer->lookahead;
+    for (int i = 0; i < 3; i++) {
+        if (lexer->lookahead != c) {
+            return false;
+        }
+        advance(lexer);
+    }
+    if (lexer->lookahead == '\r') {
+        advance(lexer);
+    }
+    if (lexer->lookahead != '\n') {
+        return false;
+    }
+    advance(lexer);
+    lexer->result_symbol = c == '-' ? FRONTMATTER_YAML_START : FRONTMATTER_TOML_START;
+    return true;
+}
+
 static bool scan(Scanner *scanner, TSLexer *lexer, const bool *valid_symbols) {
     // During error recovery, tree-sitter sets all valid_symbols to true.
     // Bail out to avoid corrupting the tag stacks with garbage state.
@@ -510,14 +1334,74 @@ static bool scan(Scanner *scanner, TSLexer *lexer, const bool *valid_symbols) {
         return false;
     }
 
-    if (valid_symbols[HTML_RAW_TEXT] && !valid_symbols[HTML_START_TAG_NAME] && !valid_symbols[HTML_END_TAG_NAME]) {
-        return scan_raw_text(scanner, lexer);
+    // Element and conditional comment content share their parse states, so
+    // the tokens that end an element and the end of a conditional comment
+    // are valid in both. An element may only end when it is innermost,
+    // opened in the current section or conditional comment.
+    bool valid[HTML_ATTRIBUTE_MUSTACHE_CONTENT + 1];
+    memcpy(valid, valid_symbols, sizeof(valid));
+    bool element = element_is_innermost(scanner);
+    valid[HTML_IMPLICIT_END_TAG] = valid[HTML_IMPLICIT_END_TAG] && element;
+    valid[MUSTACHE_END_TAG_HTML_IMPLICIT_END_TAG] = valid[MUSTACHE_END_TAG_HTML_IMPLICIT_END_TAG] && element;
+    valid[HTML_CONDITIONAL_COMMENT_END] = valid[HTML_CONDITIONAL_COMMENT_END] && !element;
+    valid_symbols = valid;
+
+    // Attribute text keeps its leading whitespace.
+    if (valid_symbols[HTML_ATTRIBUTE_TEXT] || valid_symbols[HTML_ATTRIBUTE_MUSTACHE_CONTENT]) {
+        if (scan_attribute_text(scanner, lexer, valid_symbols)) {
+            return true;
+        }
+    }
+
+    // The rest of a tag name follows what came before it without spaces. An
+    // open delimiter that starts no interpolation has been consumed in
+    // part, so it is not scanned again below.
+    if ((valid_symbols[HTML_TAG_NAME_PART] || valid_symbols[HTML_TAG_NAME_OPEN]) && !iswspace(lexer->lookahead)) {
+        int32_t open_start = has_custom_delimiters(scanner) ? (unsigned char)scanner->open_delimiter.contents[0] : '{';
+        if (is_tag_name_char(lexer->lookahead) || (lexer->lookahead == open_start && open_start != '<')) {
+            return scan_tag_name_rest(scanner, lexer, valid_symbols);
+        }
+    }
+
+    // Raw text is valid after any start tag, for <textarea>; other elements
+    // go on to the tokens below.
+    if (valid_symbols[HTML_RAW_TEXT] && !valid_symbols[HTML_START_TAG_NAME] && !valid_symbols[HTML_END_TAG_NAME] &&
+        scan_raw_text(scanner, lexer, valid_symbols)) {
+        return true;
+    }
+
+    // Content keeps its leading whitespace, as it does with the default
+    // delimiters, so that {{=<% %>=}}<%! %> is a comment.
+    if (valid_symbols[MUSTACHE_CUSTOM_CONTENT] && has_custom_delimiters(scanner)) {
+        return scan_custom_content(scanner, lexer);
     }
 
     while (iswspace(lexer->lookahead)) {
         skip(lexer);
     }
 
+    if ((valid_symbols[HTML_ATTRIBUTE_SINGLE_QUOTE] && lexer->lookahead == '\'') ||
+        (valid_symbols[HTML_ATTRIBUTE_DOUBLE_QUOTE] && lexer->lookahead == '"')) {
+        scanner->attribute_quote = (uint8_t)lexer->lookahead;
+        lexer->result_symbol = lexer->lookahead == '"' ? HTML_ATTRIBUTE_DOUBLE_QUOTE : HTML_ATTRIBUTE_SINGLE_QUOTE;
+        advance(lexer);
+        return true;
+    }
+
+    if ((valid_symbols[FRONTMATTER_YAML_START] && lexer->lookahead == '-') ||
+        (valid_symbols[FRONTMATTER_TOML_START] && lexer->lookahead == '+')) {
+        return scan_frontmatter_start(lexer);
+    }
+
+    if (scanner->set_delimiter_state != SET_DELIMITER_NONE) {
+        if (valid_symbols[MUSTACHE_DELIMITER] || valid_symbols[MUSTACHE_SET_DELIMITER_END]) {
+            return scan_set_delimiter(scanner, lexer, valid_symbols);
+        }
+        // The parser recovered from a malformed set delimiter tag.
+        scanner->set_delimiter_state = SET_DELIMITER_NONE;
+        array_clear(&scanner->pending_open_delimiter);
+        array_clear(&scanner->pending_close_delimiter);
+    }
 
     if (valid_symbols[MUSTACHE_START_TAG_NAME]) {
         return scan_mustache_start_tag_name(scanner, lexer);
@@ -527,11 +1411,58 @@ static bool scan(Scanner *scanner, TSLexer *lexer, const bool *valid_symbols) {
         return scan_mustache_end_tag_name(scanner, lexer);
     }
 
-    if (valid_symbols[MUSTACHE_END_TAG_HTML_IMPLICIT_END_TAG]) {
-        if (scan_mustache_end_tag_html_implicit_end_tag(scanner, lexer)) {
+    if (scanner->pending_implicit_end_tags > 0 && valid_symbols[HTML_IMPLICIT_END_TAG] && scanner->tags.size > 0) {
+        scanner->pending_implicit_end_tags--;
+        pop_html_tag(scanner);
+        lexer->result_symbol = HTML_IMPLICIT_END_TAG;
+        return true;
+    }
+
+    bool custom_delimiters = has_custom_delimiters(scanner);
+    if (custom_delimiters) {
+        if (valid_symbols[MUSTACHE_CUSTOM_TRIPLE_CLOSE] && lexer->lookahead == '}') {
+            advance(lexer);
+            if (!scan_close_delimiter(scanner, lexer)) {
+                return false;
+            }
+            lexer->result_symbol = MUSTACHE_CUSTOM_TRIPLE_CLOSE;
+            return true;
+        }
+        if (valid_symbols[MUSTACHE_CUSTOM_CLOSE] && lexer->lookahead == close_delimiter_start(scanner)) {
+            if (!scan_close_delimiter(scanner, lexer)) {
+                return false;
+            }
+            lexer->result_symbol = MUSTACHE_CUSTOM_CLOSE;
+            return true;
+        }
+    }
+
+    // Open delimiters starting with `<` are handled with HTML tags below.
+    int32_t open_start = custom_delimiters ? (unsigned char)scanner->open_delimiter.contents[0] : '{';
+    // A tag name may start with an interpolation, as in <{{tag}}>.
+    bool tag_name_valid = (valid_symbols[HTML_START_TAG_NAME] || valid_symbols[HTML_END_TAG_NAME] ||
+                           valid_symbols[HTML_ERRONEOUS_END_TAG_NAME]) &&
+                          !valid_symbols[HTML_RAW_TEXT];
+    bool has_text = false;
+    if (lexer->lookahead == open_start && open_start != '<' && !tag_name_valid) {
+        lexer->mark_end(lexer);
+        if (scan_open_delimiter(scanner, lexer, 0)) {
+            return scan_mustache_open(scanner, lexer, valid_symbols);
+        }
+        if (custom_delimiters) {
+            // What was consumed of a partial delimiter is text.
+            has_text = true;
+        }
+    }
+
+    if (custom_delimiters && valid_symbols[MUSTACHE_CUSTOM_TEXT] && lexer->lookahead != '<' &&
+        lexer->lookahead != '&' && (has_text || lexer->lookahead != open_start)) {
+        if (!has_text) {
+            lexer->mark_end(lexer);
+        }
+        if (scan_custom_text(scanner, lexer, has_text)) {
             return true;
         }
-        // Don't return false - continue to other checks
     }
 
     // Check for void element implicit end tag before other processing
@@ -545,22 +1476,30 @@ static bool scan(Scanner *scanner, TSLexer *lexer, const bool *valid_symbols) {
         }
     }
 
+    // Open elements end at the end of the input. A NUL character before it
+    // is content.
+    if (lexer->eof(lexer)) {
+        return valid_symbols[HTML_IMPLICIT_END_TAG] && scan_implicit_end_tag(scanner, lexer);
+    }
+
     switch (lexer->lookahead) {
         case '<':
             lexer->mark_end(lexer);
             advance(lexer);
 
-            if (lexer->lookahead == '!') {
-                advance(lexer);
-                return scan_html_comment(lexer);
+            if (open_start == '<' && (scanner->open_delimiter.size == 1 ||
+                                      lexer->lookahead == (unsigned char)scanner->open_delimiter.contents[1])) {
+                if (scan_open_delimiter(scanner, lexer, 1)) {
+                    return scan_mustache_open(scanner, lexer, valid_symbols);
+                }
+                return false;
             }
 
-            if (valid_symbols[HTML_IMPLICIT_END_TAG]) {
-                return scan_implicit_end_tag(scanner, lexer);
+            if (lexer->lookahead == '!') {
+                advance(lexer);
+                return scan_comment(scanner, lexer, valid_symbols);
             }
-            break;
 
-        case '\0':
             if (valid_symbols[HTML_IMPLICIT_END_TAG]) {
                 return scan_implicit_end_tag(scanner, lexer);
             }
@@ -614,5 +1553,9 @@ void tree_sitter_htmlmustache_external_scanner_destroy(void *payload) {
         mustache_tag_free(&scanner->mustache_tags.contents[i]);
     }
     array_delete(&scanner->mustache_tags);
+    array_delete(&scanner->open_delimiter);
+    array_delete(&scanner->close_delimiter);
+    array_delete(&scanner->pending_open_delimiter);
+    array_delete(&scanner->pending_close_delimiter);
     ts_free(scanner);
 }
@@ -325,6 +325,25 @@ static inline bool tag_is_void(const Tag *self) {
     return self->type < END_OF_VOID_TAGS;
 }
 
+static inline bool tag_is_custom(const Tag *self, const char *name) {
+    return self->type == CUSTOM && strlen(name) == self->custom_tag_name.size &&
+           memcmp(self->custom_tag_name.contents, name, self->custom_tag_name.size) == 0;
+}
+
+// SVG and MathML elements whose content is HTML again.
+static inline bool tag_is_html_integration_point(const Tag *self) {
+    static const char *names[] = {"FOREIGNOBJECT", "DESC", "MI", "MO", "MN", "MS", "MTEXT", "ANNOTATION-XML"};
+    if (self->type == TITLE) {
+        return true;
+    }
+    for (unsigned i = 0; i < sizeof(names) / sizeof(names[0]); i++) {
+        if (tag_is_custom(self, names[i])) {
+            return true;
+        }
+    }
+    return false;
+}
+
 static inline bool tag_eq(const Tag *self, const Tag *other) {
     if (self->type != other->type) return false;
     if (self->type == CUSTOM) {
@@ -377,6 +396,9 @@ static bool tag_can_contain(Tag *self, const Tag *other) {
         case OPTGROUP:
             return child != OPTGROUP;
 
+        case OPTION:
+            return child != OPTION && child != OPTGROUP;
+
         case TR:
             return child != TR;
 